@@ -0,0 +1,153 @@
+/*
+Package commands - NekoBlog backend server command line subcommands.
+This file is for search index rebuilding command.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package commands
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// Reindex 将所有公开博文分批写入搜索引擎以重建索引，每批确认写入后保存检查点，中断后再次执行将从检查点继续。
+// 指定 -target users 或 -target hashtags 时改为重建本地的用户或话题搜索索引，
+// 指定 -target autocomplete 时根据数据库和话题搜索索引重建自动补全的前缀索引。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//   - args：命令行参数，支持 -target、-batch、-rate 和 -reset
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func Reindex(logger *logrus.Logger, storeFactory *stores.Factory, args []string) error {
+	// 解析命令行参数
+	flags := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	target := flags.String("target", "posts", "重建的索引：posts、users、hashtags 或 autocomplete")
+	batchSize := flags.Int("batch", consts.SEARCH_REINDEX_DEFAULT_BATCH, "每批发送的博文数量")
+	rate := flags.Int("rate", 0, "每秒最多发送的博文数量，为 0 时不限速")
+	reset := flags.Bool("reset", false, "忽略检查点，从第一篇博文开始重建")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 || *batchSize > consts.SEARCH_REINDEX_MAX_BATCH {
+		return errors.New("batch is out of range")
+	}
+	if *rate < 0 {
+		return errors.New("rate must not be negative")
+	}
+
+	searchIndexStore := storeFactory.NewSearchIndexStore()
+	switch *target {
+	case "posts":
+	case "users":
+		started := time.Now()
+		indexed, err := searchIndexStore.RebuildUserSearchIndex(consts.SEARCH_DIRECTORY_REINDEX_BATCH)
+		if err != nil {
+			return err
+		}
+		logger.Infoln("用户搜索索引重建完成，共索引用户数:", indexed, "耗时:", time.Since(started).Round(time.Second))
+		return nil
+	case "hashtags":
+		started := time.Now()
+		indexed, err := searchIndexStore.RebuildHashtagSearchIndex(consts.SEARCH_DIRECTORY_REINDEX_BATCH)
+		if err != nil {
+			return err
+		}
+		logger.Infoln("话题搜索索引重建完成，共索引话题数:", indexed, "耗时:", time.Since(started).Round(time.Second))
+		return nil
+	case "autocomplete":
+		started := time.Now()
+		indexed, err := storeFactory.NewAutocompleteStore().RebuildAutocomplete(consts.SEARCH_DIRECTORY_REINDEX_BATCH)
+		if err != nil {
+			return err
+		}
+		logger.Infoln("自动补全索引重建完成，共索引条目数:", indexed, "耗时:", time.Since(started).Round(time.Second))
+		return nil
+	default:
+		return errors.New("target is invalid")
+	}
+
+	if *reset {
+		if err := searchIndexStore.ClearReindexCheckpoint(); err != nil {
+			return err
+		}
+	}
+	afterID, err := searchIndexStore.GetReindexCheckpoint()
+	if err != nil {
+		return err
+	}
+	if afterID != 0 {
+		logger.Infoln("从检查点继续重建搜索索引，上次完成的博文ID:", afterID)
+	}
+	total, err := searchIndexStore.CountPublicPostsAfter(afterID)
+	if err != nil {
+		return err
+	}
+	logger.Infoln("开始重建搜索索引，待索引博文数:", total)
+
+	var (
+		indexed int64
+		started = time.Now()
+	)
+	for {
+		batchStarted := time.Now()
+		posts, err := searchIndexStore.GetPublicPostsAfter(afterID, *batchSize)
+		if err != nil {
+			return err
+		}
+		if len(posts) == 0 {
+			break
+		}
+
+		// 写入失败时检查点停留在上一批，重新执行即可继续
+		if _, err := searchIndexStore.BulkIndexPosts(posts); err != nil {
+			return err
+		}
+		postIDs := make([]uint64, len(posts))
+		for index, post := range posts {
+			postIDs[index] = uint64(post.ID)
+		}
+		if err := searchIndexStore.EnqueueDeletedPosts(postIDs); err != nil {
+			return err
+		}
+		afterID = postIDs[len(postIDs)-1]
+		if err := searchIndexStore.SaveReindexCheckpoint(afterID); err != nil {
+			return err
+		}
+
+		// 报告进度，重建期间新发布的博文可能使进度超过总数
+		indexed += int64(len(posts))
+		progress := 100.0
+		if total > 0 && indexed < total {
+			progress = float64(indexed) * 100 / float64(total)
+		}
+		logger.Infof("重建搜索索引进度: %d/%d (%.1f%%)，检查点: %d", indexed, total, progress, afterID)
+
+		if len(posts) < *batchSize {
+			break
+		}
+
+		// 按限速等待，使平均发送速率不超过 rate
+		if *rate > 0 {
+			expected := time.Duration(len(posts)) * time.Second / time.Duration(*rate)
+			if elapsed := time.Since(batchStarted); elapsed < expected {
+				time.Sleep(expected - elapsed)
+			}
+		}
+	}
+
+	if err := searchIndexStore.ClearReindexCheckpoint(); err != nil {
+		return err
+	}
+	logger.Infoln("搜索索引重建完成，共索引博文数:", indexed, "耗时:", time.Since(started).Round(time.Second))
+	return nil
+}
@@ -53,10 +53,20 @@ type Config struct {
 	
 	// 搜索服务设置
 	SearchService struct {
-		Host string `toml:"host"`
-		Port int    `toml:"port"`
+		// 搜索引擎 grpc, embedded, auto，默认为 auto
+		Backend string `toml:"backend"`
+		Host    string `toml:"host"`
+		Port    int    `toml:"port"`
+		// 搜索服务 TLS 设置
+		TLS SearchServiceTLS `toml:"tls"`
 	} `toml:"search_service"`
 
+	// 敏感词过滤设置
+	SensitiveFilter struct {
+		// 敏感词库文件路径，为空时不过滤，文件修改后自动重新加载
+		Path string `toml:"path"`
+	} `toml:"sensitive_filter"`
+
 	// 压缩设置
 	Compress struct {
 		// 压缩等级
@@ -70,6 +80,20 @@ type Config struct {
 	} `toml:"env"`
 }
 
+// SearchServiceTLS 搜索服务 TLS 设置
+type SearchServiceTLS struct {
+	// 是否启用 TLS，默认为明文连接
+	Enable bool `toml:"enable"`
+	// 服务端 CA 证书路径，为空时使用系统根证书
+	CAFile string `toml:"ca_file"`
+	// 客户端证书路径，与 KeyFile 同时设置时启用 mTLS
+	CertFile string `toml:"cert_file"`
+	// 客户端私钥路径
+	KeyFile string `toml:"key_file"`
+	// 校验服务端证书时使用的主机名，为空时使用连接地址
+	ServerName string `toml:"server_name"`
+}
+
 // 配置文件对象工厂函数
 func NewConfig() (*Config, error) {
 	// 读取配置文件
@@ -0,0 +1,36 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for post analytics related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// REDIS_POST_VIEW_HLL 博文每日独立浏览者 HyperLogLog
+	REDIS_POST_VIEW_HLL = "POST:VIEWS"
+
+	// REDIS_POST_IMPRESSIONS 博文每日曝光数
+	REDIS_POST_IMPRESSIONS = "POST:IMPRESSIONS"
+
+	// REDIS_POST_ENGAGEMENTS 博文每日互动增量，用于还原某日结束时的累计互动数
+	REDIS_POST_ENGAGEMENTS = "POST:ENGAGEMENTS"
+
+	// REDIS_POST_ANALYTICS_ACTIVE 每日有浏览、曝光或互动的博文ID集合
+	REDIS_POST_ANALYTICS_ACTIVE = "POST:ANALYTICS:ACTIVE"
+
+	// POST_ANALYTICS_KEY_EXPIRE 每日统计缓存有效期（秒），须保证汇总任务能处理完前一天的数据
+	POST_ANALYTICS_KEY_EXPIRE = 3 * 24 * 60 * 60
+
+	// POST_ANALYTICS_DATE_LAYOUT 每日统计缓存键中的日期格式
+	POST_ANALYTICS_DATE_LAYOUT = "20060102"
+
+	// POST_ANALYTICS_ROLLUP_BATCH 每批汇总的博文数量
+	POST_ANALYTICS_ROLLUP_BATCH = 500
+
+	// POST_ANALYTICS_DEFAULT_DAYS 博文数据分析默认查询天数
+	POST_ANALYTICS_DEFAULT_DAYS = 30
+
+	// POST_ANALYTICS_MAX_DAYS 博文数据分析最大查询天数
+	POST_ANALYTICS_MAX_DAYS = 90
+)
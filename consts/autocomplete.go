@@ -0,0 +1,51 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for search autocomplete and history related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// REDIS_AUTOCOMPLETE_TITLE 博文标题前缀索引，完整键为 AUTOCOMPLETE:TITLE:<前缀>，成员为博文ID，得分为发布时间
+	REDIS_AUTOCOMPLETE_TITLE = "AUTOCOMPLETE:TITLE"
+
+	// REDIS_AUTOCOMPLETE_TITLE_POST 博文作者和标题哈希表，用于展示标题建议和移除博文的前缀索引
+	REDIS_AUTOCOMPLETE_TITLE_POST = "AUTOCOMPLETE:TITLE_POST"
+
+	// REDIS_AUTOCOMPLETE_HASHTAG 话题前缀索引，完整键为 AUTOCOMPLETE:HASHTAG:<前缀>，成员为话题，得分为使用次数
+	REDIS_AUTOCOMPLETE_HASHTAG = "AUTOCOMPLETE:HASHTAG"
+
+	// REDIS_AUTOCOMPLETE_USER 用户前缀索引，完整键为 AUTOCOMPLETE:USER:<前缀>，成员为用户ID
+	REDIS_AUTOCOMPLETE_USER = "AUTOCOMPLETE:USER"
+
+	// REDIS_AUTOCOMPLETE_USER_PROFILE 用户名和昵称哈希表，用于展示用户建议
+	REDIS_AUTOCOMPLETE_USER_PROFILE = "AUTOCOMPLETE:USER_PROFILE"
+
+	// REDIS_AUTOCOMPLETE_QUERY 热门搜索前缀索引，完整键为 AUTOCOMPLETE:QUERY:<前缀>，成员为搜索字符串，得分为搜索次数
+	REDIS_AUTOCOMPLETE_QUERY = "AUTOCOMPLETE:QUERY"
+
+	// REDIS_SEARCH_RECENT 用户最近搜索，完整键为 SEARCH:RECENT:<用户ID>，成员为搜索字符串，得分为搜索时间
+	REDIS_SEARCH_RECENT = "SEARCH:RECENT"
+
+	// AUTOCOMPLETE_MAX_PREFIX_LENGTH 建立前缀索引的最大前缀长度（字符）
+	AUTOCOMPLETE_MAX_PREFIX_LENGTH = 20
+
+	// AUTOCOMPLETE_PREFIX_CAPACITY 每个前缀保留的最大建议数量
+	AUTOCOMPLETE_PREFIX_CAPACITY = 50
+
+	// AUTOCOMPLETE_DEFAULT_LENGTH 每类建议的默认数量
+	AUTOCOMPLETE_DEFAULT_LENGTH = 5
+
+	// AUTOCOMPLETE_MAX_LENGTH 每类建议的最大数量
+	AUTOCOMPLETE_MAX_LENGTH = 10
+
+	// AUTOCOMPLETE_QUERY_MIN_COUNT 热门搜索出现在建议中所需的最少搜索次数，避免暴露个别用户的搜索内容
+	AUTOCOMPLETE_QUERY_MIN_COUNT = 3
+
+	// AUTOCOMPLETE_QUERY_MAX_LENGTH 记录搜索字符串的最大长度（字符）
+	AUTOCOMPLETE_QUERY_MAX_LENGTH = 50
+
+	// SEARCH_RECENT_MAX_LENGTH 每位用户保留的最近搜索数量
+	SEARCH_RECENT_MAX_LENGTH = 20
+)
@@ -23,4 +23,25 @@ const (
 
 	// NETWORK_ERROR 网络错误
 	NETWORK_ERROR serializers.ResponseCode = 4
+
+	// COMMENT_CLOSED_ERROR 博文已关闭评论
+	COMMENT_CLOSED_ERROR serializers.ResponseCode = 5
+
+	// COMMENT_FOLLOWERS_ONLY_ERROR 仅作者的关注者可评论
+	COMMENT_FOLLOWERS_ONLY_ERROR serializers.ResponseCode = 6
+
+	// COMMENT_MUTUALS_ONLY_ERROR 仅与作者互相关注的用户可评论
+	COMMENT_MUTUALS_ONLY_ERROR serializers.ResponseCode = 7
+
+	// PERMISSION_DENIED_ERROR 权限不足
+	PERMISSION_DENIED_ERROR serializers.ResponseCode = 8
+
+	// DUPLICATE_REPORT_ERROR 已举报过该内容
+	DUPLICATE_REPORT_ERROR serializers.ResponseCode = 9
+
+	// SENSITIVE_CONTENT_ERROR 内容包含禁止发布的敏感词
+	SENSITIVE_CONTENT_ERROR serializers.ResponseCode = 10
+
+	// ACCOUNT_SUSPENDED_ERROR 账号已被暂停或封禁
+	ACCOUNT_SUSPENDED_ERROR serializers.ResponseCode = 11
 )
@@ -0,0 +1,66 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for comment and reply related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// COMMENT_SORT_NEW 评论及回复按时间倒序排列
+	COMMENT_SORT_NEW = "new"
+
+	// COMMENT_SORT_OLD 评论及回复按时间正序排列
+	COMMENT_SORT_OLD = "old"
+
+	// COMMENT_SORT_TOP 评论及回复按点赞数排列
+	COMMENT_SORT_TOP = "top"
+
+	// COMMENT_SORT_HOT 评论及回复按威尔逊得分排列
+	COMMENT_SORT_HOT = "hot"
+
+	// COMMENT_LIST_DEFAULT_LENGTH 评论及回复列表默认分页长度
+	COMMENT_LIST_DEFAULT_LENGTH = 20
+
+	// COMMENT_LIST_MAX_LENGTH 评论及回复列表最大分页长度
+	COMMENT_LIST_MAX_LENGTH = 50
+
+	// COMMENT_TREE_DEFAULT_REPLY_LENGTH 评论树每层默认展开的回复数量
+	COMMENT_TREE_DEFAULT_REPLY_LENGTH = 3
+
+	// COMMENT_TREE_MAX_REPLY_LENGTH 评论树每层最多展开的回复数量
+	COMMENT_TREE_MAX_REPLY_LENGTH = 20
+
+	// COMMENT_TREE_DEFAULT_DEPTH 评论树默认展开的回复层数
+	COMMENT_TREE_DEFAULT_DEPTH = 2
+
+	// COMMENT_TREE_MAX_DEPTH 评论树最多展开的回复层数
+	COMMENT_TREE_MAX_DEPTH = 5
+
+	// COMMENT_PERMISSION_EVERYONE 所有人均可评论
+	COMMENT_PERMISSION_EVERYONE = "everyone"
+
+	// COMMENT_PERMISSION_FOLLOWERS 仅作者的关注者可评论
+	COMMENT_PERMISSION_FOLLOWERS = "followers"
+
+	// COMMENT_PERMISSION_MUTUALS 仅与作者互相关注的用户可评论
+	COMMENT_PERMISSION_MUTUALS = "mutuals"
+
+	// COMMENT_PERMISSION_CLOSED 关闭评论
+	COMMENT_PERMISSION_CLOSED = "closed"
+
+	// POST_PINNED_COMMENT_MAX 每篇博文最多置顶的评论数量
+	POST_PINNED_COMMENT_MAX = 3
+
+	// REDIS_COMMENT_SCORE_CACHE 评论排序得分缓存
+	REDIS_COMMENT_SCORE_CACHE = "COMMENT:SCORES"
+
+	// REDIS_REPLY_SCORE_CACHE 回复排序得分缓存
+	REDIS_REPLY_SCORE_CACHE = "REPLY:SCORES"
+
+	// SCORE_CACHE_EXPIRE 排序得分缓存有效期（秒）
+	SCORE_CACHE_EXPIRE = 30 * 60
+
+	// WILSON_SCORE_Z 威尔逊得分使用的置信水平对应的 z 值（95%）
+	WILSON_SCORE_Z = 1.96
+)
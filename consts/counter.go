@@ -0,0 +1,39 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for engagement counter related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// POST_COUNTER_LIKES 博文点赞计数字段
+	POST_COUNTER_LIKES = "likes"
+
+	// POST_COUNTER_FAVOURITES 博文收藏计数字段
+	POST_COUNTER_FAVOURITES = "favourites"
+
+	// POST_COUNTER_REPOSTS 博文转发计数字段
+	POST_COUNTER_REPOSTS = "reposts"
+
+	// POST_COUNTER_COMMENTS 博文评论计数字段
+	POST_COUNTER_COMMENTS = "comments"
+
+	// POST_COUNTER_VIEWS 博文浏览计数字段
+	POST_COUNTER_VIEWS = "views"
+
+	// REDIS_POST_COUNTER_CACHE 博文计数缓存
+	REDIS_POST_COUNTER_CACHE = "POST:COUNTERS"
+
+	// REDIS_POST_COUNTER_DIRTY_SET 待回写数据库的博文ID集合
+	REDIS_POST_COUNTER_DIRTY_SET = "POST:COUNTERS:DIRTY"
+
+	// POST_COUNTER_CACHE_EXPIRE 博文计数缓存有效期（秒），须远大于回写间隔
+	POST_COUNTER_CACHE_EXPIRE = 24 * 60 * 60
+
+	// POST_COUNTER_FLUSH_BATCH 每批回写数据库的博文数量
+	POST_COUNTER_FLUSH_BATCH = 500
+
+	// POST_COUNTER_RECONCILE_BATCH 每批对账的博文数量
+	POST_COUNTER_RECONCILE_BATCH = 500
+)
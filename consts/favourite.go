@@ -0,0 +1,33 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for favourite folder related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// FAVOURITE_DEFAULT_FOLDER_ID 默认收藏夹ID，未指定收藏夹的收藏均归入默认收藏夹
+	FAVOURITE_DEFAULT_FOLDER_ID = 0
+
+	// FAVOURITE_DEFAULT_FOLDER_NAME 默认收藏夹名称
+	FAVOURITE_DEFAULT_FOLDER_NAME = "默认收藏夹"
+
+	// FAVOURITE_FOLDER_NAME_MAX_LENGTH 收藏夹名称最大长度
+	FAVOURITE_FOLDER_NAME_MAX_LENGTH = 32
+
+	// FAVOURITE_FOLDER_DESCRIPTION_MAX_LENGTH 收藏夹描述最大长度
+	FAVOURITE_FOLDER_DESCRIPTION_MAX_LENGTH = 200
+
+	// FAVOURITE_FOLDER_MAX_COUNT_PER_USER 每个用户最多可创建的收藏夹数量
+	FAVOURITE_FOLDER_MAX_COUNT_PER_USER = 50
+
+	// FAVOURITE_NOTE_MAX_LENGTH 收藏备注最大长度
+	FAVOURITE_NOTE_MAX_LENGTH = 500
+
+	// FAVOURITE_LIST_DEFAULT_LENGTH 收藏列表默认获取数量
+	FAVOURITE_LIST_DEFAULT_LENGTH = 10
+
+	// FAVOURITE_LIST_MAX_LENGTH 收藏列表单次最大获取数量
+	FAVOURITE_LIST_MAX_LENGTH = 50
+)
@@ -0,0 +1,15 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for follow related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// FOLLOW_LIST_DEFAULT_LENGTH 关注列表默认分页长度
+	FOLLOW_LIST_DEFAULT_LENGTH = 20
+
+	// FOLLOW_LIST_MAX_LENGTH 关注列表最大分页长度
+	FOLLOW_LIST_MAX_LENGTH = 50
+)
@@ -0,0 +1,12 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for batch hydration related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// BATCH_HYDRATION_MAX_IDS 批量获取接口单次最多接受的ID数量
+	BATCH_HYDRATION_MAX_IDS = 100
+)
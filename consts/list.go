@@ -0,0 +1,24 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for user list related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// USER_LIST_NAME_MAX_LENGTH 列表名称最大长度
+	USER_LIST_NAME_MAX_LENGTH = 32
+
+	// USER_LIST_DESCRIPTION_MAX_LENGTH 列表描述最大长度
+	USER_LIST_DESCRIPTION_MAX_LENGTH = 200
+
+	// USER_LIST_MAX_COUNT_PER_USER 每个用户最多可创建的列表数量
+	USER_LIST_MAX_COUNT_PER_USER = 100
+
+	// USER_LIST_MAX_MEMBERS 每个列表最多可包含的成员数量
+	USER_LIST_MAX_MEMBERS = 500
+
+	// USER_LIST_TIMELINE_MAX_LENGTH 列表时间线单次最大获取数量
+	USER_LIST_TIMELINE_MAX_LENGTH = 10
+)
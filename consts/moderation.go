@@ -0,0 +1,124 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for report and moderation related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// AUTHORITY_USER 普通用户权限等级
+	AUTHORITY_USER = 0
+
+	// AUTHORITY_MODERATOR 版主权限等级，可处理举报、隐藏和删除内容、警告用户
+	AUTHORITY_MODERATOR = 1
+
+	// AUTHORITY_ADMIN 管理员权限等级，可额外暂停、封禁和限流用户
+	AUTHORITY_ADMIN = 2
+)
+
+const (
+	// REPORT_TARGET_POST 举报博文
+	REPORT_TARGET_POST = "post"
+
+	// REPORT_TARGET_COMMENT 举报评论
+	REPORT_TARGET_COMMENT = "comment"
+
+	// REPORT_TARGET_REPLY 举报回复
+	REPORT_TARGET_REPLY = "reply"
+
+	// REPORT_TARGET_USER 举报用户
+	REPORT_TARGET_USER = "user"
+
+	// REPORT_REASON_SPAM 垃圾广告
+	REPORT_REASON_SPAM = "spam"
+
+	// REPORT_REASON_MISINFORMATION 不实信息
+	REPORT_REASON_MISINFORMATION = "misinformation"
+
+	// REPORT_REASON_HARASSMENT 骚扰或人身攻击
+	REPORT_REASON_HARASSMENT = "harassment"
+
+	// REPORT_REASON_HATE 仇恨言论
+	REPORT_REASON_HATE = "hate"
+
+	// REPORT_REASON_SEXUAL 色情内容
+	REPORT_REASON_SEXUAL = "sexual"
+
+	// REPORT_REASON_VIOLENCE 暴力内容
+	REPORT_REASON_VIOLENCE = "violence"
+
+	// REPORT_REASON_ILLEGAL 违法内容
+	REPORT_REASON_ILLEGAL = "illegal"
+
+	// REPORT_REASON_OTHER 其他原因
+	REPORT_REASON_OTHER = "other"
+
+	// REPORT_REASON_SENSITIVE 命中待审核敏感词，由系统生成，用户不能使用
+	REPORT_REASON_SENSITIVE = "sensitive"
+
+	// REPORT_DETAIL_MAX_LENGTH 举报补充说明的最大字符数
+	REPORT_DETAIL_MAX_LENGTH = 500
+)
+
+const (
+	// MODERATION_CASE_PENDING 待处理的审核工单
+	MODERATION_CASE_PENDING = "pending"
+
+	// MODERATION_CASE_RESOLVED 已处理的审核工单
+	MODERATION_CASE_RESOLVED = "resolved"
+
+	// MODERATION_CASE_DISMISSED 已驳回的审核工单
+	MODERATION_CASE_DISMISSED = "dismissed"
+
+	// MODERATION_ACTION_DISMISS 驳回举报
+	MODERATION_ACTION_DISMISS = "dismiss"
+
+	// MODERATION_ACTION_HIDE 隐藏内容
+	MODERATION_ACTION_HIDE = "hide"
+
+	// MODERATION_ACTION_RESTORE 恢复已隐藏的内容
+	MODERATION_ACTION_RESTORE = "restore"
+
+	// MODERATION_ACTION_DELETE 删除内容
+	MODERATION_ACTION_DELETE = "delete"
+
+	// MODERATION_ACTION_WARN 警告内容作者
+	MODERATION_ACTION_WARN = "warn"
+
+	// MODERATION_ACTION_SUSPEND 在一段时间内暂停内容作者的账号，期间无法登录
+	MODERATION_ACTION_SUSPEND = "suspend"
+
+	// MODERATION_ACTION_BAN 永久封禁内容作者的账号
+	MODERATION_ACTION_BAN = "ban"
+
+	// MODERATION_ACTION_SHADOWBAN 限流内容作者，其内容仅自己可见，未指定时长时永久有效
+	MODERATION_ACTION_SHADOWBAN = "shadowban"
+
+	// MODERATION_ACTION_LIFT 提前解除用户的暂停、封禁和限流，到期自动解除时操作人ID为 0
+	MODERATION_ACTION_LIFT = "lift"
+
+	// MODERATION_ACTION_HOLD 系统因敏感词暂扣内容待审核，操作人ID为 0
+	MODERATION_ACTION_HOLD = "hold"
+
+	// MODERATION_REASON_MAX_LENGTH 处理理由的最大字符数
+	MODERATION_REASON_MAX_LENGTH = 500
+
+	// MODERATION_SUSPEND_MAX_HOURS 暂停账号或限流的最长时长（小时）
+	MODERATION_SUSPEND_MAX_HOURS = 24 * 365
+
+	// MODERATION_SANCTION_LIFT_INTERVAL 自动解除到期处罚的间隔（秒）
+	MODERATION_SANCTION_LIFT_INTERVAL = 60
+
+	// MODERATION_SANCTION_LIFT_BATCH 每批自动解除的到期处罚数量
+	MODERATION_SANCTION_LIFT_BATCH = 100
+
+	// MODERATION_SANCTION_EXPIRED_REASON 到期自动解除处罚时的审核日志理由
+	MODERATION_SANCTION_EXPIRED_REASON = "expired"
+
+	// MODERATION_LIST_DEFAULT_LENGTH 审核队列、举报和日志列表默认分页长度
+	MODERATION_LIST_DEFAULT_LENGTH = 20
+
+	// MODERATION_LIST_MAX_LENGTH 审核队列、举报和日志列表最大分页长度
+	MODERATION_LIST_MAX_LENGTH = 100
+)
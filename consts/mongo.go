@@ -1,9 +1,16 @@
 package consts
 
 const (
-	MONGODB_DATABASE_NAME    = "neko"
-	POST_LIKE_COLLECTION     = "post_likes"
-	POST_FAVORITE_COLLECTION = "post_favourites"
-	COMMENT_RATE_COLLECTION  = "comment_rates"
-	FOLLOW_RECORD_COLLECTION = "follow_records"
+	MONGODB_DATABASE_NAME                = "neko"
+	POST_LIKE_COLLECTION                 = "post_likes"
+	POST_FAVORITE_COLLECTION             = "post_favourites"
+	COMMENT_RATE_COLLECTION              = "comment_rates"
+	REPLY_RATE_COLLECTION                = "reply_rates"
+	FOLLOW_RECORD_COLLECTION             = "follow_records"
+	FOLLOW_REQUEST_COLLECTION            = "follow_requests"
+	BLOCK_RECORD_COLLECTION              = "block_records"
+	MUTE_RECORD_COLLECTION               = "mute_records"
+	FOLLOW_SUGGESTION_DISMISS_COLLECTION = "follow_suggestion_dismissals"
+	USER_LIST_MEMBER_COLLECTION          = "user_list_members"
+	USER_LIST_SUBSCRIPTION_COLLECTION    = "user_list_subscriptions"
 )
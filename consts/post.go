@@ -14,3 +14,11 @@ const (
 	POST_IMAGE_HEIGHT_THRESHOLD = 1080
 	POST_IMAGE_QUALITY = 75
 )
+
+const (
+	// POST_LIST_DEFAULT_LENGTH 博文列表默认获取数量
+	POST_LIST_DEFAULT_LENGTH = 10
+
+	// POST_LIST_MAX_LENGTH 博文列表单次最大获取数量
+	POST_LIST_MAX_LENGTH = 10
+)
@@ -0,0 +1,134 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for search index related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// SEARCH_INDEX_OP_CREATE 创建博文索引操作
+	SEARCH_INDEX_OP_CREATE = "create"
+
+	// SEARCH_INDEX_OP_DELETE 删除博文索引操作
+	SEARCH_INDEX_OP_DELETE = "delete"
+
+	// SEARCH_INDEX_SYNC_INTERVAL 索引同步任务执行间隔（秒）
+	SEARCH_INDEX_SYNC_INTERVAL = 10
+
+	// SEARCH_INDEX_SYNC_BATCH 每批同步的索引操作数量
+	SEARCH_INDEX_SYNC_BATCH = 50
+
+	// SEARCH_INDEX_LEASE 索引操作被领取后的租约时长（秒），须大于一批操作全部超时的耗时
+	SEARCH_INDEX_LEASE = 300
+
+	// SEARCH_INDEX_RPC_TIMEOUT 单次索引请求超时时间（秒）
+	SEARCH_INDEX_RPC_TIMEOUT = 5
+
+	// SEARCH_INDEX_RETRY_BASE 索引操作失败后首次重试的等待时间（秒）
+	SEARCH_INDEX_RETRY_BASE = 5
+
+	// SEARCH_INDEX_RETRY_MAX 索引操作重试的最长等待时间（秒）
+	SEARCH_INDEX_RETRY_MAX = 30 * 60
+)
+
+const (
+	// REDIS_SEARCH_REINDEX_CHECKPOINT 全量重建索引的检查点，记录最后一篇已确认写入的博文ID
+	REDIS_SEARCH_REINDEX_CHECKPOINT = "SEARCH:REINDEX:CHECKPOINT"
+
+	// SEARCH_REINDEX_DEFAULT_BATCH 全量重建索引时每批发送的默认博文数量
+	SEARCH_REINDEX_DEFAULT_BATCH = 200
+
+	// SEARCH_REINDEX_MAX_BATCH 全量重建索引时每批发送的最大博文数量
+	SEARCH_REINDEX_MAX_BATCH = 1000
+
+	// SEARCH_REINDEX_RPC_TIMEOUT 全量重建索引时单批请求的超时时间（秒）
+	SEARCH_REINDEX_RPC_TIMEOUT = 60
+)
+
+const (
+	// SEARCH_TYPE_POST 搜索博文
+	SEARCH_TYPE_POST = "post"
+
+	// SEARCH_TYPE_USER 搜索用户
+	SEARCH_TYPE_USER = "user"
+
+	// SEARCH_TYPE_HASHTAG 搜索话题
+	SEARCH_TYPE_HASHTAG = "hashtag"
+
+	// SEARCH_SORT_RELEVANCE 按相关度排序搜索结果
+	SEARCH_SORT_RELEVANCE = "relevance"
+
+	// SEARCH_SORT_RECENCY 按发布时间倒序排序搜索结果
+	SEARCH_SORT_RECENCY = "recency"
+
+	// SEARCH_DEFAULT_LENGTH 搜索结果默认每页数量
+	SEARCH_DEFAULT_LENGTH = 10
+
+	// SEARCH_MAX_LENGTH 搜索结果每页最大数量
+	SEARCH_MAX_LENGTH = 50
+
+	// SEARCH_MAX_OFFSET 搜索结果允许的最大偏移量，避免深分页拖慢搜索引擎
+	SEARCH_MAX_OFFSET = 1000
+
+	// SEARCH_MAX_AUTHORS 单次搜索允许筛选的最大作者数量
+	SEARCH_MAX_AUTHORS = 20
+
+	// SEARCH_QUERY_RPC_TIMEOUT 单次搜索请求的超时时间（秒）
+	SEARCH_QUERY_RPC_TIMEOUT = 3
+
+	// SEARCH_DIRECTORY_REINDEX_BATCH 重建用户和话题搜索索引时每批处理的数量
+	SEARCH_DIRECTORY_REINDEX_BATCH = 500
+)
+
+const (
+	// SEARCH_ENGINE_GRPC 仅使用外部搜索服务
+	SEARCH_ENGINE_GRPC = "grpc"
+
+	// SEARCH_ENGINE_EMBEDDED 仅使用基于 PostgreSQL 全文检索的内置搜索引擎
+	SEARCH_ENGINE_EMBEDDED = "embedded"
+
+	// SEARCH_ENGINE_AUTO 优先使用外部搜索服务，不可用时自动切换到内置搜索引擎
+	SEARCH_ENGINE_AUTO = "auto"
+
+	// SEARCH_HIGHLIGHT_PRE_TAG 高亮片段中命中词的起始标签
+	SEARCH_HIGHLIGHT_PRE_TAG = "<em>"
+
+	// SEARCH_HIGHLIGHT_POST_TAG 高亮片段中命中词的结束标签
+	SEARCH_HIGHLIGHT_POST_TAG = "</em>"
+
+	// SEARCH_HIGHLIGHT_MAX_SNIPPETS 内置搜索引擎每个字段返回的最大高亮片段数量
+	SEARCH_HIGHLIGHT_MAX_SNIPPETS = 3
+
+	// SEARCH_HIGHLIGHT_CONTEXT 内置搜索引擎高亮片段中命中词前后保留的字符数
+	SEARCH_HIGHLIGHT_CONTEXT = 20
+)
+
+const (
+	// SEARCH_GRPC_CALL_TIMEOUT 单次调用外部搜索服务的超时时间（毫秒），重试时每次调用单独计时
+	SEARCH_GRPC_CALL_TIMEOUT = 1000
+
+	// SEARCH_GRPC_MAX_ATTEMPTS 幂等调用的最大尝试次数
+	SEARCH_GRPC_MAX_ATTEMPTS = 3
+
+	// SEARCH_GRPC_RETRY_BASE 幂等调用失败后首次重试的等待时间（毫秒）
+	SEARCH_GRPC_RETRY_BASE = 50
+
+	// SEARCH_GRPC_RETRY_MAX 幂等调用重试的最长等待时间（毫秒）
+	SEARCH_GRPC_RETRY_MAX = 400
+
+	// SEARCH_BREAKER_FAILURE_THRESHOLD 连续失败多少次后打开熔断器
+	SEARCH_BREAKER_FAILURE_THRESHOLD = 5
+
+	// SEARCH_BREAKER_OPEN_DURATION 熔断器打开后等待多久放行试探请求（秒）
+	SEARCH_BREAKER_OPEN_DURATION = 30
+
+	// SEARCH_HEALTH_CHECK_INTERVAL 搜索服务健康检查间隔（秒）
+	SEARCH_HEALTH_CHECK_INTERVAL = 10
+
+	// SEARCH_HEALTH_CHECK_TIMEOUT 搜索服务健康检查超时时间（秒）
+	SEARCH_HEALTH_CHECK_TIMEOUT = 2
+
+	// SEARCH_HEALTH_SERVICE 健康检查的服务名，为空表示检查整个服务端
+	SEARCH_HEALTH_SERVICE = ""
+)
@@ -0,0 +1,27 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for sensitive word filter related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// SENSITIVE_ACTION_REJECT 拒绝发布包含敏感词的内容
+	SENSITIVE_ACTION_REJECT = "reject"
+
+	// SENSITIVE_ACTION_MASK 将敏感词替换为 *
+	SENSITIVE_ACTION_MASK = "mask"
+
+	// SENSITIVE_ACTION_REVIEW 内容发布后隐藏，等待版主审核
+	SENSITIVE_ACTION_REVIEW = "review"
+
+	// SENSITIVE_MASK_RUNE 替换敏感词的字符
+	SENSITIVE_MASK_RUNE = '*'
+
+	// SENSITIVE_WORD_RELOAD_INTERVAL 检查敏感词库文件是否变化的间隔（秒）
+	SENSITIVE_WORD_RELOAD_INTERVAL = 30
+
+	// SENSITIVE_REVIEW_SEVERITY 命中待审核敏感词时生成的审核工单严重程度
+	SENSITIVE_REVIEW_SEVERITY = 3
+)
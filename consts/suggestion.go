@@ -0,0 +1,45 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for follow suggestion related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// REDIS_FOLLOW_SUGGESTION_CACHE 关注推荐缓存
+	REDIS_FOLLOW_SUGGESTION_CACHE = "FOLLOW:SUGGESTIONS"
+
+	// FOLLOW_SUGGESTION_CACHE_EXPIRE 关注推荐缓存有效期（秒）
+	FOLLOW_SUGGESTION_CACHE_EXPIRE = 24 * 60 * 60
+
+	// FOLLOW_SUGGESTION_CACHE_SIZE 每个用户缓存的推荐数量
+	FOLLOW_SUGGESTION_CACHE_SIZE = 50
+
+	// FOLLOW_SUGGESTION_DEFAULT_LENGTH 关注推荐默认返回数量
+	FOLLOW_SUGGESTION_DEFAULT_LENGTH = 10
+
+	// FOLLOW_SUGGESTION_ACTIVE_DAYS 预计算推荐时活跃用户的判定天数
+	FOLLOW_SUGGESTION_ACTIVE_DAYS = 7
+
+	// FOLLOW_SUGGESTION_SOURCE_LIMIT 每个推荐来源参与计算的最大记录数
+	FOLLOW_SUGGESTION_SOURCE_LIMIT = 200
+
+	// FOLLOW_SUGGESTION_CANDIDATE_LIMIT 好友的好友和共同点赞来源读取的最大候选记录数
+	FOLLOW_SUGGESTION_CANDIDATE_LIMIT = 2000
+
+	// FOLLOW_SUGGESTION_HASHTAG_LIMIT 参与计算的最大话题数量
+	FOLLOW_SUGGESTION_HASHTAG_LIMIT = 10
+
+	// FOLLOW_SUGGESTION_REASON_USER_LIMIT 推荐理由中展示的最大共同关注者数量
+	FOLLOW_SUGGESTION_REASON_USER_LIMIT = 3
+
+	// FOLLOW_SUGGESTION_FRIEND_WEIGHT 共同关注者权重
+	FOLLOW_SUGGESTION_FRIEND_WEIGHT = 3.0
+
+	// FOLLOW_SUGGESTION_HASHTAG_WEIGHT 共同话题权重
+	FOLLOW_SUGGESTION_HASHTAG_WEIGHT = 2.0
+
+	// FOLLOW_SUGGESTION_LIKE_WEIGHT 共同点赞博文权重
+	FOLLOW_SUGGESTION_LIKE_WEIGHT = 1.0
+)
@@ -0,0 +1,93 @@
+/*
+Package consts - NekoBlog backend server constants.
+This file is for trending posts and hashtags related constants.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// REDIS_POST_HOT_RANKING 热门博文得分有序集合
+	REDIS_POST_HOT_RANKING = "POST:HOT"
+
+	// REDIS_POST_HOT_FEED 按作者限额筛选后的热门博文有序集合，热门列表从该集合分页
+	REDIS_POST_HOT_FEED = "POST:HOT:FEED"
+
+	// POST_HOT_INITIAL_SCORE 新博文的初始热度
+	POST_HOT_INITIAL_SCORE = 1.0
+
+	// POST_HOT_WEIGHT_LIKE 点赞的热度权重
+	POST_HOT_WEIGHT_LIKE = 1.0
+
+	// POST_HOT_WEIGHT_FAVOURITE 收藏的热度权重
+	POST_HOT_WEIGHT_FAVOURITE = 2.0
+
+	// POST_HOT_WEIGHT_COMMENT 评论的热度权重
+	POST_HOT_WEIGHT_COMMENT = 3.0
+
+	// POST_HOT_WEIGHT_REPOST 转发的热度权重
+	POST_HOT_WEIGHT_REPOST = 4.0
+
+	// POST_HOT_WEIGHT_VIEW 独立浏览的热度权重
+	POST_HOT_WEIGHT_VIEW = 0.1
+
+	// POST_HOT_HALF_LIFE 热度半衰期（秒）
+	POST_HOT_HALF_LIFE = 6 * 60 * 60
+
+	// POST_HOT_DECAY_INTERVAL 热度衰减任务的执行间隔（秒）
+	POST_HOT_DECAY_INTERVAL = 10 * 60
+
+	// POST_HOT_PRUNE_THRESHOLD 热度低于该值的博文将移出热门榜单
+	POST_HOT_PRUNE_THRESHOLD = 0.05
+
+	// POST_HOT_DECAY_BATCH 每批衰减的博文数量
+	POST_HOT_DECAY_BATCH = 1000
+
+	// POST_HOT_DEFAULT_LENGTH 热门博文列表默认长度
+	POST_HOT_DEFAULT_LENGTH = 10
+
+	// POST_HOT_MAX_LENGTH 热门博文列表最大长度
+	POST_HOT_MAX_LENGTH = 50
+
+	// POST_HOT_AUTHOR_CAP 热门榜单中同一作者的最大博文数
+	POST_HOT_AUTHOR_CAP = 2
+
+	// POST_HOT_FEED_SIZE 按作者限额筛选后的热门榜单最大长度
+	POST_HOT_FEED_SIZE = 1000
+
+	// POST_HOT_MAX_SCAN_ROUNDS 获取一页热门博文时最多扫描的批次数
+	POST_HOT_MAX_SCAN_ROUNDS = 5
+
+	// REDIS_HASHTAG_TRENDING 话题每小时热度有序集合
+	REDIS_HASHTAG_TRENDING = "HASHTAG:TRENDING"
+
+	// REDIS_HASHTAG_TRENDING_AUTHORS 话题每小时作者贡献计数
+	REDIS_HASHTAG_TRENDING_AUTHORS = "HASHTAG:TRENDING:AUTHORS"
+
+	// HASHTAG_TRENDING_HOUR_LAYOUT 话题热度缓存键中的小时格式
+	HASHTAG_TRENDING_HOUR_LAYOUT = "2006010215"
+
+	// HASHTAG_TRENDING_AUTHOR_CAP 每位作者每小时对同一话题的最大贡献
+	HASHTAG_TRENDING_AUTHOR_CAP = 1
+
+	// HASHTAG_TRENDING_KEY_EXPIRE 话题每小时热度缓存有效期（秒），须大于最大统计窗口
+	HASHTAG_TRENDING_KEY_EXPIRE = 49 * 60 * 60
+
+	// HASHTAG_TRENDING_DEFAULT_HOURS 话题热度默认统计窗口（小时）
+	HASHTAG_TRENDING_DEFAULT_HOURS = 24
+
+	// HASHTAG_TRENDING_MAX_HOURS 话题热度最大统计窗口（小时）
+	HASHTAG_TRENDING_MAX_HOURS = 48
+
+	// HASHTAG_TRENDING_CANDIDATES 从最近两小时中选取的候选话题数量
+	HASHTAG_TRENDING_CANDIDATES = 200
+
+	// HASHTAG_TRENDING_MIN_VELOCITY 进入热门话题的最低每小时热度
+	HASHTAG_TRENDING_MIN_VELOCITY = 3
+
+	// HASHTAG_TRENDING_DEFAULT_LENGTH 热门话题列表默认长度
+	HASHTAG_TRENDING_DEFAULT_LENGTH = 10
+
+	// HASHTAG_TRENDING_MAX_LENGTH 热门话题列表最大长度
+	HASHTAG_TRENDING_MAX_LENGTH = 50
+)
@@ -0,0 +1,175 @@
+/*
+Package controllers - NekoBlog backend server controllers.
+This file is for block controller, which is used to create handlee block and mute related requests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
+)
+
+// BlockController 拉黑控制器结构体
+type BlockController struct {
+	blockService *services.BlockService
+}
+
+// NewBlockController 创建拉黑控制器实例
+//
+// 返回：
+//   - *BlockController: 返回一个新的拉黑控制器实例。
+func (factory *Factory) NewBlockController() *BlockController {
+	return &BlockController{
+		blockService: factory.serviceFactory.NewBlockService(),
+	}
+}
+
+// NewBlockHandler 返回一个用于处理拉黑用户请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的拉黑用户函数
+func (controller *BlockController) NewBlockHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取被拉黑用户ID
+		reqBody := new(types.UserRelationBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "user_id is required"))
+		}
+
+		// 执行拉黑操作
+		if err := controller.blockService.BlockUser(claims.UID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewCancelBlockHandler 返回一个用于处理取消拉黑用户请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的取消拉黑用户函数
+func (controller *BlockController) NewCancelBlockHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取被拉黑用户ID
+		reqBody := new(types.UserRelationBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "user_id is required"))
+		}
+
+		// 执行取消拉黑操作
+		if err := controller.blockService.CancelBlockUser(claims.UID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewBlockListHandler 返回一个用于处理获取当前用户拉黑列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取拉黑列表函数
+func (controller *BlockController) NewBlockListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 执行获取拉黑列表操作
+		blocks, err := controller.blockService.GetBlockList(claims.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewBlockListResponse(blocks)),
+		)
+	}
+}
+
+// NewMuteHandler 返回一个用于处理屏蔽用户请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的屏蔽用户函数
+func (controller *BlockController) NewMuteHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取被屏蔽用户ID
+		reqBody := new(types.UserRelationBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "user_id is required"))
+		}
+
+		// 执行屏蔽操作
+		if err := controller.blockService.MuteUser(claims.UID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewCancelMuteHandler 返回一个用于处理取消屏蔽用户请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的取消屏蔽用户函数
+func (controller *BlockController) NewCancelMuteHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取被屏蔽用户ID
+		reqBody := new(types.UserRelationBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "user_id is required"))
+		}
+
+		// 执行取消屏蔽操作
+		if err := controller.blockService.CancelMuteUser(claims.UID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewMuteListHandler 返回一个用于处理获取当前用户屏蔽列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取屏蔽列表函数
+func (controller *BlockController) NewMuteListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 执行获取屏蔽列表操作
+		mutes, err := controller.blockService.GetMuteList(claims.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewMuteListResponse(mutes)),
+		)
+	}
+}
@@ -23,6 +23,7 @@ import (
 // CommentController 评论控制器
 type CommentController struct {
 	commentService *services.CommentService
+	threadService  *services.ThreadService
 }
 
 // NewCommentController 创建一个新的评论控制器实例。
@@ -32,6 +33,7 @@ type CommentController struct {
 func (factory *Factory) NewCommentController() *CommentController {
 	return &CommentController{
 		commentService: factory.serviceFactory.NewCommentService(),
+		threadService:  factory.serviceFactory.NewThreadService(),
 	}
 }
 
@@ -66,7 +68,7 @@ func (controller *CommentController) NewCreateCommentHandler(postStore *stores.P
 		commentID, err := controller.commentService.CreateComment(claims.UID, *reqBody.PostID, reqBody.Content, postStore, userStore)
 		if err != nil {
 			return ctx.Status(200).JSON(
-				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+				serializers.NewResponse(commentPermissionErrorCode(err), err.Error()),
 			)
 		}
 
@@ -108,7 +110,7 @@ func (controller *CommentController) NewUpdateCommentHandler() fiber.Handler {
 		err = controller.commentService.UpdateComment(*reqBody.CommentID, reqBody.Content)
 		if err != nil {
 			return ctx.Status(200).JSON(
-				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+				serializers.NewResponse(commentPermissionErrorCode(err), err.Error()),
 			)
 		}
 
@@ -153,6 +155,80 @@ func (controller *CommentController) DeleteCommentHandler() fiber.Handler {
 	}
 }
 
+// commentPermissionErrorCode 获取评论权限及敏感词错误对应的响应码，其他错误返回 SERVER_ERROR
+//
+// 参数：
+//   - err：服务层返回的错误
+//
+// 返回值：
+//   - serializers.ResponseCode：响应码
+func commentPermissionErrorCode(err error) serializers.ResponseCode {
+	switch {
+	case errors.Is(err, services.ErrCommentClosed):
+		return consts.COMMENT_CLOSED_ERROR
+	case errors.Is(err, services.ErrCommentFollowersOnly):
+		return consts.COMMENT_FOLLOWERS_ONLY_ERROR
+	case errors.Is(err, services.ErrCommentMutualsOnly):
+		return consts.COMMENT_MUTUALS_ONLY_ERROR
+	case errors.Is(err, services.ErrSensitiveContent):
+		return consts.SENSITIVE_CONTENT_ERROR
+	default:
+		return consts.SERVER_ERROR
+	}
+}
+
+// parseSortedPageQuery 解析评论和回复列表的排序及分页查询参数
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//
+// 返回值：
+//   - string：排序方式，默认为 new
+//   - string：分页游标
+//   - int：获取数量
+//   - error：参数不合法时返回错误
+func parseSortedPageQuery(ctx *fiber.Ctx) (string, string, int, error) {
+	sortMode := ctx.Query("sort", consts.COMMENT_SORT_NEW)
+	switch sortMode {
+	case consts.COMMENT_SORT_NEW, consts.COMMENT_SORT_OLD, consts.COMMENT_SORT_TOP, consts.COMMENT_SORT_HOT:
+	default:
+		return "", "", 0, errors.New("sort is invalid")
+	}
+
+	length, err := parseLengthQuery(ctx, "len", consts.COMMENT_LIST_DEFAULT_LENGTH, consts.COMMENT_LIST_MAX_LENGTH)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return sortMode, ctx.Query("cursor"), length, nil
+}
+
+// parseLengthQuery 解析可选的正整数查询参数，超过上限时取上限
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//   - key：参数名
+//   - defaultValue：参数缺失时的默认值
+//   - maxValue：参数上限
+//
+// 返回值：
+//   - int：参数值
+//   - error：参数不合法时返回错误
+func parseLengthQuery(ctx *fiber.Ctx, key string, defaultValue, maxValue int) (int, error) {
+	valueString := ctx.Query(key)
+	if valueString == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(valueString)
+	if err != nil || value <= 0 {
+		return 0, errors.New(key + " is invalid")
+	}
+	if value > maxValue {
+		value = maxValue
+	}
+	return value, nil
+}
+
 // NewCommentListHandler 下拉评论列表请求
 //
 // 返回值：
@@ -173,14 +249,78 @@ func (controller *CommentController) NewCommentListHandler() fiber.Handler {
 			)
 		}
 
-		comments, err := controller.commentService.GetCommentList(postIDUint)
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := c.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 解析排序和分页参数
+		sortMode, cursor, length, err := parseSortedPageQuery(c)
+		if err != nil {
+			return c.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		commentIDs, pinnedIDs, nextCursor, err := controller.commentService.GetCommentList(postIDUint, viewerUID, sortMode, cursor, length)
 		if err != nil {
 			return c.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
 			)
 		}
 		return c.Status(200).JSON(
-			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewCommentListResponse(comments)),
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewCommentListResponse(commentIDs, pinnedIDs, nextCursor)),
+		)
+	}
+}
+
+// NewCommentTreeHandler 获取博文评论树的请求，每条评论附带前若干层回复及作者资料
+//
+// 返回值：
+//   - fiber.Handler：新的获取评论树的函数
+func (controller *CommentController) NewCommentTreeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析查询参数
+		postID, err := parseUintQuery(ctx, "post-id")
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+		sortMode, cursor, length, err := parseSortedPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+		replyLength, err := parseLengthQuery(ctx, "reply-len", consts.COMMENT_TREE_DEFAULT_REPLY_LENGTH, consts.COMMENT_TREE_MAX_REPLY_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+		depth, err := parseLengthQuery(ctx, "depth", consts.COMMENT_TREE_DEFAULT_DEPTH, consts.COMMENT_TREE_MAX_DEPTH)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		tree, err := controller.threadService.GetCommentTree(postID, viewerUID, sortMode, cursor, length, replyLength, depth)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewCommentTreeResponse(tree)),
 		)
 	}
 }
@@ -205,7 +345,7 @@ func (controller *CommentController) NewCommentDetailHandler() fiber.Handler {
 			)
 		}
 
-		comment, likeCount, err := controller.commentService.GetCommentInfo(commentID)
+		comment, likeCount, err := controller.commentService.GetCommentInfo(commentID, getViewerUID(ctx))
 		// 若comment不存在则返回错误
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ctx.Status(200).JSON(
@@ -415,3 +555,63 @@ func (controller *CommentController) NewCancelDislikeCommentHandler() fiber.Hand
 		)
 	}
 }
+
+// NewPinCommentHandler 置顶评论的函数，仅博文作者可操作
+//
+// 返回值：
+//   - fiber.Handler：新的置顶评论的函数
+func (controller *CommentController) NewPinCommentHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		commentID, err := parseUintQuery(ctx, "comment-id")
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 获取Token Claims
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 调用服务方法置顶评论
+		if err := controller.commentService.PinComment(claims.UID, commentID); err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		// 成功时返回响应
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed"),
+		)
+	}
+}
+
+// NewUnpinCommentHandler 取消置顶评论的函数，仅博文作者可操作
+//
+// 返回值：
+//   - fiber.Handler：新的取消置顶评论的函数
+func (controller *CommentController) NewUnpinCommentHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		commentID, err := parseUintQuery(ctx, "comment-id")
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 获取Token Claims
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 调用服务方法取消置顶评论
+		if err := controller.commentService.UnpinComment(claims.UID, commentID); err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		// 成功时返回响应
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed"),
+		)
+	}
+}
@@ -8,17 +8,20 @@ Copyright (c) [2024], Author(s):
 package controllers
 
 import (
+	"github.com/sirupsen/logrus"
+
 	"github.com/Kirisakiii/neko-micro-blog-backend/services"
 )
 
 // Factory 控制器工厂
 type Factory struct {
 	serviceFactory *services.Factory
+	logger         *logrus.Logger
 }
 
 // NewFactory 创建控制器工厂
-func NewFactory(serviceFactory *services.Factory) *Factory {
-	return &Factory{serviceFactory: serviceFactory}
+func NewFactory(serviceFactory *services.Factory, logger *logrus.Logger) *Factory {
+	return &Factory{serviceFactory: serviceFactory, logger: logger}
 }
 
 
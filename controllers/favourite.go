@@ -0,0 +1,216 @@
+/*
+Package controllers - NekoBlog backend server controllers.
+This file is for favourite folder controller, which is used to handle favourite folder related requests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
+)
+
+// FavouriteController 收藏夹控制器结构体
+type FavouriteController struct {
+	favouriteService *services.FavouriteService
+}
+
+// NewFavouriteController 创建收藏夹控制器实例
+//
+// 返回：
+//   - *FavouriteController: 返回一个新的收藏夹控制器实例。
+func (factory *Factory) NewFavouriteController() *FavouriteController {
+	return &FavouriteController{
+		favouriteService: factory.serviceFactory.NewFavouriteService(),
+	}
+}
+
+// NewCreateFolderHandler 返回一个用于处理创建收藏夹请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的创建收藏夹函数
+func (controller *FavouriteController) NewCreateFolderHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.FavouriteFolderCreateBody)
+		if err := ctx.BodyParser(reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "invalid request body"))
+		}
+
+		// 执行创建收藏夹操作
+		folderID, err := controller.favouriteService.CreateFolder(claims.UID, *reqBody)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewFavouriteFolderCreateResponse(folderID)))
+	}
+}
+
+// NewUpdateFolderHandler 返回一个用于处理更新收藏夹请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的更新收藏夹函数
+func (controller *FavouriteController) NewUpdateFolderHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.FavouriteFolderUpdateBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.FolderID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "folder_id is required"))
+		}
+
+		// 执行更新收藏夹操作
+		if err := controller.favouriteService.UpdateFolder(claims.UID, *reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewDeleteFolderHandler 返回一个用于处理删除收藏夹请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的删除收藏夹函数
+func (controller *FavouriteController) NewDeleteFolderHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.FavouriteFolderBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.FolderID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "folder_id is required"))
+		}
+
+		// 执行删除收藏夹操作
+		if err := controller.favouriteService.DeleteFolder(claims.UID, reqBody.FolderID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewFolderListHandler 返回一个用于处理获取用户收藏夹请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取用户收藏夹函数
+func (controller *FavouriteController) NewFolderListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		userID, err := parseUintQuery(ctx, "user_id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		// 执行获取用户收藏夹操作
+		folders, err := controller.favouriteService.GetUserFolders(userID, getViewerUID(ctx))
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewFavouriteFolderListResponse(folders)))
+	}
+}
+
+// NewFavouriteListHandler 返回一个用于处理分页获取收藏记录请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取收藏记录函数
+func (controller *FavouriteController) NewFavouriteListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		userID, err := parseUintQuery(ctx, "user_id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		// 获取收藏夹ID，未指定时获取所有可见收藏夹中的收藏
+		var folderID *uint64
+		if ctx.Query("folder_id") != "" {
+			value, err := parseUintQuery(ctx, "folder_id")
+			if err != nil {
+				return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+			}
+			folderID = &value
+		}
+
+		// 获取分页参数
+		length, err := parseLengthQuery(ctx, "len", consts.FAVOURITE_LIST_DEFAULT_LENGTH, consts.FAVOURITE_LIST_MAX_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		// 执行获取收藏记录操作
+		records, nextCursor, err := controller.favouriteService.GetFavouritePage(userID, getViewerUID(ctx), folderID, ctx.Query("cursor"), length)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewFavouriteListResponse(records, nextCursor)))
+	}
+}
+
+// NewMoveFavouriteHandler 返回一个用于处理移动收藏请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的移动收藏函数
+func (controller *FavouriteController) NewMoveFavouriteHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.FavouriteMoveBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.PostID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "post_id is required"))
+		}
+
+		// 执行移动收藏操作
+		if err := controller.favouriteService.MoveFavourite(claims.UID, reqBody.PostID, reqBody.FolderID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewUpdateNoteHandler 返回一个用于处理更新收藏备注请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的更新收藏备注函数
+func (controller *FavouriteController) NewUpdateNoteHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.FavouriteNoteBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.PostID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "post_id is required"))
+		}
+
+		// 执行更新收藏备注操作
+		if err := controller.favouriteService.UpdateFavouriteNote(claims.UID, reqBody.PostID, reqBody.Note); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
@@ -9,6 +9,7 @@ Copyright (c) [2024], Author(s):
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -54,9 +55,13 @@ func (controller *FollowController) NewCreateFollowHandler() fiber.Handler {
 		followedID := body.UserID
 
 		// 执行关注操作
-		if err := controller.followService.FollowUser(claims.UID, followedID); err != nil {
+		isPending, err := controller.followService.FollowUser(claims.UID, followedID)
+		if err != nil {
 			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
 		}
+		if isPending {
+			return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "follow request sent"))
+		}
 
 		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
 	}
@@ -111,7 +116,7 @@ func (controller *FollowController) NewFollowListHandler() fiber.Handler {
 		}
 
 		// 执行获取关注列表操作
-		follows, err := controller.followService.GetFollowList(userID)
+		follows, err := controller.followService.GetFollowList(userID, getViewerUID(ctx))
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
@@ -177,7 +182,7 @@ func (controller *FollowController) NewFollowerListHandler() fiber.Handler {
 		}
 
 		// 执行获取粉丝列表操作
-		followers, err := controller.followService.GetFollowerList(userID)
+		followers, err := controller.followService.GetFollowerList(userID, getViewerUID(ctx))
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
@@ -221,3 +226,175 @@ func (controller *FollowController) NewFollowerCountHandler() fiber.Handler {
 		)
 	}
 }
+
+// NewFollowRequestListHandler 返回一个用于处理获取待处理关注请求列表的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取关注请求列表函数
+func (controller *FollowController) NewFollowRequestListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 执行获取关注请求列表操作
+		requests, err := controller.followService.GetFollowRequestList(claims.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewFollowRequestListResponse(requests)),
+		)
+	}
+}
+
+// NewApproveFollowRequestHandler 返回一个用于处理通过关注请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的通过关注请求函数
+func (controller *FollowController) NewApproveFollowRequestHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取请求者ID
+		reqBody := new(types.UserRelationBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "user_id is required"))
+		}
+
+		// 执行通过关注请求操作
+		if err := controller.followService.ApproveFollowRequest(claims.UID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewRejectFollowRequestHandler 返回一个用于处理拒绝关注请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的拒绝关注请求函数
+func (controller *FollowController) NewRejectFollowRequestHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取请求者ID
+		reqBody := new(types.UserRelationBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "user_id is required"))
+		}
+
+		// 执行拒绝关注请求操作
+		if err := controller.followService.RejectFollowRequest(claims.UID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// parseFollowPageQuery 解析分页关注及粉丝列表的查询参数
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//
+// 返回值：
+//   - uint64：用户ID
+//   - string：分页游标
+//   - int：获取数量
+//   - error：如果参数不合法，返回相应错误信息；否则返回 nil
+func parseFollowPageQuery(ctx *fiber.Ctx) (uint64, string, int, error) {
+	userIDString := ctx.Query("user_id")
+	if userIDString == "" {
+		return 0, "", 0, errors.New("user_id is required")
+	}
+	userID, err := strconv.ParseUint(userIDString, 10, 64)
+	if err != nil {
+		return 0, "", 0, errors.New("user_id is invalid")
+	}
+
+	length := consts.FOLLOW_LIST_DEFAULT_LENGTH
+	if lengthString := ctx.Query("len"); lengthString != "" {
+		length, err = strconv.Atoi(lengthString)
+		if err != nil || length <= 0 {
+			return 0, "", 0, errors.New("len is invalid")
+		}
+		if length > consts.FOLLOW_LIST_MAX_LENGTH {
+			length = consts.FOLLOW_LIST_MAX_LENGTH
+		}
+	}
+
+	return userID, ctx.Query("cursor"), length, nil
+}
+
+// NewFollowListPageHandler 返回一个用于处理分页获取关注列表详情请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的分页获取关注列表详情函数
+func (controller *FollowController) NewFollowListPageHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 解析查询参数
+		userID, cursor, length, err := parseFollowPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 执行获取关注列表操作
+		items, nextCursor, err := controller.followService.GetFollowListPage(userID, viewerUID, cursor, length)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewFollowListPageResponse(items, nextCursor)),
+		)
+	}
+}
+
+// NewFollowerListPageHandler 返回一个用于处理分页获取粉丝列表详情请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的分页获取粉丝列表详情函数
+func (controller *FollowController) NewFollowerListPageHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 解析查询参数
+		userID, cursor, length, err := parseFollowPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 执行获取粉丝列表操作
+		items, nextCursor, err := controller.followService.GetFollowerListPage(userID, viewerUID, cursor, length)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewFollowListPageResponse(items, nextCursor)),
+		)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Package controllers - NekoBlog backend server controllers.
+This file is for batch hydration controller, which is used to create handlee batch hydration related requests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
+)
+
+// HydrationController 批量获取控制器结构体
+type HydrationController struct {
+	hydrationService *services.HydrationService
+}
+
+// NewHydrationController 创建批量获取控制器实例
+//
+// 返回：
+//   - *HydrationController: 返回一个新的批量获取控制器实例。
+func (factory *Factory) NewHydrationController() *HydrationController {
+	return &HydrationController{
+		hydrationService: factory.serviceFactory.NewHydrationService(),
+	}
+}
+
+// parseIDListQuery 解析以逗号分隔的ID列表查询参数，去除重复ID并保持原有顺序
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//   - key：参数名
+//   - max：允许的最大ID数量
+//
+// 返回值：
+//   - []uint64：ID列表
+//   - error：如果参数缺失、不合法或数量超出限制，返回相应错误信息；否则返回 nil
+func parseIDListQuery(ctx *fiber.Ctx, key string, max int) ([]uint64, error) {
+	valueString := ctx.Query(key)
+	if valueString == "" {
+		return nil, errors.New(key + " is required")
+	}
+	parts := strings.Split(valueString, ",")
+	ids := make([]uint64, 0, len(parts))
+	seen := make(map[uint64]struct{}, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, errors.New(key + " is invalid")
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) > max {
+		return nil, errors.New("too many " + key)
+	}
+	return ids, nil
+}
+
+// getViewerUID 获取查看者ID，匿名访问时返回 0
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//
+// 返回值：
+//   - uint64：查看者ID
+func getViewerUID(ctx *fiber.Ctx) uint64 {
+	if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+		return claims.UID
+	}
+	return 0
+}
+
+// NewPostBatchHandler 返回一个用于处理批量获取博文请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的批量获取博文函数
+func (controller *HydrationController) NewPostBatchHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		postIDs, err := parseIDListQuery(ctx, "ids", consts.BATCH_HYDRATION_MAX_IDS)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		posts, err := controller.hydrationService.GetPosts(postIDs, getViewerUID(ctx))
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewPostBatchResponse(posts)))
+	}
+}
+
+// NewCommentBatchHandler 返回一个用于处理批量获取评论请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的批量获取评论函数
+func (controller *HydrationController) NewCommentBatchHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		commentIDs, err := parseIDListQuery(ctx, "ids", consts.BATCH_HYDRATION_MAX_IDS)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		comments, err := controller.hydrationService.GetComments(commentIDs, getViewerUID(ctx))
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewCommentBatchResponse(comments)))
+	}
+}
+
+// NewUserBatchHandler 返回一个用于处理批量获取用户请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的批量获取用户函数
+func (controller *HydrationController) NewUserBatchHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		uids, err := parseIDListQuery(ctx, "ids", consts.BATCH_HYDRATION_MAX_IDS)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		users, err := controller.hydrationService.GetUsers(uids, getViewerUID(ctx))
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewUserBatchResponse(users)))
+	}
+}
@@ -0,0 +1,382 @@
+/*
+Package controllers - NekoBlog backend server controllers.
+This file is for user list controller, which is used to create handlee user list related requests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
+)
+
+// UserListController 用户列表控制器结构体
+type UserListController struct {
+	userListService *services.UserListService
+	logger          *logrus.Logger
+}
+
+// NewUserListController 创建用户列表控制器实例
+//
+// 返回：
+//   - *UserListController: 返回一个新的用户列表控制器实例。
+func (factory *Factory) NewUserListController() *UserListController {
+	return &UserListController{
+		userListService: factory.serviceFactory.NewUserListService(),
+		logger:          factory.logger,
+	}
+}
+
+// parseUintQuery 解析必填的无符号整数查询参数
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//   - key：参数名
+//
+// 返回值：
+//   - uint64：参数值
+//   - error：如果参数缺失或不合法，返回相应错误信息；否则返回 nil
+func parseUintQuery(ctx *fiber.Ctx, key string) (uint64, error) {
+	valueString := ctx.Query(key)
+	if valueString == "" {
+		return 0, errors.New(key + " is required")
+	}
+	value, err := strconv.ParseUint(valueString, 10, 64)
+	if err != nil {
+		return 0, errors.New(key + " is invalid")
+	}
+	return value, nil
+}
+
+// NewCreateListHandler 返回一个用于处理创建列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的创建列表函数
+func (controller *UserListController) NewCreateListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.UserListCreateBody)
+		if err := ctx.BodyParser(reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "invalid request body"))
+		}
+
+		// 执行创建列表操作
+		listID, err := controller.userListService.CreateList(claims.UID, *reqBody)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewUserListCreateResponse(listID)))
+	}
+}
+
+// NewUpdateListHandler 返回一个用于处理更新列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的更新列表函数
+func (controller *UserListController) NewUpdateListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.UserListUpdateBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.ListID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "list_id is required"))
+		}
+
+		// 执行更新列表操作
+		if err := controller.userListService.UpdateList(claims.UID, *reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewDeleteListHandler 返回一个用于处理删除列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的删除列表函数
+func (controller *UserListController) NewDeleteListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.UserListBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.ListID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "list_id is required"))
+		}
+
+		// 执行删除列表操作
+		if err := controller.userListService.DeleteList(claims.UID, reqBody.ListID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewListDetailHandler 返回一个用于处理获取列表详情请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取列表详情函数
+func (controller *UserListController) NewListDetailHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 获取列表ID
+		listID, err := parseUintQuery(ctx, "list_id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		// 执行获取列表详情操作
+		detail, err := controller.userListService.GetListDetail(listID, viewerUID)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewUserListDetailResponse(detail)))
+	}
+}
+
+// NewUserListsHandler 返回一个用于处理获取用户创建的列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取用户创建的列表函数
+func (controller *UserListController) NewUserListsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 获取用户ID
+		userID, err := parseUintQuery(ctx, "user_id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		// 执行获取用户创建的列表操作
+		lists, err := controller.userListService.GetUserLists(userID, viewerUID)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewUserListListResponse(lists)))
+	}
+}
+
+// NewSubscribedListsHandler 返回一个用于处理获取已订阅列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取已订阅列表函数
+func (controller *UserListController) NewSubscribedListsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 执行获取已订阅列表操作
+		lists, err := controller.userListService.GetSubscribedLists(claims.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewUserListListResponse(lists)))
+	}
+}
+
+// NewAddMemberHandler 返回一个用于处理添加列表成员请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的添加列表成员函数
+func (controller *UserListController) NewAddMemberHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.UserListMemberBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.ListID == 0 || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "list_id and user_id are required"))
+		}
+
+		// 执行添加列表成员操作
+		if err := controller.userListService.AddMember(claims.UID, reqBody.ListID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewRemoveMemberHandler 返回一个用于处理移除列表成员请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的移除列表成员函数
+func (controller *UserListController) NewRemoveMemberHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.UserListMemberBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.ListID == 0 || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "list_id and user_id are required"))
+		}
+
+		// 执行移除列表成员操作
+		if err := controller.userListService.RemoveMember(claims.UID, reqBody.ListID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewMemberListHandler 返回一个用于处理获取列表成员请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取列表成员函数
+func (controller *UserListController) NewMemberListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 获取列表ID
+		listID, err := parseUintQuery(ctx, "list_id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		// 执行获取列表成员操作
+		memberIDs, err := controller.userListService.GetMemberList(listID, viewerUID)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewUserListMemberListResponse(memberIDs)))
+	}
+}
+
+// NewSubscribeHandler 返回一个用于处理订阅列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的订阅列表函数
+func (controller *UserListController) NewSubscribeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.UserListBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.ListID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "list_id is required"))
+		}
+
+		// 执行订阅列表操作
+		if err := controller.userListService.Subscribe(claims.UID, reqBody.ListID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewUnsubscribeHandler 返回一个用于处理取消订阅列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的取消订阅列表函数
+func (controller *UserListController) NewUnsubscribeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.UserListBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.ListID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "list_id is required"))
+		}
+
+		// 执行取消订阅列表操作
+		if err := controller.userListService.Unsubscribe(claims.UID, reqBody.ListID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewTimelineHandler 返回一个用于处理获取列表时间线请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取列表时间线函数
+func (controller *UserListController) NewTimelineHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 获取请求参数
+		listID, err := parseUintQuery(ctx, "list_id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		length, err := parseLengthQuery(ctx, "len", consts.USER_LIST_TIMELINE_MAX_LENGTH, consts.USER_LIST_TIMELINE_MAX_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		from := ctx.Query("from")
+		if from != "" {
+			_, err := strconv.ParseUint(from, 10, 64)
+			if err != nil {
+				return ctx.Status(200).JSON(
+					serializers.NewResponse(consts.PARAMETER_ERROR, "invalid from id"),
+				)
+			}
+		}
+
+		// 执行获取列表时间线操作
+		posts, err := controller.userListService.GetTimeline(listID, viewerUID, length, from)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		// 记录时间线中博文的曝光，记录失败不影响返回结果
+		if err := controller.userListService.RecordImpressions(posts); err != nil {
+			controller.logger.Warnln("记录博文曝光失败:", err.Error())
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewPostListResponse(posts)))
+	}
+}
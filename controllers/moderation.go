@@ -0,0 +1,245 @@
+/*
+Package controllers - NekoBlog backend server controllers.
+This file is for report and moderation controller.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
+)
+
+// ModerationController 举报与审核控制器
+type ModerationController struct {
+	moderationService *services.ModerationService
+}
+
+// NewModerationController 创建举报与审核控制器实例
+//
+// 返回：
+//   - *ModerationController: 返回一个新的举报与审核控制器实例。
+func (factory *Factory) NewModerationController() *ModerationController {
+	return &ModerationController{
+		moderationService: factory.serviceFactory.NewModerationService(),
+	}
+}
+
+// moderationErrorCode 将举报与审核服务返回的错误转换为响应码
+//
+// 参数：
+//   - err：服务层返回的错误
+//
+// 返回值：
+//   - serializers.ResponseCode：响应码
+func moderationErrorCode(err error) serializers.ResponseCode {
+	switch {
+	case errors.Is(err, services.ErrPermissionDenied):
+		return consts.PERMISSION_DENIED_ERROR
+	case errors.Is(err, services.ErrDuplicateReport):
+		return consts.DUPLICATE_REPORT_ERROR
+	default:
+		return consts.SERVER_ERROR
+	}
+}
+
+// parseModerationPageQuery 解析审核相关列表的分页查询参数
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//
+// 返回值：
+//   - int：偏移量
+//   - int：每页数量
+//   - error：参数不合法时返回错误
+func parseModerationPageQuery(ctx *fiber.Ctx) (int, int, error) {
+	var offset int
+	if ctx.Query("offset") != "" {
+		value, err := parseUintQuery(ctx, "offset")
+		if err != nil {
+			return 0, 0, err
+		}
+		offset = int(value)
+	}
+	length, err := parseLengthQuery(ctx, "len", consts.MODERATION_LIST_DEFAULT_LENGTH, consts.MODERATION_LIST_MAX_LENGTH)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, length, nil
+}
+
+// NewReportHandler 返回一个用于处理举报请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的举报函数
+func (controller *ModerationController) NewReportHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		reqBody := new(types.ReportCreateBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.TargetID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "target_type and target_id are required"))
+		}
+
+		if err := controller.moderationService.ReportTarget(claims.UID, *reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(moderationErrorCode(err), err.Error()))
+		}
+
+		return ctx.Status(200).JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewModerationQueueHandler 返回一个用于处理获取审核队列请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取审核队列函数
+func (controller *ModerationController) NewModerationQueueHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		offset, length, err := parseModerationPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		cases, hasMore, err := controller.moderationService.GetModerationQueue(claims.UID, ctx.Query("status"), offset, length)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(moderationErrorCode(err), err.Error()))
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewModerationQueueResponse(cases, hasMore)),
+		)
+	}
+}
+
+// NewCaseReportListHandler 返回一个用于处理获取审核工单举报列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取审核工单举报列表函数
+func (controller *ModerationController) NewCaseReportListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		caseID, err := parseUintQuery(ctx, "case_id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		offset, length, err := parseModerationPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		reports, hasMore, err := controller.moderationService.GetCaseReports(claims.UID, caseID, offset, length)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(moderationErrorCode(err), err.Error()))
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewContentReportListResponse(reports, hasMore)),
+		)
+	}
+}
+
+// NewModerationActionHandler 返回一个用于处理审核操作请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的审核操作函数
+func (controller *ModerationController) NewModerationActionHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		reqBody := new(types.ModerationActionBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.Action == "" {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "action is required"))
+		}
+		if reqBody.CaseID == 0 && (reqBody.TargetType == "" || reqBody.TargetID == 0) {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "case_id or target_type and target_id are required"))
+		}
+
+		if err := controller.moderationService.TakeAction(claims.UID, *reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(moderationErrorCode(err), err.Error()))
+		}
+
+		return ctx.Status(200).JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewModerationLogListHandler 返回一个用于处理获取审核日志请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取审核日志函数
+func (controller *ModerationController) NewModerationLogListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		query := types.ModerationLogQuery{TargetType: ctx.Query("target_type")}
+		var err error
+		if query.TargetType != "" {
+			if query.TargetID, err = parseUintQuery(ctx, "target_id"); err != nil {
+				return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+			}
+		}
+		if ctx.Query("target_uid") != "" {
+			if query.TargetUID, err = parseUintQuery(ctx, "target_uid"); err != nil {
+				return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+			}
+		}
+		if ctx.Query("moderator") != "" {
+			if query.ModeratorUID, err = parseUintQuery(ctx, "moderator"); err != nil {
+				return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+			}
+		}
+		if query.Offset, query.Length, err = parseModerationPageQuery(ctx); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		logs, hasMore, err := controller.moderationService.GetModerationLogs(claims.UID, query)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(moderationErrorCode(err), err.Error()))
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewModerationLogListResponse(logs, hasMore)),
+		)
+	}
+}
+
+// NewUserWarningListHandler 返回一个用于处理获取当前用户收到的警告请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取警告列表函数
+func (controller *ModerationController) NewUserWarningListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		offset, length, err := parseModerationPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		warnings, hasMore, err := controller.moderationService.GetUserWarnings(claims.UID, offset, length)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewUserWarningListResponse(warnings, hasMore)),
+		)
+	}
+}
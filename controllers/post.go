@@ -13,29 +13,31 @@ import (
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
-	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
 	"github.com/Kirisakiii/neko-micro-blog-backend/services"
-	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
 	"github.com/Kirisakiii/neko-micro-blog-backend/types"
-	"github.com/Kirisakiii/neko-micro-blog-backend/utils/functools"
 	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
 )
 
 // PostController 博文控制器结构体
 type PostController struct {
-	postService *services.PostService
+	postService      *services.PostService
+	favouriteService *services.FavouriteService
+	logger           *logrus.Logger
 }
 
 // NewPostController 博文控制器工厂函数。
 //
 // 返回值：
 //   - *PostController 博文控制器指针
-func (factory *Factory) NewPostController(searchServiceClient search.SearchEngineClient) *PostController {
+func (factory *Factory) NewPostController() *PostController {
 	return &PostController{
-		postService: factory.serviceFactory.NewPostService(searchServiceClient),
+		postService:      factory.serviceFactory.NewPostService(),
+		favouriteService: factory.serviceFactory.NewFavouriteService(),
+		logger:           factory.logger,
 	}
 }
 
@@ -43,56 +45,54 @@ func (factory *Factory) NewPostController(searchServiceClient search.SearchEngin
 //
 // 返回值：
 //   - fiber.Handle：新的博文列表函数
-func (controller *PostController) NewPostListHandler(userStore *stores.UserStore) fiber.Handler {
+func (controller *PostController) NewPostListHandler() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		// 获取请求参数
 		reqType := ctx.Query("type")
-		uid := ctx.Query("uid")
-		length := ctx.Query("len")
-		from := ctx.Query("from")
+		if reqType == "" {
+			reqType = "all"
+		}
+		var (
+			uid uint64
+			err error
+		)
 		if reqType == "user" || reqType == "liked" || reqType == "favourited" {
-			_, err := strconv.ParseUint(uid, 10, 64)
+			uid, err = strconv.ParseUint(ctx.Query("uid"), 10, 64)
 			if err != nil {
 				return ctx.Status(200).JSON(
 					serializers.NewResponse(consts.PARAMETER_ERROR, "invalid uid"),
 				)
 			}
 		}
-		if length != "" {
-			_, err := strconv.ParseUint(length, 10, 64)
-			if err != nil {
-				return ctx.Status(200).JSON(
-					serializers.NewResponse(consts.PARAMETER_ERROR, "invalid length"),
-				)
-			}
+		length, err := parseLengthQuery(ctx, "len", consts.POST_LIST_DEFAULT_LENGTH, consts.POST_LIST_MAX_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, "invalid length"),
+			)
 		}
-		if from != "" {
-			_, err := strconv.ParseUint(from, 10, 64)
+		cursor := ctx.Query("cursor")
+		viewerUID := getViewerUID(ctx)
+
+		// 收藏列表可按收藏夹筛选
+		var folderID *uint64
+		if reqType == "favourited" && ctx.Query("folder-id") != "" {
+			value, err := parseUintQuery(ctx, "folder-id")
 			if err != nil {
-				return ctx.Status(200).JSON(
-					serializers.NewResponse(consts.PARAMETER_ERROR, "invalid from id"),
-				)
+				return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
 			}
+			folderID = &value
 		}
 
 		// 获取帖子列表
 		var (
-			posts []int64
-			err   error
+			posts      []int64
+			nextCursor string
 		)
 		switch reqType {
-		case "":
-			posts, err = controller.postService.GetPostList("all", "", length, from, userStore)
-		case "all":
-			posts, err = controller.postService.GetPostList("all", "", length, from, userStore)
-		case "user":
-			posts, err = controller.postService.GetPostList("user", uid, length, from, userStore)
-		case "liked":
-			posts, err = controller.postService.GetPostList("liked", uid, length, from, userStore)
-			posts = functools.Reverse(posts)
+		case "all", "user", "liked":
+			posts, nextCursor, err = controller.postService.GetPostList(reqType, uid, viewerUID, cursor, length)
 		case "favourited":
-			posts, err = controller.postService.GetPostList("favourited", uid, length, from, userStore)
-			posts = functools.Reverse(posts)
+			posts, nextCursor, err = controller.getFavouritedPostList(uid, viewerUID, folderID, cursor, length)
 		default:
 			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "invalid type"))
 		}
@@ -102,13 +102,43 @@ func (controller *PostController) NewPostListHandler(userStore *stores.UserStore
 			)
 		}
 
+		// 记录列表中博文的曝光，记录失败不影响返回结果
+		if err := controller.postService.RecordImpressions(posts); err != nil {
+			controller.logger.Warnln("记录博文曝光失败:", err.Error())
+		}
+
 		// 返回结果
 		return ctx.Status(200).JSON(
-			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewPostListResponse(posts)),
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewPostPageResponse(posts, nextCursor)),
 		)
 	}
 }
 
+// getFavouritedPostList 按收藏时间倒序分页获取用户收藏的博文，可按收藏夹筛选
+//
+// 参数：
+//   - uid：收藏者ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - folderID：收藏夹ID，为 nil 时获取所有可见收藏夹中的收藏
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []int64：博文ID列表
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (controller *PostController) getFavouritedPostList(uid, viewerUID uint64, folderID *uint64, cursor string, length int) ([]int64, string, error) {
+	records, nextCursor, err := controller.favouriteService.GetFavouritePage(uid, viewerUID, folderID, cursor, length)
+	if err != nil {
+		return nil, "", err
+	}
+	posts := make([]int64, len(records))
+	for index, record := range records {
+		posts[index] = record.PostID
+	}
+	return posts, nextCursor, nil
+}
+
 // NewDetailHandler 获取文章信息的函数
 //
 // 返回值：
@@ -131,8 +161,14 @@ func (controller *PostController) NewPostDetailHandler() fiber.Handler {
 			)
 		}
 
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
 		// 获取帖子的详细信息
-		post, likeCount, favouriteCount, err := controller.postService.GetPostInfo(postID)
+		post, counter, err := controller.postService.GetPostInfo(postID, viewerUID)
 		// 若post不存在则返回错误
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ctx.Status(200).JSON(
@@ -147,9 +183,14 @@ func (controller *PostController) NewPostDetailHandler() fiber.Handler {
 			)
 		}
 
+		// 记录浏览，记录失败不影响返回结果
+		if err := controller.postService.RecordPostView(postID, viewerUID, ctx.IP()); err != nil {
+			controller.logger.Warnln("记录博文浏览失败:", err.Error())
+		}
+
 		// 返回结果
 		return ctx.Status(200).JSON(
-			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewPostDetailResponse(post, likeCount, favouriteCount)),
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewPostDetailResponse(post, counter)),
 		)
 	}
 }
@@ -186,6 +227,11 @@ func (controller *PostController) NewCreatePostHandler() fiber.Handler {
 
 		// 创建博文
 		postInfo, err := controller.postService.CreatePost(claims.UID, ctx.IP(), reqBody)
+		if errors.Is(err, services.ErrSensitiveContent) {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SENSITIVE_CONTENT_ERROR, err.Error()),
+			)
+		}
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
@@ -375,8 +421,17 @@ func (controller *PostController) NewFavouritePostHandler() fiber.Handler {
 			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "post id must be a number"))
 		}
 
+		// 获取收藏夹ID，未指定时收藏到默认收藏夹
+		var folderID uint64
+		if ctx.Query("folder-id") != "" {
+			folderID, err = parseUintQuery(ctx, "folder-id")
+			if err != nil {
+				return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+			}
+		}
+
 		// 执行收藏操作
-		if err := controller.postService.FavouritePost(int64(claims.UID), int64(postIDUint)); err != nil {
+		if err := controller.postService.FavouritePost(int64(claims.UID), int64(postIDUint), folderID); err != nil {
 			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
 		}
 
@@ -444,3 +499,59 @@ func (controller *PostController) NewDeletePostHandler() fiber.Handler {
 		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
 	}
 }
+
+// NewCommentPermissionHandler 返回一个用于处理修改博文评论权限请求的 Fiber 处理函数
+//
+// 返回值：
+//   - fiber.Handler：新的修改博文评论权限函数
+func (controller *PostController) NewCommentPermissionHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.PostCommentPermissionBody)
+		if err := ctx.BodyParser(reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		if reqBody.PostID == nil || reqBody.Permission == "" {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "post_id and permission are required"))
+		}
+
+		// 执行修改评论权限操作
+		if err := controller.postService.UpdateCommentPermission(claims.UID, *reqBody.PostID, reqBody.Permission); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewPostAnalyticsHandler 返回一个用于处理获取博文数据分析请求的 Fiber 处理函数
+//
+// 返回值：
+//   - fiber.Handler：新的获取博文数据分析函数
+func (controller *PostController) NewPostAnalyticsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求参数
+		postID, err := parseUintQuery(ctx, "post-id")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		days, err := parseLengthQuery(ctx, "days", consts.POST_ANALYTICS_DEFAULT_DAYS, consts.POST_ANALYTICS_MAX_DAYS)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		// 获取博文数据分析
+		analytics, err := controller.postService.GetPostAnalytics(claims.UID, postID, days)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewPostAnalyticsResponse(analytics)))
+	}
+}
@@ -9,6 +9,7 @@ Copyright (c) [2024], Author(s):
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
@@ -17,11 +18,13 @@ import (
 	"github.com/Kirisakiii/neko-micro-blog-backend/types"
 	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // ReplyController 博文控制器结构体
 type ReplyController struct {
-	replyService *services.ReplyService
+	replyService  *services.ReplyService
+	threadService *services.ThreadService
 }
 
 // NewReplyController 博文控制器工厂函数。
@@ -30,7 +33,8 @@ type ReplyController struct {
 //   - *ReplyController 博文控制器指针
 func (factory *Factory) NewReplyController() *ReplyController {
 	return &ReplyController{
-		replyService: factory.serviceFactory.NewReplyService(),
+		replyService:  factory.serviceFactory.NewReplyService(),
+		threadService: factory.serviceFactory.NewThreadService(),
 	}
 }
 
@@ -68,7 +72,7 @@ func (controller *ReplyController) NewCreateReplyHandler(commentStore *stores.Co
 		err := controller.replyService.CreateReply(claims.UID, reqBody.CommentID, reqBody.ParentReplyID, reqBody.Content, commentStore, userStore)
 		if err != nil {
 			return ctx.Status(200).JSON(
-				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+				serializers.NewResponse(commentPermissionErrorCode(err), err.Error()),
 			)
 		}
 
@@ -145,7 +149,7 @@ func (controller *ReplyController) NewUpdateReplyHandler() fiber.Handler {
 		err = controller.replyService.UpdateReply(claims.UID, reqBody.ReplyID, reqBody.Content)
 		if err != nil {
 			return ctx.Status(200).JSON(
-				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+				serializers.NewResponse(commentPermissionErrorCode(err), err.Error()),
 			)
 		}
 
@@ -176,8 +180,78 @@ func (controller *ReplyController) NewGetReplyListHandler() fiber.Handler {
 			)
 		}
 
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 解析排序和分页参数
+		sortMode, cursor, length, err := parseSortedPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
 		// 调用服务方法获取回复列表
-		replyList, err := controller.replyService.GetReplyList(commentIDUint64)
+		replyList, nextCursor, err := controller.replyService.GetReplyList(commentIDUint64, viewerUID, sortMode, cursor, length)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		// 成功时返回响应
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewReplyListResponse(replyList, nextCursor)),
+		)
+	}
+}
+
+// NewGetReplyTreeHandler 处理获取回复子树的请求，用于加载评论树中未展开的回复。
+//
+// 返回：
+//   - fiber.Handler：获取回复子树的请求handler
+func (controller *ReplyController) NewGetReplyTreeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析查询参数
+		commentID, err := parseUintQuery(ctx, "comment-id")
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+		var parentReplyID uint64
+		if ctx.Query("parent-reply-id") != "" {
+			parentReplyID, err = parseUintQuery(ctx, "parent-reply-id")
+			if err != nil {
+				return ctx.Status(200).JSON(
+					serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+				)
+			}
+		}
+		length, err := parseLengthQuery(ctx, "len", consts.COMMENT_TREE_DEFAULT_REPLY_LENGTH, consts.COMMENT_TREE_MAX_REPLY_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+		depth, err := parseLengthQuery(ctx, "depth", consts.COMMENT_TREE_DEFAULT_DEPTH, consts.COMMENT_TREE_MAX_DEPTH)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 获取查看者ID，未登录时为 0
+		var viewerUID uint64
+		if claims, ok := ctx.Locals("claims").(*types.BearerTokenClaims); ok {
+			viewerUID = claims.UID
+		}
+
+		// 调用服务方法获取回复子树
+		tree, err := controller.threadService.GetReplyTree(commentID, parentReplyID, viewerUID, ctx.Query("cursor"), length, depth)
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
@@ -186,7 +260,7 @@ func (controller *ReplyController) NewGetReplyListHandler() fiber.Handler {
 
 		// 成功时返回响应
 		return ctx.Status(200).JSON(
-			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewReplyListResponse(replyList)),
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewReplyTreeResponse(tree)),
 		)
 	}
 }
@@ -212,8 +286,109 @@ func (controller *ReplyController) NewGetReplyDetailHandler() fiber.Handler {
 		}
 
 		// 调用服务方法获取回复
-		reply, err := controller.replyService.GetReplyDetail(replyIDUint64)
+		reply, likes, dislikes, err := controller.replyService.GetReplyDetail(replyIDUint64, getViewerUID(ctx))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, "reply does not exist"),
+			)
+		}
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		// 成功时返回响应
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewReplyDetailResponse(reply, likes, dislikes)),
+		)
+	}
+}
+
+// NewReplyUserStatusHandler 处理获取用户对回复的点赞和点踩状态的请求。
+//
+// 返回：
+//   - fiber.Handler：获取用户对回复状态的请求handler
+func (controller *ReplyController) NewReplyUserStatusHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		replyID, err := parseUintQuery(ctx, "reply-id")
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 获取Token Claims
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 调用服务方法获取用户对回复的状态
+		liked, disliked, err := controller.replyService.GetReplyUserStatus(claims.UID, replyID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		// 成功时返回响应
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewReplyUserStatusResponse(liked, disliked)),
+		)
+	}
+}
+
+// NewLikeReplyHandler 处理点赞回复的请求。
+//
+// 返回：
+//   - fiber.Handler：点赞回复的请求handler
+func (controller *ReplyController) NewLikeReplyHandler() fiber.Handler {
+	return newReplyRateHandler(controller.replyService.LikeReply)
+}
+
+// NewCancelLikeReplyHandler 处理取消点赞回复的请求。
+//
+// 返回：
+//   - fiber.Handler：取消点赞回复的请求handler
+func (controller *ReplyController) NewCancelLikeReplyHandler() fiber.Handler {
+	return newReplyRateHandler(controller.replyService.CancelLikeReply)
+}
+
+// NewDislikeReplyHandler 处理点踩回复的请求。
+//
+// 返回：
+//   - fiber.Handler：点踩回复的请求handler
+func (controller *ReplyController) NewDislikeReplyHandler() fiber.Handler {
+	return newReplyRateHandler(controller.replyService.DislikeReply)
+}
+
+// NewCancelDislikeReplyHandler 处理取消点踩回复的请求。
+//
+// 返回：
+//   - fiber.Handler：取消点踩回复的请求handler
+func (controller *ReplyController) NewCancelDislikeReplyHandler() fiber.Handler {
+	return newReplyRateHandler(controller.replyService.CancelDislikeReply)
+}
+
+// newReplyRateHandler 创建评价回复的请求handler
+//
+// 参数：
+//   - rate：评价操作
+//
+// 返回：
+//   - fiber.Handler：评价回复的请求handler
+func newReplyRateHandler(rate func(uid, replyID uint64) error) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		replyID, err := parseUintQuery(ctx, "reply-id")
 		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 获取Token Claims
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 调用服务方法评价回复
+		if err := rate(claims.UID, replyID); err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
 			)
@@ -221,7 +396,7 @@ func (controller *ReplyController) NewGetReplyDetailHandler() fiber.Handler {
 
 		// 成功时返回响应
 		return ctx.Status(200).JSON(
-			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewReplyDetailResponse(reply)),
+			serializers.NewResponse(consts.SUCCESS, "succeed"),
 		)
 	}
 }
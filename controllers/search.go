@@ -7,11 +7,14 @@ Copyright (c) [2024], Author(s):
 package controllers
 
 import (
+	"errors"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
-	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
 	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
 	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
 	"github.com/gofiber/fiber/v2"
 )
@@ -20,9 +23,145 @@ type SearchController struct {
 	searchService *services.SearchService
 }
 
-func (factory *Factory) NewSearchController(searchServiceClient search.SearchEngineClient) *SearchController {
+func (factory *Factory) NewSearchController() *SearchController {
 	return &SearchController{
-		searchService: factory.serviceFactory.NewSearchService(searchServiceClient),
+		searchService: factory.serviceFactory.NewSearchService(),
+	}
+}
+
+// parseDateQuery 解析日期查询参数，格式为 YYYY-MM-DD，参数为空时返回零值
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//   - key：参数名
+//
+// 返回值：
+//   - time.Time：当天零点的本地时间
+//   - error：参数格式不正确时返回错误
+func parseDateQuery(ctx *fiber.Ctx, key string) (time.Time, error) {
+	valueString := ctx.Query(key)
+	if valueString == "" {
+		return time.Time{}, nil
+	}
+	value, err := time.ParseInLocation(time.DateOnly, valueString, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New(key + " is invalid")
+	}
+	return value, nil
+}
+
+// parseSearchPageQuery 解析搜索结果的偏移量和每页数量
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//
+// 返回值：
+//   - int：结果偏移量，未提供时为 0
+//   - int：每页数量
+//   - error：参数格式不正确时返回错误
+func parseSearchPageQuery(ctx *fiber.Ctx) (int, int, error) {
+	var offset int
+	if ctx.Query("offset") != "" {
+		value, err := parseUintQuery(ctx, "offset")
+		if err != nil {
+			return 0, 0, err
+		}
+		offset = int(min(value, consts.SEARCH_MAX_OFFSET))
+	}
+	length, err := parseLengthQuery(ctx, "len", consts.SEARCH_DEFAULT_LENGTH, consts.SEARCH_MAX_LENGTH)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, length, nil
+}
+
+// parseKeywordQuery 解析搜索关键字，去除首尾空白和指定的前缀符号
+//
+// 参数：
+//   - ctx：Fiber 上下文
+//   - prefix：需要去除的前缀符号，如 @ 或 #
+//
+// 返回值：
+//   - string：搜索关键字
+//   - error：关键字为空时返回错误
+func parseKeywordQuery(ctx *fiber.Ctx, prefix string) (string, error) {
+	keyword := strings.TrimPrefix(strings.TrimSpace(ctx.Query("q")), prefix)
+	if keyword == "" {
+		return "", errors.New("query content is required")
+	}
+	return keyword, nil
+}
+
+// NewSearchHandler 创建一个新的搜索handler，根据 type 参数搜索博文、用户或话题，默认搜索博文
+func (controller *SearchController) NewSearchHandler() fiber.Handler {
+	postHandler := controller.NewSearchPostHandler()
+	userHandler := controller.NewSearchUserHandler()
+	hashtagHandler := controller.NewSearchHashtagHandler()
+	return func(ctx *fiber.Ctx) error {
+		switch ctx.Query("type", consts.SEARCH_TYPE_POST) {
+		case consts.SEARCH_TYPE_POST:
+			return postHandler(ctx)
+		case consts.SEARCH_TYPE_USER:
+			return userHandler(ctx)
+		case consts.SEARCH_TYPE_HASHTAG:
+			return hashtagHandler(ctx)
+		default:
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, "type is invalid"),
+			)
+		}
+	}
+}
+
+// NewSearchUserHandler 创建一个新的搜索用户的handler
+func (controller *SearchController) NewSearchUserHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求参数
+		keyword, err := parseKeywordQuery(ctx, "@")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		offset, length, err := parseSearchPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		uids, nextCursor, err := controller.searchService.SearchUsers(keyword, getViewerUID(ctx), ctx.Query("cursor"), offset, length)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "", serializers.NewUserSearchResponse(uids, nextCursor)),
+		)
+	}
+}
+
+// NewSearchHashtagHandler 创建一个新的搜索话题的handler
+func (controller *SearchController) NewSearchHashtagHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求参数
+		keyword, err := parseKeywordQuery(ctx, "#")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		offset, length, err := parseSearchPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		hashtags, nextCursor, err := controller.searchService.SearchHashtags(keyword, getViewerUID(ctx), ctx.Query("cursor"), offset, length)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "", serializers.NewHashtagSearchResponse(hashtags, nextCursor)),
+		)
 	}
 }
 
@@ -44,8 +183,159 @@ func (controller *SearchController) NewSearchPostHandler() fiber.Handler {
 				serializers.NewResponse(consts.PARAMETER_ERROR, "query content is invalid"),
 			)
 		}
+		query := types.PostSearchQuery{
+			Query:  decodedQueryString,
+			Sort:   ctx.Query("sort", consts.SEARCH_SORT_RELEVANCE),
+			Cursor: ctx.Query("cursor"),
+		}
+
+		// 解析排序方式
+		if query.Sort != consts.SEARCH_SORT_RELEVANCE && query.Sort != consts.SEARCH_SORT_RECENCY {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, "sort is invalid"),
+			)
+		}
+
+		// 解析作者筛选
+		if ctx.Query("author") != "" {
+			query.AuthorIDs, err = parseIDListQuery(ctx, "author", consts.SEARCH_MAX_AUTHORS)
+			if err != nil {
+				return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+			}
+		}
+
+		// 解析发布日期范围，截止日期包含当天
+		query.Since, err = parseDateQuery(ctx, "since")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		until, err := parseDateQuery(ctx, "until")
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		if !until.IsZero() {
+			query.Until = until.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		if !query.Since.IsZero() && !query.Until.IsZero() && query.Since.After(query.Until) {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, "since must not be after until"),
+			)
+		}
+
+		// 解析分页参数
+		query.Offset, query.Length, err = parseSearchPageQuery(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		result, err := controller.searchService.SearchPost(query, getViewerUID(ctx))
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "", serializers.NewPostSearchResponse(result)),
+		)
+	}
+}
+
+// NewAutocompleteHandler 创建一个新的搜索自动补全的handler
+func (controller *SearchController) NewAutocompleteHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求参数
+		prefix := strings.TrimSpace(ctx.Query("q"))
+		if prefix == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, "query content is required"),
+			)
+		}
+		length, err := parseLengthQuery(ctx, "len", consts.AUTOCOMPLETE_DEFAULT_LENGTH, consts.AUTOCOMPLETE_MAX_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		suggestions, err := controller.searchService.Autocomplete(prefix, getViewerUID(ctx), length)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "", serializers.NewAutocompleteResponse(suggestions)),
+		)
+	}
+}
+
+// NewRecentSearchListHandler 创建一个新的获取最近搜索的handler
+func (controller *SearchController) NewRecentSearchListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		searches, err := controller.searchService.GetRecentSearches(claims.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "", serializers.NewRecentSearchListResponse(searches)),
+		)
+	}
+}
+
+// NewClearRecentSearchHandler 创建一个新的清除最近搜索的handler
+func (controller *SearchController) NewClearRecentSearchHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		if err := controller.searchService.ClearRecentSearches(claims.UID); err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		return ctx.Status(200).JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
+
+// NewSearchPrivacyHandler 创建一个新的获取搜索隐私设置的handler
+func (controller *SearchController) NewSearchPrivacyHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		setting, err := controller.searchService.GetPrivacySetting(claims.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "", serializers.NewSearchPrivacyResponse(setting)),
+		)
+	}
+}
+
+// NewUpdateSearchPrivacyHandler 创建一个新的修改搜索隐私设置的handler
+func (controller *SearchController) NewUpdateSearchPrivacyHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody := new(types.SearchPrivacyBody)
+		if err := ctx.BodyParser(reqBody); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "invalid request body"))
+		}
 
-		result, err := controller.searchService.SearchPost(decodedQueryString)
+		setting, err := controller.searchService.UpdatePrivacySetting(claims.UID, reqBody)
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
@@ -53,7 +343,7 @@ func (controller *SearchController) NewSearchPostHandler() fiber.Handler {
 		}
 
 		return ctx.Status(200).JSON(
-			serializers.NewResponse(consts.SUCCESS, "", serializers.NewPostListResponse(result.Ids)),
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewSearchPrivacyResponse(setting)),
 		)
 	}
 }
@@ -0,0 +1,95 @@
+/*
+Package controllers - NekoBlog backend server controllers.
+This file is for follow suggestion controller, which is used to create handlee follow suggestion related requests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
+)
+
+// SuggestionController 关注推荐控制器结构体
+type SuggestionController struct {
+	suggestionService *services.SuggestionService
+}
+
+// NewSuggestionController 创建关注推荐控制器实例
+//
+// 返回：
+//   - *SuggestionController: 返回一个新的关注推荐控制器实例。
+func (factory *Factory) NewSuggestionController() *SuggestionController {
+	return &SuggestionController{
+		suggestionService: factory.serviceFactory.NewSuggestionService(),
+	}
+}
+
+// NewSuggestionListHandler 返回一个用于处理获取关注推荐请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取关注推荐函数
+func (controller *SuggestionController) NewSuggestionListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取数量
+		length := consts.FOLLOW_SUGGESTION_DEFAULT_LENGTH
+		if lengthString := ctx.Query("len"); lengthString != "" {
+			var err error
+			length, err = strconv.Atoi(lengthString)
+			if err != nil || length <= 0 {
+				return ctx.Status(200).JSON(
+					serializers.NewResponse(consts.PARAMETER_ERROR, "len is invalid"),
+				)
+			}
+			if length > consts.FOLLOW_SUGGESTION_CACHE_SIZE {
+				length = consts.FOLLOW_SUGGESTION_CACHE_SIZE
+			}
+		}
+
+		// 执行获取关注推荐操作
+		items, err := controller.suggestionService.GetSuggestions(claims.UID, length)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewFollowSuggestionListResponse(items)),
+		)
+	}
+}
+
+// NewDismissSuggestionHandler 返回一个用于处理忽略关注推荐请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的忽略关注推荐函数
+func (controller *SuggestionController) NewDismissSuggestionHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取令牌声明
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 获取被忽略的用户ID
+		reqBody := new(types.UserRelationBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil || reqBody.UserID == 0 {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, "user_id is required"))
+		}
+
+		// 执行忽略关注推荐操作
+		if err := controller.suggestionService.DismissSuggestion(claims.UID, reqBody.UserID); err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed"))
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Package controllers - NekoBlog backend server controllers.
+This file is for trending controller, which is used to create handlee trending posts and hashtags related requests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/services"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/serializers"
+)
+
+// TrendingController 热门控制器结构体
+type TrendingController struct {
+	trendingService *services.TrendingService
+	logger          *logrus.Logger
+}
+
+// NewTrendingController 创建热门控制器实例
+//
+// 返回：
+//   - *TrendingController: 返回一个新的热门控制器实例。
+func (factory *Factory) NewTrendingController() *TrendingController {
+	return &TrendingController{
+		trendingService: factory.serviceFactory.NewTrendingService(),
+		logger:          factory.logger,
+	}
+}
+
+// NewHotPostListHandler 返回一个用于处理获取热门博文列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取热门博文列表函数
+func (controller *TrendingController) NewHotPostListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		length, err := parseLengthQuery(ctx, "len", consts.POST_HOT_DEFAULT_LENGTH, consts.POST_HOT_MAX_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		ids, nextCursor, err := controller.trendingService.GetHotPosts(getViewerUID(ctx), ctx.Query("cursor"), length)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		// 记录热门列表中博文的曝光，记录失败不影响返回结果
+		if err := controller.trendingService.RecordImpressions(ids); err != nil {
+			controller.logger.Warnln("记录博文曝光失败:", err.Error())
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewHotPostListResponse(ids, nextCursor)))
+	}
+}
+
+// NewTrendingHashtagListHandler 返回一个用于处理获取热门话题列表请求的 Fiber 处理函数
+//
+// 返回：
+//   - fiber.Handler: 新的获取热门话题列表函数
+func (controller *TrendingController) NewTrendingHashtagListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		hours, err := parseLengthQuery(ctx, "hours", consts.HASHTAG_TRENDING_DEFAULT_HOURS, consts.HASHTAG_TRENDING_MAX_HOURS)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+		length, err := parseLengthQuery(ctx, "len", consts.HASHTAG_TRENDING_DEFAULT_LENGTH, consts.HASHTAG_TRENDING_MAX_LENGTH)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()))
+		}
+
+		hashtags, err := controller.trendingService.GetTrendingHashtags(hours, length)
+		if err != nil {
+			return ctx.Status(200).JSON(serializers.NewResponse(consts.SERVER_ERROR, err.Error()))
+		}
+
+		return ctx.JSON(serializers.NewResponse(consts.SUCCESS, "succeed", serializers.NewTrendingHashtagListResponse(hashtags)))
+	}
+}
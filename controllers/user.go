@@ -120,6 +120,11 @@ func (controller *UserController) NewRegisterHandler() fiber.Handler {
 
 		// 注册用户
 		err = controller.userService.RegisterUser(reqBody.Username, reqBody.Password)
+		if errors.Is(err, services.ErrSensitiveContent) {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SENSITIVE_CONTENT_ERROR, err.Error()),
+			)
+		}
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
@@ -170,6 +175,16 @@ func (controller *UserController) NewLoginHandler() fiber.Handler {
 
 		// 登陆
 		token, err := controller.userService.LoginUser(reqBody.Username, reqBody.Password, ctx.IP(), browserInfo, os)
+		var suspendedErr *services.AccountSuspendedError
+		if errors.As(err, &suspendedErr) {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(
+					consts.ACCOUNT_SUSPENDED_ERROR,
+					err.Error(),
+					serializers.NewAccountSuspensionResponse(suspendedErr.Suspension),
+				),
+			)
+		}
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
@@ -289,6 +304,11 @@ func (controller *UserController) NewUpdateProfileHandler() fiber.Handler {
 
 		// 更新用户资料
 		err = controller.userService.UpdateUserInfo(claims.UID, reqBody)
+		if errors.Is(err, services.ErrSensitiveContent) {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SENSITIVE_CONTENT_ERROR, err.Error()),
+			)
+		}
 		if err != nil {
 			return ctx.Status(500).JSON(
 				serializers.NewResponse(consts.SERVER_ERROR, "failed to update profile"),
@@ -301,3 +321,43 @@ func (controller *UserController) NewUpdateProfileHandler() fiber.Handler {
 		)
 	}
 }
+
+// NewUpdatePrivacyHandler 返回更新用户隐私设置的处理函数。
+//
+// 返回值：
+//   - fiber.Handler：新的更新用户隐私设置的处理函数。
+func (controller *UserController) NewUpdatePrivacyHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求体
+		reqBody := new(types.UserUpdatePrivacyBody)
+		err := ctx.BodyParser(reqBody)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, err.Error()),
+			)
+		}
+
+		// 校验参数
+		if reqBody.IsPrivate == nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.PARAMETER_ERROR, "is_private is required"),
+			)
+		}
+
+		// 获取Token Claims
+		claims := ctx.Locals("claims").(*types.BearerTokenClaims)
+
+		// 更新隐私设置
+		err = controller.userService.UpdateUserPrivacy(claims.UID, *reqBody.IsPrivate)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		// 返回成功的 JSON 响应
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(consts.SUCCESS, "privacy updated successfully"),
+		)
+	}
+}
@@ -0,0 +1,44 @@
+package crons
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// AnalyticsRollupJob 博文每日统计汇总任务
+type AnalyticsRollupJob struct {
+	logger         *logrus.Logger         // 日志记录器
+	analyticsStore *stores.AnalyticsStore // 博文数据分析存储
+}
+
+// NewAnalyticsRollupJob 创建一个新的博文每日统计汇总任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *AnalyticsRollupJob：新的博文每日统计汇总任务。
+func NewAnalyticsRollupJob(logger *logrus.Logger, storeFactory *stores.Factory) *AnalyticsRollupJob {
+	return &AnalyticsRollupJob{
+		logger:         logger,
+		analyticsStore: storeFactory.NewAnalyticsStore(),
+	}
+}
+
+// Run 执行博文每日统计汇总任务，汇总前一日和当日的数据，前一日的数据在跨日后得以补全，互动数按当日增量还原为前一日结束时的值。
+func (job *AnalyticsRollupJob) Run() {
+	job.logger.Debugln("正在执行博文每日统计汇总任务...")
+
+	now := time.Now()
+	for _, date := range []time.Time{now.AddDate(0, 0, -1), now} {
+		if _, err := job.analyticsStore.RollupDay(date); err != nil {
+			job.logger.Errorln("汇总博文每日统计失败:", err)
+		}
+	}
+
+	job.logger.Debugln("博文每日统计汇总任务执行完毕")
+}
@@ -0,0 +1,95 @@
+package crons
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// CounterFlushJob 互动计数回写任务
+type CounterFlushJob struct {
+	logger       *logrus.Logger       // 日志记录器
+	counterStore *stores.CounterStore // 互动计数存储
+}
+
+// NewCounterFlushJob 创建一个新的互动计数回写任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *CounterFlushJob：新的互动计数回写任务。
+func NewCounterFlushJob(logger *logrus.Logger, storeFactory *stores.Factory) *CounterFlushJob {
+	return &CounterFlushJob{
+		logger:       logger,
+		counterStore: storeFactory.NewCounterStore(),
+	}
+}
+
+// Run 执行互动计数回写任务，将 Redis 中被修改过的博文计数写入数据库。
+func (job *CounterFlushJob) Run() {
+	job.logger.Debugln("正在执行互动计数回写任务...")
+
+	for {
+		flushed, err := job.counterStore.FlushPostCounters(consts.POST_COUNTER_FLUSH_BATCH)
+		if err != nil {
+			job.logger.Errorln("回写互动计数失败:", err)
+			return
+		}
+		if flushed < consts.POST_COUNTER_FLUSH_BATCH {
+			break
+		}
+	}
+
+	job.logger.Debugln("互动计数回写任务执行完毕")
+}
+
+// CounterReconcileJob 互动计数对账任务
+type CounterReconcileJob struct {
+	logger       *logrus.Logger       // 日志记录器
+	counterStore *stores.CounterStore // 互动计数存储
+}
+
+// NewCounterReconcileJob 创建一个新的互动计数对账任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *CounterReconcileJob：新的互动计数对账任务。
+func NewCounterReconcileJob(logger *logrus.Logger, storeFactory *stores.Factory) *CounterReconcileJob {
+	return &CounterReconcileJob{
+		logger:       logger,
+		counterStore: storeFactory.NewCounterStore(),
+	}
+}
+
+// Run 执行互动计数对账任务，逐批以点赞、收藏、评论和转发记录校正博文计数。
+func (job *CounterReconcileJob) Run() {
+	job.logger.Debugln("正在执行互动计数对账任务...")
+
+	var (
+		afterID uint64
+		total   int
+	)
+	for {
+		lastID, repaired, err := job.counterStore.ReconcilePostCounters(afterID, consts.POST_COUNTER_RECONCILE_BATCH)
+		if err != nil {
+			job.logger.Errorln("互动计数对账失败:", err)
+			return
+		}
+		total += repaired
+		if lastID == 0 {
+			break
+		}
+		afterID = lastID
+	}
+	if total > 0 {
+		job.logger.Warnln("互动计数对账校正了", total, "篇博文的计数")
+	}
+
+	job.logger.Debugln("互动计数对账任务执行完毕")
+}
@@ -1,11 +1,15 @@
 package crons
 
 import (
+	"fmt"
+
 	"github.com/redis/go-redis/v9"
 	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
 	"github.com/Kirisakiii/neko-micro-blog-backend/utils/jobs"
 )
 
@@ -15,7 +19,8 @@ import (
 //   - logger：日志记录器
 //   - db：数据库连接
 //   - redisClient：Redis 连接
-func InitJobs(logger *logrus.Logger, db *gorm.DB, redisClient *redis.Client) {
+//   - storeFactory：数据访问层工厂
+func InitJobs(logger *logrus.Logger, db *gorm.DB, redisClient *redis.Client, storeFactory *stores.Factory) {
 	// 创建定时任务
 	crontab := cron.New()
 
@@ -34,6 +39,58 @@ func InitJobs(logger *logrus.Logger, db *gorm.DB, redisClient *redis.Client) {
 	if err != nil {
 		logger.Panicln(err.Error())
 	}
+	// 关注推荐预计算任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, "@every 6h", NewFollowSuggestionJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+
+	// 互动计数回写任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, "@every 1m", NewCounterFlushJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+	// 互动计数对账任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, "@every 6h", NewCounterReconcileJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+
+	// 博文每日统计汇总任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, "@every 1h", NewAnalyticsRollupJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+
+	// 博文热度衰减任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, fmt.Sprintf("@every %ds", consts.POST_HOT_DECAY_INTERVAL), NewHotScoreDecayJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+
+	// 搜索索引同步任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, fmt.Sprintf("@every %ds", consts.SEARCH_INDEX_SYNC_INTERVAL), NewSearchIndexSyncJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+
+	// 搜索服务健康检查任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, fmt.Sprintf("@every %ds", consts.SEARCH_HEALTH_CHECK_INTERVAL), NewSearchHealthCheckJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+
+	// 到期处罚自动解除任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, fmt.Sprintf("@every %ds", consts.MODERATION_SANCTION_LIFT_INTERVAL), NewSanctionLiftJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
+
+	// 敏感词库热加载任务
+	_, err = jobs.AddSkipIfStillRunningJob(crontab, fmt.Sprintf("@every %ds", consts.SENSITIVE_WORD_RELOAD_INTERVAL), NewSensitiveWordReloadJob(logger, storeFactory))
+	if err != nil {
+		logger.Panicln(err.Error())
+	}
 
 	// 启动定时任务
 	crontab.Start()
@@ -0,0 +1,62 @@
+package crons
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// SanctionLiftJob 到期处罚自动解除任务
+type SanctionLiftJob struct {
+	logger          *logrus.Logger          // 日志记录器
+	moderationStore *stores.ModerationStore // 举报与审核存储
+}
+
+// NewSanctionLiftJob 创建一个新的到期处罚自动解除任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *SanctionLiftJob：新的到期处罚自动解除任务。
+func NewSanctionLiftJob(logger *logrus.Logger, storeFactory *stores.Factory) *SanctionLiftJob {
+	return &SanctionLiftJob{
+		logger:          logger,
+		moderationStore: storeFactory.NewModerationStore(),
+	}
+}
+
+// Run 执行到期处罚自动解除任务，解除已到期的暂停、封禁和限流，并修正与处罚记录不一致的限流标记。
+func (job *SanctionLiftJob) Run() {
+	job.logger.Debugln("正在执行到期处罚自动解除任务...")
+
+	var lifted int
+	for {
+		count, err := job.moderationStore.LiftExpiredSanctions(consts.MODERATION_SANCTION_LIFT_BATCH)
+		lifted += count
+		if err != nil {
+			job.logger.Errorln("解除到期处罚失败:", err)
+			break
+		}
+		if count < consts.MODERATION_SANCTION_LIFT_BATCH {
+			break
+		}
+	}
+	if lifted > 0 {
+		job.logger.Infoln("已自动解除到期处罚，数量:", lifted)
+	}
+
+	// 即使解除失败也修正限流标记，避免到期的限流无法解除
+	corrected, err := job.moderationStore.SyncShadowbannedUsers()
+	if err != nil {
+		job.logger.Errorln("修正限流标记失败:", err)
+		return
+	}
+	if corrected > 0 {
+		job.logger.Infoln("已修正限流标记，用户数:", corrected)
+	}
+
+	job.logger.Debugln("到期处罚自动解除任务执行完毕")
+}
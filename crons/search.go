@@ -0,0 +1,88 @@
+package crons
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// SearchIndexSyncJob 搜索索引同步任务
+type SearchIndexSyncJob struct {
+	logger           *logrus.Logger           // 日志记录器
+	searchIndexStore *stores.SearchIndexStore // 搜索索引同步存储
+}
+
+// NewSearchIndexSyncJob 创建一个新的搜索索引同步任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *SearchIndexSyncJob：新的搜索索引同步任务。
+func NewSearchIndexSyncJob(logger *logrus.Logger, storeFactory *stores.Factory) *SearchIndexSyncJob {
+	return &SearchIndexSyncJob{
+		logger:           logger,
+		searchIndexStore: storeFactory.NewSearchIndexStore(),
+	}
+}
+
+// Run 执行搜索索引同步任务，将发件箱中到期的索引操作写入搜索引擎。
+func (job *SearchIndexSyncJob) Run() {
+	job.logger.Debugln("正在执行搜索索引同步任务...")
+
+	var synced, failed int
+	for {
+		claimed, batchFailed, err := job.searchIndexStore.SyncSearchIndex(consts.SEARCH_INDEX_SYNC_BATCH)
+		synced += claimed - batchFailed
+		failed += batchFailed
+		if err != nil {
+			job.logger.Errorln("同步搜索索引失败:", err)
+			return
+		}
+		// 本批存在失败时说明搜索服务可能不可用，留待下次执行
+		if claimed < consts.SEARCH_INDEX_SYNC_BATCH || batchFailed > 0 {
+			break
+		}
+	}
+	if failed > 0 {
+		job.logger.Warnln("部分搜索索引操作同步失败，将按退避时间重试，失败数:", failed)
+	}
+
+	job.logger.Debugln("搜索索引同步任务执行完毕，同步成功数:", synced)
+}
+
+// SearchHealthCheckJob 搜索服务健康检查任务
+type SearchHealthCheckJob struct {
+	logger           *logrus.Logger           // 日志记录器
+	searchIndexStore *stores.SearchIndexStore // 搜索索引存储
+	unhealthy        bool                     // 上次检查时搜索服务是否不可用
+}
+
+// NewSearchHealthCheckJob 创建一个新的搜索服务健康检查任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *SearchHealthCheckJob：新的搜索服务健康检查任务。
+func NewSearchHealthCheckJob(logger *logrus.Logger, storeFactory *stores.Factory) *SearchHealthCheckJob {
+	return &SearchHealthCheckJob{
+		logger:           logger,
+		searchIndexStore: storeFactory.NewSearchIndexStore(),
+	}
+}
+
+// Run 执行搜索服务健康检查任务，搜索服务不可用时打开熔断器，恢复后关闭熔断器，仅在状态变化时记录日志。
+func (job *SearchHealthCheckJob) Run() {
+	err := job.searchIndexStore.CheckSearchEngineHealth()
+	switch {
+	case err != nil && !job.unhealthy:
+		job.logger.Warnln("搜索服务健康检查失败，搜索将降级:", err)
+	case err == nil && job.unhealthy:
+		job.logger.Infoln("搜索服务已恢复")
+	}
+	job.unhealthy = err != nil
+}
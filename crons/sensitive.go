@@ -0,0 +1,40 @@
+package crons
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// SensitiveWordReloadJob 敏感词库热加载任务
+type SensitiveWordReloadJob struct {
+	logger             *logrus.Logger             // 日志记录器
+	sensitiveWordStore *stores.SensitiveWordStore // 敏感词库存储
+}
+
+// NewSensitiveWordReloadJob 创建一个新的敏感词库热加载任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *SensitiveWordReloadJob：新的敏感词库热加载任务。
+func NewSensitiveWordReloadJob(logger *logrus.Logger, storeFactory *stores.Factory) *SensitiveWordReloadJob {
+	return &SensitiveWordReloadJob{
+		logger:             logger,
+		sensitiveWordStore: storeFactory.NewSensitiveWordStore(),
+	}
+}
+
+// Run 执行敏感词库热加载任务，词库文件发生变化时重新加载，加载失败时继续使用原有词库。
+func (job *SensitiveWordReloadJob) Run() {
+	count, err := job.sensitiveWordStore.ReloadSensitiveWords()
+	if err != nil {
+		job.logger.Errorln("重新加载敏感词库失败，继续使用原有词库:", err)
+		return
+	}
+	if count >= 0 {
+		job.logger.Infoln("敏感词库已重新加载，规则数:", count)
+	}
+}
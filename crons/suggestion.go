@@ -0,0 +1,55 @@
+package crons
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// FollowSuggestionJob 关注推荐预计算任务
+type FollowSuggestionJob struct {
+	logger          *logrus.Logger          // 日志记录器
+	suggestionStore *stores.SuggestionStore // 关注推荐存储
+}
+
+// NewFollowSuggestionJob 创建一个新的关注推荐预计算任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *FollowSuggestionJob：新的关注推荐预计算任务。
+func NewFollowSuggestionJob(logger *logrus.Logger, storeFactory *stores.Factory) *FollowSuggestionJob {
+	return &FollowSuggestionJob{
+		logger:          logger,
+		suggestionStore: storeFactory.NewSuggestionStore(),
+	}
+}
+
+// Run 执行关注推荐预计算任务，为活跃用户计算并缓存关注推荐。
+func (job *FollowSuggestionJob) Run() {
+	job.logger.Debugln("正在执行关注推荐预计算任务...")
+
+	// 获取活跃用户
+	uids, err := job.suggestionStore.GetActiveUIDs(consts.FOLLOW_SUGGESTION_ACTIVE_DAYS)
+	if err != nil {
+		job.logger.Errorln("获取活跃用户列表失败:", err)
+		return
+	}
+
+	// 逐个计算并缓存关注推荐
+	for _, uid := range uids {
+		suggestions, err := job.suggestionStore.ComputeSuggestions(uid, consts.FOLLOW_SUGGESTION_CACHE_SIZE)
+		if err != nil {
+			job.logger.Errorln("计算关注推荐失败:", err)
+			continue
+		}
+		if err := job.suggestionStore.CacheSuggestions(uid, suggestions); err != nil {
+			job.logger.Errorln("缓存关注推荐失败:", err)
+		}
+	}
+
+	job.logger.Debugln("关注推荐预计算任务执行完毕")
+}
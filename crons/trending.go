@@ -0,0 +1,50 @@
+package crons
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/calculators"
+)
+
+// HotScoreDecayJob 博文热度衰减任务
+type HotScoreDecayJob struct {
+	logger        *logrus.Logger        // 日志记录器
+	trendingStore *stores.TrendingStore // 热门存储
+}
+
+// NewHotScoreDecayJob 创建一个新的博文热度衰减任务。
+//
+// 参数：
+//   - logger：日志记录器
+//   - storeFactory：数据访问层工厂
+//
+// 返回值：
+//   - *HotScoreDecayJob：新的博文热度衰减任务。
+func NewHotScoreDecayJob(logger *logrus.Logger, storeFactory *stores.Factory) *HotScoreDecayJob {
+	return &HotScoreDecayJob{
+		logger:        logger,
+		trendingStore: storeFactory.NewTrendingStore(),
+	}
+}
+
+// Run 执行博文热度衰减任务，按半衰期降低所有博文的热度并移除热度过低的博文，随后按作者限额重建热门列表。
+func (job *HotScoreDecayJob) Run() {
+	job.logger.Debugln("正在执行博文热度衰减任务...")
+
+	factor := calculators.DecayFactor(consts.POST_HOT_DECAY_INTERVAL, consts.POST_HOT_HALF_LIFE)
+	removed, err := job.trendingStore.DecayHotScores(factor, consts.POST_HOT_PRUNE_THRESHOLD)
+	if err != nil {
+		job.logger.Errorln("衰减博文热度失败:", err)
+		return
+	}
+
+	size, err := job.trendingStore.RebuildHotFeed()
+	if err != nil {
+		job.logger.Errorln("重建热门列表失败:", err)
+		return
+	}
+
+	job.logger.Debugln("博文热度衰减任务执行完毕，移出热门榜单的博文数:", removed, "热门列表博文数:", size)
+}
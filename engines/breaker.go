@@ -0,0 +1,124 @@
+/*
+Package engines - NekoBlog backend server search engines.
+This file is for circuit breaker of search service calls.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package engines
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器已打开，请求未发送至搜索服务
+var ErrCircuitOpen = errors.New("search service circuit breaker is open")
+
+// breakerState 熔断器状态
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // 关闭，正常放行请求
+	breakerOpen                         // 打开，直接拒绝请求
+	breakerHalfOpen                     // 半开，仅放行一个试探请求
+)
+
+// circuitBreaker 熔断器，连续失败达到阈值后打开，冷却期过后放行一个试探请求，试探成功则关闭
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	failures  int           // 连续失败次数
+	openedAt  time.Time     // 最近一次打开的时间
+	probing   bool          // 半开状态下是否已有试探请求在执行
+	threshold int           // 打开熔断器的连续失败次数
+	cooldown  time.Duration // 打开后放行试探请求前的等待时间
+}
+
+// newCircuitBreaker 返回一个新的熔断器
+//
+// 参数：
+//   - threshold：打开熔断器的连续失败次数
+//   - cooldown：打开后放行试探请求前的等待时间
+//
+// 返回值：
+//   - *circuitBreaker：熔断器
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow 判断是否放行请求，放行后须调用 record 报告结果
+//
+// 返回值：
+//   - error：熔断器打开时返回 ErrCircuitOpen；否则返回 nil
+func (breaker *circuitBreaker) allow() error {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	switch breaker.state {
+	case breakerOpen:
+		if time.Since(breaker.openedAt) < breaker.cooldown {
+			return ErrCircuitOpen
+		}
+		breaker.state = breakerHalfOpen
+		breaker.probing = true
+		return nil
+	case breakerHalfOpen:
+		if breaker.probing {
+			return ErrCircuitOpen
+		}
+		breaker.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record 报告请求结果
+//
+// 参数：
+//   - success：请求是否成功，不代表搜索服务故障的错误视为成功
+func (breaker *circuitBreaker) record(success bool) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.probing = false
+	if success {
+		breaker.state = breakerClosed
+		breaker.failures = 0
+		return
+	}
+	breaker.failures++
+	if breaker.state == breakerHalfOpen || breaker.failures >= breaker.threshold {
+		breaker.state = breakerOpen
+		breaker.openedAt = time.Now()
+	}
+}
+
+// trip 立即打开熔断器，用于健康检查发现搜索服务不可用时
+func (breaker *circuitBreaker) trip() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if breaker.state != breakerOpen {
+		breaker.state = breakerOpen
+		breaker.openedAt = time.Now()
+	}
+}
+
+// reset 关闭熔断器，用于健康检查确认搜索服务恢复时
+func (breaker *circuitBreaker) reset() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.state = breakerClosed
+	breaker.failures = 0
+}
+
+// isOpen 判断熔断器是否处于打开或半开状态
+func (breaker *circuitBreaker) isOpen() bool {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	return breaker.state != breakerClosed
+}
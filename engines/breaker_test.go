@@ -0,0 +1,127 @@
+/*
+Package engines - NekoBlog backend server search engines.
+This file is for circuit breaker tests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package engines
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// breakerStep 熔断器测试的单个操作
+type breakerStep struct {
+	action   string // allow、success、failure、expire、trip 或 reset
+	wantErr  error  // action 为 allow 时期望的返回值
+	wantOpen bool   // 操作后 isOpen 的期望值
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	const threshold = 3
+	tests := []struct {
+		name  string
+		steps []breakerStep
+	}{
+		{
+			name: "未达到阈值时保持关闭",
+			steps: []breakerStep{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow"},
+			},
+		},
+		{
+			name: "连续失败达到阈值后打开",
+			steps: []breakerStep{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "failure", wantOpen: true},
+				{action: "allow", wantErr: ErrCircuitOpen, wantOpen: true},
+			},
+		},
+		{
+			name: "成功后重新计算连续失败次数",
+			steps: []breakerStep{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "success"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow"},
+			},
+		},
+		{
+			name: "冷却期后只放行一个试探请求",
+			steps: []breakerStep{
+				{action: "trip", wantOpen: true},
+				{action: "expire", wantOpen: true},
+				{action: "allow", wantOpen: true},
+				{action: "allow", wantErr: ErrCircuitOpen, wantOpen: true},
+			},
+		},
+		{
+			name: "试探成功后关闭",
+			steps: []breakerStep{
+				{action: "trip", wantOpen: true},
+				{action: "expire", wantOpen: true},
+				{action: "allow", wantOpen: true},
+				{action: "success"},
+				{action: "allow"},
+				{action: "allow"},
+			},
+		},
+		{
+			name: "试探失败后重新打开",
+			steps: []breakerStep{
+				{action: "trip", wantOpen: true},
+				{action: "expire", wantOpen: true},
+				{action: "allow", wantOpen: true},
+				{action: "failure", wantOpen: true},
+				{action: "allow", wantErr: ErrCircuitOpen, wantOpen: true},
+			},
+		},
+		{
+			name: "重置后关闭",
+			steps: []breakerStep{
+				{action: "trip", wantOpen: true},
+				{action: "reset"},
+				{action: "allow"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaker := newCircuitBreaker(threshold, time.Hour)
+			for index, step := range test.steps {
+				switch step.action {
+				case "allow":
+					if err := breaker.allow(); !errors.Is(err, step.wantErr) {
+						t.Fatalf("step %d: allow() = %v, want %v", index, err, step.wantErr)
+					}
+				case "success":
+					breaker.record(true)
+				case "failure":
+					breaker.record(false)
+				case "expire":
+					// 将打开时间提前到冷却期之前，避免测试等待
+					breaker.openedAt = time.Now().Add(-2 * breaker.cooldown)
+				case "trip":
+					breaker.trip()
+				case "reset":
+					breaker.reset()
+				default:
+					t.Fatalf("step %d: unknown action %q", index, step.action)
+				}
+				if open := breaker.isOpen(); open != step.wantOpen {
+					t.Fatalf("step %d (%s): isOpen() = %v, want %v", index, step.action, open, step.wantOpen)
+				}
+			}
+		})
+	}
+}
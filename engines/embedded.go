@@ -0,0 +1,215 @@
+/*
+Package engines - NekoBlog backend server search engines.
+This file is for embedded PostgreSQL full-text search engine.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package engines
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+// upsertDocumentSQL 写入或更新博文文档，标题词元权重为 A，内容词元为默认权重 D
+const upsertDocumentSQL = `INSERT INTO search_post_documents (post_id, uid, title, content, posted_at, tokens)
+VALUES (?, ?, ?, ?, ?, setweight(array_to_tsvector(?::text[]), 'A') || array_to_tsvector(?::text[]))
+ON CONFLICT (post_id) DO UPDATE SET
+	uid = EXCLUDED.uid,
+	title = EXCLUDED.title,
+	content = EXCLUDED.content,
+	posted_at = EXCLUDED.posted_at,
+	tokens = EXCLUDED.tokens`
+
+// EmbeddedEngine 基于 PostgreSQL 全文检索的内置搜索引擎，中文按单字和相邻双字切分词元
+type EmbeddedEngine struct {
+	db *gorm.DB
+}
+
+// NewEmbeddedEngine 返回一个新的内置搜索引擎实例
+//
+// 参数：
+//   - db：数据库连接
+//
+// 返回值：
+//   - *EmbeddedEngine：内置搜索引擎实例
+func NewEmbeddedEngine(db *gorm.DB) *EmbeddedEngine {
+	return &EmbeddedEngine{db: db}
+}
+
+// embeddedHit 内置搜索引擎的命中记录
+type embeddedHit struct {
+	PostID  int64   `gorm:"column:post_id"`
+	Title   string  `gorm:"column:title"`
+	Content string  `gorm:"column:content"`
+	Score   float32 `gorm:"column:score"`
+}
+
+// indexTokens 切分索引词元，结果不为 nil 以免生成 NULL 词元
+//
+// 参数：
+//   - text：文本内容
+//
+// 返回值：
+//   - pq.StringArray：索引词元
+func indexTokens(text string) pq.StringArray {
+	tokens := parsers.TokenizeForIndex(text)
+	if tokens == nil {
+		return pq.StringArray{}
+	}
+	return tokens
+}
+
+// upsertDocument 写入或更新博文文档
+//
+// 参数：
+//   - tx：数据库连接或事务
+//   - document：博文文档
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func upsertDocument(tx *gorm.DB, document *search.PostDocument) error {
+	return tx.Exec(upsertDocumentSQL,
+		document.Id,
+		document.Uid,
+		document.Title,
+		document.Content,
+		time.Unix(document.CreatedAt, 0),
+		indexTokens(document.Title),
+		indexTokens(document.Content),
+	).Error
+}
+
+// Name 返回搜索引擎名称
+func (engine *EmbeddedEngine) Name() string {
+	return consts.SEARCH_ENGINE_EMBEDDED
+}
+
+// CreatePostIndex 创建博文索引
+func (engine *EmbeddedEngine) CreatePostIndex(ctx context.Context, request *search.CreatePostIndexRequest) error {
+	return upsertDocument(engine.db.WithContext(ctx), &search.PostDocument{
+		Id:        request.Id,
+		Title:     request.Title,
+		Content:   request.Content,
+		Uid:       request.Uid,
+		CreatedAt: request.CreatedAt,
+	})
+}
+
+// UpdatePostIndex 更新博文索引
+func (engine *EmbeddedEngine) UpdatePostIndex(ctx context.Context, request *search.UpdatePostIndexRequest) error {
+	return upsertDocument(engine.db.WithContext(ctx), &search.PostDocument{
+		Id:        request.Id,
+		Title:     request.Title,
+		Content:   request.Content,
+		Uid:       request.Uid,
+		CreatedAt: request.CreatedAt,
+	})
+}
+
+// DeletePostIndex 删除博文索引
+func (engine *EmbeddedEngine) DeletePostIndex(ctx context.Context, request *search.DeletePostIndexRequest) error {
+	return engine.db.WithContext(ctx).Where("post_id = ?", request.Id).Delete(&models.SearchPostDocument{}).Error
+}
+
+// BulkIndexPosts 在一个事务中批量写入博文索引
+func (engine *EmbeddedEngine) BulkIndexPosts(ctx context.Context, documents []*search.PostDocument) (int64, error) {
+	err := engine.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, document := range documents {
+			if err := upsertDocument(tx, document); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(documents)), nil
+}
+
+// filterDocuments 构造按查询词元、作者和发布时间过滤的查询
+//
+// 参数：
+//   - ctx：上下文
+//   - request：搜索请求
+//   - tsquery：查询词元组成的 tsquery
+//
+// 返回值：
+//   - *gorm.DB：查询
+func (engine *EmbeddedEngine) filterDocuments(ctx context.Context, request *search.SearchRequest, tsquery string) *gorm.DB {
+	query := engine.db.WithContext(ctx).Model(&models.SearchPostDocument{}).Where("tokens @@ CAST(? AS tsquery)", tsquery)
+	if len(request.AuthorIds) > 0 {
+		query = query.Where("uid IN ?", request.AuthorIds)
+	}
+	if request.Since != 0 {
+		query = query.Where("posted_at >= ?", time.Unix(request.Since, 0))
+	}
+	if request.Until != 0 {
+		query = query.Where("posted_at <= ?", time.Unix(request.Until, 0))
+	}
+	return query
+}
+
+// Search 搜索博文，所有查询词元均命中的博文视为匹配
+func (engine *EmbeddedEngine) Search(ctx context.Context, request *search.SearchRequest) (*search.SearchResponse, error) {
+	tokens := parsers.TokenizeForQuery(request.Query)
+	if len(tokens) == 0 {
+		return &search.SearchResponse{}, nil
+	}
+	// 词元仅包含字母和数字，无需转义
+	quoted := make([]string, len(tokens))
+	for i, token := range tokens {
+		quoted[i] = "'" + token + "'"
+	}
+	tsquery := strings.Join(quoted, " & ")
+
+	var total int64
+	if err := engine.filterDocuments(ctx, request, tsquery).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	order := "score DESC, post_id DESC"
+	if request.Sort == search.SearchSort_SEARCH_SORT_RECENCY {
+		order = "posted_at DESC, post_id DESC"
+	}
+	limit := int(request.Limit)
+	if limit == 0 {
+		limit = consts.SEARCH_DEFAULT_LENGTH
+	}
+	var hits []embeddedHit
+	result := engine.filterDocuments(ctx, request, tsquery).
+		Select("post_id, title, content, ts_rank(tokens, CAST(? AS tsquery)) AS score", tsquery).
+		Order(order).
+		Offset(int(request.Offset)).
+		Limit(limit).
+		Scan(&hits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	response := &search.SearchResponse{
+		Ids:   make([]int64, len(hits)),
+		Hits:  make([]*search.SearchHit, len(hits)),
+		Total: uint64(total),
+	}
+	terms := parsers.SplitSearchTerms(request.Query)
+	for i, hit := range hits {
+		response.Ids[i] = hit.PostID
+		response.Hits[i] = &search.SearchHit{Id: hit.PostID, Score: hit.Score}
+		if request.Highlight {
+			response.Hits[i].TitleHighlights = highlightSnippets(hit.Title, terms)
+			response.Hits[i].ContentHighlights = highlightSnippets(hit.Content, terms)
+		}
+	}
+	return response, nil
+}
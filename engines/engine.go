@@ -0,0 +1,40 @@
+/*
+Package engines - NekoBlog backend server search engines.
+This file is for search engine abstraction.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package engines
+
+import (
+	"context"
+
+	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
+)
+
+// SearchEngine 博文搜索引擎，请求和响应沿用搜索服务的协议消息
+type SearchEngine interface {
+	// Name 返回搜索引擎名称
+	Name() string
+
+	// CreatePostIndex 创建博文索引
+	CreatePostIndex(ctx context.Context, request *search.CreatePostIndexRequest) error
+
+	// UpdatePostIndex 更新博文索引
+	UpdatePostIndex(ctx context.Context, request *search.UpdatePostIndexRequest) error
+
+	// DeletePostIndex 删除博文索引
+	DeletePostIndex(ctx context.Context, request *search.DeletePostIndexRequest) error
+
+	// BulkIndexPosts 批量写入博文索引，返回确认写入的博文数量
+	BulkIndexPosts(ctx context.Context, documents []*search.PostDocument) (int64, error)
+
+	// Search 搜索博文
+	Search(ctx context.Context, request *search.SearchRequest) (*search.SearchResponse, error)
+}
+
+// HealthChecker 支持健康检查的搜索引擎
+type HealthChecker interface {
+	// CheckHealth 检查搜索引擎是否可用
+	CheckHealth(ctx context.Context) error
+}
@@ -0,0 +1,105 @@
+/*
+Package engines - NekoBlog backend server search engines.
+This file is for failover search engine.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package engines
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
+)
+
+// FailoverEngine 故障转移搜索引擎，索引同时写入主、备搜索引擎，搜索优先使用主搜索引擎，失败时改用备用搜索引擎
+type FailoverEngine struct {
+	primary  SearchEngine
+	fallback SearchEngine
+	logger   *logrus.Logger
+}
+
+// NewFailoverEngine 返回一个新的故障转移搜索引擎实例
+//
+// 参数：
+//   - primary：主搜索引擎
+//   - fallback：备用搜索引擎
+//   - logger：日志记录器
+//
+// 返回值：
+//   - *FailoverEngine：故障转移搜索引擎实例
+func NewFailoverEngine(primary, fallback SearchEngine, logger *logrus.Logger) *FailoverEngine {
+	return &FailoverEngine{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// Name 返回搜索引擎名称
+func (engine *FailoverEngine) Name() string {
+	return consts.SEARCH_ENGINE_AUTO
+}
+
+// CreatePostIndex 创建博文索引，先写入备用搜索引擎，任一写入失败时返回错误以便整体重试
+func (engine *FailoverEngine) CreatePostIndex(ctx context.Context, request *search.CreatePostIndexRequest) error {
+	if err := engine.fallback.CreatePostIndex(ctx, request); err != nil {
+		return err
+	}
+	return engine.primary.CreatePostIndex(ctx, request)
+}
+
+// UpdatePostIndex 更新博文索引，先写入备用搜索引擎，任一写入失败时返回错误以便整体重试
+func (engine *FailoverEngine) UpdatePostIndex(ctx context.Context, request *search.UpdatePostIndexRequest) error {
+	if err := engine.fallback.UpdatePostIndex(ctx, request); err != nil {
+		return err
+	}
+	return engine.primary.UpdatePostIndex(ctx, request)
+}
+
+// DeletePostIndex 删除博文索引，先从备用搜索引擎删除，任一删除失败时返回错误以便整体重试
+func (engine *FailoverEngine) DeletePostIndex(ctx context.Context, request *search.DeletePostIndexRequest) error {
+	if err := engine.fallback.DeletePostIndex(ctx, request); err != nil {
+		return err
+	}
+	return engine.primary.DeletePostIndex(ctx, request)
+}
+
+// BulkIndexPosts 批量写入博文索引，返回主搜索引擎确认写入的博文数量
+func (engine *FailoverEngine) BulkIndexPosts(ctx context.Context, documents []*search.PostDocument) (int64, error) {
+	if _, err := engine.fallback.BulkIndexPosts(ctx, documents); err != nil {
+		return 0, err
+	}
+	return engine.primary.BulkIndexPosts(ctx, documents)
+}
+
+// Search 搜索博文，主搜索引擎失败时使用备用搜索引擎
+func (engine *FailoverEngine) Search(ctx context.Context, request *search.SearchRequest) (*search.SearchResponse, error) {
+	response, err := engine.primary.Search(ctx, request)
+	if err == nil {
+		return response, nil
+	}
+	// 熔断器打开期间每次搜索都会直接降级，不重复记录日志
+	if !errors.Is(err, ErrCircuitOpen) {
+		engine.logger.Warnln("搜索引擎", engine.primary.Name(), "搜索失败，改用", engine.fallback.Name(), "：", err.Error())
+	}
+
+	// 主搜索引擎可能已耗尽请求的超时时间，备用搜索引擎使用独立的超时时间
+	fallbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), consts.SEARCH_QUERY_RPC_TIMEOUT*time.Second)
+	defer cancel()
+	return engine.fallback.Search(fallbackCtx, request)
+}
+
+// CheckHealth 检查主搜索引擎是否可用，备用搜索引擎与主数据库共用连接，不单独检查
+func (engine *FailoverEngine) CheckHealth(ctx context.Context) error {
+	checker, ok := engine.primary.(HealthChecker)
+	if !ok {
+		return nil
+	}
+	return checker.CheckHealth(ctx)
+}
@@ -0,0 +1,229 @@
+/*
+Package engines - NekoBlog backend server search engines.
+This file is for gRPC search service client engine.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package engines
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/configs"
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/calculators"
+)
+
+// GRPCEngine 通过 gRPC 调用外部搜索服务的搜索引擎
+//
+// 每次调用单独设置超时时间，幂等调用在搜索服务暂时不可用时带随机抖动重试；
+// 连续失败后熔断器打开，调用直接返回 ErrCircuitOpen，避免请求堆积在不可用的搜索服务上。
+type GRPCEngine struct {
+	client  search.SearchEngineClient
+	health  grpc_health_v1.HealthClient
+	breaker *circuitBreaker
+}
+
+// NewGRPCEngine 返回一个新的 gRPC 搜索引擎实例
+//
+// 参数：
+//   - conn：搜索服务连接
+//
+// 返回值：
+//   - *GRPCEngine：gRPC 搜索引擎实例
+func NewGRPCEngine(conn grpc.ClientConnInterface) *GRPCEngine {
+	return &GRPCEngine{
+		client:  search.NewSearchEngineClient(conn),
+		health:  grpc_health_v1.NewHealthClient(conn),
+		breaker: newCircuitBreaker(consts.SEARCH_BREAKER_FAILURE_THRESHOLD, consts.SEARCH_BREAKER_OPEN_DURATION*time.Second),
+	}
+}
+
+// NewTransportCredentials 根据配置创建搜索服务连接的传输凭据
+//
+// 参数：
+//   - options：TLS 设置
+//
+// 返回值：
+//   - credentials.TransportCredentials：未启用 TLS 时为明文凭据
+//   - error：如果读取证书失败，返回相应错误信息；否则返回 nil
+func NewTransportCredentials(options configs.SearchServiceTLS) (credentials.TransportCredentials, error) {
+	if !options.Enable {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: options.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if options.CAFile != "" {
+		pem, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificate found in %s", options.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if options.CertFile != "" || options.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// isUnavailable 判断错误是否表示搜索服务暂时不可用，此类错误计入熔断器并允许重试
+func isUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// invoke 经熔断器调用搜索服务，每次尝试单独设置超时时间
+//
+// 参数：
+//   - ctx：上下文，其截止时间限制包括重试在内的总耗时
+//   - idempotent：调用是否幂等，仅幂等调用会重试
+//   - call：实际的调用
+//
+// 返回值：
+//   - error：如果调用失败，返回最后一次尝试的错误信息；否则返回 nil
+func (engine *GRPCEngine) invoke(ctx context.Context, idempotent bool, call func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		if err := engine.breaker.allow(); err != nil {
+			return err
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, consts.SEARCH_GRPC_CALL_TIMEOUT*time.Millisecond)
+		err := call(callCtx)
+		cancel()
+		unavailable := isUnavailable(err)
+		engine.breaker.record(!unavailable)
+		if !unavailable || !idempotent || attempt >= consts.SEARCH_GRPC_MAX_ATTEMPTS {
+			return err
+		}
+
+		delay := calculators.Backoff(attempt, consts.SEARCH_GRPC_RETRY_BASE*time.Millisecond, consts.SEARCH_GRPC_RETRY_MAX*time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Name 返回搜索引擎名称
+func (engine *GRPCEngine) Name() string {
+	return consts.SEARCH_ENGINE_GRPC
+}
+
+// CreatePostIndex 创建博文索引，搜索服务可能拒绝重复创建，因此不重试
+func (engine *GRPCEngine) CreatePostIndex(ctx context.Context, request *search.CreatePostIndexRequest) error {
+	return engine.invoke(ctx, false, func(ctx context.Context) error {
+		_, err := engine.client.CreatePostIndex(ctx, request)
+		return err
+	})
+}
+
+// UpdatePostIndex 更新博文索引
+func (engine *GRPCEngine) UpdatePostIndex(ctx context.Context, request *search.UpdatePostIndexRequest) error {
+	return engine.invoke(ctx, true, func(ctx context.Context) error {
+		_, err := engine.client.UpdatePostIndex(ctx, request)
+		return err
+	})
+}
+
+// DeletePostIndex 删除博文索引
+func (engine *GRPCEngine) DeletePostIndex(ctx context.Context, request *search.DeletePostIndexRequest) error {
+	return engine.invoke(ctx, true, func(ctx context.Context) error {
+		_, err := engine.client.DeletePostIndex(ctx, request)
+		return err
+	})
+}
+
+// BulkIndexPosts 通过客户端流批量写入博文索引，流式调用使用 ctx 的超时时间且不重试，由重建索引的检查点负责续传
+func (engine *GRPCEngine) BulkIndexPosts(ctx context.Context, documents []*search.PostDocument) (int64, error) {
+	if err := engine.breaker.allow(); err != nil {
+		return 0, err
+	}
+	indexed, err := engine.bulkIndexPosts(ctx, documents)
+	engine.breaker.record(!isUnavailable(err))
+	return indexed, err
+}
+
+// bulkIndexPosts 发送博文索引流并等待确认
+func (engine *GRPCEngine) bulkIndexPosts(ctx context.Context, documents []*search.PostDocument) (int64, error) {
+	stream, err := engine.client.BulkIndexPosts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, document := range documents {
+		if err := stream.Send(document); err != nil {
+			// Send 失败时真正的错误需通过 CloseAndRecv 获取
+			_, err = stream.CloseAndRecv()
+			return 0, err
+		}
+	}
+	response, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, err
+	}
+	return response.Indexed, nil
+}
+
+// Search 搜索博文
+func (engine *GRPCEngine) Search(ctx context.Context, request *search.SearchRequest) (*search.SearchResponse, error) {
+	var response *search.SearchResponse
+	err := engine.invoke(ctx, true, func(ctx context.Context) error {
+		var err error
+		response, err = engine.client.Search(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CheckHealth 通过标准 gRPC 健康检查协议检查搜索服务，并据此打开或关闭熔断器
+//
+// 搜索服务未实现健康检查协议时视为可用，由熔断器根据实际调用结果判断。
+func (engine *GRPCEngine) CheckHealth(ctx context.Context) error {
+	response, err := engine.health.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: consts.SEARCH_HEALTH_SERVICE})
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	if err == nil && response.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		err = errors.New("search service is " + response.Status.String())
+	}
+	if err != nil {
+		engine.breaker.trip()
+		return err
+	}
+	if engine.breaker.isOpen() {
+		engine.breaker.reset()
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Package engines - NekoBlog backend server search engines.
+This file is for search result highlighting.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package engines
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+)
+
+// highlightSnippets 在文本中标记检索片段，返回包含命中词的若干片段，片段内容经过 HTML 转义
+//
+// 参数：
+//   - text：原始文本
+//   - terms：小写的检索片段
+//
+// 返回值：
+//   - []string：高亮片段，没有命中时为空
+func highlightSnippets(text string, terms []string) []string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 标记命中的字符
+	matched := make([]bool, len(runes))
+	for _, term := range terms {
+		termRunes := []rune(term)
+		for i := 0; i+len(termRunes) <= len(lower); i++ {
+			if string(lower[i:i+len(termRunes)]) == term {
+				for j := i; j < i+len(termRunes); j++ {
+					matched[j] = true
+				}
+			}
+		}
+	}
+
+	// 以命中词为中心截取片段，相互重叠的片段合并为一个
+	var windows [][2]int
+	for i := 0; i < len(runes); {
+		if !matched[i] {
+			i++
+			continue
+		}
+		end := i
+		for end < len(runes) && matched[end] {
+			end++
+		}
+		start := max(i-consts.SEARCH_HIGHLIGHT_CONTEXT, 0)
+		stop := min(end+consts.SEARCH_HIGHLIGHT_CONTEXT, len(runes))
+		if last := len(windows) - 1; last >= 0 && start <= windows[last][1] {
+			windows[last][1] = stop
+		} else {
+			if len(windows) == consts.SEARCH_HIGHLIGHT_MAX_SNIPPETS {
+				break
+			}
+			windows = append(windows, [2]int{start, stop})
+		}
+		i = end
+	}
+
+	snippets := make([]string, len(windows))
+	for i, window := range windows {
+		snippets[i] = renderSnippet(runes, matched, window[0], window[1])
+	}
+	return snippets
+}
+
+// renderSnippet 渲染片段，为命中的字符加上高亮标签
+//
+// 参数：
+//   - runes：原始文本
+//   - matched：每个字符是否命中
+//   - start：片段起始位置
+//   - end：片段结束位置，不包含该位置
+//
+// 返回值：
+//   - string：高亮片段，截断处以省略号表示
+func renderSnippet(runes []rune, matched []bool, start, end int) string {
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && matched[j] == matched[i] {
+			j++
+		}
+		if matched[i] {
+			builder.WriteString(consts.SEARCH_HIGHLIGHT_PRE_TAG)
+			builder.WriteString(html.EscapeString(string(runes[i:j])))
+			builder.WriteString(consts.SEARCH_HIGHLIGHT_POST_TAG)
+		} else {
+			builder.WriteString(html.EscapeString(string(runes[i:j])))
+		}
+		i = j
+	}
+	if end < len(runes) {
+		builder.WriteString("…")
+	}
+	return builder.String()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,34 +16,36 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 
+	"github.com/Kirisakiii/neko-micro-blog-backend/commands"
 	"github.com/Kirisakiii/neko-micro-blog-backend/configs"
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
 	"github.com/Kirisakiii/neko-micro-blog-backend/controllers"
 	"github.com/Kirisakiii/neko-micro-blog-backend/crons"
+	"github.com/Kirisakiii/neko-micro-blog-backend/engines"
 	"github.com/Kirisakiii/neko-micro-blog-backend/loggers"
 	"github.com/Kirisakiii/neko-micro-blog-backend/middlewares"
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
-	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
 	"github.com/Kirisakiii/neko-micro-blog-backend/services"
 	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/filters"
 )
 
 var (
-	logger              *logrus.Logger
-	cfg                 *configs.Config
-	db                  *gorm.DB
-	redisClient         *redis.Client
-	mongoClient         *mongo.Client
-	searchSeviceConn    *grpc.ClientConn
-	searchServiceClient search.SearchEngineClient
-	storeFactory        *stores.Factory
-	controllerFactory   *controllers.Factory
-	middlewareFactory   *middlewares.Factory
+	logger            *logrus.Logger
+	cfg               *configs.Config
+	db                *gorm.DB
+	redisClient       *redis.Client
+	mongoClient       *mongo.Client
+	searchSeviceConn  *grpc.ClientConn
+	searchEngine      engines.SearchEngine
+	storeFactory      *stores.Factory
+	controllerFactory *controllers.Factory
+	middlewareFactory *middlewares.Factory
 )
 
 func init() {
@@ -132,19 +135,68 @@ func init() {
 	}
 	logger.Debugln("MongoDB 连接成功")
 
-	// 建立搜索服务 gRPC 连接
-	searchSeviceConn, err = grpc.Dial(fmt.Sprintf("%s:%d", cfg.SearchService.Host, cfg.SearchService.Port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// 建立搜索服务 gRPC 连接，自动模式下证书加载或连接失败时仅使用内置搜索引擎
+	searchBackend := cfg.SearchService.Backend
+	if searchBackend == "" {
+		searchBackend = consts.SEARCH_ENGINE_AUTO
+	}
+	if searchBackend != consts.SEARCH_ENGINE_EMBEDDED {
+		var searchCredentials credentials.TransportCredentials
+		searchCredentials, err = engines.NewTransportCredentials(cfg.SearchService.TLS)
+		if err == nil {
+			searchSeviceConn, err = grpc.Dial(fmt.Sprintf("%s:%d", cfg.SearchService.Host, cfg.SearchService.Port), grpc.WithTransportCredentials(searchCredentials))
+		}
+		if err != nil {
+			if searchBackend == consts.SEARCH_ENGINE_GRPC {
+				logger.Panicln("连接至搜索服务失败：", err.Error())
+			}
+			logger.Warnln("连接至搜索服务失败，将使用内置搜索引擎：", err.Error())
+			searchBackend = consts.SEARCH_ENGINE_EMBEDDED
+		}
+	}
+
+	// 创建搜索引擎
+	switch searchBackend {
+	case consts.SEARCH_ENGINE_GRPC:
+		searchEngine = engines.NewGRPCEngine(searchSeviceConn)
+	case consts.SEARCH_ENGINE_EMBEDDED:
+		searchEngine = engines.NewEmbeddedEngine(db)
+	case consts.SEARCH_ENGINE_AUTO:
+		searchEngine = engines.NewFailoverEngine(
+			engines.NewGRPCEngine(searchSeviceConn),
+			engines.NewEmbeddedEngine(db),
+			logger,
+		)
+	default:
+		logger.Panicln("未知的搜索引擎：", searchBackend)
+	}
+	logger.Debugln("使用搜索引擎:", searchEngine.Name())
+
+	// 加载敏感词库
+	sensitiveFilter, err := filters.NewSensitiveFilter(cfg.SensitiveFilter.Path)
 	if err != nil {
-		logger.Panicln("连接至搜索服务失败：", err.Error())
+		logger.Panicln("加载敏感词库失败：", err.Error())
 	}
-	searchServiceClient = search.NewSearchEngineClient(searchSeviceConn)
 
 	// 建立数据访问层工厂
-	storeFactory = stores.NewFactory(db, redisClient, mongoClient, searchServiceClient)
+	storeFactory = stores.NewFactory(db, redisClient, mongoClient, searchEngine, sensitiveFilter)
+
+	// 检查搜索服务状态，不可用时熔断器立即打开，恢复后由健康检查任务关闭
+	if err = storeFactory.NewSearchIndexStore().CheckSearchEngineHealth(); err != nil {
+		logger.Warnln("搜索服务健康检查失败，搜索将降级：", err.Error())
+	}
+
+	// 创建 MongoDB 索引
+	logger.Debugln("正在创建 MongoDB 索引...")
+	err = storeFactory.EnsureIndexes()
+	if err != nil {
+		logger.Panicln("创建 MongoDB 索引失败：", err.Error())
+	}
 
 	// 建立控制器层工厂
 	controllerFactory = controllers.NewFactory(
-		services.NewFactory(storeFactory),
+		services.NewFactory(storeFactory, logger),
+		logger,
 	)
 
 	// 建立中间件工厂
@@ -152,8 +204,23 @@ func init() {
 }
 
 func main() {
-	// 初始化定时任务
-	crons.InitJobs(logger, db, redisClient)
+	// 执行命令行子命令，执行完毕后退出而不启动服务器
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reindex":
+			if err := commands.Reindex(logger, storeFactory, os.Args[2:]); err != nil {
+				logger.Fatalln("重建搜索索引失败：", err.Error())
+			}
+		default:
+			logger.Fatalln("未知的子命令：", os.Args[1])
+		}
+		return
+	}
+
+	// 初始化定时任务，Prefork 模式下仅在主进程中运行，避免每个子进程重复执行
+	if !fiber.IsChild() {
+		crons.InitJobs(logger, db, redisClient, storeFactory)
+	}
 
 	// 创建 fiber 实例
 	var fiberConfig fiber.Config
@@ -176,6 +243,7 @@ func main() {
 
 	// Auth 中间件
 	authMiddleware := middlewareFactory.NewTokenAuthMiddleware()
+	optionalAuthMiddleware := authMiddleware.NewOptionalMiddleware()
 
 	// 静态资源路由
 	resource := app.Group("/resources")
@@ -197,6 +265,12 @@ func main() {
 	// token.Get("/check", tokenController.NewCheckTokenHandler())      // 检查令牌可用性
 	// token.Post("/refresh", tokenController.NewRefreshTokenHandler()) // 刷新令牌
 
+	// 批量获取控制器，路由分别注册在博文、评论和用户路由组下
+	hydrationController := controllerFactory.NewHydrationController()
+
+	// 热门控制器，热门博文路由注册在博文路由组下
+	trendingController := controllerFactory.NewTrendingController()
+
 	// User 路由
 	userController := controllerFactory.NewUserController()
 	user := api.Group("/user")
@@ -206,11 +280,13 @@ func main() {
 	user.Post("/upload-avatar", authMiddleware.NewMiddleware(), userController.NewUploadAvatarHandler()) // 上传头像
 	user.Post("/update-psw", userController.NewUpdatePasswordHandler())                                  // 修改密码
 	user.Post("/edit", authMiddleware.NewMiddleware(), userController.NewUpdateProfileHandler())         // 修改用户资料
+	user.Post("/privacy", authMiddleware.NewMiddleware(), userController.NewUpdatePrivacyHandler())      // 修改隐私设置
+	user.Get("/batch", optionalAuthMiddleware, hydrationController.NewUserBatchHandler())                // 批量获取用户信息
 
 	// Post 路由
-	postController := controllerFactory.NewPostController(searchServiceClient)
+	postController := controllerFactory.NewPostController()
 	post := api.Group("/post")
-	post.Get("/list", postController.NewPostListHandler(storeFactory.NewUserStore()))                              // 获取文章列表
+	post.Get("/list", optionalAuthMiddleware, postController.NewPostListHandler())                                 // 获取文章列表
 	post.Get("/user-status", authMiddleware.NewMiddleware(), postController.NewPostUserStatusHandler())            // 获取用户文章状态
 	post.Post("/new", authMiddleware.NewMiddleware(), postController.NewCreatePostHandler())                       // 创建文章
 	post.Post("/upload-img", authMiddleware.NewMiddleware(), postController.NewUploadPostImageHandler())           // 上传博文图片
@@ -218,14 +294,20 @@ func main() {
 	post.Post("/cancel-like", authMiddleware.NewMiddleware(), postController.NewCancelLikePostHandler())           // 取消点赞文章
 	post.Post("/favourite", authMiddleware.NewMiddleware(), postController.NewFavouritePostHandler())              // 收藏文章
 	post.Post("/cancel-favourite", authMiddleware.NewMiddleware(), postController.NewCancelFavouritePostHandler()) // 取消收藏文章
-	post.Get("/:post", postController.NewPostDetailHandler())                                                      // 获取文章信息
+	post.Post("/comment-permission", authMiddleware.NewMiddleware(), postController.NewCommentPermissionHandler()) // 修改文章评论权限
+	post.Get("/analytics", authMiddleware.NewMiddleware(), postController.NewPostAnalyticsHandler())               // 获取文章数据分析
+	post.Get("/hot", optionalAuthMiddleware, trendingController.NewHotPostListHandler())                           // 获取热门文章列表
+	post.Get("/batch", optionalAuthMiddleware, hydrationController.NewPostBatchHandler())                          // 批量获取文章信息
+	post.Get("/:post", optionalAuthMiddleware, postController.NewPostDetailHandler())                              // 获取文章信息
 	post.Delete("/:post", authMiddleware.NewMiddleware(), postController.NewDeletePostHandler())                   // 删除文章
 
 	// Comment 路由
 	commentController := controllerFactory.NewCommentController()
 	comment := api.Group("/comment")
-	comment.Get("/list", commentController.NewCommentListHandler())                                                     // 获取评论列表
-	comment.Get("/detail", commentController.NewCommentDetailHandler())                                                 // 获取评论详情信息
+	comment.Get("/list", optionalAuthMiddleware, commentController.NewCommentListHandler())                             // 获取评论列表
+	comment.Get("/tree", optionalAuthMiddleware, commentController.NewCommentTreeHandler())                             // 获取评论树
+	comment.Get("/batch", optionalAuthMiddleware, hydrationController.NewCommentBatchHandler())                         // 批量获取评论信息
+	comment.Get("/detail", optionalAuthMiddleware, commentController.NewCommentDetailHandler())                         // 获取评论详情信息
 	comment.Get("/user-status", authMiddleware.NewMiddleware(), commentController.NewCommentUserStatusHandler())        // 获取用户评论状态
 	comment.Post("/edit", authMiddleware.NewMiddleware(), commentController.NewUpdateCommentHandler())                  // 修改评论
 	comment.Post("/delete", authMiddleware.NewMiddleware(), commentController.DeleteCommentHandler())                   // 删除评论
@@ -233,6 +315,8 @@ func main() {
 	comment.Post("/cancel-like", authMiddleware.NewMiddleware(), commentController.NewCancelLikeCommentHandler())       // 取消点赞评论
 	comment.Post("/dislike", authMiddleware.NewMiddleware(), commentController.NewDislikeCommentHandler())              // 踩评论
 	comment.Post("/cancel-dislike", authMiddleware.NewMiddleware(), commentController.NewCancelDislikeCommentHandler()) // 取消踩评论
+	comment.Post("/pin", authMiddleware.NewMiddleware(), commentController.NewPinCommentHandler())                      // 置顶评论
+	comment.Post("/unpin", authMiddleware.NewMiddleware(), commentController.NewUnpinCommentHandler())                  // 取消置顶评论
 	comment.Post("/new", authMiddleware.NewMiddleware(), commentController.NewCreateCommentHandler(
 		storeFactory.NewPostStore(),
 		storeFactory.NewUserStore(),
@@ -241,29 +325,106 @@ func main() {
 	// Reply 路由
 	replyController := controllerFactory.NewReplyController()
 	reply := api.Group("/reply")
-	reply.Get("/list", replyController.NewGetReplyListHandler())     // 获取回复列表
-	reply.Get("/detail", replyController.NewGetReplyDetailHandler()) // 获取回复详情信息
+	reply.Get("/list", optionalAuthMiddleware, replyController.NewGetReplyListHandler())     // 获取回复列表
+	reply.Get("/tree", optionalAuthMiddleware, replyController.NewGetReplyTreeHandler())     // 获取回复子树
+	reply.Get("/detail", optionalAuthMiddleware, replyController.NewGetReplyDetailHandler()) // 获取回复详情信息
 	reply.Post("/new", authMiddleware.NewMiddleware(), replyController.NewCreateReplyHandler(
 		storeFactory.NewCommentStore(),
 		storeFactory.NewUserStore()),
 	) // 创建回复
-	reply.Post("/edit", authMiddleware.NewMiddleware(), replyController.NewUpdateReplyHandler()) // 修改回复
-	reply.Post("/delete", authMiddleware.NewMiddleware(), replyController.DeleteReplyHandler())  // 删除回复
+	reply.Post("/edit", authMiddleware.NewMiddleware(), replyController.NewUpdateReplyHandler())                  // 修改回复
+	reply.Post("/delete", authMiddleware.NewMiddleware(), replyController.DeleteReplyHandler())                   // 删除回复
+	reply.Get("/user-status", authMiddleware.NewMiddleware(), replyController.NewReplyUserStatusHandler())        // 获取用户回复状态
+	reply.Post("/like", authMiddleware.NewMiddleware(), replyController.NewLikeReplyHandler())                    // 点赞回复
+	reply.Post("/cancel-like", authMiddleware.NewMiddleware(), replyController.NewCancelLikeReplyHandler())       // 取消点赞回复
+	reply.Post("/dislike", authMiddleware.NewMiddleware(), replyController.NewDislikeReplyHandler())              // 踩回复
+	reply.Post("/cancel-dislike", authMiddleware.NewMiddleware(), replyController.NewCancelDislikeReplyHandler()) // 取消踩回复
+
+	// Hashtag 路由
+	hashtag := api.Group("/hashtag")
+	hashtag.Get("/trending", trendingController.NewTrendingHashtagListHandler()) // 获取热门话题
 
 	// Search 路由
-	searchController := controllerFactory.NewSearchController(searchServiceClient)
+	searchController := controllerFactory.NewSearchController()
+	api.Get("/search", optionalAuthMiddleware, searchController.NewSearchHandler()) // 搜索博文、用户或话题
 	search := api.Group("/search")
-	search.Get("/post", searchController.NewSearchPostHandler()) // 搜索文章
+	search.Get("/post", optionalAuthMiddleware, searchController.NewSearchPostHandler())                         // 搜索文章
+	search.Get("/autocomplete", optionalAuthMiddleware, searchController.NewAutocompleteHandler())               // 搜索自动补全
+	search.Get("/recent", authMiddleware.NewMiddleware(), searchController.NewRecentSearchListHandler())         // 获取最近搜索
+	search.Post("/recent/clear", authMiddleware.NewMiddleware(), searchController.NewClearRecentSearchHandler()) // 清除最近搜索
+	search.Get("/privacy", authMiddleware.NewMiddleware(), searchController.NewSearchPrivacyHandler())           // 获取搜索隐私设置
+	search.Post("/privacy", authMiddleware.NewMiddleware(), searchController.NewUpdateSearchPrivacyHandler())    // 修改搜索隐私设置
 
 	// follow 路由
 	followController := controllerFactory.NewFollowController()
 	follow := api.Group("/follow")
-	follow.Post("/new", authMiddleware.NewMiddleware(), followController.NewCreateFollowHandler())    // 关注用户
-	follow.Post("/delete", authMiddleware.NewMiddleware(), followController.NewCancelFollowHandler()) // 取消关注用户
-	follow.Get("/list", followController.NewFollowListHandler())                                      // 获取关注列表
-	follow.Get("/list-count", followController.NewFollowCountHandler())                               // 获取关注人数
-	follow.Get("/follower-list", followController.NewFollowerListHandler())                           // 获取粉丝列表
-	follow.Get("/follower-list-count", followController.NewFollowerCountHandler())                    // 获取粉丝人数
+	follow.Post("/new", authMiddleware.NewMiddleware(), followController.NewCreateFollowHandler())                     // 关注用户
+	follow.Post("/delete", authMiddleware.NewMiddleware(), followController.NewCancelFollowHandler())                  // 取消关注用户
+	follow.Get("/list", optionalAuthMiddleware, followController.NewFollowListHandler())                               // 获取关注列表
+	follow.Get("/list-count", followController.NewFollowCountHandler())                                                // 获取关注人数
+	follow.Get("/follower-list", optionalAuthMiddleware, followController.NewFollowerListHandler())                    // 获取粉丝列表
+	follow.Get("/follower-list-count", followController.NewFollowerCountHandler())                                     // 获取粉丝人数
+	follow.Get("/list-detail", optionalAuthMiddleware, followController.NewFollowListPageHandler())                    // 分页获取关注列表详情
+	follow.Get("/follower-list-detail", optionalAuthMiddleware, followController.NewFollowerListPageHandler())         // 分页获取粉丝列表详情
+	follow.Get("/request-list", authMiddleware.NewMiddleware(), followController.NewFollowRequestListHandler())        // 获取关注请求列表
+	follow.Post("/request-approve", authMiddleware.NewMiddleware(), followController.NewApproveFollowRequestHandler()) // 通过关注请求
+	follow.Post("/request-reject", authMiddleware.NewMiddleware(), followController.NewRejectFollowRequestHandler())   // 拒绝关注请求
+
+	// suggestion 路由
+	suggestionController := controllerFactory.NewSuggestionController()
+	suggestion := api.Group("/suggestion")
+	suggestion.Get("/list", authMiddleware.NewMiddleware(), suggestionController.NewSuggestionListHandler())        // 获取关注推荐
+	suggestion.Post("/dismiss", authMiddleware.NewMiddleware(), suggestionController.NewDismissSuggestionHandler()) // 忽略关注推荐
+
+	// list 路由
+	userListController := controllerFactory.NewUserListController()
+	list := api.Group("/list")
+	list.Post("/new", authMiddleware.NewMiddleware(), userListController.NewCreateListHandler())             // 创建列表
+	list.Post("/update", authMiddleware.NewMiddleware(), userListController.NewUpdateListHandler())          // 更新列表
+	list.Post("/delete", authMiddleware.NewMiddleware(), userListController.NewDeleteListHandler())          // 删除列表
+	list.Get("/detail", optionalAuthMiddleware, userListController.NewListDetailHandler())                   // 获取列表详情
+	list.Get("/user", optionalAuthMiddleware, userListController.NewUserListsHandler())                      // 获取用户创建的列表
+	list.Get("/subscribed", authMiddleware.NewMiddleware(), userListController.NewSubscribedListsHandler())  // 获取已订阅的列表
+	list.Post("/member/add", authMiddleware.NewMiddleware(), userListController.NewAddMemberHandler())       // 添加列表成员
+	list.Post("/member/remove", authMiddleware.NewMiddleware(), userListController.NewRemoveMemberHandler()) // 移除列表成员
+	list.Get("/member/list", optionalAuthMiddleware, userListController.NewMemberListHandler())              // 获取列表成员
+	list.Post("/subscribe", authMiddleware.NewMiddleware(), userListController.NewSubscribeHandler())        // 订阅列表
+	list.Post("/unsubscribe", authMiddleware.NewMiddleware(), userListController.NewUnsubscribeHandler())    // 取消订阅列表
+	list.Get("/timeline", optionalAuthMiddleware, userListController.NewTimelineHandler())                   // 获取列表时间线
+
+	// favourite 路由
+	favouriteController := controllerFactory.NewFavouriteController()
+	favourite := api.Group("/favourite")
+	favourite.Post("/folder/new", authMiddleware.NewMiddleware(), favouriteController.NewCreateFolderHandler())    // 创建收藏夹
+	favourite.Post("/folder/update", authMiddleware.NewMiddleware(), favouriteController.NewUpdateFolderHandler()) // 更新收藏夹
+	favourite.Post("/folder/delete", authMiddleware.NewMiddleware(), favouriteController.NewDeleteFolderHandler()) // 删除收藏夹
+	favourite.Get("/folder/list", optionalAuthMiddleware, favouriteController.NewFolderListHandler())              // 获取用户的收藏夹
+	favourite.Get("/list", optionalAuthMiddleware, favouriteController.NewFavouriteListHandler())                  // 获取收藏记录
+	favourite.Post("/move", authMiddleware.NewMiddleware(), favouriteController.NewMoveFavouriteHandler())         // 移动收藏
+	favourite.Post("/note", authMiddleware.NewMiddleware(), favouriteController.NewUpdateNoteHandler())            // 更新收藏备注
+
+	// block 路由
+	blockController := controllerFactory.NewBlockController()
+	block := api.Group("/block")
+	block.Post("/new", authMiddleware.NewMiddleware(), blockController.NewBlockHandler())          // 拉黑用户
+	block.Post("/delete", authMiddleware.NewMiddleware(), blockController.NewCancelBlockHandler()) // 取消拉黑用户
+	block.Get("/list", authMiddleware.NewMiddleware(), blockController.NewBlockListHandler())      // 获取拉黑列表
+
+	// mute 路由
+	mute := api.Group("/mute")
+	mute.Post("/new", authMiddleware.NewMiddleware(), blockController.NewMuteHandler())          // 屏蔽用户
+	mute.Post("/delete", authMiddleware.NewMiddleware(), blockController.NewCancelMuteHandler()) // 取消屏蔽用户
+	mute.Get("/list", authMiddleware.NewMiddleware(), blockController.NewMuteListHandler())      // 获取屏蔽列表
+
+	// moderation 路由
+	moderationController := controllerFactory.NewModerationController()
+	api.Post("/report", authMiddleware.NewMiddleware(), moderationController.NewReportHandler()) // 举报博文、评论、回复或用户
+	moderation := api.Group("/moderation")
+	moderation.Get("/queue", authMiddleware.NewMiddleware(), moderationController.NewModerationQueueHandler())    // 获取审核队列
+	moderation.Get("/reports", authMiddleware.NewMiddleware(), moderationController.NewCaseReportListHandler())   // 获取审核工单的举报
+	moderation.Post("/action", authMiddleware.NewMiddleware(), moderationController.NewModerationActionHandler()) // 执行审核操作
+	moderation.Get("/logs", authMiddleware.NewMiddleware(), moderationController.NewModerationLogListHandler())   // 获取审核日志
+	moderation.Get("/warnings", authMiddleware.NewMiddleware(), moderationController.NewUserWarningListHandler()) // 获取自己收到的警告
 
 	// 启动服务器
 	log.Fatal(app.Listen(fmt.Sprintf("%s:%d", cfg.Database.Host, cfg.Server.Port)))
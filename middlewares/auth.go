@@ -86,3 +86,42 @@ func (middleware *TokenAuthMiddleware) NewMiddleware() fiber.Handler {
 		return ctx.Next()
 	}
 }
+
+// NewOptionalMiddleware 可选 Token 认证中间件，令牌缺失或无效时按匿名用户继续处理
+//
+// 参数
+//   - ctx：Fiber 上下文。
+//
+// 返回值
+//   - error：错误
+func (middleware *TokenAuthMiddleware) NewOptionalMiddleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 从请求头中获取 Token
+		token := ctx.Get("Authorization")
+		if len(token) < 7 || token[:7] != "Bearer " {
+			return ctx.Next()
+		}
+		token = token[7:]
+
+		// 验证 Token
+		claims, err := parsers.ParseToken(token)
+		if err != nil {
+			return ctx.Next()
+		}
+
+		// 检验 Token 是否可用
+		isAvaliable, err := middleware.userStore.IsUserTokenAvaliable(token)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(consts.SERVER_ERROR, err.Error()),
+			)
+		}
+
+		// 将 claims 信息存入 ctx.Locals 中
+		if isAvaliable {
+			ctx.Locals("claims", claims)
+		}
+
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,22 @@
+/*
+Package models - NekoBlog backend server database models
+This file is for post analytics related models.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import "time"
+
+// PostDailyStat 博文每日统计模型，浏览和曝光为当日数据，互动数为当日结束时的累计值
+type PostDailyStat struct {
+	PostID      uint64    `gorm:"column:post_id;primaryKey"`        // 博文ID
+	Date        time.Time `gorm:"column:date;type:date;primaryKey"` // 统计日期
+	Views       int64     `gorm:"column:views"`                     // 当日独立浏览者数
+	Impressions int64     `gorm:"column:impressions"`               // 当日曝光数
+	Likes       int64     `gorm:"column:likes"`                     // 累计点赞数
+	Favourites  int64     `gorm:"column:favourites"`                // 累计收藏数
+	Comments    int64     `gorm:"column:comments"`                  // 累计评论数
+	Reposts     int64     `gorm:"column:reposts"`                   // 累计转发数
+	UpdatedAt   time.Time `gorm:"column:updated_at"`                // 更新时间
+}
@@ -0,0 +1,25 @@
+/*
+Package models - NekoBlog backend server database models
+This file is for block and mute related models.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+)
+
+// BlockInfo 拉黑信息模型
+type BlockInfo struct {
+	UserID    uint64    `bson:"uid"`        // 拉黑者ID
+	BlockedID uint64    `bson:"blocked_id"` // 被拉黑者ID
+	BlockedAt time.Time `bson:"blocked_at"` // 拉黑时间
+}
+
+// MuteInfo 屏蔽信息模型
+type MuteInfo struct {
+	UserID  uint64    `bson:"uid"`      // 屏蔽者ID
+	MutedID uint64    `bson:"muted_id"` // 被屏蔽者ID
+	MutedAt time.Time `bson:"muted_at"` // 屏蔽时间
+}
@@ -0,0 +1,20 @@
+/*
+Package models - NekoBlog backend server database models
+This file is for engagement counter related models.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import "time"
+
+// PostCounter 博文互动计数模型，由 Redis 计数缓存回写，并定期与点赞、收藏等记录对账
+type PostCounter struct {
+	PostID     uint64    `gorm:"column:post_id;primaryKey"` // 博文ID
+	Likes      int64     `gorm:"column:likes"`              // 点赞数
+	Favourites int64     `gorm:"column:favourites"`         // 收藏数
+	Reposts    int64     `gorm:"column:reposts"`            // 转发数
+	Comments   int64     `gorm:"column:comments"`           // 评论数
+	Views      int64     `gorm:"column:views"`              // 浏览数
+	UpdatedAt  time.Time `gorm:"column:updated_at"`         // 更新时间
+}
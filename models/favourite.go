@@ -0,0 +1,31 @@
+/*
+Package models - NekoBlog backend server database models
+This file is for favourite folder related models.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FavouriteFolderInfo 收藏夹信息模型
+type FavouriteFolderInfo struct {
+	gorm.Model         // 基本模型
+	OwnerUID    uint64 `gorm:"column:owner_uid;index"`          // 创建者ID
+	Name        string `gorm:"column:name"`                     // 收藏夹名称
+	Description string `gorm:"column:description"`              // 收藏夹描述
+	IsPrivate   bool   `gorm:"column:is_private;default:false"` // 是否为私密收藏夹
+}
+
+// PostFavouriteInfo 博文收藏记录模型
+type PostFavouriteInfo struct {
+	UserID       int64     `bson:"uid"`           // 收藏者ID
+	PostID       int64     `bson:"post_id"`       // 博文ID
+	FolderID     uint64    `bson:"folder_id"`     // 所属收藏夹ID，为 0 时表示默认收藏夹
+	Note         string    `bson:"note"`          // 收藏备注
+	FavouritedAt time.Time `bson:"favourited_at"` // 收藏时间
+}
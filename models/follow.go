@@ -16,4 +16,17 @@ type FollowInfo struct {
 	UserID      uint64    `bson:"uid"`         // 关注ID
 	FollowedID  uint64    `bson:"followed_id"` // 被关注者ID
 	FollowedAt  time.Time `bson:"followed_at"` // 关注时间
-}
\ No newline at end of file
+}
+
+// FollowRequestInfo 关注请求信息模型
+type FollowRequestInfo struct {
+	UserID      uint64    `bson:"uid"`          // 请求者ID
+	FollowedID  uint64    `bson:"followed_id"`  // 被请求关注者ID
+	RequestedAt time.Time `bson:"requested_at"` // 请求时间
+}
+// FollowSuggestionDismissInfo 忽略关注推荐信息模型
+type FollowSuggestionDismissInfo struct {
+	UserID      uint64    `bson:"uid"`          // 用户ID
+	DismissedID uint64    `bson:"dismissed_id"` // 被忽略的推荐用户ID
+	DismissedAt time.Time `bson:"dismissed_at"` // 忽略时间
+}
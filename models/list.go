@@ -0,0 +1,36 @@
+/*
+Package models - NekoBlog backend server database models
+This file is for user list related models.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserListInfo 用户列表信息模型
+type UserListInfo struct {
+	gorm.Model         // 基本模型
+	OwnerUID    uint64 `gorm:"column:owner_uid;index"`          // 创建者ID
+	Name        string `gorm:"column:name"`                     // 列表名称
+	Description string `gorm:"column:description"`              // 列表描述
+	IsPrivate   bool   `gorm:"column:is_private;default:false"` // 是否为私密列表
+}
+
+// UserListMemberInfo 用户列表成员信息模型
+type UserListMemberInfo struct {
+	ListID  uint64    `bson:"list_id"`  // 列表ID
+	UserID  uint64    `bson:"uid"`      // 成员ID
+	AddedAt time.Time `bson:"added_at"` // 加入时间
+}
+
+// UserListSubscriptionInfo 用户列表订阅信息模型
+type UserListSubscriptionInfo struct {
+	ListID       uint64    `bson:"list_id"`       // 列表ID
+	UserID       uint64    `bson:"uid"`           // 订阅者ID
+	SubscribedAt time.Time `bson:"subscribed_at"` // 订阅时间
+}
@@ -39,6 +39,50 @@ func Migrate(db *gorm.DB) error {
 	if err = db.AutoMigrate(&PostInfo{}); err != nil {
 		return err
 	}
+	// 用户博文列表按作者过滤并按ID倒序分页
+	if err = db.Exec("CREATE INDEX IF NOT EXISTS idx_post_infos_uid_id ON post_infos (uid, id DESC)").Error; err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&PostCounter{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&PostDailyStat{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&SearchIndexOutbox{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&UserSearchIndex{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&HashtagSearchIndex{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&PostHashtagRecord{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&SearchPostDocument{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&SearchPrivacySetting{}); err != nil {
+		return err
+	}
+	// 用户和话题搜索按前缀匹配，内置搜索引擎按词元检索，话题记录按话题查找最新博文
+	for _, statement := range []string{
+		"CREATE INDEX IF NOT EXISTS idx_user_search_indices_username ON user_search_indices (username text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_user_search_indices_nickname ON user_search_indices (nickname text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_user_search_indices_pinyin ON user_search_indices (pinyin text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_user_search_indices_pinyin_initials ON user_search_indices (pinyin_initials text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_hashtag_search_indices_name ON hashtag_search_indices (name text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_hashtag_search_indices_pinyin ON hashtag_search_indices (pinyin text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_hashtag_search_indices_pinyin_initials ON hashtag_search_indices (pinyin_initials text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_search_post_documents_tokens ON search_post_documents USING GIN (tokens)",
+		"CREATE INDEX IF NOT EXISTS idx_post_hashtag_records_name ON post_hashtag_records (name, post_id DESC)",
+	} {
+		if err = db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
 	
 	// Comment 相关
 	if err = db.AutoMigrate(&CommentInfo{}); err != nil {
@@ -50,5 +94,53 @@ func Migrate(db *gorm.DB) error {
 		return err
 	}
 
+	// UserList 相关
+	if err = db.AutoMigrate(&UserListInfo{}); err != nil {
+		return err
+	}
+
+	// Favourite 相关
+	if err = db.AutoMigrate(&FavouriteFolderInfo{}); err != nil {
+		return err
+	}
+
+	// Moderation 相关
+	if err = db.AutoMigrate(&ModerationCase{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&ContentReport{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&ModerationLog{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&UserWarning{}); err != nil {
+		return err
+	}
+	if err = db.AutoMigrate(&UserSuspension{}); err != nil {
+		return err
+	}
+	// 每个目标最多一个待处理工单，审核队列按严重程度和举报人数排序，审核日志禁止修改和删除
+	// 生效中的处罚按用户查询，到期自动解除按到期时间扫描
+	for _, statement := range []string{
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_moderation_cases_pending_target ON moderation_cases (target_type, target_id) WHERE status = 'pending'",
+		"CREATE INDEX IF NOT EXISTS idx_moderation_cases_queue ON moderation_cases (status, severity DESC, report_count DESC, id)",
+		"CREATE INDEX IF NOT EXISTS idx_user_suspensions_active ON user_suspensions (uid, kind) WHERE lifted_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_user_suspensions_expiring ON user_suspensions (expires_at) WHERE lifted_at IS NULL AND expires_at IS NOT NULL",
+		`CREATE OR REPLACE FUNCTION reject_moderation_log_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'moderation logs are immutable';
+		END;
+		$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS moderation_logs_immutable ON moderation_logs",
+		"CREATE TRIGGER moderation_logs_immutable BEFORE UPDATE OR DELETE ON moderation_logs FOR EACH ROW EXECUTE FUNCTION reject_moderation_log_change()",
+		"DROP TRIGGER IF EXISTS moderation_logs_no_truncate ON moderation_logs",
+		"CREATE TRIGGER moderation_logs_no_truncate BEFORE TRUNCATE ON moderation_logs FOR EACH STATEMENT EXECUTE FUNCTION reject_moderation_log_change()",
+	} {
+		if err = db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,73 @@
+/*
+Package models - NekoBlog backend server database models
+This file is for report and moderation related models.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import "time"
+
+// ModerationCase 审核工单，同一目标的举报汇总到同一个待处理工单
+type ModerationCase struct {
+	ID             uint64     `gorm:"column:id;primaryKey"`    // 工单ID
+	TargetType     string     `gorm:"column:target_type"`      // 目标类型：post、comment、reply 或 user
+	TargetID       uint64     `gorm:"column:target_id"`        // 目标ID
+	TargetUID      uint64     `gorm:"column:target_uid;index"` // 目标作者ID，目标为用户时即用户ID
+	Status         string     `gorm:"column:status"`           // 状态：pending、resolved 或 dismissed
+	Severity       int        `gorm:"column:severity"`         // 严重程度，取所有举报原因中最高的一个
+	ReportCount    int64      `gorm:"column:report_count"`     // 举报人数
+	Resolution     string     `gorm:"column:resolution"`       // 处理方式
+	ResolvedBy     *uint64    `gorm:"column:resolved_by"`      // 处理人ID
+	ResolvedAt     *time.Time `gorm:"column:resolved_at"`      // 处理时间
+	LastReportedAt time.Time  `gorm:"column:last_reported_at"` // 最近一次被举报的时间
+	CreatedAt      time.Time  `gorm:"column:created_at"`       // 创建时间
+}
+
+// ContentReport 举报记录，同一用户对同一工单只能举报一次
+type ContentReport struct {
+	ID          uint64    `gorm:"column:id;primaryKey"`                                              // 举报ID
+	CaseID      uint64    `gorm:"column:case_id;uniqueIndex:idx_content_reports_case_reporter"`      // 审核工单ID
+	ReporterUID uint64    `gorm:"column:reporter_uid;uniqueIndex:idx_content_reports_case_reporter"` // 举报人ID
+	Reason      string    `gorm:"column:reason"`                                                     // 举报原因
+	Detail      string    `gorm:"column:detail"`                                                     // 补充说明
+	CreatedAt   time.Time `gorm:"column:created_at"`                                                 // 举报时间
+}
+
+// ModerationLog 审核操作日志，只允许插入，数据库触发器拒绝修改和删除
+type ModerationLog struct {
+	ID           uint64     `gorm:"column:id;primaryKey"`                                // 日志ID
+	ModeratorUID uint64     `gorm:"column:moderator_uid;index"`                          // 操作人ID
+	Action       string     `gorm:"column:action"`                                       // 操作
+	TargetType   string     `gorm:"column:target_type;index:idx_moderation_logs_target"` // 目标类型
+	TargetID     uint64     `gorm:"column:target_id;index:idx_moderation_logs_target"`   // 目标ID
+	TargetUID    uint64     `gorm:"column:target_uid;index"`                             // 目标作者ID
+	CaseID       *uint64    `gorm:"column:case_id"`                                      // 审核工单ID，直接处理时为空
+	Reason       string     `gorm:"column:reason"`                                       // 处理理由
+	ExpiresAt    *time.Time `gorm:"column:expires_at"`                                   // 处罚到期时间，仅暂停账号时有值
+	CreatedAt    time.Time  `gorm:"column:created_at"`                                   // 操作时间
+}
+
+// UserWarning 用户收到的警告
+type UserWarning struct {
+	ID           uint64    `gorm:"column:id;primaryKey"` // 警告ID
+	UID          uint64    `gorm:"column:uid;index"`     // 被警告用户ID
+	ModeratorUID uint64    `gorm:"column:moderator_uid"` // 操作人ID
+	TargetType   string    `gorm:"column:target_type"`   // 违规内容类型
+	TargetID     uint64    `gorm:"column:target_id"`     // 违规内容ID
+	Reason       string    `gorm:"column:reason"`        // 警告理由
+	CreatedAt    time.Time `gorm:"column:created_at"`    // 警告时间
+}
+
+// UserSuspension 用户账号处罚记录，包括暂停、封禁和限流
+type UserSuspension struct {
+	ID           uint64     `gorm:"column:id;primaryKey"`                 // 记录ID
+	UID          uint64     `gorm:"column:uid;index"`                     // 被处罚用户ID
+	Kind         string     `gorm:"column:kind;not null;default:suspend"` // 处罚类型：suspend、ban 或 shadowban
+	ModeratorUID uint64     `gorm:"column:moderator_uid"`                 // 操作人ID
+	Reason       string     `gorm:"column:reason"`                        // 处罚理由
+	ExpiresAt    *time.Time `gorm:"column:expires_at"`                    // 到期时间，为空时永久有效
+	LiftedAt     *time.Time `gorm:"column:lifted_at"`                     // 解除时间，到期自动解除时为到期时间
+	LiftedBy     *uint64    `gorm:"column:lifted_by"`                     // 提前解除的操作人ID，到期自动解除时为空
+	CreatedAt    time.Time  `gorm:"column:created_at"`                    // 创建时间
+}
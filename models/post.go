@@ -7,22 +7,33 @@ Copyright (c) [2024], Author(s):
 package models
 
 import (
+	"time"
+
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 // PostInfo 博文信息模型
 type PostInfo struct {
-	gorm.Model                  // 基本模型
-	ParentPostID *uint64        `gorm:"column:parent_post_id"`          // 转发自文章ID
-	UID          uint64         `gorm:"column:uid"`                     // 用户ID
-	IpAddrress   *string        `gorm:"column:ip_address"`              // IP地址
-	Title        string         `gorm:"column:title"`                   // 标题
-	Content      string         `gorm:"column:content"`                 // 内容
-	Images       pq.StringArray `gorm:"column:images;type:text[]"`      // 图片
-	Like         pq.Int64Array  `gorm:"column:like;type:bigint[]"`      // 点赞数 记录UID
-	Favourite    pq.Int64Array  `gorm:"column:favourite;type:bigint[]"` // 收藏数 记录UID
-	Farward      pq.Int64Array  `gorm:"column:farward;type:bigint[]"`   // 转发数 记录UID
-	IsPublic     bool           `gorm:"column:is_public;default:true"`  // 是否公开
+	gorm.Model                       // 基本模型
+	ParentPostID      *uint64        `gorm:"column:parent_post_id"`                      // 转发自文章ID
+	UID               uint64         `gorm:"column:uid"`                                 // 用户ID
+	IpAddrress        *string        `gorm:"column:ip_address"`                          // IP地址
+	Title             string         `gorm:"column:title"`                               // 标题
+	Content           string         `gorm:"column:content"`                             // 内容
+	Images            pq.StringArray `gorm:"column:images;type:text[]"`                  // 图片
+	Like              pq.Int64Array  `gorm:"column:like;type:bigint[]"`                  // 点赞数 记录UID
+	Favourite         pq.Int64Array  `gorm:"column:favourite;type:bigint[]"`             // 收藏数 记录UID
+	Farward           pq.Int64Array  `gorm:"column:farward;type:bigint[]"`               // 转发数 记录UID
+	IsPublic          bool           `gorm:"column:is_public;default:true"`              // 是否公开
+	CommentPermission string         `gorm:"column:comment_permission;default:everyone"` // 评论权限：everyone、followers、mutuals 或 closed
+	PinnedCommentIDs  pq.Int64Array  `gorm:"column:pinned_comment_ids;type:bigint[]"`    // 置顶评论ID，按置顶顺序排列
 	// Share     uint64 `gorm:"column:share"`                           // 分享数 暂时不实现
 }
+
+// PostLikeInfo 博文点赞记录模型
+type PostLikeInfo struct {
+	UserID  int64     `bson:"uid"`      // 点赞者ID
+	PostID  int64     `bson:"post_id"`  // 博文ID
+	LikedAt time.Time `bson:"liked_at"` // 点赞时间
+}
@@ -0,0 +1,64 @@
+/*
+Package models - NekoBlog backend server database models
+This file is for search index related models.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import "time"
+
+// SearchIndexOutbox 待同步到搜索引擎的索引操作，与博文变更在同一事务中写入
+type SearchIndexOutbox struct {
+	ID            uint64    `gorm:"column:id;primaryKey"`         // 操作ID
+	PostID        uint64    `gorm:"column:post_id;index"`         // 博文ID
+	Operation     string    `gorm:"column:operation"`             // 索引操作：create 或 delete
+	Attempts      int       `gorm:"column:attempts;default:0"`    // 已失败的次数
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at;index"` // 下次执行时间
+	LastError     string    `gorm:"column:last_error"`            // 最近一次失败的错误信息
+	CreatedAt     time.Time `gorm:"column:created_at"`            // 创建时间
+}
+
+// UserSearchIndex 用户搜索索引，保存用于前缀和拼音匹配的小写字段
+type UserSearchIndex struct {
+	UID            uint64    `gorm:"column:uid;primaryKey;autoIncrement:false"` // 用户ID
+	UserName       string    `gorm:"column:username"`                           // 小写用户名
+	NickName       string    `gorm:"column:nickname"`                           // 小写昵称
+	Pinyin         string    `gorm:"column:pinyin"`                             // 昵称全拼
+	PinyinInitials string    `gorm:"column:pinyin_initials"`                    // 昵称拼音首字母
+	UpdatedAt      time.Time `gorm:"column:updated_at"`                         // 更新时间
+}
+
+// HashtagSearchIndex 话题搜索索引，记录话题被多少篇博文使用
+type HashtagSearchIndex struct {
+	Name           string    `gorm:"column:name;primaryKey"`      // 小写话题，不包含 # 前缀
+	Pinyin         string    `gorm:"column:pinyin"`               // 话题全拼
+	PinyinInitials string    `gorm:"column:pinyin_initials"`      // 话题拼音首字母
+	PostCount      int64     `gorm:"column:post_count;default:0"` // 使用该话题的博文数量
+	LastUsedAt     time.Time `gorm:"column:last_used_at"`         // 最近一次被使用的时间
+}
+
+// PostHashtagRecord 博文使用的话题记录，用于按话题查找博文及其作者
+type PostHashtagRecord struct {
+	PostID uint64 `gorm:"column:post_id;primaryKey;autoIncrement:false"` // 博文ID
+	Name   string `gorm:"column:name;primaryKey"`                        // 小写话题，不包含 # 前缀
+	UID    uint64 `gorm:"column:uid"`                                    // 作者ID
+}
+
+// SearchPostDocument 内置搜索引擎的博文文档，词元由应用切分后写入
+type SearchPostDocument struct {
+	PostID   uint64    `gorm:"column:post_id;primaryKey;autoIncrement:false"` // 博文ID
+	UID      uint64    `gorm:"column:uid;index"`                              // 作者ID
+	Title    string    `gorm:"column:title"`                                  // 标题
+	Content  string    `gorm:"column:content"`                                // 内容
+	PostedAt time.Time `gorm:"column:posted_at;index"`                        // 博文发布时间
+	Tokens   string    `gorm:"column:tokens;type:tsvector"`                   // 词元，标题词元权重高于内容词元
+}
+
+// SearchPrivacySetting 用户搜索隐私设置，没有记录时默认保存最近搜索并计入热门搜索
+type SearchPrivacySetting struct {
+	UID          uint64    `gorm:"column:uid;primaryKey;autoIncrement:false"` // 用户ID
+	SaveHistory  bool      `gorm:"column:save_history"`                       // 是否保存最近搜索
+	ShareQueries bool      `gorm:"column:share_queries"`                      // 是否将搜索内容计入热门搜索
+	UpdatedAt    time.Time `gorm:"column:updated_at"`                         // 更新时间
+}
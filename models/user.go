@@ -15,14 +15,16 @@ import (
 
 // UserInfo 用户信息模型
 type UserInfo struct {
-	gorm.Model            // 基本模型
-	UserName   string     `gorm:"unique;column:username"`             // 用户名
-	NickName   *string    `gorm:"column:nickname"`                    // 昵称
-	Avatar     string     `gorm:"default:vanilla.webp;column:avatar"` // 头像
-	Birth      *time.Time `gorm:"column:birth"`                       // 生日
-	Gender     *string    `gorm:"column:gender"`                      // 性别
-	Authority  uint64     `gorm:"default:0;column:authority"`         // 权限等级
-	Level      uint64     `gorm:"default:1;column:level"`             // 等级
+	gorm.Model                // 基本模型
+	UserName       string     `gorm:"unique;column:username"`               // 用户名
+	NickName       *string    `gorm:"column:nickname"`                      // 昵称
+	Avatar         string     `gorm:"default:vanilla.webp;column:avatar"`   // 头像
+	Birth          *time.Time `gorm:"column:birth"`                         // 生日
+	Gender         *string    `gorm:"column:gender"`                        // 性别
+	Authority      uint64     `gorm:"default:0;column:authority"`           // 权限等级
+	Level          uint64     `gorm:"default:1;column:level"`               // 等级
+	IsPrivate      bool       `gorm:"default:false;column:is_private"`      // 是否为私密账号
+	IsShadowbanned bool       `gorm:"default:false;column:is_shadowbanned"` // 是否被限流，由生效中的限流处罚决定
 }
 
 // UserAuthInfo 用户认证信息模型
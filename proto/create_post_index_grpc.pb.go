@@ -20,6 +20,9 @@ const _ = grpc.SupportPackageIsVersion7
 
 const (
 	SearchEngine_CreatePostIndex_FullMethodName = "/SearchEngine/CreatePostIndex"
+	SearchEngine_UpdatePostIndex_FullMethodName = "/SearchEngine/UpdatePostIndex"
+	SearchEngine_DeletePostIndex_FullMethodName = "/SearchEngine/DeletePostIndex"
+	SearchEngine_BulkIndexPosts_FullMethodName  = "/SearchEngine/BulkIndexPosts"
 	SearchEngine_Search_FullMethodName          = "/SearchEngine/Search"
 )
 
@@ -28,6 +31,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SearchEngineClient interface {
 	CreatePostIndex(ctx context.Context, in *CreatePostIndexRequest, opts ...grpc.CallOption) (*CreatePostIndexResponse, error)
+	UpdatePostIndex(ctx context.Context, in *UpdatePostIndexRequest, opts ...grpc.CallOption) (*UpdatePostIndexResponse, error)
+	DeletePostIndex(ctx context.Context, in *DeletePostIndexRequest, opts ...grpc.CallOption) (*DeletePostIndexResponse, error)
+	BulkIndexPosts(ctx context.Context, opts ...grpc.CallOption) (SearchEngine_BulkIndexPostsClient, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 }
 
@@ -48,6 +54,58 @@ func (c *searchEngineClient) CreatePostIndex(ctx context.Context, in *CreatePost
 	return out, nil
 }
 
+func (c *searchEngineClient) UpdatePostIndex(ctx context.Context, in *UpdatePostIndexRequest, opts ...grpc.CallOption) (*UpdatePostIndexResponse, error) {
+	out := new(UpdatePostIndexResponse)
+	err := c.cc.Invoke(ctx, SearchEngine_UpdatePostIndex_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchEngineClient) DeletePostIndex(ctx context.Context, in *DeletePostIndexRequest, opts ...grpc.CallOption) (*DeletePostIndexResponse, error) {
+	out := new(DeletePostIndexResponse)
+	err := c.cc.Invoke(ctx, SearchEngine_DeletePostIndex_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchEngineClient) BulkIndexPosts(ctx context.Context, opts ...grpc.CallOption) (SearchEngine_BulkIndexPostsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SearchEngine_ServiceDesc.Streams[0], SearchEngine_BulkIndexPosts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &searchEngineBulkIndexPostsClient{stream}
+	return x, nil
+}
+
+type SearchEngine_BulkIndexPostsClient interface {
+	Send(*PostDocument) error
+	CloseAndRecv() (*BulkIndexPostsResponse, error)
+	grpc.ClientStream
+}
+
+type searchEngineBulkIndexPostsClient struct {
+	grpc.ClientStream
+}
+
+func (x *searchEngineBulkIndexPostsClient) Send(m *PostDocument) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *searchEngineBulkIndexPostsClient) CloseAndRecv() (*BulkIndexPostsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkIndexPostsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *searchEngineClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
 	out := new(SearchResponse)
 	err := c.cc.Invoke(ctx, SearchEngine_Search_FullMethodName, in, out, opts...)
@@ -62,6 +120,9 @@ func (c *searchEngineClient) Search(ctx context.Context, in *SearchRequest, opts
 // for forward compatibility
 type SearchEngineServer interface {
 	CreatePostIndex(context.Context, *CreatePostIndexRequest) (*CreatePostIndexResponse, error)
+	UpdatePostIndex(context.Context, *UpdatePostIndexRequest) (*UpdatePostIndexResponse, error)
+	DeletePostIndex(context.Context, *DeletePostIndexRequest) (*DeletePostIndexResponse, error)
+	BulkIndexPosts(SearchEngine_BulkIndexPostsServer) error
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	mustEmbedUnimplementedSearchEngineServer()
 }
@@ -73,6 +134,15 @@ type UnimplementedSearchEngineServer struct {
 func (UnimplementedSearchEngineServer) CreatePostIndex(context.Context, *CreatePostIndexRequest) (*CreatePostIndexResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreatePostIndex not implemented")
 }
+func (UnimplementedSearchEngineServer) UpdatePostIndex(context.Context, *UpdatePostIndexRequest) (*UpdatePostIndexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePostIndex not implemented")
+}
+func (UnimplementedSearchEngineServer) DeletePostIndex(context.Context, *DeletePostIndexRequest) (*DeletePostIndexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePostIndex not implemented")
+}
+func (UnimplementedSearchEngineServer) BulkIndexPosts(SearchEngine_BulkIndexPostsServer) error {
+	return status.Errorf(codes.Unimplemented, "method BulkIndexPosts not implemented")
+}
 func (UnimplementedSearchEngineServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
 }
@@ -107,6 +177,68 @@ func _SearchEngine_CreatePostIndex_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SearchEngine_UpdatePostIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePostIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchEngineServer).UpdatePostIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchEngine_UpdatePostIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchEngineServer).UpdatePostIndex(ctx, req.(*UpdatePostIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchEngine_DeletePostIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePostIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchEngineServer).DeletePostIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchEngine_DeletePostIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchEngineServer).DeletePostIndex(ctx, req.(*DeletePostIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchEngine_BulkIndexPosts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SearchEngineServer).BulkIndexPosts(&searchEngineBulkIndexPostsServer{stream})
+}
+
+type SearchEngine_BulkIndexPostsServer interface {
+	SendAndClose(*BulkIndexPostsResponse) error
+	Recv() (*PostDocument, error)
+	grpc.ServerStream
+}
+
+type searchEngineBulkIndexPostsServer struct {
+	grpc.ServerStream
+}
+
+func (x *searchEngineBulkIndexPostsServer) SendAndClose(m *BulkIndexPostsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *searchEngineBulkIndexPostsServer) Recv() (*PostDocument, error) {
+	m := new(PostDocument)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _SearchEngine_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchRequest)
 	if err := dec(in); err != nil {
@@ -136,11 +268,25 @@ var SearchEngine_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreatePostIndex",
 			Handler:    _SearchEngine_CreatePostIndex_Handler,
 		},
+		{
+			MethodName: "UpdatePostIndex",
+			Handler:    _SearchEngine_UpdatePostIndex_Handler,
+		},
+		{
+			MethodName: "DeletePostIndex",
+			Handler:    _SearchEngine_DeletePostIndex_Handler,
+		},
 		{
 			MethodName: "Search",
 			Handler:    _SearchEngine_Search_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BulkIndexPosts",
+			Handler:       _SearchEngine_BulkIndexPosts_Handler,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "create_post_index.proto",
 }
@@ -0,0 +1,148 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for block and mute related services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// BlockService 拉黑与屏蔽服务
+type BlockService struct {
+	blockStore *stores.BlockStore
+	userStore  *stores.UserStore
+}
+
+// NewBlockService 返回一个新的拉黑服务实例。
+//
+// 返回：
+//   - *BlockService: 返回一个指向新的拉黑服务实例的指针。
+func (factory *Factory) NewBlockService() *BlockService {
+	return &BlockService{
+		blockStore: factory.storeFactory.NewBlockStore(),
+		userStore:  factory.storeFactory.NewUserStore(),
+	}
+}
+
+// validateTarget 校验操作目标用户
+//
+// 参数：
+//   - uid：用户ID
+//   - targetID：目标用户ID
+//
+// 返回值：
+//   - error：如果目标用户不合法，返回相应错误信息；否则返回 nil
+func (service *BlockService) validateTarget(uid, targetID uint64) error {
+	if uid == targetID {
+		return errors.New("cannot perform this action on yourself")
+	}
+	_, err := service.userStore.GetUserByUID(targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("user does not exist")
+	}
+	return err
+}
+
+// BlockUser 拉黑用户
+//
+// 参数：
+//   - uid：用户ID
+//   - blockedID：被拉黑用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *BlockService) BlockUser(uid, blockedID uint64) error {
+	if err := service.validateTarget(uid, blockedID); err != nil {
+		return err
+	}
+	return service.blockStore.BlockUser(uid, blockedID)
+}
+
+// CancelBlockUser 取消拉黑用户
+//
+// 参数：
+//   - uid：用户ID
+//   - blockedID：被拉黑用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *BlockService) CancelBlockUser(uid, blockedID uint64) error {
+	return service.blockStore.CancelBlockUser(uid, blockedID)
+}
+
+// GetBlockList 获取拉黑列表
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []models.BlockInfo：拉黑列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *BlockService) GetBlockList(uid uint64) ([]models.BlockInfo, error) {
+	return service.blockStore.GetBlockList(uid)
+}
+
+// MuteUser 屏蔽用户
+//
+// 参数：
+//   - uid：用户ID
+//   - mutedID：被屏蔽用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *BlockService) MuteUser(uid, mutedID uint64) error {
+	if err := service.validateTarget(uid, mutedID); err != nil {
+		return err
+	}
+	return service.blockStore.MuteUser(uid, mutedID)
+}
+
+// CancelMuteUser 取消屏蔽用户
+//
+// 参数：
+//   - uid：用户ID
+//   - mutedID：被屏蔽用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *BlockService) CancelMuteUser(uid, mutedID uint64) error {
+	return service.blockStore.CancelMuteUser(uid, mutedID)
+}
+
+// GetMuteList 获取屏蔽列表
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []models.MuteInfo：屏蔽列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *BlockService) GetMuteList(uid uint64) ([]models.MuteInfo, error) {
+	return service.blockStore.GetMuteList(uid)
+}
+
+// getAuthorFilter 构建查看者的作者过滤条件，排除屏蔽、拉黑的用户、被限流的其他用户和无权查看的私密账号
+//
+// 参数：
+//   - blockStore：拉黑存储
+//   - followStore：关注存储
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - stores.AuthorFilter：作者过滤条件
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func getAuthorFilter(blockStore *stores.BlockStore, followStore *stores.FollowStore, viewerUID uint64) (stores.AuthorFilter, error) {
+	hiddenUIDs, err := blockStore.GetHiddenUIDs(viewerUID)
+	if err != nil {
+		return stores.AuthorFilter{}, err
+	}
+	return followStore.GetAuthorFilter(viewerUID, hiddenUIDs)
+}
@@ -10,13 +10,33 @@ package services
 import (
 	"errors"
 
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
 	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
 )
 
+var (
+	// ErrCommentClosed 博文已关闭评论
+	ErrCommentClosed = errors.New("comments are closed on this post")
+
+	// ErrCommentFollowersOnly 仅作者的关注者可评论
+	ErrCommentFollowersOnly = errors.New("only followers of the author can comment on this post")
+
+	// ErrCommentMutualsOnly 仅与作者互相关注的用户可评论
+	ErrCommentMutualsOnly = errors.New("only mutual followers of the author can comment on this post")
+)
+
 // CommentService 评论服务
 type CommentService struct {
-	commentStore *stores.CommentStore
+	commentStore       *stores.CommentStore
+	postStore          *stores.PostStore
+	counterStore       *stores.CounterStore
+	trendingStore      *stores.TrendingStore
+	blockStore         *stores.BlockStore
+	followStore        *stores.FollowStore
+	sensitiveWordStore *stores.SensitiveWordStore
 }
 
 // NewCommentService 返回一个新的评论服务实例。
@@ -25,7 +45,13 @@ type CommentService struct {
 //   - *CommentService: 返回一个指向新的评论服务实例的指针。
 func (factory *Factory) NewCommentService() *CommentService {
 	return &CommentService{
-		commentStore: factory.storeFactory.NewCommentStore(),
+		commentStore:       factory.storeFactory.NewCommentStore(),
+		postStore:          factory.storeFactory.NewPostStore(),
+		counterStore:       factory.storeFactory.NewCounterStore(),
+		trendingStore:      factory.storeFactory.NewTrendingStore(),
+		blockStore:         factory.storeFactory.NewBlockStore(),
+		followStore:        factory.storeFactory.NewFollowStore(),
+		sensitiveWordStore: factory.storeFactory.NewSensitiveWordStore(),
 	}
 }
 
@@ -50,17 +76,60 @@ func (service *CommentService) CreateComment(uid uint64, postID uint64, content
 		return 0, errors.New("post does not exist")
 	}
 
+	// 校验评论者与博文作者之间是否存在拉黑关系
+	post, err := postStore.GetPost(postID)
+	if err != nil {
+		return 0, err
+	}
+	isBlocked, err := service.blockStore.IsBlockedBetween(uid, post.UID)
+	if err != nil {
+		return 0, err
+	}
+	if isBlocked {
+		return 0, errors.New("user is blocked")
+	}
+
+	// 校验评论者是否有权查看私密账号的博文
+	isAccessible, err := service.followStore.IsAccessible(uid, post.UID)
+	if err != nil {
+		return 0, err
+	}
+	if !isAccessible {
+		return 0, errors.New("post is not accessible")
+	}
+
+	// 校验评论者是否满足博文的评论权限
+	if err := checkCommentPermission(service.followStore, uid, post); err != nil {
+		return 0, err
+	}
+
 	// 根据 UID 获取 Username
 	user, err := userStore.GetUserByUID(uid)
 	if err != nil {
 		return 0, err
 	}
 
-	// 调用存储层的方法存储评论
-	commentID, err := service.commentStore.CreateComment(uid, user.UserName, postID, content)
+	// 检查敏感词
+	hold, err := checkSensitive(service.sensitiveWordStore, &content)
 	if err != nil {
 		return 0, err
 	}
+
+	// 调用存储层的方法存储评论，命中待审核敏感词的评论在同一事务中暂扣至审核通过
+	commentID, err := service.commentStore.CreateComment(uid, user.UserName, postID, content, hold)
+	if err != nil {
+		return 0, err
+	}
+
+	// 暂扣的评论在恢复时再计入博文评论数
+	if hold != nil {
+		return commentID, nil
+	}
+
+	// 更新博文评论数和热度
+	if err := recordEngagement(service.counterStore, service.trendingStore, uid, postID, consts.POST_COUNTER_COMMENTS, 1); err != nil {
+		return 0, err
+	}
 	return commentID, nil
 }
 
@@ -83,13 +152,28 @@ func (service *CommentService) UpdateComment(commentID uint64, content string) e
 	if !exists {
 		return errors.New("comment does not exist")
 	}
+	comment, err := service.commentStore.GetComment(commentID)
+	if err != nil {
+		return err
+	}
 
-	// 调用数据库或其他存储方法更新评论内容
-	err = service.commentStore.UpdateComment(commentID, content)
+	// 检查敏感词
+	hold, err := checkSensitive(service.sensitiveWordStore, &content)
 	if err != nil {
 		return err
 	}
 
+	// 调用数据库或其他存储方法更新评论内容，命中待审核敏感词的评论在同一事务中暂扣至审核通过
+	err = service.commentStore.UpdateComment(commentID, content, hold)
+	if err != nil {
+		return err
+	}
+
+	// 暂扣的评论不再计入博文评论数，恢复时重新计入
+	if hold != nil {
+		return recordEngagement(service.counterStore, service.trendingStore, comment.UID, comment.PostID, consts.POST_COUNTER_COMMENTS, -1)
+	}
+
 	// 如果更新成功，返回nil
 	return nil
 }
@@ -110,6 +194,11 @@ func (service *CommentService) DeleteComment(commentID uint64) error {
 	if !exists {
 		return errors.New("comment does not exist")
 	}
+	comment, err := service.commentStore.GetComment(commentID)
+	if err != nil {
+		return err
+	}
+
 	// 调用评论存储中的删除评论方法
 	err = service.commentStore.DeleteComment(commentID)
 	if err != nil {
@@ -117,25 +206,218 @@ func (service *CommentService) DeleteComment(commentID uint64) error {
 		return err
 	}
 
+	// 取消已删除评论的置顶
+	if err := service.postStore.UnpinComment(comment.PostID, commentID); err != nil {
+		return err
+	}
+
+	// 更新博文评论数和热度
+	if err := recordEngagement(service.counterStore, service.trendingStore, comment.UID, comment.PostID, consts.POST_COUNTER_COMMENTS, -1); err != nil {
+		return err
+	}
+
 	// 如果没有发生错误，则返回 nil
 	return nil
 }
 
-// GetCommentList 获取评论列表
+// GetCommentList 按排序方式分页获取评论列表，第一页开头为置顶评论
+//
+// 参数：
+//   - postID：博文ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - sortMode：排序方式，new、old、top 或 hot
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
 //
 // 返回值：
-//   - 成功则返回评论列表
-//   - 失败返回nil
-func (service *CommentService) GetCommentList(postID uint64) ([]models.CommentInfo, error) {
-	return service.commentStore.GetCommentList(postID)
+//   - []uint64：评论ID列表
+//   - []uint64：对查看者可见的置顶评论ID
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *CommentService) GetCommentList(postID, viewerUID uint64, sortMode, cursor string, length int) ([]uint64, []uint64, string, error) {
+	// 校验查看者是否有权查看私密账号的博文
+	if err := checkPostAccess(service.postStore, service.followStore, postID, viewerUID); err != nil {
+		return nil, nil, "", err
+	}
+
+	// 获取对查看者不可见的作者，包括屏蔽、拉黑和被限流的用户
+	hidden, err := service.blockStore.GetHiddenAuthors(viewerUID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	commentIDs, nextCursor, err := getSortedPage(newCommentPageSource(service.commentStore, postID, hidden), sortMode, cursor, length)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	commentIDs, pinnedIDs, err := applyPinnedComments(service.postStore, service.commentStore, postID, commentIDs, cursor == "", hidden)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return commentIDs, pinnedIDs, nextCursor, nil
+}
+
+// applyPinnedComments 从评论列表中移除置顶评论，并在第一页开头按置顶顺序插入
+//
+// 参数：
+//   - postStore：博文存储
+//   - commentStore：评论存储
+//   - postID：博文ID
+//   - commentIDs：当前页的评论ID
+//   - firstPage：当前页是否为第一页
+//   - hidden：对查看者不可见的作者
+//
+// 返回值：
+//   - []uint64：处理后的评论ID列表
+//   - []uint64：对查看者可见的置顶评论ID
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func applyPinnedComments(postStore *stores.PostStore, commentStore *stores.CommentStore, postID uint64, commentIDs []uint64, firstPage bool, hidden stores.HiddenAuthors) ([]uint64, []uint64, error) {
+	post, err := postStore.GetPost(postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errors.New("post does not exist")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(post.PinnedCommentIDs) == 0 {
+		return commentIDs, []uint64{}, nil
+	}
+
+	pinnedIDs := make([]uint64, len(post.PinnedCommentIDs))
+	for index, id := range post.PinnedCommentIDs {
+		pinnedIDs[index] = uint64(id)
+	}
+	pinnedIDs, err = commentStore.FilterCommentIDs(pinnedIDs, hidden)
+	if err != nil {
+		return nil, nil, err
+	}
+	pinned := make(map[uint64]struct{}, len(post.PinnedCommentIDs))
+	for _, id := range post.PinnedCommentIDs {
+		pinned[uint64(id)] = struct{}{}
+	}
+
+	result := make([]uint64, 0, len(pinnedIDs)+len(commentIDs))
+	if firstPage {
+		result = append(result, pinnedIDs...)
+	}
+	for _, id := range commentIDs {
+		if _, ok := pinned[id]; !ok {
+			result = append(result, id)
+		}
+	}
+	return result, pinnedIDs, nil
+}
+
+// checkCommentPermission 校验用户是否满足博文的评论权限，博文作者不受限制
+//
+// 参数：
+//   - followStore：关注存储
+//   - uid：评论者ID
+//   - post：博文信息
+//
+// 返回值：
+//   - error：不满足评论权限时返回 ErrCommentClosed、ErrCommentFollowersOnly 或 ErrCommentMutualsOnly
+func checkCommentPermission(followStore *stores.FollowStore, uid uint64, post models.PostInfo) error {
+	if uid == post.UID {
+		return nil
+	}
+
+	switch post.CommentPermission {
+	case consts.COMMENT_PERMISSION_CLOSED:
+		return ErrCommentClosed
+	case consts.COMMENT_PERMISSION_FOLLOWERS, consts.COMMENT_PERMISSION_MUTUALS:
+		isFollowing, err := followStore.IsFollowing(uid, post.UID)
+		if err != nil {
+			return err
+		}
+		if !isFollowing {
+			if post.CommentPermission == consts.COMMENT_PERMISSION_MUTUALS {
+				return ErrCommentMutualsOnly
+			}
+			return ErrCommentFollowersOnly
+		}
+		if post.CommentPermission == consts.COMMENT_PERMISSION_MUTUALS {
+			isFollowed, err := followStore.IsFollowing(post.UID, uid)
+			if err != nil {
+				return err
+			}
+			if !isFollowed {
+				return ErrCommentMutualsOnly
+			}
+		}
+	}
+	return nil
+}
+
+// checkPostAccess 校验查看者是否有权查看博文及其评论，私密账号的博文仅对本人和已关注者可见
+//
+// 参数：
+//   - postStore：博文存储
+//   - followStore：关注存储
+//   - postID：博文ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - error：博文不存在或无权查看时返回相应错误信息；否则返回 nil
+func checkPostAccess(postStore *stores.PostStore, followStore *stores.FollowStore, postID, viewerUID uint64) error {
+	post, err := postStore.GetPost(postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("post does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	isAccessible, err := followStore.IsAccessible(viewerUID, post.UID)
+	if err != nil {
+		return err
+	}
+	if !isAccessible {
+		return errors.New("post is not accessible")
+	}
+	return nil
 }
 
-// GetCommentInfo 获取评论信息
+// newCommentPageSource 创建博文评论的排序分页数据来源
+//
+// 参数：
+//   - commentStore：评论存储
+//   - postID：博文ID
+//   - hidden：对查看者不可见的作者
+//
+// 返回值：
+//   - sortedPageSource：排序分页数据来源
+func newCommentPageSource(commentStore *stores.CommentStore, postID uint64, hidden stores.HiddenAuthors) sortedPageSource {
+	return sortedPageSource{
+		listByID: func(fromID uint64, ascending bool, length int) ([]uint64, error) {
+			comments, err := commentStore.GetCommentListByID(postID, fromID, ascending, length, hidden)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]uint64, len(comments))
+			for index, comment := range comments {
+				ids[index] = uint64(comment.ID)
+			}
+			return ids, nil
+		},
+		listByScore: func(mode string, fromScore float64, fromID uint64, length int) ([]uint64, []float64, error) {
+			return commentStore.GetCommentIDsByScore(postID, mode, fromScore, fromID, length)
+		},
+		filter: func(ids []uint64) ([]uint64, error) {
+			return commentStore.FilterCommentIDs(ids, hidden)
+		},
+	}
+}
+
+// GetCommentInfo 获取评论信息，被限流用户的评论对其他用户表现为不存在
+//
+// 参数：
+//   - commentID：评论ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
 //
 // 返回值：
 //   - 成功返回评论体
 //   - 失败返回nil
-func (service *CommentService) GetCommentInfo(commentID uint64) (models.CommentInfo, int64, error) {
+func (service *CommentService) GetCommentInfo(commentID, viewerUID uint64) (models.CommentInfo, int64, error) {
 	// 检查评论是否存在
 	exists, err := service.commentStore.ValidateCommentExistence(commentID)
 	if err != nil {
@@ -145,7 +427,18 @@ func (service *CommentService) GetCommentInfo(commentID uint64) (models.CommentI
 		return models.CommentInfo{}, 0, errors.New("comment does not exist")
 	}
 
-	return service.commentStore.GetCommentInfo(commentID)
+	comment, likeCount, err := service.commentStore.GetCommentInfo(commentID)
+	if err != nil {
+		return models.CommentInfo{}, 0, err
+	}
+	shadowbannedUIDs, err := service.blockStore.GetShadowbannedUIDsAmong(viewerUID, []uint64{comment.UID})
+	if err != nil {
+		return models.CommentInfo{}, 0, err
+	}
+	if len(shadowbannedUIDs) > 0 {
+		return models.CommentInfo{}, 0, errors.New("comment does not exist")
+	}
+	return comment, likeCount, nil
 }
 
 // GetCommentUserStatus
@@ -201,6 +494,11 @@ func (service *CommentService) LikeComment(uid, commentID uint64) error {
 		return err
 	}
 
+	// 更新排序得分缓存
+	if err := service.commentStore.RefreshCommentScore(commentID); err != nil {
+		return err
+	}
+
 	// 如果点赞成功，返回nil
 	return nil
 }
@@ -228,6 +526,11 @@ func (service *CommentService) CancelLikeComment(uid, commentID uint64) error {
 		return err
 	}
 
+	// 更新排序得分缓存
+	if err := service.commentStore.RefreshCommentScore(commentID); err != nil {
+		return err
+	}
+
 	// 如果取消点赞成功，返回nil
 	return nil
 }
@@ -255,6 +558,11 @@ func (service *CommentService) DislikeComment(uid, commentID uint64) error {
 		return err
 	}
 
+	// 更新排序得分缓存
+	if err := service.commentStore.RefreshCommentScore(commentID); err != nil {
+		return err
+	}
+
 	// 如果点踩成功，返回nil
 	return nil
 }
@@ -282,6 +590,83 @@ func (service *CommentService) CancelDislikeComment(uid, commentID uint64) error
 		return err
 	}
 
+	// 更新排序得分缓存
+	if err := service.commentStore.RefreshCommentScore(commentID); err != nil {
+		return err
+	}
+
 	// 如果取消点踩成功，返回nil
 	return nil
 }
+
+// PinComment 置顶评论，仅博文作者可操作
+//
+// 参数：
+//   - uid：操作者ID
+//   - commentID：评论ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *CommentService) PinComment(uid, commentID uint64) error {
+	post, err := service.getOwnedCommentPost(uid, commentID)
+	if err != nil {
+		return err
+	}
+	for _, id := range post.PinnedCommentIDs {
+		if uint64(id) == commentID {
+			return errors.New("comment is already pinned")
+		}
+	}
+
+	pinned, err := service.postStore.PinComment(uint64(post.ID), commentID, consts.POST_PINNED_COMMENT_MAX)
+	if err != nil {
+		return err
+	}
+	if !pinned {
+		return errors.New("pinned comment limit reached")
+	}
+	return nil
+}
+
+// UnpinComment 取消置顶评论，仅博文作者可操作
+//
+// 参数：
+//   - uid：操作者ID
+//   - commentID：评论ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *CommentService) UnpinComment(uid, commentID uint64) error {
+	post, err := service.getOwnedCommentPost(uid, commentID)
+	if err != nil {
+		return err
+	}
+	return service.postStore.UnpinComment(uint64(post.ID), commentID)
+}
+
+// getOwnedCommentPost 获取评论所属的博文，并校验操作者是否为博文作者
+//
+// 参数：
+//   - uid：操作者ID
+//   - commentID：评论ID
+//
+// 返回值：
+//   - models.PostInfo：博文信息
+//   - error：评论不存在或操作者不是博文作者时返回错误
+func (service *CommentService) getOwnedCommentPost(uid, commentID uint64) (models.PostInfo, error) {
+	comment, err := service.commentStore.GetComment(commentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.PostInfo{}, errors.New("comment does not exist")
+	}
+	if err != nil {
+		return models.PostInfo{}, err
+	}
+	post, err := service.postStore.GetPost(comment.PostID)
+	if err != nil {
+		return models.PostInfo{}, err
+	}
+	if post.UID != uid {
+		return models.PostInfo{}, errors.New("permission denied")
+	}
+	return post, nil
+}
@@ -6,20 +6,26 @@ Copyright (c) [2024], Author(s):
 */
 package services
 
-import "github.com/Kirisakiii/neko-micro-blog-backend/stores"
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
 
 // Factory 服务工厂
 type Factory struct {
 	storeFactory *stores.Factory
+	logger       *logrus.Logger
 }
 
 // NewFactory 创建服务工厂
 //
 // 参数：
 // storeFactory *stores.Factory - 存储工厂
+// logger *logrus.Logger - 日志记录器，用于记录不影响请求结果的失败
 //
 // 返回值：
 // *Factory - 服务工厂
-func NewFactory(storeFactory *stores.Factory) *Factory {
-	return &Factory{storeFactory: storeFactory}
+func NewFactory(storeFactory *stores.Factory, logger *logrus.Logger) *Factory {
+	return &Factory{storeFactory: storeFactory, logger: logger}
 }
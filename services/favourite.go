@@ -0,0 +1,363 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for favourite folder related services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/generators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+// FavouriteService 收藏夹服务
+type FavouriteService struct {
+	favouriteStore *stores.FavouriteStore
+	postStore      *stores.PostStore
+	followStore    *stores.FollowStore
+	blockStore     *stores.BlockStore
+}
+
+// NewFavouriteService 返回一个新的收藏夹服务实例。
+//
+// 返回：
+//   - *FavouriteService: 返回一个指向新的收藏夹服务实例的指针。
+func (factory *Factory) NewFavouriteService() *FavouriteService {
+	return &FavouriteService{
+		favouriteStore: factory.storeFactory.NewFavouriteStore(),
+		postStore:      factory.storeFactory.NewPostStore(),
+		followStore:    factory.storeFactory.NewFollowStore(),
+		blockStore:     factory.storeFactory.NewBlockStore(),
+	}
+}
+
+// validateFolderInfo 校验收藏夹名称和描述
+//
+// 参数：
+//   - name：收藏夹名称
+//   - description：收藏夹描述
+//
+// 返回值：
+//   - error：如果不合法，返回相应错误信息；否则返回 nil
+func validateFolderInfo(name, description string) error {
+	if name == "" {
+		return errors.New("folder name is required")
+	}
+	if utf8.RuneCountInString(name) > consts.FAVOURITE_FOLDER_NAME_MAX_LENGTH {
+		return errors.New("folder name is too long")
+	}
+	if utf8.RuneCountInString(description) > consts.FAVOURITE_FOLDER_DESCRIPTION_MAX_LENGTH {
+		return errors.New("folder description is too long")
+	}
+	return nil
+}
+
+// getFolder 获取收藏夹信息
+//
+// 参数：
+//   - favouriteStore：收藏夹存储
+//   - folderID：收藏夹ID
+//
+// 返回值：
+//   - models.FavouriteFolderInfo：收藏夹信息
+//   - error：如果收藏夹不存在或发生错误，返回相应错误信息；否则返回 nil
+func getFolder(favouriteStore *stores.FavouriteStore, folderID uint64) (models.FavouriteFolderInfo, error) {
+	folderInfo, err := favouriteStore.GetFolder(folderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.FavouriteFolderInfo{}, errors.New("folder does not exist")
+	}
+	return folderInfo, err
+}
+
+// checkFolderOwner 校验收藏夹是否属于该用户，默认收藏夹属于所有用户
+//
+// 参数：
+//   - favouriteStore：收藏夹存储
+//   - uid：用户ID
+//   - folderID：收藏夹ID
+//
+// 返回值：
+//   - error：如果收藏夹不存在、不属于该用户或发生错误，返回相应错误信息；否则返回 nil
+func checkFolderOwner(favouriteStore *stores.FavouriteStore, uid, folderID uint64) error {
+	if folderID == consts.FAVOURITE_DEFAULT_FOLDER_ID {
+		return nil
+	}
+	folderInfo, err := getFolder(favouriteStore, folderID)
+	if err != nil {
+		return err
+	}
+	if folderInfo.OwnerUID != uid {
+		return errors.New("permission denied")
+	}
+	return nil
+}
+
+// CreateFolder 创建收藏夹
+//
+// 参数：
+//   - uid：用户ID
+//   - reqBody：创建收藏夹请求体
+//
+// 返回值：
+//   - uint64：新收藏夹ID
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FavouriteService) CreateFolder(uid uint64, reqBody types.FavouriteFolderCreateBody) (uint64, error) {
+	name := strings.TrimSpace(reqBody.Name)
+	description := strings.TrimSpace(reqBody.Description)
+	if err := validateFolderInfo(name, description); err != nil {
+		return 0, err
+	}
+
+	count, err := service.favouriteStore.CountFoldersByOwner(uid)
+	if err != nil {
+		return 0, err
+	}
+	if count >= consts.FAVOURITE_FOLDER_MAX_COUNT_PER_USER {
+		return 0, errors.New("too many folders")
+	}
+
+	folderInfo, err := service.favouriteStore.CreateFolder(uid, name, description, reqBody.IsPrivate)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(folderInfo.ID), nil
+}
+
+// UpdateFolder 更新收藏夹
+//
+// 参数：
+//   - uid：用户ID
+//   - reqBody：更新收藏夹请求体
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FavouriteService) UpdateFolder(uid uint64, reqBody types.FavouriteFolderUpdateBody) error {
+	folderInfo, err := getFolder(service.favouriteStore, reqBody.FolderID)
+	if err != nil {
+		return err
+	}
+	if folderInfo.OwnerUID != uid {
+		return errors.New("permission denied")
+	}
+
+	name, description := folderInfo.Name, folderInfo.Description
+	if reqBody.Name != nil {
+		name = strings.TrimSpace(*reqBody.Name)
+	}
+	if reqBody.Description != nil {
+		description = strings.TrimSpace(*reqBody.Description)
+	}
+	if err := validateFolderInfo(name, description); err != nil {
+		return err
+	}
+
+	isPrivate := folderInfo.IsPrivate
+	if reqBody.IsPrivate != nil {
+		isPrivate = *reqBody.IsPrivate
+	}
+
+	return service.favouriteStore.UpdateFolder(reqBody.FolderID, name, description, isPrivate)
+}
+
+// DeleteFolder 删除收藏夹，其中的收藏移回默认收藏夹
+//
+// 参数：
+//   - uid：用户ID
+//   - folderID：收藏夹ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FavouriteService) DeleteFolder(uid, folderID uint64) error {
+	if folderID == consts.FAVOURITE_DEFAULT_FOLDER_ID {
+		return errors.New("default folder cannot be deleted")
+	}
+	if err := checkFolderOwner(service.favouriteStore, uid, folderID); err != nil {
+		return err
+	}
+	return service.favouriteStore.DeleteFolder(uid, folderID)
+}
+
+// GetUserFolders 获取用户的收藏夹及其收藏数量，私密收藏夹仅创建者本人可见
+//
+// 参数：
+//   - ownerUID：创建者ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - []types.FavouriteFolder：收藏夹列表，第一项为默认收藏夹
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FavouriteService) GetUserFolders(ownerUID, viewerUID uint64) ([]types.FavouriteFolder, error) {
+	folderInfos, err := service.favouriteStore.GetFoldersByOwner(ownerUID, ownerUID == viewerUID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := service.favouriteStore.CountFavouritesByFolder(ownerUID)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]types.FavouriteFolder, 0, len(folderInfos)+1)
+	folders = append(folders, types.FavouriteFolder{
+		Folder: models.FavouriteFolderInfo{OwnerUID: ownerUID, Name: consts.FAVOURITE_DEFAULT_FOLDER_NAME},
+		Count:  counts[consts.FAVOURITE_DEFAULT_FOLDER_ID],
+	})
+	for _, folderInfo := range folderInfos {
+		folders = append(folders, types.FavouriteFolder{
+			Folder: folderInfo,
+			Count:  counts[uint64(folderInfo.ID)],
+		})
+	}
+	return folders, nil
+}
+
+// MoveFavourite 将收藏移动到另一个收藏夹
+//
+// 参数：
+//   - uid：用户ID
+//   - postID：博文ID
+//   - folderID：目标收藏夹ID，为 0 时移回默认收藏夹
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FavouriteService) MoveFavourite(uid, postID, folderID uint64) error {
+	if err := checkFolderOwner(service.favouriteStore, uid, folderID); err != nil {
+		return err
+	}
+	exists, err := service.favouriteStore.MoveFavourite(uid, postID, folderID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("user has not favourited this post")
+	}
+	return nil
+}
+
+// UpdateFavouriteNote 更新收藏备注
+//
+// 参数：
+//   - uid：用户ID
+//   - postID：博文ID
+//   - note：收藏备注，为空时清除备注
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FavouriteService) UpdateFavouriteNote(uid, postID uint64, note string) error {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > consts.FAVOURITE_NOTE_MAX_LENGTH {
+		return errors.New("note is too long")
+	}
+	exists, err := service.favouriteStore.UpdateFavouriteNote(uid, postID, note)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("user has not favourited this post")
+	}
+	return nil
+}
+
+// GetFavouritePage 按收藏时间倒序分页获取用户的收藏
+//
+// 参数：
+//   - uid：收藏者ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - folderID：收藏夹ID，为 nil 时获取查看者可见的所有收藏夹中的收藏
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []models.PostFavouriteInfo：收藏记录，已排除查看者无权查看的博文，数量可能少于 length
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FavouriteService) GetFavouritePage(uid, viewerUID uint64, folderID *uint64, cursor string, length int) ([]models.PostFavouriteInfo, string, error) {
+	// 私密收藏夹仅创建者本人可见
+	var excludedFolderIDs []uint64
+	if uid != viewerUID {
+		if folderID != nil && *folderID != consts.FAVOURITE_DEFAULT_FOLDER_ID {
+			folderInfo, err := getFolder(service.favouriteStore, *folderID)
+			if err != nil {
+				return nil, "", err
+			}
+			if folderInfo.OwnerUID != uid || folderInfo.IsPrivate {
+				// 对无权查看者隐藏私密收藏夹的存在
+				return nil, "", errors.New("folder does not exist")
+			}
+		}
+		if folderID == nil {
+			privateFolderIDs, err := service.favouriteStore.GetPrivateFolderIDs(uid)
+			if err != nil {
+				return nil, "", err
+			}
+			excludedFolderIDs = privateFolderIDs
+		}
+	}
+
+	// 解析游标
+	var (
+		fromTime time.Time
+		fromID   uint64
+	)
+	if cursor != "" {
+		fromMilli, id, err := parsers.ParseCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		fromTime, fromID = time.UnixMilli(fromMilli), id
+	}
+
+	// 多获取一条记录用于判断是否有下一页
+	records, err := service.favouriteStore.GetFavouritePage(uid, folderID, excludedFolderIDs, fromTime, fromID, length+1)
+	if err != nil {
+		return nil, "", err
+	}
+	hasMore := len(records) > length
+	if hasMore {
+		records = records[:length]
+	}
+	var nextCursor string
+	if hasMore {
+		last := records[len(records)-1]
+		nextCursor = generators.GenerateCursor(last.FavouritedAt.UnixMilli(), uint64(last.PostID))
+	}
+
+	// 排除查看者屏蔽、拉黑的用户、被限流的其他用户和无权查看的私密账号的博文
+	filter, err := getAuthorFilter(service.blockStore, service.followStore, viewerUID)
+	if err != nil {
+		return nil, "", err
+	}
+	postIDs := make([]int64, len(records))
+	for index, record := range records {
+		postIDs[index] = record.PostID
+	}
+	visibleIDs, err := service.postStore.FilterPostIDsByAuthor(postIDs, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(visibleIDs) == len(records) {
+		return records, nextCursor, nil
+	}
+	visible := make(map[int64]struct{}, len(visibleIDs))
+	for _, id := range visibleIDs {
+		visible[id] = struct{}{}
+	}
+	filtered := make([]models.PostFavouriteInfo, 0, len(visibleIDs))
+	for _, record := range records {
+		if _, ok := visible[record.PostID]; ok {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nextCursor, nil
+}
@@ -9,13 +9,21 @@ Copyright (c) [2024], Author(s):
 package services
 
 import (
+	"errors"
+	"time"
+
 	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/generators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
 )
 
 // FollowService 关注服务
 type FollowService struct {
 	followStore *stores.FollowStore
+	blockStore  *stores.BlockStore
+	userStore   *stores.UserStore
 }
 
 // NewFollowService 返回一个新的关注服务实例。
@@ -25,19 +33,50 @@ type FollowService struct {
 func (factory *Factory) NewFollowService() *FollowService {
 	return &FollowService{
 		followStore: factory.storeFactory.NewFollowStore(),
+		blockStore:  factory.storeFactory.NewBlockStore(),
+		userStore:   factory.storeFactory.NewUserStore(),
 	}
 }
 
-// FollowUser 关注用户
+// FollowUser 关注用户，被关注用户为私密账号时创建关注请求
 //
 // 参数：
 //   - uid：用户ID
 //   - followedID：被关注用户ID
 //
 // 返回值：
+//   - bool：是否仅创建了待处理的关注请求
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (service *FollowService) FollowUser(uid, followedID uint64) error {
-	return service.followStore.FollowUser(uid, followedID)
+func (service *FollowService) FollowUser(uid, followedID uint64) (bool, error) {
+	if uid == followedID {
+		return false, errors.New("cannot follow yourself")
+	}
+
+	// 校验双方是否存在拉黑关系
+	isBlocked, err := service.blockStore.IsBlockedBetween(uid, followedID)
+	if err != nil {
+		return false, err
+	}
+	if isBlocked {
+		return false, errors.New("user is blocked")
+	}
+
+	// 私密账号需要经过对方同意
+	followedUser, err := service.userStore.GetUserByUID(followedID)
+	if err != nil {
+		return false, err
+	}
+	if followedUser.IsPrivate {
+		isFollowing, err := service.followStore.IsFollowing(uid, followedID)
+		if err != nil {
+			return false, err
+		}
+		if !isFollowing {
+			return true, service.followStore.CreateFollowRequest(uid, followedID)
+		}
+	}
+
+	return false, service.followStore.FollowUser(uid, followedID)
 }
 
 // CancelFollowUser 取消关注用户
@@ -52,12 +91,19 @@ func (service *FollowService) CancelFollowUser(uid, followedID uint64) error {
 	return service.followStore.CancelFollowUser(uid, followedID)
 }
 
-// GetFOllowList 获取关注列表
+// GetFOllowList 获取关注列表，私密账号的关注列表仅对本人和已关注者可见
+//
+// 参数：
+//   - userID：用户ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
 //
 // 返回值：
 //   - []models.FollowInfo：关注列表
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (service *FollowService) GetFollowList(userID uint64) ([]models.FollowInfo, error) {
+func (service *FollowService) GetFollowList(userID, viewerUID uint64) ([]models.FollowInfo, error) {
+	if err := service.checkFollowListAccess(userID, viewerUID); err != nil {
+		return nil, err
+	}
 	return service.followStore.GetFollowList(userID)
 }
 
@@ -73,15 +119,41 @@ func (service *FollowService) GetFollowCountByUID(uid uint64) (int64, error) {
     return service.followStore.GetFollowedsByUID(uid)
 }
 
-// GetFOllowerList 获取关注列表
+// GetFOllowerList 获取粉丝列表，私密账号的粉丝列表仅对本人和已关注者可见
+//
+// 参数：
+//   - userID：用户ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
 //
 // 返回值：
 //   - []models.FollowInfo：粉丝列表
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (service *FollowService) GetFollowerList(userID uint64) ([]models.FollowInfo, error) {
+func (service *FollowService) GetFollowerList(userID, viewerUID uint64) ([]models.FollowInfo, error) {
+	if err := service.checkFollowListAccess(userID, viewerUID); err != nil {
+		return nil, err
+	}
 	return service.followStore.GetFollowerList(userID)
 }
 
+// checkFollowListAccess 校验查看者能否查看用户的关注关系
+//
+// 参数：
+//   - uid：用户ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - error：无权查看时返回 account is private
+func (service *FollowService) checkFollowListAccess(uid, viewerUID uint64) error {
+	isAccessible, err := service.followStore.IsAccessible(viewerUID, uid)
+	if err != nil {
+		return err
+	}
+	if !isAccessible {
+		return errors.New("account is private")
+	}
+	return nil
+}
+
 // GetFollowCountByUID 获取用户的粉丝数量
 //
 //	参数：
@@ -92,4 +164,195 @@ func (service *FollowService) GetFollowerList(userID uint64) ([]models.FollowInf
 //	  - error：如果发生错误，返回相应错误信息；否则返回 nil
 func (service *FollowService) GetFollowerCountByUID(uid uint64) (int64, error) {
     return service.followStore.GetFollowersByUID(uid)
-}
\ No newline at end of file
+}
+
+// GetFollowRequestList 获取待处理的关注请求列表
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []models.FollowRequestInfo：关注请求列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FollowService) GetFollowRequestList(uid uint64) ([]models.FollowRequestInfo, error) {
+	return service.followStore.GetFollowRequestList(uid)
+}
+
+// ApproveFollowRequest 通过关注请求
+//
+// 参数：
+//   - uid：用户ID
+//   - requesterID：请求者ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FollowService) ApproveFollowRequest(uid, requesterID uint64) error {
+	return service.followStore.ApproveFollowRequest(uid, requesterID)
+}
+
+// RejectFollowRequest 拒绝关注请求
+//
+// 参数：
+//   - uid：用户ID
+//   - requesterID：请求者ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FollowService) RejectFollowRequest(uid, requesterID uint64) error {
+	return service.followStore.RejectFollowRequest(uid, requesterID)
+}
+
+// GetFollowListPage 分页获取带有用户资料和关系标记的关注列表
+//
+// 参数：
+//   - uid：用户ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []types.FollowListItem：关注列表
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FollowService) GetFollowListPage(uid, viewerUID uint64, cursor string, length int) ([]types.FollowListItem, string, error) {
+	return service.getFollowPage(uid, viewerUID, cursor, length, false)
+}
+
+// GetFollowerListPage 分页获取带有用户资料和关系标记的粉丝列表
+//
+// 参数：
+//   - uid：用户ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []types.FollowListItem：粉丝列表
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FollowService) GetFollowerListPage(uid, viewerUID uint64, cursor string, length int) ([]types.FollowListItem, string, error) {
+	return service.getFollowPage(uid, viewerUID, cursor, length, true)
+}
+
+// getFollowPage 分页获取关注或粉丝列表，并批量补全用户资料和查看者关系
+//
+// 参数：
+//   - uid：用户ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
+//   - isFollowerList：是否获取粉丝列表
+//
+// 返回值：
+//   - []types.FollowListItem：列表项
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *FollowService) getFollowPage(uid, viewerUID uint64, cursor string, length int, isFollowerList bool) ([]types.FollowListItem, string, error) {
+	// 私密账号的关注关系仅对本人和已关注者可见
+	if err := service.checkFollowListAccess(uid, viewerUID); err != nil {
+		return nil, "", err
+	}
+
+	// 解析游标
+	var (
+		fromTime time.Time
+		fromID   uint64
+		err      error
+	)
+	if cursor != "" {
+		var fromMilli int64
+		fromMilli, fromID, err = parsers.ParseCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		fromTime = time.UnixMilli(fromMilli)
+	}
+
+	// 多获取一条记录用于判断是否有下一页
+	var records []models.FollowInfo
+	if isFollowerList {
+		records, err = service.followStore.GetFollowerListPage(uid, fromTime, fromID, length+1)
+	} else {
+		records, err = service.followStore.GetFollowListPage(uid, fromTime, fromID, length+1)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	hasMore := len(records) > length
+	if hasMore {
+		records = records[:length]
+	}
+
+	// 提取对方用户ID
+	targetIDs := make([]uint64, len(records))
+	for index, record := range records {
+		if isFollowerList {
+			targetIDs[index] = record.UserID
+		} else {
+			targetIDs[index] = record.FollowedID
+		}
+	}
+
+	// 批量获取用户资料
+	users, err := service.userStore.GetUsersByUIDs(targetIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	userMap := make(map[uint64]models.UserInfo, len(users))
+	for _, user := range users {
+		userMap[uint64(user.ID)] = user
+	}
+
+	// 批量获取查看者与列表用户之间的关系
+	var followingIDs, followerIDs, blockedIDs []uint64
+	if viewerUID != 0 {
+		followingIDs, err = service.followStore.GetFollowedIDsAmong(viewerUID, targetIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		followerIDs, err = service.followStore.GetFollowerIDsAmong(viewerUID, targetIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		blockedIDs, err = service.blockStore.GetBlockedIDsAmong(viewerUID, targetIDs)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	relations := make(map[uint64]*types.UserRelation, len(targetIDs))
+	for _, targetID := range targetIDs {
+		relations[targetID] = &types.UserRelation{}
+	}
+	for _, id := range followingIDs {
+		relations[id].Following = true
+	}
+	for _, id := range followerIDs {
+		relations[id].FollowedBy = true
+	}
+	for _, id := range blockedIDs {
+		relations[id].Blocked = true
+	}
+
+	// 组装列表项，跳过已不存在的用户
+	items := make([]types.FollowListItem, 0, len(records))
+	for index, record := range records {
+		user, ok := userMap[targetIDs[index]]
+		if !ok {
+			continue
+		}
+		items = append(items, types.FollowListItem{
+			User:       user,
+			FollowedAt: record.FollowedAt,
+			Relation:   *relations[targetIDs[index]],
+		})
+	}
+
+	// 生成下一页游标
+	var nextCursor string
+	if hasMore {
+		last := records[len(records)-1]
+		nextCursor = generators.GenerateCursor(last.FollowedAt.UnixMilli(), targetIDs[len(targetIDs)-1])
+	}
+
+	return items, nextCursor, nil
+}
@@ -0,0 +1,355 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for batch hydration services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// HydrationService 批量获取服务，使用集合查询补全列表接口返回的ID
+type HydrationService struct {
+	postStore    *stores.PostStore
+	counterStore *stores.CounterStore
+	commentStore *stores.CommentStore
+	replyStore   *stores.ReplyStore
+	userStore    *stores.UserStore
+	blockStore   *stores.BlockStore
+	followStore  *stores.FollowStore
+}
+
+// NewHydrationService 返回一个新的批量获取服务实例。
+//
+// 返回：
+//   - *HydrationService: 返回一个指向新的批量获取服务实例的指针。
+func (factory *Factory) NewHydrationService() *HydrationService {
+	return &HydrationService{
+		postStore:    factory.storeFactory.NewPostStore(),
+		counterStore: factory.storeFactory.NewCounterStore(),
+		commentStore: factory.storeFactory.NewCommentStore(),
+		replyStore:   factory.storeFactory.NewReplyStore(),
+		userStore:    factory.storeFactory.NewUserStore(),
+		blockStore:   factory.storeFactory.NewBlockStore(),
+		followStore:  factory.storeFactory.NewFollowStore(),
+	}
+}
+
+// GetPosts 批量获取博文，不存在或对查看者不可见的博文将被忽略
+//
+// 参数：
+//   - postIDs：博文ID列表
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - []types.HydratedPost：博文列表，按参数顺序排列
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *HydrationService) GetPosts(postIDs []uint64, viewerUID uint64) ([]types.HydratedPost, error) {
+	posts, err := service.postStore.GetPostsByIDs(postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 过滤对查看者不可见的作者
+	postAuthorIDs := make([]uint64, len(posts))
+	for index, post := range posts {
+		postAuthorIDs[index] = post.UID
+	}
+	excludedUIDs, err := service.getExcludedUIDs(viewerUID, postAuthorIDs)
+	if err != nil {
+		return nil, err
+	}
+	postMap := make(map[uint64]models.PostInfo, len(posts))
+	authorIDs := make([]uint64, 0, len(posts))
+	for _, post := range posts {
+		if _, ok := excludedUIDs[post.UID]; ok {
+			continue
+		}
+		postMap[uint64(post.ID)] = post
+		authorIDs = append(authorIDs, post.UID)
+	}
+	visibleIDs := make([]uint64, 0, len(postMap))
+	for _, postID := range postIDs {
+		if _, ok := postMap[postID]; ok {
+			visibleIDs = append(visibleIDs, postID)
+		}
+	}
+
+	// 批量获取作者资料和计数
+	authors, err := service.getUserMap(authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	counters, err := service.counterStore.GetPostCounters(visibleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量获取查看者的点赞和收藏状态
+	liked := map[uint64]struct{}{}
+	favourited := map[uint64]struct{}{}
+	if viewerUID != 0 {
+		likedIDs, err := service.postStore.GetLikedPostIDsAmong(viewerUID, visibleIDs)
+		if err != nil {
+			return nil, err
+		}
+		liked = toIDSet(likedIDs)
+		favouritedIDs, err := service.postStore.GetFavouritedPostIDsAmong(viewerUID, visibleIDs)
+		if err != nil {
+			return nil, err
+		}
+		favourited = toIDSet(favouritedIDs)
+	}
+
+	hydrated := make([]types.HydratedPost, 0, len(visibleIDs))
+	for _, postID := range visibleIDs {
+		post := postMap[postID]
+		_, isLiked := liked[postID]
+		_, isFavourited := favourited[postID]
+		hydrated = append(hydrated, types.HydratedPost{
+			Post:         post,
+			Author:       lookupUser(authors, post.UID),
+			Counter:      counters[postID],
+			IsLiked:      isLiked,
+			IsFavourited: isFavourited,
+		})
+	}
+	return hydrated, nil
+}
+
+// GetComments 批量获取评论，不存在或对查看者不可见的评论将被忽略
+//
+// 参数：
+//   - commentIDs：评论ID列表
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - []types.HydratedComment：评论列表，按参数顺序排列
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *HydrationService) GetComments(commentIDs []uint64, viewerUID uint64) ([]types.HydratedComment, error) {
+	comments, err := service.commentStore.GetCommentsByIDs(commentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取评论所属博文的作者，用于校验私密账号的访问权限
+	postIDs := make([]uint64, 0, len(comments))
+	for _, comment := range comments {
+		postIDs = append(postIDs, comment.PostID)
+	}
+	posts, err := service.postStore.GetPostsByIDs(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	postAuthors := make(map[uint64]uint64, len(posts))
+	candidateUIDs := make([]uint64, 0, len(posts)+len(comments))
+	for _, post := range posts {
+		postAuthors[uint64(post.ID)] = post.UID
+		candidateUIDs = append(candidateUIDs, post.UID)
+	}
+	for _, comment := range comments {
+		candidateUIDs = append(candidateUIDs, comment.UID)
+	}
+
+	// 过滤对查看者不可见的评论
+	excludedUIDs, err := service.getExcludedUIDs(viewerUID, candidateUIDs)
+	if err != nil {
+		return nil, err
+	}
+	commentMap := make(map[uint64]models.CommentInfo, len(comments))
+	authorIDs := make([]uint64, 0, len(comments))
+	for _, comment := range comments {
+		postAuthor, ok := postAuthors[comment.PostID]
+		if !ok {
+			continue
+		}
+		if _, ok := excludedUIDs[postAuthor]; ok {
+			continue
+		}
+		if _, ok := excludedUIDs[comment.UID]; ok {
+			continue
+		}
+		commentMap[uint64(comment.ID)] = comment
+		authorIDs = append(authorIDs, comment.UID)
+	}
+	visibleIDs := make([]uint64, 0, len(commentMap))
+	for _, commentID := range commentIDs {
+		if _, ok := commentMap[commentID]; ok {
+			visibleIDs = append(visibleIDs, commentID)
+		}
+	}
+
+	// 批量获取作者资料和计数
+	authors, err := service.getUserMap(authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	likeCounts, dislikeCounts, err := service.commentStore.GetCommentRatingCounts(visibleIDs)
+	if err != nil {
+		return nil, err
+	}
+	replyCounts, err := service.replyStore.CountRepliesByCommentIDs(visibleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量获取查看者的评价
+	rates := map[uint64]string{}
+	if viewerUID != 0 {
+		rates, err = service.commentStore.GetCommentRatesAmong(viewerUID, visibleIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hydrated := make([]types.HydratedComment, 0, len(visibleIDs))
+	for _, commentID := range visibleIDs {
+		comment := commentMap[commentID]
+		hydrated = append(hydrated, types.HydratedComment{
+			Comment:      comment,
+			Author:       lookupUser(authors, comment.UID),
+			LikeCount:    likeCounts[commentID],
+			DislikeCount: dislikeCounts[commentID],
+			ReplyCount:   replyCounts[commentID],
+			IsLiked:      rates[commentID] == "like",
+			IsDisliked:   rates[commentID] == "dislike",
+		})
+	}
+	return hydrated, nil
+}
+
+// GetUsers 批量获取用户资料及查看者与其之间的关系，不存在的用户将被忽略
+//
+// 参数：
+//   - uids：用户ID列表
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - []types.HydratedUser：用户列表，按参数顺序排列
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *HydrationService) GetUsers(uids []uint64, viewerUID uint64) ([]types.HydratedUser, error) {
+	users, err := service.getUserMap(uids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量获取查看者与用户之间的关系
+	var following, followedBy, blocked map[uint64]struct{}
+	if viewerUID != 0 {
+		followedIDs, err := service.followStore.GetFollowedIDsAmong(viewerUID, uids)
+		if err != nil {
+			return nil, err
+		}
+		followerIDs, err := service.followStore.GetFollowerIDsAmong(viewerUID, uids)
+		if err != nil {
+			return nil, err
+		}
+		blockedIDs, err := service.blockStore.GetBlockedIDsAmong(viewerUID, uids)
+		if err != nil {
+			return nil, err
+		}
+		following, followedBy, blocked = toIDSet(followedIDs), toIDSet(followerIDs), toIDSet(blockedIDs)
+	}
+
+	hydrated := make([]types.HydratedUser, 0, len(users))
+	for _, uid := range uids {
+		user, ok := users[uid]
+		if !ok {
+			continue
+		}
+		item := types.HydratedUser{User: user}
+		if viewerUID != 0 {
+			_, isFollowing := following[uid]
+			_, isFollowedBy := followedBy[uid]
+			_, isBlocked := blocked[uid]
+			item.Relation = &types.UserRelation{
+				Following:  isFollowing,
+				FollowedBy: isFollowedBy,
+				Blocked:    isBlocked,
+			}
+		}
+		hydrated = append(hydrated, item)
+	}
+	return hydrated, nil
+}
+
+// getExcludedUIDs 获取对查看者不可见的用户，包括屏蔽、拉黑的用户和给定用户中被限流的其他用户以及无权查看的私密账号
+//
+// 参数：
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - uids：需要检查限流状态和私密账号访问权限的用户ID
+//
+// 返回值：
+//   - map[uint64]struct{}：不可见的用户ID集合
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *HydrationService) getExcludedUIDs(viewerUID uint64, uids []uint64) (map[uint64]struct{}, error) {
+	hiddenUIDs, err := service.blockStore.GetHiddenUIDs(viewerUID)
+	if err != nil {
+		return nil, err
+	}
+	shadowbannedUIDs, err := service.blockStore.GetShadowbannedUIDsAmong(viewerUID, uids)
+	if err != nil {
+		return nil, err
+	}
+	inaccessibleUIDs, err := service.followStore.GetInaccessibleUIDsAmong(viewerUID, uids)
+	if err != nil {
+		return nil, err
+	}
+	excludedUIDs := append(hiddenUIDs, shadowbannedUIDs...)
+	return toIDSet(append(excludedUIDs, inaccessibleUIDs...)), nil
+}
+
+// getUserMap 批量获取用户资料
+//
+// 参数：
+//   - uids：用户ID列表，可以包含重复ID
+//
+// 返回值：
+//   - map[uint64]models.UserInfo：用户ID到用户资料的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *HydrationService) getUserMap(uids []uint64) (map[uint64]models.UserInfo, error) {
+	users, err := service.userStore.GetUsersByUIDs(uids)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint64]models.UserInfo, len(users))
+	for _, user := range users {
+		userMap[uint64(user.ID)] = user
+	}
+	return userMap, nil
+}
+
+// lookupUser 从用户资料映射中查找用户
+//
+// 参数：
+//   - users：用户ID到用户资料的映射
+//   - uid：用户ID
+//
+// 返回值：
+//   - *models.UserInfo：用户资料，不存在时为 nil
+func lookupUser(users map[uint64]models.UserInfo, uid uint64) *models.UserInfo {
+	user, ok := users[uid]
+	if !ok {
+		return nil
+	}
+	return &user
+}
+
+// toIDSet 将ID列表转换为集合
+//
+// 参数：
+//   - ids：ID列表
+//
+// 返回值：
+//   - map[uint64]struct{}：ID集合
+func toIDSet(ids []uint64) map[uint64]struct{} {
+	set := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,445 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for user list related services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// UserListService 用户列表服务
+type UserListService struct {
+	userListStore  *stores.UserListStore
+	postStore      *stores.PostStore
+	analyticsStore *stores.AnalyticsStore
+	followStore    *stores.FollowStore
+	blockStore     *stores.BlockStore
+	userStore      *stores.UserStore
+}
+
+// NewUserListService 返回一个新的用户列表服务实例。
+//
+// 返回：
+//   - *UserListService: 返回一个指向新的用户列表服务实例的指针。
+func (factory *Factory) NewUserListService() *UserListService {
+	return &UserListService{
+		userListStore:  factory.storeFactory.NewUserListStore(),
+		postStore:      factory.storeFactory.NewPostStore(),
+		analyticsStore: factory.storeFactory.NewAnalyticsStore(),
+		followStore:    factory.storeFactory.NewFollowStore(),
+		blockStore:     factory.storeFactory.NewBlockStore(),
+		userStore:      factory.storeFactory.NewUserStore(),
+	}
+}
+
+// validateListInfo 校验列表名称和描述
+//
+// 参数：
+//   - name：列表名称
+//   - description：列表描述
+//
+// 返回值：
+//   - error：如果不合法，返回相应错误信息；否则返回 nil
+func validateListInfo(name, description string) error {
+	if name == "" {
+		return errors.New("list name is required")
+	}
+	if utf8.RuneCountInString(name) > consts.USER_LIST_NAME_MAX_LENGTH {
+		return errors.New("list name is too long")
+	}
+	if utf8.RuneCountInString(description) > consts.USER_LIST_DESCRIPTION_MAX_LENGTH {
+		return errors.New("list description is too long")
+	}
+	return nil
+}
+
+// getList 获取列表信息
+//
+// 参数：
+//   - listID：列表ID
+//
+// 返回值：
+//   - models.UserListInfo：列表信息
+//   - error：如果列表不存在或发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) getList(listID uint64) (models.UserListInfo, error) {
+	listInfo, err := service.userListStore.GetList(listID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.UserListInfo{}, errors.New("list does not exist")
+	}
+	return listInfo, err
+}
+
+// getOwnedList 获取用户自己创建的列表信息
+//
+// 参数：
+//   - uid：用户ID
+//   - listID：列表ID
+//
+// 返回值：
+//   - models.UserListInfo：列表信息
+//   - error：如果列表不存在、不属于该用户或发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) getOwnedList(uid, listID uint64) (models.UserListInfo, error) {
+	listInfo, err := service.getList(listID)
+	if err != nil {
+		return models.UserListInfo{}, err
+	}
+	if listInfo.OwnerUID != uid {
+		return models.UserListInfo{}, errors.New("permission denied")
+	}
+	return listInfo, nil
+}
+
+// getAccessibleList 获取查看者有权查看的列表信息，私密列表仅创建者可见
+//
+// 参数：
+//   - listID：列表ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - models.UserListInfo：列表信息
+//   - error：如果列表不存在、无权查看或发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) getAccessibleList(listID, viewerUID uint64) (models.UserListInfo, error) {
+	listInfo, err := service.getList(listID)
+	if err != nil {
+		return models.UserListInfo{}, err
+	}
+	if listInfo.IsPrivate && listInfo.OwnerUID != viewerUID {
+		// 对无权查看者隐藏私密列表的存在
+		return models.UserListInfo{}, errors.New("list does not exist")
+	}
+	return listInfo, nil
+}
+
+// CreateList 创建用户列表
+//
+// 参数：
+//   - uid：用户ID
+//   - reqBody：创建列表请求体
+//
+// 返回值：
+//   - uint64：新列表ID
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) CreateList(uid uint64, reqBody types.UserListCreateBody) (uint64, error) {
+	name := strings.TrimSpace(reqBody.Name)
+	description := strings.TrimSpace(reqBody.Description)
+	if err := validateListInfo(name, description); err != nil {
+		return 0, err
+	}
+
+	count, err := service.userListStore.CountListsByOwner(uid)
+	if err != nil {
+		return 0, err
+	}
+	if count >= consts.USER_LIST_MAX_COUNT_PER_USER {
+		return 0, errors.New("too many lists")
+	}
+
+	listInfo, err := service.userListStore.CreateList(uid, name, description, reqBody.IsPrivate)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(listInfo.ID), nil
+}
+
+// UpdateList 更新用户列表
+//
+// 参数：
+//   - uid：用户ID
+//   - reqBody：更新列表请求体
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) UpdateList(uid uint64, reqBody types.UserListUpdateBody) error {
+	listInfo, err := service.getOwnedList(uid, reqBody.ListID)
+	if err != nil {
+		return err
+	}
+
+	name, description := listInfo.Name, listInfo.Description
+	if reqBody.Name != nil {
+		name = strings.TrimSpace(*reqBody.Name)
+	}
+	if reqBody.Description != nil {
+		description = strings.TrimSpace(*reqBody.Description)
+	}
+	if err := validateListInfo(name, description); err != nil {
+		return err
+	}
+
+	isPrivate := listInfo.IsPrivate
+	if reqBody.IsPrivate != nil {
+		isPrivate = *reqBody.IsPrivate
+	}
+
+	return service.userListStore.UpdateList(reqBody.ListID, name, description, isPrivate)
+}
+
+// DeleteList 删除用户列表
+//
+// 参数：
+//   - uid：用户ID
+//   - listID：列表ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) DeleteList(uid, listID uint64) error {
+	if _, err := service.getOwnedList(uid, listID); err != nil {
+		return err
+	}
+	return service.userListStore.DeleteList(listID)
+}
+
+// GetListDetail 获取用户列表详情
+//
+// 参数：
+//   - listID：列表ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - types.UserListDetail：列表详情
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) GetListDetail(listID, viewerUID uint64) (types.UserListDetail, error) {
+	listInfo, err := service.getAccessibleList(listID, viewerUID)
+	if err != nil {
+		return types.UserListDetail{}, err
+	}
+
+	memberCount, err := service.userListStore.CountMembers(listID)
+	if err != nil {
+		return types.UserListDetail{}, err
+	}
+	subscriberCount, err := service.userListStore.CountSubscribers(listID)
+	if err != nil {
+		return types.UserListDetail{}, err
+	}
+	var isSubscribed bool
+	if viewerUID != 0 {
+		isSubscribed, err = service.userListStore.IsSubscribed(listID, viewerUID)
+		if err != nil {
+			return types.UserListDetail{}, err
+		}
+	}
+
+	return types.UserListDetail{
+		List:            listInfo,
+		MemberCount:     memberCount,
+		SubscriberCount: subscriberCount,
+		IsSubscribed:    isSubscribed,
+	}, nil
+}
+
+// GetUserLists 获取用户创建的列表，私密列表仅创建者本人可见
+//
+// 参数：
+//   - ownerUID：创建者ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - []models.UserListInfo：列表信息
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) GetUserLists(ownerUID, viewerUID uint64) ([]models.UserListInfo, error) {
+	return service.userListStore.GetListsByOwner(ownerUID, ownerUID == viewerUID)
+}
+
+// GetSubscribedLists 获取用户订阅的列表，已转为私密的列表不会返回
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []models.UserListInfo：列表信息，按订阅时间倒序排列
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) GetSubscribedLists(uid uint64) ([]models.UserListInfo, error) {
+	listIDs, err := service.userListStore.GetSubscribedListIDs(uid)
+	if err != nil {
+		return nil, err
+	}
+	listInfos, err := service.userListStore.GetListsByIDs(listIDs)
+	if err != nil {
+		return nil, err
+	}
+	listMap := make(map[uint64]models.UserListInfo, len(listInfos))
+	for _, listInfo := range listInfos {
+		listMap[uint64(listInfo.ID)] = listInfo
+	}
+
+	subscribedLists := make([]models.UserListInfo, 0, len(listInfos))
+	for _, listID := range listIDs {
+		listInfo, ok := listMap[listID]
+		if !ok || (listInfo.IsPrivate && listInfo.OwnerUID != uid) {
+			continue
+		}
+		subscribedLists = append(subscribedLists, listInfo)
+	}
+	return subscribedLists, nil
+}
+
+// AddMember 向列表中添加成员
+//
+// 参数：
+//   - uid：用户ID
+//   - listID：列表ID
+//   - memberID：成员ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) AddMember(uid, listID, memberID uint64) error {
+	if _, err := service.getOwnedList(uid, listID); err != nil {
+		return err
+	}
+
+	_, err := service.userStore.GetUserByUID(memberID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("user does not exist")
+	}
+	if err != nil {
+		return err
+	}
+
+	isBlocked, err := service.blockStore.IsBlockedBetween(uid, memberID)
+	if err != nil {
+		return err
+	}
+	if isBlocked {
+		return errors.New("user is blocked")
+	}
+
+	count, err := service.userListStore.CountMembers(listID)
+	if err != nil {
+		return err
+	}
+	if count >= consts.USER_LIST_MAX_MEMBERS {
+		return errors.New("too many members")
+	}
+
+	return service.userListStore.AddMember(listID, memberID)
+}
+
+// RemoveMember 从列表中移除成员
+//
+// 参数：
+//   - uid：用户ID
+//   - listID：列表ID
+//   - memberID：成员ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) RemoveMember(uid, listID, memberID uint64) error {
+	if _, err := service.getOwnedList(uid, listID); err != nil {
+		return err
+	}
+	return service.userListStore.RemoveMember(listID, memberID)
+}
+
+// GetMemberList 获取列表成员ID
+//
+// 参数：
+//   - listID：列表ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - []uint64：成员ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) GetMemberList(listID, viewerUID uint64) ([]uint64, error) {
+	if _, err := service.getAccessibleList(listID, viewerUID); err != nil {
+		return nil, err
+	}
+	return service.userListStore.GetMemberIDs(listID)
+}
+
+// Subscribe 订阅列表
+//
+// 参数：
+//   - uid：用户ID
+//   - listID：列表ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) Subscribe(uid, listID uint64) error {
+	listInfo, err := service.getAccessibleList(listID, uid)
+	if err != nil {
+		return err
+	}
+	if listInfo.OwnerUID == uid {
+		return errors.New("cannot subscribe to your own list")
+	}
+	return service.userListStore.Subscribe(listID, uid)
+}
+
+// Unsubscribe 取消订阅列表
+//
+// 参数：
+//   - uid：用户ID
+//   - listID：列表ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) Unsubscribe(uid, listID uint64) error {
+	return service.userListStore.Unsubscribe(listID, uid)
+}
+
+// GetTimeline 获取列表时间线，合并列表成员的博文
+//
+// 参数：
+//   - listID：列表ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - length：获取数量
+//   - from：起始博文ID
+//
+// 返回值：
+//   - []int64：博文ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) GetTimeline(listID, viewerUID uint64, length int, from string) ([]int64, error) {
+	if _, err := service.getAccessibleList(listID, viewerUID); err != nil {
+		return nil, err
+	}
+
+	// 成员变动实时生效，每次请求时读取当前成员
+	memberIDs, err := service.userListStore.GetMemberIDs(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 排除查看者屏蔽、拉黑以及无权查看的用户
+	filter, err := getAuthorFilter(service.blockStore, service.followStore, viewerUID)
+	if err != nil {
+		return nil, err
+	}
+
+	postInfos, err := service.postStore.GetPostListByUIDs(memberIDs, from, length, filter)
+	if err != nil {
+		return nil, err
+	}
+	postIDs := make([]int64, len(postInfos))
+	for index, post := range postInfos {
+		postIDs[index] = int64(post.ID)
+	}
+	return postIDs, nil
+}
+
+// RecordImpressions 记录时间线中博文的曝光
+//
+// 参数：
+//   - postIDs：出现在时间线中的博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *UserListService) RecordImpressions(postIDs []int64) error {
+	ids := make([]uint64, len(postIDs))
+	for index, postID := range postIDs {
+		ids[index] = uint64(postID)
+	}
+	return service.analyticsStore.RecordImpressions(ids)
+}
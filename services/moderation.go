@@ -0,0 +1,598 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for report and moderation related services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+var (
+	// ErrPermissionDenied 权限不足
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrDuplicateReport 已举报过该内容
+	ErrDuplicateReport = errors.New("you have already reported this target")
+)
+
+// AccountSuspendedError 账号已被暂停或封禁
+type AccountSuspendedError struct {
+	Suspension models.UserSuspension // 生效中的处罚记录
+}
+
+// Error 返回包含处罚原因和到期时间的错误信息
+func (err *AccountSuspendedError) Error() string {
+	var sb strings.Builder
+	if err.Suspension.ExpiresAt == nil {
+		sb.WriteString("account has been banned permanently")
+	} else {
+		sb.WriteString("account is suspended until ")
+		sb.WriteString(err.Suspension.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if err.Suspension.Reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(err.Suspension.Reason)
+	}
+	return sb.String()
+}
+
+// reportReasonSeverity 举报原因对应的严重程度，数值越大越优先处理
+var reportReasonSeverity = map[string]int{
+	consts.REPORT_REASON_OTHER:          1,
+	consts.REPORT_REASON_SPAM:           1,
+	consts.REPORT_REASON_MISINFORMATION: 2,
+	consts.REPORT_REASON_HARASSMENT:     3,
+	consts.REPORT_REASON_HATE:           4,
+	consts.REPORT_REASON_SEXUAL:         4,
+	consts.REPORT_REASON_VIOLENCE:       4,
+	consts.REPORT_REASON_ILLEGAL:        5,
+}
+
+// moderationActionAuthority 审核操作所需的最低权限等级
+var moderationActionAuthority = map[string]uint64{
+	consts.MODERATION_ACTION_DISMISS:   consts.AUTHORITY_MODERATOR,
+	consts.MODERATION_ACTION_HIDE:      consts.AUTHORITY_MODERATOR,
+	consts.MODERATION_ACTION_RESTORE:   consts.AUTHORITY_MODERATOR,
+	consts.MODERATION_ACTION_DELETE:    consts.AUTHORITY_MODERATOR,
+	consts.MODERATION_ACTION_WARN:      consts.AUTHORITY_MODERATOR,
+	consts.MODERATION_ACTION_SUSPEND:   consts.AUTHORITY_ADMIN,
+	consts.MODERATION_ACTION_BAN:       consts.AUTHORITY_ADMIN,
+	consts.MODERATION_ACTION_SHADOWBAN: consts.AUTHORITY_ADMIN,
+	consts.MODERATION_ACTION_LIFT:      consts.AUTHORITY_ADMIN,
+}
+
+// ModerationService 举报与审核服务
+type ModerationService struct {
+	moderationStore   *stores.ModerationStore
+	userStore         *stores.UserStore
+	commentStore      *stores.CommentStore
+	replyStore        *stores.ReplyStore
+	counterStore      *stores.CounterStore
+	trendingStore     *stores.TrendingStore
+	postStore         *stores.PostStore
+	autocompleteStore *stores.AutocompleteStore
+	logger            *logrus.Logger
+}
+
+// NewModerationService 返回一个新的举报与审核服务实例。
+//
+// 返回：
+//   - *ModerationService: 返回一个指向新的举报与审核服务实例的指针。
+func (factory *Factory) NewModerationService() *ModerationService {
+	return &ModerationService{
+		moderationStore:   factory.storeFactory.NewModerationStore(),
+		userStore:         factory.storeFactory.NewUserStore(),
+		commentStore:      factory.storeFactory.NewCommentStore(),
+		replyStore:        factory.storeFactory.NewReplyStore(),
+		counterStore:      factory.storeFactory.NewCounterStore(),
+		trendingStore:     factory.storeFactory.NewTrendingStore(),
+		postStore:         factory.storeFactory.NewPostStore(),
+		autocompleteStore: factory.storeFactory.NewAutocompleteStore(),
+		logger:            factory.logger,
+	}
+}
+
+// isContentTarget 判断目标类型是否为内容
+func isContentTarget(targetType string) bool {
+	switch targetType {
+	case consts.REPORT_TARGET_POST, consts.REPORT_TARGET_COMMENT, consts.REPORT_TARGET_REPLY:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkAuthority 校验用户的权限等级
+//
+// 参数：
+//   - uid：用户ID
+//   - minimum：所需的最低权限等级
+//
+// 返回值：
+//   - *models.UserInfo：用户信息
+//   - error：权限不足时返回 ErrPermissionDenied
+func (service *ModerationService) checkAuthority(uid uint64, minimum uint64) (*models.UserInfo, error) {
+	user, err := service.userStore.GetUserByUID(uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPermissionDenied
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Authority < minimum {
+		return nil, ErrPermissionDenied
+	}
+	return user, nil
+}
+
+// ReportTarget 举报博文、评论、回复或用户
+//
+// 参数：
+//   - reporterUID：举报人ID
+//   - body：举报请求体
+//
+// 返回值：
+//   - error：举报人已举报过该目标的待处理工单时返回 ErrDuplicateReport
+func (service *ModerationService) ReportTarget(reporterUID uint64, body types.ReportCreateBody) error {
+	if !isContentTarget(body.TargetType) && body.TargetType != consts.REPORT_TARGET_USER {
+		return errors.New("invalid target type")
+	}
+	severity, ok := reportReasonSeverity[body.Reason]
+	if !ok {
+		return errors.New("invalid report reason")
+	}
+	detail := strings.TrimSpace(body.Detail)
+	if utf8.RuneCountInString(detail) > consts.REPORT_DETAIL_MAX_LENGTH {
+		return errors.New("report detail is too long")
+	}
+
+	targetUID, err := service.moderationStore.GetTargetUID(body.TargetType, body.TargetID, false)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("report target does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	if targetUID == reporterUID {
+		return errors.New("cannot report yourself")
+	}
+
+	created, err := service.moderationStore.CreateReport(&models.ContentReport{
+		ReporterUID: reporterUID,
+		Reason:      body.Reason,
+		Detail:      detail,
+	}, body.TargetType, body.TargetID, targetUID, severity)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrDuplicateReport
+	}
+	return nil
+}
+
+// GetModerationQueue 获取审核队列，仅版主可操作
+//
+// 参数：
+//   - uid：操作人ID
+//   - status：工单状态，为空时获取待处理工单
+//   - offset：偏移量
+//   - length：每页数量
+//
+// 返回值：
+//   - []models.ModerationCase：审核工单列表
+//   - bool：是否还有更多
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ModerationService) GetModerationQueue(uid uint64, status string, offset, length int) ([]models.ModerationCase, bool, error) {
+	if _, err := service.checkAuthority(uid, consts.AUTHORITY_MODERATOR); err != nil {
+		return nil, false, err
+	}
+	switch status {
+	case "":
+		status = consts.MODERATION_CASE_PENDING
+	case consts.MODERATION_CASE_PENDING, consts.MODERATION_CASE_RESOLVED, consts.MODERATION_CASE_DISMISSED:
+	default:
+		return nil, false, errors.New("invalid case status")
+	}
+
+	cases, err := service.moderationStore.GetModerationQueue(status, offset, length+1)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(cases) > length
+	return cases[:min(len(cases), length)], hasMore, nil
+}
+
+// GetCaseReports 获取审核工单下的举报，仅版主可操作
+//
+// 参数：
+//   - uid：操作人ID
+//   - caseID：工单ID
+//   - offset：偏移量
+//   - length：每页数量
+//
+// 返回值：
+//   - []models.ContentReport：举报列表
+//   - bool：是否还有更多
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ModerationService) GetCaseReports(uid, caseID uint64, offset, length int) ([]models.ContentReport, bool, error) {
+	if _, err := service.checkAuthority(uid, consts.AUTHORITY_MODERATOR); err != nil {
+		return nil, false, err
+	}
+	reports, err := service.moderationStore.GetCaseReports(caseID, offset, length+1)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(reports) > length
+	return reports[:min(len(reports), length)], hasMore, nil
+}
+
+// resolveActionTarget 确定审核操作的目标，指定工单时以工单的目标为准
+//
+// 参数：
+//   - body：审核操作请求体
+//
+// 返回值：
+//   - *models.ModerationLog：填写了目标信息的审核日志
+//   - error：如果目标不合法，返回相应错误信息；否则返回 nil
+func (service *ModerationService) resolveActionTarget(body types.ModerationActionBody) (*models.ModerationLog, error) {
+	entry := &models.ModerationLog{
+		Action:     body.Action,
+		TargetType: body.TargetType,
+		TargetID:   body.TargetID,
+	}
+	if body.CaseID != 0 {
+		moderationCase, err := service.moderationStore.GetModerationCase(body.CaseID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("case does not exist")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if moderationCase.Status != consts.MODERATION_CASE_PENDING {
+			return nil, errors.New("case has already been closed")
+		}
+		entry.CaseID = &moderationCase.ID
+		entry.TargetType = moderationCase.TargetType
+		entry.TargetID = moderationCase.TargetID
+	} else if body.Action == consts.MODERATION_ACTION_DISMISS {
+		return nil, errors.New("case_id is required")
+	}
+
+	if !isContentTarget(entry.TargetType) && entry.TargetType != consts.REPORT_TARGET_USER {
+		return nil, errors.New("invalid target type")
+	}
+	targetUID, err := service.moderationStore.GetTargetUID(entry.TargetType, entry.TargetID, true)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("target does not exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry.TargetUID = targetUID
+	return entry, nil
+}
+
+// TakeAction 执行审核操作，所有操作均写入审核日志
+//
+// 参数：
+//   - uid：操作人ID
+//   - body：审核操作请求体
+//
+// 返回值：
+//   - error：权限不足时返回 ErrPermissionDenied
+func (service *ModerationService) TakeAction(uid uint64, body types.ModerationActionBody) error {
+	minimum, ok := moderationActionAuthority[body.Action]
+	if !ok {
+		return errors.New("invalid moderation action")
+	}
+	moderator, err := service.checkAuthority(uid, minimum)
+	if err != nil {
+		return err
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if utf8.RuneCountInString(reason) > consts.MODERATION_REASON_MAX_LENGTH {
+		return errors.New("reason is too long")
+	}
+
+	entry, err := service.resolveActionTarget(body)
+	if err != nil {
+		return err
+	}
+	entry.ModeratorUID = uid
+	entry.Reason = reason
+	entry.CreatedAt = time.Now()
+
+	// 不能处理权限等级不低于自己的用户及其内容
+	if entry.TargetUID != uid {
+		target, err := service.userStore.GetUserByUID(entry.TargetUID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && target.Authority >= moderator.Authority {
+			return ErrPermissionDenied
+		}
+	}
+
+	switch body.Action {
+	case consts.MODERATION_ACTION_DISMISS:
+		// 因敏感词暂扣的内容在驳回后一并恢复
+		if isContentTarget(entry.TargetType) {
+			hidden, err := service.moderationStore.IsContentHidden(entry.TargetType, entry.TargetID)
+			if err != nil {
+				return err
+			}
+			if hidden {
+				return service.restoreContent(entry.TargetType, entry.TargetID, entry)
+			}
+		}
+		return service.moderationStore.RecordAction(entry)
+	case consts.MODERATION_ACTION_HIDE:
+		return service.hideContent(entry)
+	case consts.MODERATION_ACTION_RESTORE:
+		if !isContentTarget(entry.TargetType) {
+			return errors.New("only posts, comments and replies can be restored")
+		}
+		hidden, err := service.moderationStore.IsContentHidden(entry.TargetType, entry.TargetID)
+		if err != nil {
+			return err
+		}
+		if !hidden {
+			return errors.New("content is not hidden")
+		}
+		return service.restoreContent(entry.TargetType, entry.TargetID, entry)
+	case consts.MODERATION_ACTION_DELETE:
+		return service.deleteContent(entry)
+	case consts.MODERATION_ACTION_WARN:
+		return service.moderationStore.WarnUser(entry)
+	case consts.MODERATION_ACTION_LIFT:
+		lifted, err := service.moderationStore.LiftSanctions(entry)
+		if err != nil {
+			return err
+		}
+		if lifted == 0 {
+			return errors.New("user has no active sanction")
+		}
+		return nil
+	default:
+		return service.sanctionUser(entry, body.Duration)
+	}
+}
+
+// sanctionUser 暂停、封禁或限流用户，暂停和封禁立即使用户的全部令牌失效
+//
+// 参数：
+//   - entry：审核日志
+//   - duration：处罚时长（小时），为 0 时永久有效
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ModerationService) sanctionUser(entry *models.ModerationLog, duration uint64) error {
+	switch {
+	case duration > consts.MODERATION_SUSPEND_MAX_HOURS:
+		return errors.New("duration is invalid")
+	case entry.Action == consts.MODERATION_ACTION_SUSPEND && duration == 0:
+		return errors.New("duration is required for suspension")
+	case entry.Action == consts.MODERATION_ACTION_BAN && duration != 0:
+		return errors.New("ban is permanent, use suspend for a limited duration")
+	}
+	if duration > 0 {
+		expiresAt := entry.CreatedAt.Add(time.Duration(duration) * time.Hour)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	if err := service.moderationStore.SanctionUser(entry); err != nil {
+		return err
+	}
+	if entry.Action == consts.MODERATION_ACTION_SHADOWBAN {
+		return nil
+	}
+	return service.userStore.RevokeUserTokens(entry.TargetUID)
+}
+
+// hideContent 隐藏内容，隐藏的博文移出热门榜单和自动补全，隐藏的评论不再计入博文评论数
+func (service *ModerationService) hideContent(entry *models.ModerationLog) error {
+	if !isContentTarget(entry.TargetType) {
+		return errors.New("only posts, comments and replies can be hidden")
+	}
+	hidden, err := service.moderationStore.IsContentHidden(entry.TargetType, entry.TargetID)
+	if err != nil {
+		return err
+	}
+	if hidden {
+		return errors.New("content has already been hidden")
+	}
+
+	// 内容隐藏后无法直接查询，需事先获取博文的话题和评论所属的博文
+	var (
+		post    models.PostInfo
+		comment models.CommentInfo
+	)
+	switch entry.TargetType {
+	case consts.REPORT_TARGET_POST:
+		if post, err = service.postStore.GetPost(entry.TargetID); err != nil {
+			return err
+		}
+	case consts.REPORT_TARGET_COMMENT:
+		if comment, err = service.commentStore.GetComment(entry.TargetID); err != nil {
+			return err
+		}
+	}
+
+	if err := service.moderationStore.HideContent(entry); err != nil {
+		return err
+	}
+	switch entry.TargetType {
+	case consts.REPORT_TARGET_POST:
+		if err := service.autocompleteStore.RemovePostTitle(entry.TargetID); err != nil {
+			return err
+		}
+		if err := service.autocompleteStore.IncrHashtags(parsers.ParseHashtags(post.Title+" "+post.Content), -1); err != nil {
+			return err
+		}
+		return service.trendingStore.RemoveHotPost(entry.TargetID)
+	case consts.REPORT_TARGET_COMMENT:
+		return recordEngagement(service.counterStore, service.trendingStore, comment.UID, comment.PostID, consts.POST_COUNTER_COMMENTS, -1)
+	default:
+		return nil
+	}
+}
+
+// restoreContent 恢复已隐藏的内容，恢复的博文重新写入自动补全
+//
+// 参数：
+//   - targetType：内容类型
+//   - targetID：内容ID
+//   - entry：审核日志，为 nil 时不记录日志
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ModerationService) restoreContent(targetType string, targetID uint64, entry *models.ModerationLog) error {
+	if err := service.moderationStore.RestoreContent(targetType, targetID, entry); err != nil {
+		return err
+	}
+	switch targetType {
+	case consts.REPORT_TARGET_POST:
+		post, err := service.postStore.GetPost(targetID)
+		if err != nil {
+			return err
+		}
+		if err := service.autocompleteStore.IncrHashtags(parsers.ParseHashtags(post.Title+" "+post.Content), 1); err != nil {
+			return err
+		}
+		if !post.IsPublic {
+			return nil
+		}
+		return service.autocompleteStore.IndexPostTitle(targetID, post.UID, post.Title, post.CreatedAt)
+	case consts.REPORT_TARGET_COMMENT:
+		comment, err := service.commentStore.GetComment(targetID)
+		if err != nil {
+			return err
+		}
+		return recordEngagement(service.counterStore, service.trendingStore, comment.UID, comment.PostID, consts.POST_COUNTER_COMMENTS, 1)
+	default:
+		return nil
+	}
+}
+
+// deleteContent 删除内容，删除和审核日志在同一事务中写入，提交后再清理数据库之外的关联数据
+func (service *ModerationService) deleteContent(entry *models.ModerationLog) error {
+	if !isContentTarget(entry.TargetType) {
+		return errors.New("only posts, comments and replies can be deleted")
+	}
+
+	content, err := service.moderationStore.DeleteContent(entry)
+	if err != nil {
+		return err
+	}
+
+	// 内容和审核日志已提交，清理失败时仅记录日志，避免重试时因内容不存在而失败
+	if err := service.clearDeletedContent(content); err != nil {
+		service.logger.Warnln("清理已删除内容的关联数据失败:", err.Error())
+	}
+	return nil
+}
+
+// clearDeletedContent 清理已删除内容在数据库之外的关联数据，
+// 已隐藏的内容在隐藏时已移出热门榜单、自动补全和博文评论数，此处只清理其余关联数据
+//
+// 参数：
+//   - content：删除前的内容
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ModerationService) clearDeletedContent(content interface{}) error {
+	switch content := content.(type) {
+	case *models.PostInfo:
+		postID := uint64(content.ID)
+		if !content.DeletedAt.Valid {
+			if err := service.autocompleteStore.RemovePostTitle(postID); err != nil {
+				return err
+			}
+			if err := service.autocompleteStore.IncrHashtags(parsers.ParseHashtags(content.Title+" "+content.Content), -1); err != nil {
+				return err
+			}
+			if err := service.trendingStore.RemoveHotPost(postID); err != nil {
+				return err
+			}
+		}
+		return service.counterStore.DeletePostCounter(postID)
+	case *models.CommentInfo:
+		commentID := uint64(content.ID)
+		if !content.DeletedAt.Valid {
+			if err := recordEngagement(service.counterStore, service.trendingStore, content.UID, content.PostID, consts.POST_COUNTER_COMMENTS, -1); err != nil {
+				return err
+			}
+		}
+		if err := service.postStore.UnpinComment(content.PostID, commentID); err != nil {
+			return err
+		}
+		return service.commentStore.ClearCommentRecords(content.PostID, commentID)
+	case *models.ReplyInfo:
+		return service.replyStore.ClearReplyRecords(content.CommentID, uint64(content.ID))
+	default:
+		return nil
+	}
+}
+
+// GetModerationLogs 获取审核日志，仅版主可操作
+//
+// 参数：
+//   - uid：操作人ID
+//   - query：筛选条件
+//
+// 返回值：
+//   - []models.ModerationLog：审核日志
+//   - bool：是否还有更多
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ModerationService) GetModerationLogs(uid uint64, query types.ModerationLogQuery) ([]models.ModerationLog, bool, error) {
+	if _, err := service.checkAuthority(uid, consts.AUTHORITY_MODERATOR); err != nil {
+		return nil, false, err
+	}
+	if query.TargetType != "" && !isContentTarget(query.TargetType) && query.TargetType != consts.REPORT_TARGET_USER {
+		return nil, false, errors.New("invalid target type")
+	}
+	length := query.Length
+	query.Length++
+	logs, err := service.moderationStore.GetModerationLogs(query)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(logs) > length
+	return logs[:min(len(logs), length)], hasMore, nil
+}
+
+// GetUserWarnings 获取用户自己收到的警告
+//
+// 参数：
+//   - uid：用户ID
+//   - offset：偏移量
+//   - length：每页数量
+//
+// 返回值：
+//   - []models.UserWarning：警告列表
+//   - bool：是否还有更多
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ModerationService) GetUserWarnings(uid uint64, offset, length int) ([]models.UserWarning, bool, error) {
+	warnings, err := service.moderationStore.GetUserWarnings(uid, offset, length+1)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(warnings) > length
+	return warnings[:min(len(warnings), length)], hasMore, nil
+}
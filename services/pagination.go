@@ -0,0 +1,96 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for sorted cursor pagination shared by comments and replies.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+	"math"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/generators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+// sortedPageSource 排序分页的数据来源
+type sortedPageSource struct {
+	// listByID 按ID分页获取对象ID，查询时已排除不可见的用户
+	listByID func(fromID uint64, ascending bool, length int) ([]uint64, error)
+	// listByScore 按缓存得分分页获取对象ID
+	listByScore func(mode string, fromScore float64, fromID uint64, length int) ([]uint64, []float64, error)
+	// filter 过滤已删除和不可见用户的对象ID
+	filter func(ids []uint64) ([]uint64, error)
+}
+
+// getSortedPage 按排序方式分页获取对象ID
+//
+// 参数：
+//   - source：数据来源
+//   - sortMode：排序方式，new、old、top 或 hot
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []uint64：对象ID列表，按分数排序时过滤后的数量可能少于 length
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func getSortedPage(source sortedPageSource, sortMode, cursor string, length int) ([]uint64, string, error) {
+	// 解析游标，按分数排序时排序键为得分的二进制表示
+	var (
+		fromKey int64
+		fromID  uint64
+		err     error
+	)
+	if cursor != "" {
+		fromKey, fromID, err = parsers.ParseCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// 多获取一条记录用于判断是否有下一页
+	var (
+		ids    []uint64
+		scores []float64
+	)
+	switch sortMode {
+	case consts.COMMENT_SORT_NEW, consts.COMMENT_SORT_OLD:
+		ids, err = source.listByID(fromID, sortMode == consts.COMMENT_SORT_OLD, length+1)
+	case consts.COMMENT_SORT_TOP, consts.COMMENT_SORT_HOT:
+		ids, scores, err = source.listByScore(sortMode, math.Float64frombits(uint64(fromKey)), fromID, length+1)
+	default:
+		return nil, "", errors.New("invalid sort mode")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	hasMore := len(ids) > length
+	if hasMore {
+		ids = ids[:length]
+	}
+
+	// 生成下一页游标
+	var nextCursor string
+	if hasMore {
+		lastIndex := len(ids) - 1
+		var lastKey int64
+		if scores != nil {
+			lastKey = int64(math.Float64bits(scores[lastIndex]))
+		}
+		nextCursor = generators.GenerateCursor(lastKey, ids[lastIndex])
+	}
+
+	// 缓存中的对象可能已被删除或属于不可见的用户
+	if scores != nil {
+		ids, err = source.filter(ids)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return ids, nextCursor, nil
+}
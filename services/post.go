@@ -10,109 +10,249 @@ Copyright (c) [2024], Author(s):
 package services
 
 import (
-	"context"
 	"errors"
 	"mime/multipart"
 	"strconv"
+	"time"
 
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
-	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
 	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
 	"github.com/Kirisakiii/neko-micro-blog-backend/types"
 	"github.com/Kirisakiii/neko-micro-blog-backend/utils/converters"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/generators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
 	"github.com/Kirisakiii/neko-micro-blog-backend/utils/validers"
-	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // PostService 博文服务
 type PostService struct {
-	postStore           *stores.PostStore
-	searchServiceClient search.SearchEngineClient
+	postStore          *stores.PostStore
+	counterStore       *stores.CounterStore
+	analyticsStore     *stores.AnalyticsStore
+	trendingStore      *stores.TrendingStore
+	favouriteStore     *stores.FavouriteStore
+	userStore          *stores.UserStore
+	blockStore         *stores.BlockStore
+	followStore        *stores.FollowStore
+	autocompleteStore  *stores.AutocompleteStore
+	sensitiveWordStore *stores.SensitiveWordStore
+	logger             *logrus.Logger
 }
 
 // PostService 返回一个新的 PostService 实例
 //
 // 返回值：
 //   - *PostService：新的 PostService 实力。
-func (factory *Factory) NewPostService(searchServiceClient search.SearchEngineClient) *PostService {
+func (factory *Factory) NewPostService() *PostService {
 	return &PostService{
-		postStore:           factory.storeFactory.NewPostStore(),
-		searchServiceClient: searchServiceClient,
+		postStore:          factory.storeFactory.NewPostStore(),
+		counterStore:       factory.storeFactory.NewCounterStore(),
+		analyticsStore:     factory.storeFactory.NewAnalyticsStore(),
+		trendingStore:      factory.storeFactory.NewTrendingStore(),
+		favouriteStore:     factory.storeFactory.NewFavouriteStore(),
+		userStore:          factory.storeFactory.NewUserStore(),
+		blockStore:         factory.storeFactory.NewBlockStore(),
+		followStore:        factory.storeFactory.NewFollowStore(),
+		autocompleteStore:  factory.storeFactory.NewAutocompleteStore(),
+		sensitiveWordStore: factory.storeFactory.NewSensitiveWordStore(),
+		logger:             factory.logger,
 	}
 }
 
-// GetPostList 获取适用于用户查看的帖子信息列表。
+// GetPostList 按游标分页获取适用于用户查看的帖子ID列表。
+//
+// 参数：
+// - reqType：列表类型，all、user 或 liked
+// - uid：用户ID，reqType 为 all 时忽略
+// - viewerUID：查看者ID，为 0 时表示匿名用户，列表中将排除其无权查看的私密账号和被限流的其他用户，全站和点赞列表还将排除其屏蔽和拉黑的用户，与作者之间存在拉黑关系时无法查看用户列表
+// - cursor：分页游标，为空时获取第一页
+// - length：获取数量
+//
 // 返回值：
-// - []models.UserPostInfo: 包含适用于用户查看的帖子信息的切片。
+// - []int64: 帖子ID列表，过滤后的数量可能少于 length。
+// - string: 下一页游标，没有更多数据时为空。
 // - error: 在获取帖子信息过程中遇到的任何错误，如果有的话。
-func (service *PostService) GetPostList(reqType, uid, length, from string, userStore *stores.UserStore) ([]int64, error) {
+func (service *PostService) GetPostList(reqType string, uid, viewerUID uint64, cursor string, length int) ([]int64, string, error) {
+	// 解析游标，点赞列表的排序键为点赞时间，其余列表仅按博文ID排序
 	var (
-		postInfos  []models.PostInfo
-		userRecord pq.Int64Array
-		err        error
-		uidInt64   int64
+		fromKey int64
+		fromID  uint64
+		err     error
 	)
-
-	if reqType != "all" {
-		uidInt64, err = strconv.ParseInt(uid, 10, 64)
+	if cursor != "" {
+		fromKey, fromID, err = parsers.ParseCursor(cursor)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	}
 
-	queryLenth := 10
-	if length != "" {
-		queryLenth, err = strconv.Atoi(length)
+	// 多获取一条记录用于判断是否有下一页
+	var postInfos []models.PostInfo
+	switch reqType {
+	case "all":
+		var filter stores.AuthorFilter
+		filter, err = getAuthorFilter(service.blockStore, service.followStore, viewerUID)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+		postInfos, err = service.postStore.GetPostList(fromID, length+1, filter)
+	case "user":
+		// 查看者与作者之间存在拉黑关系时不可查看
+		if viewerUID != 0 && viewerUID != uid {
+			var isBlocked bool
+			isBlocked, err = service.blockStore.IsBlockedBetween(viewerUID, uid)
+			if err != nil {
+				return nil, "", err
+			}
+			if isBlocked {
+				return nil, "", errors.New("user is blocked")
+			}
 		}
-		if queryLenth > 10 {
-			queryLenth = 10
+		var isAccessible bool
+		isAccessible, err = service.followStore.IsAccessible(viewerUID, uid)
+		if err != nil {
+			return nil, "", err
 		}
+		if !isAccessible {
+			return nil, "", errors.New("account is private")
+		}
+		// 被限流用户的博文仅自己可见
+		if viewerUID != uid {
+			var isShadowbanned bool
+			isShadowbanned, err = service.blockStore.IsShadowbanned(uid)
+			if err != nil {
+				return nil, "", err
+			}
+			if isShadowbanned {
+				return []int64{}, "", nil
+			}
+		}
+		postInfos, err = service.postStore.GetPostListByUID(uid, fromID, length+1)
+	case "liked":
+		return service.getLikedPostList(uid, viewerUID, time.UnixMilli(fromKey), fromID, length)
+	default:
+		return nil, "", errors.New("invalid type")
+	}
+	if err != nil {
+		return nil, "", err
 	}
 
-	switch reqType {
-	case "all":
-		postInfos, err = service.postStore.GetPostList(from, queryLenth)
-	case "user":
-		postInfos, err = service.postStore.GetPostListByUID(uid)
-	case "liked":
-		userRecord, err = userStore.GetUserLikedRecord(uidInt64)
-	case "favourited":
-		userRecord, err = userStore.GetUserFavoriteRecord(uidInt64)
+	hasMore := len(postInfos) > length
+	if hasMore {
+		postInfos = postInfos[:length]
+	}
+	postIDs := make([]int64, len(postInfos))
+	for index, post := range postInfos {
+		postIDs[index] = int64(post.ID)
+	}
+	var nextCursor string
+	if hasMore {
+		nextCursor = generators.GenerateCursor(0, uint64(postIDs[len(postIDs)-1]))
 	}
+	return postIDs, nextCursor, nil
+}
+
+// getLikedPostList 按点赞时间倒序分页获取用户点赞的帖子ID列表。
+//
+// 参数：
+// - uid：用户ID
+// - viewerUID：查看者ID，为 0 时表示匿名用户
+// - fromTime：上一页最后一条记录的点赞时间
+// - fromID：上一页最后一条记录的博文ID，为 0 时获取第一页
+// - length：获取数量
+//
+// 返回值：
+// - []int64: 帖子ID列表，过滤后的数量可能少于 length。
+// - string: 下一页游标，没有更多数据时为空。
+// - error: 在获取帖子信息过程中遇到的任何错误，如果有的话。
+func (service *PostService) getLikedPostList(uid, viewerUID uint64, fromTime time.Time, fromID uint64, length int) ([]int64, string, error) {
+	records, err := service.postStore.GetLikedPage(uid, fromTime, fromID, length+1)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	hasMore := len(records) > length
+	if hasMore {
+		records = records[:length]
+	}
+	var nextCursor string
+	if hasMore {
+		last := records[len(records)-1]
+		nextCursor = generators.GenerateCursor(last.LikedAt.UnixMilli(), uint64(last.PostID))
 	}
 
-	if reqType == "all" || reqType == "user" {
-		postIDs := make([]int64, len(postInfos))
-		for index, post := range postInfos {
-			postIDs[index] = int64(post.ID)
-		}
-		return postIDs, nil
+	postIDs := make([]int64, len(records))
+	for index, record := range records {
+		postIDs[index] = record.PostID
 	}
 
-	if userRecord == nil {
-		return nil, nil
+	// 排除查看者屏蔽、拉黑的用户、被限流的其他用户和无权查看的私密账号的博文
+	filter, err := getAuthorFilter(service.blockStore, service.followStore, viewerUID)
+	if err != nil {
+		return nil, "", err
 	}
-	postIDs := make([]int64, len(userRecord))
-	for index, id := range userRecord {
-		postIDs[index] = int64(id)
+	postIDs, err = service.postStore.FilterPostIDsByAuthor(postIDs, filter)
+	if err != nil {
+		return nil, "", err
 	}
-	return postIDs, nil
+	return postIDs, nextCursor, nil
 }
 
 // GetPostInfoByUsername 根据用户名获取用户信息。
 //
 // 参数：
-//   - UID：用户ID
+//   - postID：博文ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
 //
 // 返回值：
-//   - *models.postInfo：用户信息模型。
-func (service *PostService) GetPostInfo(postID uint64) (models.PostInfo, int64, int64, error) {
-	return service.postStore.GetPostInfo(postID)
+//   - models.PostInfo：博文信息模型。
+//   - models.PostCounter：博文的互动计数。
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *PostService) GetPostInfo(postID, viewerUID uint64) (models.PostInfo, models.PostCounter, error) {
+	post, err := service.postStore.GetPost(postID)
+	if err != nil {
+		return models.PostInfo{}, models.PostCounter{}, err
+	}
+
+	// 查看者与作者之间存在拉黑关系时不可查看
+	if viewerUID != 0 && viewerUID != post.UID {
+		isBlocked, err := service.blockStore.IsBlockedBetween(viewerUID, post.UID)
+		if err != nil {
+			return models.PostInfo{}, models.PostCounter{}, err
+		}
+		if isBlocked {
+			return models.PostInfo{}, models.PostCounter{}, errors.New("user is blocked")
+		}
+	}
+
+	// 校验查看者是否有权查看私密账号的博文
+	isAccessible, err := service.followStore.IsAccessible(viewerUID, post.UID)
+	if err != nil {
+		return models.PostInfo{}, models.PostCounter{}, err
+	}
+	if !isAccessible {
+		return models.PostInfo{}, models.PostCounter{}, errors.New("post is not accessible")
+	}
+
+	// 被限流用户的博文对其他用户表现为不存在
+	if viewerUID != post.UID {
+		isShadowbanned, err := service.blockStore.IsShadowbanned(post.UID)
+		if err != nil {
+			return models.PostInfo{}, models.PostCounter{}, err
+		}
+		if isShadowbanned {
+			return models.PostInfo{}, models.PostCounter{}, gorm.ErrRecordNotFound
+		}
+	}
+
+	counter, err := service.counterStore.GetPostCounter(postID)
+	if err != nil {
+		return models.PostInfo{}, models.PostCounter{}, err
+	}
+
+	return post, counter, nil
 }
 
 // CreatePost 根据用户提交的帖子信息创建帖子。
@@ -137,22 +277,51 @@ func (service *PostService) CreatePost(uid uint64, ipAddr string, postReqInfo ty
 		}
 	}
 
-	// 调用存储层的方法创建帖子
-	postInfo, err := service.postStore.CreatePost(uid, ipAddr, postReqInfo)
+	// 检查敏感词
+	hold, err := checkSensitive(service.sensitiveWordStore, &postReqInfo.Title, &postReqInfo.Content)
 	if err != nil {
 		return models.PostInfo{}, err
 	}
 
-	// 写入搜索引擎索引库
-	_, err = service.searchServiceClient.CreatePostIndex(context.TODO(), &search.CreatePostIndexRequest{
-		Id:      int64(postInfo.ID),
-		Title:   postReqInfo.Title,
-		Content: postReqInfo.Content,
-	})
+	// 被限流作者的话题不计入热门话题，在写入前检查以免博文提交后才失败
+	isShadowbanned, err := service.blockStore.IsShadowbanned(uid)
 	if err != nil {
 		return models.PostInfo{}, err
 	}
 
+	// 调用存储层的方法创建帖子，命中待审核敏感词的博文在同一事务中暂扣至审核通过
+	postInfo, err := service.postStore.CreatePost(uid, ipAddr, postReqInfo, hold)
+	if err != nil {
+		return models.PostInfo{}, err
+	}
+
+	// 暂扣的博文不进入热门榜单和自动补全
+	if hold != nil {
+		return postInfo, nil
+	}
+
+	// 加入热门榜单并记录话题热度，博文已提交，失败时仅记录日志
+	if err := service.trendingStore.AddHotPost(uint64(postInfo.ID)); err != nil {
+		service.logger.Warnln("加入热门榜单失败:", err.Error())
+	}
+	hashtags := parsers.ParseHashtags(postReqInfo.Title + " " + postReqInfo.Content)
+	if !isShadowbanned {
+		if err := service.trendingStore.RecordHashtags(uid, hashtags); err != nil {
+			service.logger.Warnln("记录话题热度失败:", err.Error())
+		}
+	}
+
+	// 写入自动补全索引，私密账号和被限流作者的博文标题在获取建议时排除
+	// 博文已提交，索引写入失败时仅记录日志，避免客户端重试产生重复博文
+	if err := service.autocompleteStore.IncrHashtags(hashtags, 1); err != nil {
+		service.logger.Warnln("更新话题自动补全索引失败:", err.Error())
+	}
+	if postInfo.IsPublic {
+		if err := service.autocompleteStore.IndexPostTitle(uint64(postInfo.ID), uid, postInfo.Title, postInfo.CreatedAt); err != nil {
+			service.logger.Warnln("写入标题自动补全索引失败:", err.Error())
+		}
+	}
+
 	return postInfo, nil
 }
 
@@ -203,7 +372,13 @@ func (service *PostService) UploadPostImage(postImage *multipart.FileHeader) (st
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
 func (service *PostService) LikePost(uid, postID int64) error {
 	// 调用post存储中的点赞方法
-	return service.postStore.LikePost(uid, postID)
+	changed, err := service.postStore.LikePost(uid, postID)
+	if err != nil || !changed {
+		return err
+	}
+
+	// 仅在记录实际变化时更新计数，避免重复请求造成计数漂移
+	return recordEngagement(service.counterStore, service.trendingStore, uint64(uid), uint64(postID), consts.POST_COUNTER_LIKES, 1)
 }
 
 // CancelLikePost 取消点赞博文
@@ -216,7 +391,12 @@ func (service *PostService) LikePost(uid, postID int64) error {
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
 func (service *PostService) CancelLikePost(uid, postID int64) error {
 	// 调用post存储中的取消点赞方法
-	return service.postStore.CancelLikePost(uid, postID)
+	changed, err := service.postStore.CancelLikePost(uid, postID)
+	if err != nil || !changed {
+		return err
+	}
+
+	return recordEngagement(service.counterStore, service.trendingStore, uint64(uid), uint64(postID), consts.POST_COUNTER_LIKES, -1)
 }
 
 // FavouritePost 收藏博文
@@ -224,12 +404,22 @@ func (service *PostService) CancelLikePost(uid, postID int64) error {
 // 参数：
 //   - uid：用户ID
 //   - postID：待收藏博文的ID
+//   - folderID：收藏夹ID，为 0 时收藏到默认收藏夹
 //
 // 返回值：
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (service *PostService) FavouritePost(uid, postID int64) error {
+func (service *PostService) FavouritePost(uid, postID int64, folderID uint64) error {
+	if err := checkFolderOwner(service.favouriteStore, uint64(uid), folderID); err != nil {
+		return err
+	}
+
 	// 调用post存储中的收藏方法
-	return service.postStore.FavouritePost(uid, postID)
+	changed, err := service.postStore.FavouritePost(uid, postID, folderID)
+	if err != nil || !changed {
+		return err
+	}
+
+	return recordEngagement(service.counterStore, service.trendingStore, uint64(uid), uint64(postID), consts.POST_COUNTER_FAVOURITES, 1)
 }
 
 // CancelFavouritePost 取消收藏博文
@@ -242,7 +432,12 @@ func (service *PostService) FavouritePost(uid, postID int64) error {
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
 func (service *PostService) CancelFavouritePost(uid, postID int64) error {
 	// 调用post存储中的取消收藏方法
-	return service.postStore.CancelFavouritePost(uid, postID)
+	changed, err := service.postStore.CancelFavouritePost(uid, postID)
+	if err != nil || !changed {
+		return err
+	}
+
+	return recordEngagement(service.counterStore, service.trendingStore, uint64(uid), uint64(postID), consts.POST_COUNTER_FAVOURITES, -1)
 }
 
 // GetPostUserStatus 获取用户对帖子的状态
@@ -268,6 +463,138 @@ func (service *PostService) GetPostUserStatus(uid, postID int64) (bool, bool, er
 // 返回值：
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
 func (service *PostService) DeletePost(postID uint64) error {
+	post, err := service.postStore.GetPost(postID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
 	// 调用post存储中的删除post方法
-	return service.postStore.DeletePost(postID)
+	if err := service.postStore.DeletePost(postID); err != nil {
+		return err
+	}
+
+	// 移出自动补全索引，博文已删除，失败时仅记录日志
+	if err := service.autocompleteStore.RemovePostTitle(postID); err != nil {
+		service.logger.Warnln("移除标题自动补全索引失败:", err.Error())
+	}
+	if err := service.autocompleteStore.IncrHashtags(parsers.ParseHashtags(post.Title+" "+post.Content), -1); err != nil {
+		service.logger.Warnln("更新话题自动补全索引失败:", err.Error())
+	}
+
+	// 删除博文的互动计数并移出热门榜单，失败时仅记录日志
+	if err := service.counterStore.DeletePostCounter(postID); err != nil {
+		service.logger.Warnln("删除博文互动计数失败:", err.Error())
+	}
+	if err := service.trendingStore.RemoveHotPost(postID); err != nil {
+		service.logger.Warnln("移出热门榜单失败:", err.Error())
+	}
+	return nil
+}
+
+// UpdateCommentPermission 修改博文的评论权限，仅博文作者可操作
+//
+// 参数：
+//   - uid：操作者ID
+//   - postID：博文ID
+//   - permission：评论权限，everyone、followers、mutuals 或 closed
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *PostService) UpdateCommentPermission(uid, postID uint64, permission string) error {
+	switch permission {
+	case consts.COMMENT_PERMISSION_EVERYONE, consts.COMMENT_PERMISSION_FOLLOWERS, consts.COMMENT_PERMISSION_MUTUALS, consts.COMMENT_PERMISSION_CLOSED:
+	default:
+		return errors.New("invalid comment permission")
+	}
+
+	post, err := service.postStore.GetPost(postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("post does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	if post.UID != uid {
+		return errors.New("permission denied")
+	}
+	return service.postStore.UpdateCommentPermission(postID, permission)
+}
+
+// RecordPostView 记录博文浏览，同一浏览者每天只计入一次浏览数
+//
+// 参数：
+//   - postID：博文ID
+//   - viewerUID：浏览者ID，为 0 时表示匿名用户
+//   - viewerIP：浏览者IP地址，用于区分匿名浏览者
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *PostService) RecordPostView(postID, viewerUID uint64, viewerIP string) error {
+	viewerKey := "ip:" + viewerIP
+	if viewerUID != 0 {
+		viewerKey = "uid:" + strconv.FormatUint(viewerUID, 10)
+	}
+	isNewViewer, err := service.analyticsStore.RecordView(postID, viewerKey)
+	if err != nil || !isNewViewer {
+		return err
+	}
+	if err := recordEngagement(service.counterStore, service.trendingStore, viewerUID, postID, consts.POST_COUNTER_VIEWS, 1); err != nil {
+		return err
+	}
+
+	// 记录登录用户的浏览历史
+	if viewerUID == 0 {
+		return nil
+	}
+	return service.userStore.AddViewedRecord(viewerUID, postID)
+}
+
+// RecordImpressions 记录博文在列表中的曝光
+//
+// 参数：
+//   - postIDs：出现在列表中的博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *PostService) RecordImpressions(postIDs []int64) error {
+	ids := make([]uint64, len(postIDs))
+	for index, postID := range postIDs {
+		ids[index] = uint64(postID)
+	}
+	return service.analyticsStore.RecordImpressions(ids)
+}
+
+// GetPostAnalytics 获取博文的数据分析，仅博文作者可查看
+//
+// 参数：
+//   - uid：请求者ID
+//   - postID：博文ID
+//   - days：查询最近的天数，包含当日
+//
+// 返回值：
+//   - types.PostAnalytics：博文数据分析
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *PostService) GetPostAnalytics(uid, postID uint64, days int) (types.PostAnalytics, error) {
+	post, err := service.postStore.GetPost(postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.PostAnalytics{}, errors.New("post does not exist")
+	}
+	if err != nil {
+		return types.PostAnalytics{}, err
+	}
+	if post.UID != uid {
+		return types.PostAnalytics{}, errors.New("permission denied")
+	}
+
+	counter, err := service.counterStore.GetPostCounter(postID)
+	if err != nil {
+		return types.PostAnalytics{}, err
+	}
+	to := time.Now()
+	from := to.AddDate(0, 0, 1-days)
+	daily, err := service.analyticsStore.GetDailyStats(postID, from, to)
+	if err != nil {
+		return types.PostAnalytics{}, err
+	}
+	return types.PostAnalytics{PostID: postID, Counter: counter, Daily: daily}, nil
 }
@@ -10,13 +10,20 @@ package services
 import (
 	"errors"
 
+	"gorm.io/gorm"
+
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
 	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
 )
 
 // ReplyService 用户服务
 type ReplyService struct {
-	replyStore *stores.ReplyStore
+	replyStore         *stores.ReplyStore
+	commentStore       *stores.CommentStore
+	blockStore         *stores.BlockStore
+	postStore          *stores.PostStore
+	followStore        *stores.FollowStore
+	sensitiveWordStore *stores.SensitiveWordStore
 }
 
 // NewReplayService 返回一个新的评论服务实例。
@@ -25,7 +32,12 @@ type ReplyService struct {
 //   - *ReplyService: 返回一个指向新的评论服务实例的指针。
 func (factory *Factory) NewReplyService() *ReplyService {
 	return &ReplyService{
-		replyStore: factory.storeFactory.NewReplyStore(),
+		replyStore:         factory.storeFactory.NewReplyStore(),
+		commentStore:       factory.storeFactory.NewCommentStore(),
+		blockStore:         factory.storeFactory.NewBlockStore(),
+		postStore:          factory.storeFactory.NewPostStore(),
+		followStore:        factory.storeFactory.NewFollowStore(),
+		sensitiveWordStore: factory.storeFactory.NewSensitiveWordStore(),
 	}
 }
 
@@ -51,6 +63,37 @@ func (service *ReplyService) CreateReply(uid, commentID, parentReplyID uint64, c
 		return errors.New("comment does not exist")
 	}
 
+	// 校验回复者与评论作者之间是否存在拉黑关系
+	comment, err := commentStore.GetComment(commentID)
+	if err != nil {
+		return err
+	}
+	isBlocked, err := service.blockStore.IsBlockedBetween(uid, comment.UID)
+	if err != nil {
+		return err
+	}
+	if isBlocked {
+		return errors.New("user is blocked")
+	}
+
+	// 校验回复者是否有权查看私密账号的博文
+	post, err := service.postStore.GetPost(comment.PostID)
+	if err != nil {
+		return err
+	}
+	isAccessible, err := service.followStore.IsAccessible(uid, post.UID)
+	if err != nil {
+		return err
+	}
+	if !isAccessible {
+		return errors.New("post is not accessible")
+	}
+
+	// 校验回复者是否满足博文的评论权限
+	if err := checkCommentPermission(service.followStore, uid, post); err != nil {
+		return err
+	}
+
 	var parentReplyUIDField *uint64 = nil
 	// 校验回复是否存在
 	if parentReplyID != 0 {
@@ -66,6 +109,15 @@ func (service *ReplyService) CreateReply(uid, commentID, parentReplyID uint64, c
 			return err
 		}
 		parentReplyUIDField = &parentReplyInfo.UID
+
+		// 校验回复者与父回复作者之间是否存在拉黑关系
+		isBlocked, err = service.blockStore.IsBlockedBetween(uid, parentReplyInfo.UID)
+		if err != nil {
+			return err
+		}
+		if isBlocked {
+			return errors.New("user is blocked")
+		}
 	}
 
 	var parentReplyIDField *uint64 = nil
@@ -73,12 +125,15 @@ func (service *ReplyService) CreateReply(uid, commentID, parentReplyID uint64, c
 		parentReplyIDField = &parentReplyID
 	}
 
-	// 调用存储层的方法存储评论
-	err = service.replyStore.CreateReply(uid, commentID, parentReplyIDField, parentReplyUIDField, content)
+	// 检查敏感词
+	hold, err := checkSensitive(service.sensitiveWordStore, &content)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	// 调用存储层的方法存储评论，命中待审核敏感词的回复在同一事务中暂扣至审核通过
+	_, err = service.replyStore.CreateReply(uid, commentID, parentReplyIDField, parentReplyUIDField, content, hold)
+	return err
 }
 
 // DelteeReply 修改回复
@@ -110,8 +165,14 @@ func (service *ReplyService) DeleteReply(uid, replyID uint64) error {
 //
 //	-error 如果评论存在返回修改回复时候的信息
 func (service *ReplyService) UpdateReply(uid, replyID uint64, content string) error {
-	// 调用数据库或其他存储方法更新评论内容
-	err := service.replyStore.UpdateReply(uid, replyID, content)
+	// 检查敏感词
+	hold, err := checkSensitive(service.sensitiveWordStore, &content)
+	if err != nil {
+		return err
+	}
+
+	// 调用数据库或其他存储方法更新评论内容，命中待审核敏感词的回复在同一事务中暂扣至审核通过
+	err = service.replyStore.UpdateReply(uid, replyID, content, hold)
 	if err != nil {
 		return err
 	}
@@ -120,44 +181,199 @@ func (service *ReplyService) UpdateReply(uid, replyID uint64, content string) er
 	return nil
 }
 
-// GetReplyList 获取回复列表
+// GetReplyList 按排序方式分页获取回复列表
 //
 // 参数：
 //   - commentID：评论ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - sortMode：排序方式，new、old、top 或 hot
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
 //
 // 返回值：
-//   - []uint64：回复列表
+//   - []uint64：回复ID列表
+//   - string：下一页游标，没有更多数据时为空
 //   - error：获取失败返回错误
-func (service *ReplyService) GetReplyList(commentID uint64) ([]uint64, error) {
-	// 调用数据库或其他存储方法获取评论列表
-	replyList, err := service.replyStore.GetReplyList(commentID)
+func (service *ReplyService) GetReplyList(commentID, viewerUID uint64, sortMode, cursor string, length int) ([]uint64, string, error) {
+	// 校验查看者是否有权查看私密账号的博文
+	comment, err := service.commentStore.GetComment(commentID)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	replyListUint64 := make([]uint64, len(replyList))
-	for index, reply := range replyList {
-		replyListUint64[index] = uint64(reply.ID)
+	if err := checkPostAccess(service.postStore, service.followStore, comment.PostID, viewerUID); err != nil {
+		return nil, "", err
 	}
 
-	// 如果获取成功，返回评论列表
-	return replyListUint64, nil
+	// 获取对查看者不可见的作者，包括屏蔽、拉黑和被限流的用户
+	hidden, err := service.blockStore.GetHiddenAuthors(viewerUID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return getSortedPage(sortedPageSource{
+		listByID: func(fromID uint64, ascending bool, length int) ([]uint64, error) {
+			replies, err := service.replyStore.GetReplyListByID(commentID, fromID, ascending, length, hidden)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]uint64, len(replies))
+			for index, reply := range replies {
+				ids[index] = uint64(reply.ID)
+			}
+			return ids, nil
+		},
+		listByScore: func(mode string, fromScore float64, fromID uint64, length int) ([]uint64, []float64, error) {
+			return service.replyStore.GetReplyIDsByScore(commentID, mode, fromScore, fromID, length)
+		},
+		filter: func(ids []uint64) ([]uint64, error) {
+			return service.replyStore.FilterReplyIDs(ids, hidden)
+		},
+	}, sortMode, cursor, length)
 }
 
-// GetReplyDetail 获取回复
+// GetReplyDetail 获取回复及其点赞和点踩数，被限流用户的回复对其他用户表现为不存在
 //
 // 参数：
 //   - replyID：回复ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
 //
 // 返回值：
 //   - models.ReplyInfo：回复信息
-//   - error：获取失败返回错误
-func (service *ReplyService) GetReplyDetail(replyID uint64) (models.ReplyInfo, error) {
+//   - int64：点赞数
+//   - int64：点踩数
+//   - error：获取失败返回错误，回复不存在时返回 gorm.ErrRecordNotFound
+func (service *ReplyService) GetReplyDetail(replyID, viewerUID uint64) (models.ReplyInfo, int64, int64, error) {
 	// 调用数据库或其他存储方法获取评论
 	reply, err := service.replyStore.GetReply(replyID)
 	if err != nil {
-		return models.ReplyInfo{}, err
+		return models.ReplyInfo{}, 0, 0, err
+	}
+	shadowbannedUIDs, err := service.blockStore.GetShadowbannedUIDsAmong(viewerUID, []uint64{reply.UID})
+	if err != nil {
+		return models.ReplyInfo{}, 0, 0, err
+	}
+	if len(shadowbannedUIDs) > 0 {
+		return models.ReplyInfo{}, 0, 0, gorm.ErrRecordNotFound
+	}
+
+	// 获取点赞和点踩数
+	likes, dislikes, err := service.replyStore.GetReplyRatingCount(replyID)
+	if err != nil {
+		return models.ReplyInfo{}, 0, 0, err
 	}
 
 	// 如果获取成功，返回评论
-	return reply, nil
-}
\ No newline at end of file
+	return reply, likes, dislikes, nil
+}
+
+// GetReplyUserStatus 获取用户对回复的点赞和点踩状态
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - bool：是否已点赞
+//   - bool：是否已点踩
+//   - error：获取失败返回错误
+func (service *ReplyService) GetReplyUserStatus(uid, replyID uint64) (bool, bool, error) {
+	if err := service.checkReplyExistence(replyID); err != nil {
+		return false, false, err
+	}
+	return service.replyStore.GetReplyUserStatus(uid, replyID)
+}
+
+// LikeReply 点赞回复
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：点赞失败返回错误
+func (service *ReplyService) LikeReply(uid, replyID uint64) error {
+	if err := service.checkReplyExistence(replyID); err != nil {
+		return err
+	}
+	if err := service.replyStore.LikeReply(uid, replyID); err != nil {
+		return err
+	}
+
+	// 更新排序得分缓存
+	return service.replyStore.RefreshReplyScore(replyID)
+}
+
+// CancelLikeReply 取消点赞回复
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：取消失败返回错误
+func (service *ReplyService) CancelLikeReply(uid, replyID uint64) error {
+	if err := service.checkReplyExistence(replyID); err != nil {
+		return err
+	}
+	if err := service.replyStore.CancelLikeReply(uid, replyID); err != nil {
+		return err
+	}
+
+	// 更新排序得分缓存
+	return service.replyStore.RefreshReplyScore(replyID)
+}
+
+// DislikeReply 点踩回复
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：点踩失败返回错误
+func (service *ReplyService) DislikeReply(uid, replyID uint64) error {
+	if err := service.checkReplyExistence(replyID); err != nil {
+		return err
+	}
+	if err := service.replyStore.DislikeReply(uid, replyID); err != nil {
+		return err
+	}
+
+	// 更新排序得分缓存
+	return service.replyStore.RefreshReplyScore(replyID)
+}
+
+// CancelDislikeReply 取消点踩回复
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：取消失败返回错误
+func (service *ReplyService) CancelDislikeReply(uid, replyID uint64) error {
+	if err := service.checkReplyExistence(replyID); err != nil {
+		return err
+	}
+	if err := service.replyStore.CancelDislikeReply(uid, replyID); err != nil {
+		return err
+	}
+
+	// 更新排序得分缓存
+	return service.replyStore.RefreshReplyScore(replyID)
+}
+
+// checkReplyExistence 检查回复是否存在
+//
+// 参数：
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：回复不存在或查询失败时返回错误
+func (service *ReplyService) checkReplyExistence(replyID uint64) error {
+	_, err := service.replyStore.GetReply(replyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("reply does not exist")
+	}
+	return err
+}
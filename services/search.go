@@ -7,31 +7,426 @@ Copyright (c) [2024], Author(s):
 package services
 
 import (
-	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/engines"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
 	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/generators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
 )
 
 type SearchService struct {
-	searchServiceClient search.SearchEngineClient
+	postStore         *stores.PostStore
+	blockStore        *stores.BlockStore
+	followStore       *stores.FollowStore
+	searchIndexStore  *stores.SearchIndexStore
+	autocompleteStore *stores.AutocompleteStore
 }
 
-func (factory *Factory) NewSearchService(searchServiceClient search.SearchEngineClient) *SearchService {
+func (factory *Factory) NewSearchService() *SearchService {
 	return &SearchService{
-		searchServiceClient: searchServiceClient,
+		postStore:         factory.storeFactory.NewPostStore(),
+		blockStore:        factory.storeFactory.NewBlockStore(),
+		followStore:       factory.storeFactory.NewFollowStore(),
+		searchIndexStore:  factory.storeFactory.NewSearchIndexStore(),
+		autocompleteStore: factory.storeFactory.NewAutocompleteStore(),
 	}
 }
 
-// SearchPost 搜索帖子
+// parseSearchOffset 解析搜索结果的偏移量，游标非空时优先使用游标中的偏移量
 //
 // 参数：
-//   - queryString 搜索字符串
+//   - cursor 分页游标
+//   - offset 结果偏移量
 //
 // 返回值：
-//   - *search.SearchResponse 搜索结果
+//   - int 不超过最大偏移量的结果偏移量
 //   - error 错误
-func (service *SearchService) SearchPost(queryString string) (*search.SearchResponse, error) {
-	return service.searchServiceClient.Search(context.TODO(), &search.SearchRequest{
-		Query: queryString,
-	})
+func parseSearchOffset(cursor string, offset int) (int, error) {
+	if cursor != "" {
+		_, cursorOffset, err := parsers.ParseCursor(cursor)
+		if err != nil {
+			return 0, err
+		}
+		return int(min(cursorOffset, consts.SEARCH_MAX_OFFSET)), nil
+	}
+	return min(offset, consts.SEARCH_MAX_OFFSET), nil
+}
+
+// generateSearchCursor 生成搜索结果下一页的游标，已达到最大偏移量时返回空字符串
+//
+// 参数：
+//   - nextOffset 下一页偏移量
+//
+// 返回值：
+//   - string 分页游标
+func generateSearchCursor(nextOffset int) string {
+	if nextOffset >= consts.SEARCH_MAX_OFFSET {
+		return ""
+	}
+	return generators.GenerateCursor(0, uint64(nextOffset))
+}
+
+// SearchPost 搜索帖子，结果会排除查看者不可见的博文
+//
+// 参数：
+//   - query 搜索条件
+//   - viewerUID 查看者ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - types.PostSearchResult 搜索结果
+//   - error 错误
+func (service *SearchService) SearchPost(query types.PostSearchQuery, viewerUID uint64) (types.PostSearchResult, error) {
+	offset, err := parseSearchOffset(query.Cursor, query.Offset)
+	if err != nil {
+		return types.PostSearchResult{}, err
+	}
+	if offset >= consts.SEARCH_MAX_OFFSET {
+		return types.PostSearchResult{Hits: []types.PostSearchHit{}}, nil
+	}
+
+	request := &search.SearchRequest{
+		Query:     query.Query,
+		Offset:    uint32(offset),
+		Limit:     uint32(query.Length),
+		AuthorIds: query.AuthorIDs,
+		Highlight: true,
+	}
+	if !query.Since.IsZero() {
+		request.Since = query.Since.Unix()
+	}
+	if !query.Until.IsZero() {
+		request.Until = query.Until.Unix()
+	}
+	if query.Sort == consts.SEARCH_SORT_RECENCY {
+		request.Sort = search.SearchSort_SEARCH_SORT_RECENCY
+	}
+
+	response, err := service.searchIndexStore.SearchPosts(request)
+	if errors.Is(err, engines.ErrCircuitOpen) {
+		// 搜索服务熔断期间返回空结果，而非让请求失败
+		return types.PostSearchResult{Hits: []types.PostSearchHit{}, Degraded: true}, nil
+	}
+	if err != nil {
+		return types.PostSearchResult{}, err
+	}
+	if offset == 0 {
+		if err := service.recordQuery(viewerUID, query.Query); err != nil {
+			return types.PostSearchResult{}, err
+		}
+	}
+
+	// 兼容只返回ID列表的搜索引擎
+	hits := response.Hits
+	if len(hits) == 0 {
+		hits = make([]*search.SearchHit, len(response.Ids))
+		for index, id := range response.Ids {
+			hits[index] = &search.SearchHit{Id: id}
+		}
+	}
+	total := response.Total
+	if total < uint64(offset+len(hits)) {
+		total = uint64(offset + len(hits))
+	}
+
+	// 排除查看者屏蔽和拉黑的用户、无权查看的私密账号以及已删除或不公开的博文
+	filter, err := getAuthorFilter(service.blockStore, service.followStore, viewerUID)
+	if err != nil {
+		return types.PostSearchResult{}, err
+	}
+	postIDs := make([]int64, len(hits))
+	for index, hit := range hits {
+		postIDs[index] = hit.Id
+	}
+	visibleIDs, err := service.postStore.GetVisiblePostIDs(postIDs, filter)
+	if err != nil {
+		return types.PostSearchResult{}, err
+	}
+	visible := make(map[int64]struct{}, len(visibleIDs))
+	for _, id := range visibleIDs {
+		visible[id] = struct{}{}
+	}
+
+	result := types.PostSearchResult{
+		Hits:  make([]types.PostSearchHit, 0, len(visibleIDs)),
+		Total: total,
+	}
+	for _, hit := range hits {
+		if _, ok := visible[hit.Id]; !ok {
+			continue
+		}
+		result.Hits = append(result.Hits, types.PostSearchHit{
+			PostID:            hit.Id,
+			Score:             hit.Score,
+			TitleHighlights:   hit.TitleHighlights,
+			ContentHighlights: hit.ContentHighlights,
+		})
+	}
+
+	// 偏移量按搜索引擎返回的命中数推进，被过滤的命中不会在下一页重复出现
+	nextOffset := offset + len(hits)
+	if len(hits) > 0 && uint64(nextOffset) < total {
+		result.NextCursor = generateSearchCursor(nextOffset)
+		if result.NextCursor != "" {
+			result.NextOffset = nextOffset
+		}
+	}
+	return result, nil
+}
+
+// SearchUsers 按用户名、昵称或昵称拼音前缀搜索用户，结果会排除查看者屏蔽、拉黑的用户和被限流的其他用户
+//
+// 参数：
+//   - keyword 搜索关键字
+//   - viewerUID 查看者ID，为 0 时表示匿名用户
+//   - cursor 分页游标，非空时优先于 offset
+//   - offset 结果偏移量
+//   - length 每页数量
+//
+// 返回值：
+//   - []uint64 用户ID列表
+//   - string 下一页游标，没有更多结果时为空
+//   - error 错误
+func (service *SearchService) SearchUsers(keyword string, viewerUID uint64, cursor string, offset, length int) ([]uint64, string, error) {
+	offset, err := parseSearchOffset(cursor, offset)
+	if err != nil {
+		return nil, "", err
+	}
+	hidden, err := service.blockStore.GetHiddenAuthors(viewerUID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 多获取一条用于判断是否还有下一页
+	uids, err := service.searchIndexStore.SearchUsers(strings.ToLower(keyword), hidden, offset, length+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset == 0 {
+		if err := service.recordQuery(viewerUID, keyword); err != nil {
+			return nil, "", err
+		}
+	}
+	var nextCursor string
+	if len(uids) > length {
+		uids = uids[:length]
+		nextCursor = generateSearchCursor(offset + length)
+	}
+	return uids, nextCursor, nil
+}
+
+// SearchHashtags 按话题或话题拼音前缀搜索话题
+//
+// 参数：
+//   - keyword 搜索关键字，不包含 # 前缀
+//   - viewerUID 查看者ID，为 0 时表示匿名用户
+//   - cursor 分页游标，非空时优先于 offset
+//   - offset 结果偏移量
+//   - length 每页数量
+//
+// 返回值：
+//   - []models.HashtagSearchIndex 话题列表
+//   - string 下一页游标，没有更多结果时为空
+//   - error 错误
+func (service *SearchService) SearchHashtags(keyword string, viewerUID uint64, cursor string, offset, length int) ([]models.HashtagSearchIndex, string, error) {
+	offset, err := parseSearchOffset(cursor, offset)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 多获取一条用于判断是否还有下一页
+	hashtags, err := service.searchIndexStore.SearchHashtags(strings.ToLower(keyword), offset, length+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset == 0 {
+		if err := service.recordQuery(viewerUID, "#"+keyword); err != nil {
+			return nil, "", err
+		}
+	}
+	var nextCursor string
+	if len(hashtags) > length {
+		hashtags = hashtags[:length]
+		nextCursor = generateSearchCursor(offset + length)
+	}
+	return hashtags, nextCursor, nil
+}
+
+// recordQuery 按用户的搜索隐私设置记录一次搜索，匿名用户的搜索仅计入热门搜索
+//
+// 参数：
+//   - viewerUID 查看者ID，为 0 时表示匿名用户
+//   - query 搜索字符串
+//
+// 返回值：
+//   - error 错误
+func (service *SearchService) recordQuery(viewerUID uint64, query string) error {
+	saveHistory, shareQuery := false, true
+	if viewerUID != 0 {
+		setting, err := service.autocompleteStore.GetPrivacySetting(viewerUID)
+		if err != nil {
+			return err
+		}
+		saveHistory, shareQuery = setting.SaveHistory, setting.ShareQueries
+	}
+	return service.autocompleteStore.RecordQuery(viewerUID, stores.NormalizeQuery(query), saveHistory, shareQuery)
+}
+
+// Autocomplete 获取与前缀匹配的标题、话题、用户和热门搜索建议，
+// 标题和用户建议会排除查看者屏蔽、拉黑的用户和被限流的用户，标题建议还会排除查看者无权查看的私密账号
+//
+// 参数：
+//   - prefix 搜索字符串前缀
+//   - viewerUID 查看者ID，为 0 时表示匿名用户
+//   - length 每类建议的数量
+//
+// 返回值：
+//   - types.AutocompleteSuggestions 自动补全建议
+//   - error 错误
+func (service *SearchService) Autocomplete(prefix string, viewerUID uint64, length int) (types.AutocompleteSuggestions, error) {
+	prefix = stores.NormalizeQuery(prefix)
+	if utf8.RuneCountInString(prefix) > consts.AUTOCOMPLETE_MAX_PREFIX_LENGTH {
+		return types.AutocompleteSuggestions{}, nil
+	}
+
+	// 以 # 开头时仅建议话题
+	if hashtag, ok := strings.CutPrefix(prefix, "#"); ok {
+		suggestions, err := service.autocompleteStore.Suggest(hashtag, length)
+		if err != nil {
+			return types.AutocompleteSuggestions{}, err
+		}
+		return types.AutocompleteSuggestions{Hashtags: suggestions.Hashtags}, nil
+	}
+
+	suggestions, err := service.autocompleteStore.Suggest(prefix, length)
+	if err != nil {
+		return types.AutocompleteSuggestions{}, err
+	}
+	hiddenUIDs, err := service.blockStore.GetHiddenUIDs(viewerUID)
+	if err != nil {
+		return types.AutocompleteSuggestions{}, err
+	}
+
+	// 同名标题只保留最新的一条
+	authorIDs := make([]uint64, len(suggestions.Titles))
+	for index, title := range suggestions.Titles {
+		authorIDs[index] = title.UID
+	}
+	candidateUIDs := append([]uint64{}, authorIDs...)
+	for _, user := range suggestions.Users {
+		candidateUIDs = append(candidateUIDs, user.UID)
+	}
+	shadowbannedUIDs, err := service.blockStore.GetShadowbannedUIDsAmong(viewerUID, candidateUIDs)
+	if err != nil {
+		return types.AutocompleteSuggestions{}, err
+	}
+	hidden := toIDSet(append(hiddenUIDs, shadowbannedUIDs...))
+	inaccessibleUIDs, err := service.followStore.GetInaccessibleUIDsAmong(viewerUID, authorIDs)
+	if err != nil {
+		return types.AutocompleteSuggestions{}, err
+	}
+	inaccessible := toIDSet(inaccessibleUIDs)
+	titles := make([]types.TitleSuggestion, 0, length)
+	seenTitles := make(map[string]struct{}, length)
+	for _, title := range suggestions.Titles {
+		if len(titles) == length {
+			break
+		}
+		if _, ok := hidden[title.UID]; ok {
+			continue
+		}
+		if _, ok := inaccessible[title.UID]; ok {
+			continue
+		}
+		if _, ok := seenTitles[title.Title]; ok {
+			continue
+		}
+		seenTitles[title.Title] = struct{}{}
+		titles = append(titles, title)
+	}
+	suggestions.Titles = titles
+
+	users := make([]types.UserSuggestion, 0, length)
+	for _, user := range suggestions.Users {
+		if len(users) == length {
+			break
+		}
+		if _, ok := hidden[user.UID]; !ok {
+			users = append(users, user)
+		}
+	}
+	suggestions.Users = users
+	return suggestions, nil
+}
+
+// GetRecentSearches 获取用户的最近搜索
+//
+// 参数：
+//   - uid 用户ID
+//
+// 返回值：
+//   - []types.RecentSearch 按时间倒序排列的最近搜索
+//   - error 错误
+func (service *SearchService) GetRecentSearches(uid uint64) ([]types.RecentSearch, error) {
+	return service.autocompleteStore.GetRecentSearches(uid)
+}
+
+// ClearRecentSearches 清除用户的最近搜索
+//
+// 参数：
+//   - uid 用户ID
+//
+// 返回值：
+//   - error 错误
+func (service *SearchService) ClearRecentSearches(uid uint64) error {
+	return service.autocompleteStore.ClearRecentSearches(uid)
+}
+
+// GetPrivacySetting 获取用户的搜索隐私设置
+//
+// 参数：
+//   - uid 用户ID
+//
+// 返回值：
+//   - models.SearchPrivacySetting 搜索隐私设置
+//   - error 错误
+func (service *SearchService) GetPrivacySetting(uid uint64) (models.SearchPrivacySetting, error) {
+	return service.autocompleteStore.GetPrivacySetting(uid)
+}
+
+// UpdatePrivacySetting 更新用户的搜索隐私设置，关闭保存最近搜索时清除已保存的最近搜索
+//
+// 参数：
+//   - uid 用户ID
+//   - reqBody 请求体
+//
+// 返回值：
+//   - models.SearchPrivacySetting 更新后的搜索隐私设置
+//   - error 错误
+func (service *SearchService) UpdatePrivacySetting(uid uint64, reqBody *types.SearchPrivacyBody) (models.SearchPrivacySetting, error) {
+	setting, err := service.autocompleteStore.GetPrivacySetting(uid)
+	if err != nil {
+		return models.SearchPrivacySetting{}, err
+	}
+	if reqBody.SaveHistory != nil {
+		setting.SaveHistory = *reqBody.SaveHistory
+	}
+	if reqBody.ShareQueries != nil {
+		setting.ShareQueries = *reqBody.ShareQueries
+	}
+	if err := service.autocompleteStore.SavePrivacySetting(setting); err != nil {
+		return models.SearchPrivacySetting{}, err
+	}
+	if !setting.SaveHistory {
+		if err := service.autocompleteStore.ClearRecentSearches(uid); err != nil {
+			return models.SearchPrivacySetting{}, err
+		}
+	}
+	return setting, nil
 }
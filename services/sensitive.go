@@ -0,0 +1,67 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for sensitive word checking related services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+)
+
+// ErrSensitiveContent 内容包含禁止发布的敏感词
+var ErrSensitiveContent = errors.New("content contains sensitive words")
+
+// checkSensitive 检查文本中的敏感词，命中 mask 规则的片段直接在原文本中替换为 *
+//
+// 参数：
+//   - store：敏感词库存储
+//   - texts：待检查的文本
+//
+// 返回值：
+//   - *stores.ReviewHold：命中需要人工审核的敏感词时返回包含全部命中敏感词的暂扣信息，否则返回 nil
+//   - error：命中禁止发布的敏感词时返回 ErrSensitiveContent
+func checkSensitive(store *stores.SensitiveWordStore, texts ...*string) (*stores.ReviewHold, error) {
+	var (
+		review bool
+		words  []string
+	)
+	for _, text := range texts {
+		result := store.CheckText(*text)
+		switch result.Action {
+		case consts.SENSITIVE_ACTION_REJECT:
+			return nil, ErrSensitiveContent
+		case consts.SENSITIVE_ACTION_REVIEW:
+			review = true
+		}
+		*text = result.Text
+		words = append(words, result.Words...)
+	}
+	if !review {
+		return nil, nil
+	}
+	return &stores.ReviewHold{Words: words}, nil
+}
+
+// checkSensitiveName 检查用户名或昵称中的敏感词，名称修改后立即对所有人可见且无法暂扣，命中需要人工审核的敏感词时同样拒绝
+//
+// 参数：
+//   - store：敏感词库存储
+//   - name：待检查的名称，命中 mask 规则的片段直接替换为 *
+//
+// 返回值：
+//   - error：命中禁止发布或需要人工审核的敏感词时返回 ErrSensitiveContent
+func checkSensitiveName(store *stores.SensitiveWordStore, name *string) error {
+	hold, err := checkSensitive(store, name)
+	if err != nil {
+		return err
+	}
+	if hold != nil {
+		return ErrSensitiveContent
+	}
+	return nil
+}
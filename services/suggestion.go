@@ -0,0 +1,161 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for follow suggestion related services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// SuggestionService 关注推荐服务
+type SuggestionService struct {
+	suggestionStore *stores.SuggestionStore
+	followStore     *stores.FollowStore
+	blockStore      *stores.BlockStore
+	userStore       *stores.UserStore
+}
+
+// NewSuggestionService 返回一个新的关注推荐服务实例。
+//
+// 返回：
+//   - *SuggestionService: 返回一个指向新的关注推荐服务实例的指针。
+func (factory *Factory) NewSuggestionService() *SuggestionService {
+	return &SuggestionService{
+		suggestionStore: factory.storeFactory.NewSuggestionStore(),
+		followStore:     factory.storeFactory.NewFollowStore(),
+		blockStore:      factory.storeFactory.NewBlockStore(),
+		userStore:       factory.storeFactory.NewUserStore(),
+	}
+}
+
+// GetSuggestions 获取关注推荐，优先读取预计算的缓存
+//
+// 参数：
+//   - uid：用户ID
+//   - length：获取数量
+//
+// 返回值：
+//   - []types.FollowSuggestionItem：关注推荐列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *SuggestionService) GetSuggestions(uid uint64, length int) ([]types.FollowSuggestionItem, error) {
+	suggestions, ok, err := service.suggestionStore.GetCachedSuggestions(uid)
+	if err != nil {
+		return nil, err
+	}
+	// 缓存不存在时即时计算
+	if !ok {
+		suggestions, err = service.suggestionStore.ComputeSuggestions(uid, consts.FOLLOW_SUGGESTION_CACHE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		if err := service.suggestionStore.CacheSuggestions(uid, suggestions); err != nil {
+			return nil, err
+		}
+	}
+
+	// 缓存计算后可能已关注、拉黑、被拉黑或忽略了部分用户，需再次过滤
+	candidateIDs := make([]uint64, len(suggestions))
+	for index, suggestion := range suggestions {
+		candidateIDs[index] = suggestion.UID
+	}
+	followedIDs, err := service.followStore.GetFollowedIDsAmong(uid, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	blockedIDs, err := service.blockStore.GetBlockedIDsAmong(uid, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	blockerIDs, err := service.blockStore.GetBlockerIDsAmong(uid, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	dismissedIDs, err := service.suggestionStore.GetDismissedIDs(uid)
+	if err != nil {
+		return nil, err
+	}
+	excludedIDs := make(map[uint64]struct{}, len(followedIDs)+len(blockedIDs)+len(blockerIDs)+len(dismissedIDs))
+	for _, ids := range [][]uint64{followedIDs, blockedIDs, blockerIDs, dismissedIDs} {
+		for _, id := range ids {
+			excludedIDs[id] = struct{}{}
+		}
+	}
+
+	filtered := make([]types.FollowSuggestion, 0, length)
+	for _, suggestion := range suggestions {
+		if len(filtered) >= length {
+			break
+		}
+		if _, ok := excludedIDs[suggestion.UID]; ok {
+			continue
+		}
+		filtered = append(filtered, suggestion)
+	}
+
+	// 批量获取被推荐用户和推荐理由中好友的资料
+	userIDs := make([]uint64, 0, len(filtered))
+	for _, suggestion := range filtered {
+		userIDs = append(userIDs, suggestion.UID)
+		userIDs = append(userIDs, suggestion.FriendIDs...)
+	}
+	users, err := service.userStore.GetUsersByUIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint64]models.UserInfo, len(users))
+	for _, user := range users {
+		userMap[uint64(user.ID)] = user
+	}
+
+	items := make([]types.FollowSuggestionItem, 0, len(filtered))
+	for _, suggestion := range filtered {
+		user, ok := userMap[suggestion.UID]
+		if !ok {
+			continue
+		}
+		friends := make([]models.UserInfo, 0, len(suggestion.FriendIDs))
+		for _, friendID := range suggestion.FriendIDs {
+			if friend, ok := userMap[friendID]; ok {
+				friends = append(friends, friend)
+			}
+		}
+		items = append(items, types.FollowSuggestionItem{
+			User:       user,
+			Friends:    friends,
+			Suggestion: suggestion,
+		})
+	}
+	return items, nil
+}
+
+// DismissSuggestion 忽略关注推荐
+//
+// 参数：
+//   - uid：用户ID
+//   - dismissedID：被忽略的推荐用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *SuggestionService) DismissSuggestion(uid, dismissedID uint64) error {
+	if uid == dismissedID {
+		return errors.New("cannot perform this action on yourself")
+	}
+	_, err := service.userStore.GetUserByUID(dismissedID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("user does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	return service.suggestionStore.DismissSuggestion(uid, dismissedID)
+}
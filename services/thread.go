@@ -0,0 +1,358 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for comment thread related services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/generators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+// ThreadService 评论树服务
+type ThreadService struct {
+	commentStore *stores.CommentStore
+	postStore    *stores.PostStore
+	replyStore   *stores.ReplyStore
+	blockStore   *stores.BlockStore
+	followStore  *stores.FollowStore
+	userStore    *stores.UserStore
+}
+
+// NewThreadService 返回一个新的评论树服务实例。
+//
+// 返回：
+//   - *ThreadService: 返回一个指向新的评论树服务实例的指针。
+func (factory *Factory) NewThreadService() *ThreadService {
+	return &ThreadService{
+		commentStore: factory.storeFactory.NewCommentStore(),
+		postStore:    factory.storeFactory.NewPostStore(),
+		replyStore:   factory.storeFactory.NewReplyStore(),
+		blockStore:   factory.storeFactory.NewBlockStore(),
+		followStore:  factory.storeFactory.NewFollowStore(),
+		userStore:    factory.storeFactory.NewUserStore(),
+	}
+}
+
+// replyExpansion 逐层展开的回复
+type replyExpansion struct {
+	children map[uint64][]models.ReplyInfo // 父回复ID到已展开子回复的映射
+	hasMore  map[uint64]bool               // 父回复是否还有未展开的子回复
+}
+
+// GetCommentTree 分页获取博文的评论树，每条评论附带前若干条回复及其子回复，第一页开头为置顶评论。
+// 查询次数只与展开层数有关，与评论和回复的数量无关。
+//
+// 参数：
+//   - postID：博文ID
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - sortMode：评论排序方式，new、old、top 或 hot
+//   - cursor：评论分页游标，为空时获取第一页
+//   - length：评论数量
+//   - replyLength：每层展开的回复数量
+//   - depth：展开的回复层数
+//
+// 返回值：
+//   - types.CommentTree：评论树
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ThreadService) GetCommentTree(postID, viewerUID uint64, sortMode, cursor string, length, replyLength, depth int) (types.CommentTree, error) {
+	// 校验查看者是否有权查看私密账号的博文
+	if err := checkPostAccess(service.postStore, service.followStore, postID, viewerUID); err != nil {
+		return types.CommentTree{}, err
+	}
+
+	// 获取对查看者不可见的作者，包括屏蔽、拉黑和被限流的用户
+	hidden, err := service.blockStore.GetHiddenAuthors(viewerUID)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+
+	// 分页获取评论
+	commentIDs, nextCursor, err := getSortedPage(newCommentPageSource(service.commentStore, postID, hidden), sortMode, cursor, length)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+	commentIDs, pinnedIDs, err := applyPinnedComments(service.postStore, service.commentStore, postID, commentIDs, cursor == "", hidden)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+	pinned := make(map[uint64]struct{}, len(pinnedIDs))
+	for _, id := range pinnedIDs {
+		pinned[id] = struct{}{}
+	}
+	comments, err := service.commentStore.GetCommentsByIDs(commentIDs)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+	commentMap := make(map[uint64]models.CommentInfo, len(comments))
+	for _, comment := range comments {
+		commentMap[uint64(comment.ID)] = comment
+	}
+
+	// 获取每条评论的直接回复
+	topReplies, err := service.replyStore.GetTopLevelReplies(commentIDs, 0, replyLength+1, hidden)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+	repliesByComment := make(map[uint64][]models.ReplyInfo, len(commentIDs))
+	for _, reply := range topReplies {
+		repliesByComment[reply.CommentID] = append(repliesByComment[reply.CommentID], reply)
+	}
+	hasMoreReplies := make(map[uint64]bool, len(repliesByComment))
+	roots := make([]models.ReplyInfo, 0, len(topReplies))
+	for commentID, replies := range repliesByComment {
+		if len(replies) > replyLength {
+			repliesByComment[commentID] = replies[:replyLength]
+			hasMoreReplies[commentID] = true
+		}
+		roots = append(roots, repliesByComment[commentID]...)
+	}
+
+	// 逐层展开子回复
+	expansion, err := service.expandReplies(roots, replyLength, depth, hidden)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+
+	// 组装评论树，并收集作者ID
+	uids := make([]uint64, 0, len(comments)+len(roots))
+	nodes := make([]types.CommentNode, 0, len(commentIDs))
+	for _, commentID := range commentIDs {
+		comment, ok := commentMap[commentID]
+		if !ok {
+			continue
+		}
+		uids = append(uids, comment.UID)
+		_, isPinned := pinned[commentID]
+		node := types.CommentNode{Comment: comment, IsPinned: isPinned, Replies: expansion.nodes(repliesByComment[commentID], &uids)}
+		if hasMoreReplies[commentID] {
+			node.NextCursor = generateReplyCursor(repliesByComment[commentID])
+		}
+		nodes = append(nodes, node)
+	}
+
+	users, err := service.getUserMap(uids)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+	likes, _, err := service.commentStore.GetCommentRatingCounts(commentIDs)
+	if err != nil {
+		return types.CommentTree{}, err
+	}
+	return types.CommentTree{Comments: nodes, NextCursor: nextCursor, Users: users, CommentLikes: likes}, nil
+}
+
+// GetReplyTree 分页获取评论或回复下的回复子树，用于加载评论树中未展开的回复
+//
+// 参数：
+//   - commentID：评论ID
+//   - parentReplyID：父回复ID，为 0 时获取评论的直接回复
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - cursor：分页游标，为空时获取第一页
+//   - length：每层展开的回复数量
+//   - depth：展开的回复层数
+//
+// 返回值：
+//   - types.ReplyTree：回复子树
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ThreadService) GetReplyTree(commentID, parentReplyID, viewerUID uint64, cursor string, length, depth int) (types.ReplyTree, error) {
+	// 检查评论及父回复是否存在
+	exists, err := service.commentStore.ValidateCommentExistence(commentID)
+	if err != nil {
+		return types.ReplyTree{}, err
+	}
+	if !exists {
+		return types.ReplyTree{}, errors.New("comment does not exist")
+	}
+
+	// 校验查看者是否有权查看私密账号的博文
+	comment, err := service.commentStore.GetComment(commentID)
+	if err != nil {
+		return types.ReplyTree{}, err
+	}
+	if err := checkPostAccess(service.postStore, service.followStore, comment.PostID, viewerUID); err != nil {
+		return types.ReplyTree{}, err
+	}
+
+	if parentReplyID != 0 {
+		exists, err = service.replyStore.ValidateReplyExistence(commentID, parentReplyID)
+		if err != nil {
+			return types.ReplyTree{}, err
+		}
+		if !exists {
+			return types.ReplyTree{}, errors.New("reply does not exist")
+		}
+	}
+
+	// 解析游标
+	var fromID uint64
+	if cursor != "" {
+		_, fromID, err = parsers.ParseCursor(cursor)
+		if err != nil {
+			return types.ReplyTree{}, err
+		}
+	}
+
+	// 获取对查看者不可见的作者，包括屏蔽、拉黑和被限流的用户
+	hidden, err := service.blockStore.GetHiddenAuthors(viewerUID)
+	if err != nil {
+		return types.ReplyTree{}, err
+	}
+
+	// 获取第一层回复
+	var roots []models.ReplyInfo
+	if parentReplyID == 0 {
+		roots, err = service.replyStore.GetTopLevelReplies([]uint64{commentID}, fromID, length+1, hidden)
+	} else {
+		roots, err = service.replyStore.GetChildReplies([]uint64{parentReplyID}, fromID, length+1, hidden)
+	}
+	if err != nil {
+		return types.ReplyTree{}, err
+	}
+	var nextCursor string
+	if len(roots) > length {
+		roots = roots[:length]
+		nextCursor = generateReplyCursor(roots)
+	}
+
+	// 逐层展开子回复
+	expansion, err := service.expandReplies(roots, length, depth, hidden)
+	if err != nil {
+		return types.ReplyTree{}, err
+	}
+	uids := make([]uint64, 0, len(roots))
+	nodes := expansion.nodes(roots, &uids)
+
+	users, err := service.getUserMap(uids)
+	if err != nil {
+		return types.ReplyTree{}, err
+	}
+	return types.ReplyTree{Replies: nodes, NextCursor: nextCursor, Users: users}, nil
+}
+
+// expandReplies 从第一层回复开始逐层展开子回复，每层只进行一次查询
+//
+// 参数：
+//   - roots：第一层回复
+//   - length：每条回复展开的子回复数量
+//   - depth：展开的回复层数，包括第一层
+//   - hidden：对查看者不可见的作者
+//
+// 返回值：
+//   - replyExpansion：展开结果
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ThreadService) expandReplies(roots []models.ReplyInfo, length, depth int, hidden stores.HiddenAuthors) (replyExpansion, error) {
+	expansion := replyExpansion{
+		children: make(map[uint64][]models.ReplyInfo),
+		hasMore:  make(map[uint64]bool),
+	}
+
+	current := roots
+	for level := 1; level < depth && len(current) > 0; level++ {
+		parentIDs := make([]uint64, len(current))
+		for index, reply := range current {
+			parentIDs[index] = uint64(reply.ID)
+		}
+		children, err := service.replyStore.GetChildReplies(parentIDs, 0, length+1, hidden)
+		if err != nil {
+			return replyExpansion{}, err
+		}
+		for _, child := range children {
+			parentID := *child.ParentReplyID
+			expansion.children[parentID] = append(expansion.children[parentID], child)
+		}
+
+		next := make([]models.ReplyInfo, 0, len(children))
+		for _, parentID := range parentIDs {
+			group := expansion.children[parentID]
+			if len(group) > length {
+				group = group[:length]
+				expansion.children[parentID] = group
+				expansion.hasMore[parentID] = true
+			}
+			next = append(next, group...)
+		}
+		current = next
+	}
+
+	// 达到层数限制时，标记仍有子回复的回复
+	if len(current) > 0 {
+		parentIDs := make([]uint64, len(current))
+		for index, reply := range current {
+			parentIDs[index] = uint64(reply.ID)
+		}
+		repliedIDs, err := service.replyStore.GetRepliedReplyIDs(parentIDs, hidden)
+		if err != nil {
+			return replyExpansion{}, err
+		}
+		for _, id := range repliedIDs {
+			expansion.hasMore[id] = true
+		}
+	}
+	return expansion, nil
+}
+
+// nodes 将回复及其已展开的子回复组装为回复树节点，并收集回复作者ID
+//
+// 参数：
+//   - replies：同一层的回复
+//   - uids：用于收集回复作者ID的切片
+//
+// 返回值：
+//   - []types.ReplyNode：回复树节点
+func (expansion replyExpansion) nodes(replies []models.ReplyInfo, uids *[]uint64) []types.ReplyNode {
+	nodes := make([]types.ReplyNode, 0, len(replies))
+	for _, reply := range replies {
+		*uids = append(*uids, reply.UID)
+		replyID := uint64(reply.ID)
+		children := expansion.children[replyID]
+		node := types.ReplyNode{Reply: reply, Children: expansion.nodes(children, uids)}
+		if expansion.hasMore[replyID] {
+			node.NextCursor = generateReplyCursor(children)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// getUserMap 批量获取用户资料
+//
+// 参数：
+//   - uids：用户ID列表，可以包含重复ID
+//
+// 返回值：
+//   - map[uint64]models.UserInfo：用户ID到用户资料的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *ThreadService) getUserMap(uids []uint64) (map[uint64]models.UserInfo, error) {
+	users, err := service.userStore.GetUsersByUIDs(uids)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint64]models.UserInfo, len(users))
+	for _, user := range users {
+		userMap[uint64(user.ID)] = user
+	}
+	return userMap, nil
+}
+
+// generateReplyCursor 根据已加载的回复生成加载更多的游标，未加载任何回复时游标指向第一条
+//
+// 参数：
+//   - loaded：已加载的回复，按ID正序排列
+//
+// 返回值：
+//   - string：游标
+func generateReplyCursor(loaded []models.ReplyInfo) string {
+	var lastID uint64
+	if len(loaded) > 0 {
+		lastID = uint64(loaded[len(loaded)-1].ID)
+	}
+	return generators.GenerateCursor(0, lastID)
+}
@@ -0,0 +1,213 @@
+/*
+Package services - NekoBlog backend server services.
+This file is for trending posts and hashtags services.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/stores"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/calculators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/generators"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+// TrendingService 热门博文及热门话题服务
+type TrendingService struct {
+	postStore      *stores.PostStore
+	trendingStore  *stores.TrendingStore
+	analyticsStore *stores.AnalyticsStore
+	blockStore     *stores.BlockStore
+	followStore    *stores.FollowStore
+}
+
+// NewTrendingService 返回一个新的热门服务实例。
+//
+// 返回：
+//   - *TrendingService: 返回一个指向新的热门服务实例的指针。
+func (factory *Factory) NewTrendingService() *TrendingService {
+	return &TrendingService{
+		postStore:      factory.storeFactory.NewPostStore(),
+		trendingStore:  factory.storeFactory.NewTrendingStore(),
+		analyticsStore: factory.storeFactory.NewAnalyticsStore(),
+		blockStore:     factory.storeFactory.NewBlockStore(),
+		followStore:    factory.storeFactory.NewFollowStore(),
+	}
+}
+
+// recordEngagement 记录博文互动，同时更新互动计数和博文热度，作者对自己博文的互动不计入热度
+//
+// 参数：
+//   - counterStore：互动计数存储
+//   - trendingStore：热门存储
+//   - actorUID：互动者ID，为 0 时表示匿名用户
+//   - postID：博文ID
+//   - field：互动计数字段
+//   - delta：互动数量的变化，可以为负数
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func recordEngagement(counterStore *stores.CounterStore, trendingStore *stores.TrendingStore, actorUID, postID uint64, field string, delta int64) error {
+	if err := counterStore.IncrPostCounter(postID, field, delta); err != nil {
+		return err
+	}
+	return trendingStore.IncrHotScore(postID, actorUID, field, delta)
+}
+
+// GetHotPosts 按热度分页获取热门博文，同一作者的博文数量上限已在热门列表重建时保证
+//
+// 参数：
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - cursor：分页游标，为空时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []uint64：博文ID列表，过滤后的数量可能少于 length
+//   - string：下一页游标，没有更多数据时为空
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *TrendingService) GetHotPosts(viewerUID uint64, cursor string, length int) ([]uint64, string, error) {
+	var (
+		fromKey int64
+		fromID  uint64
+		err     error
+	)
+	if cursor != "" {
+		fromKey, fromID, err = parsers.ParseCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// 排除查看者屏蔽和拉黑的用户，被限流的用户和私密账号逐批检查
+	hiddenUIDs, err := service.blockStore.GetHiddenUIDs(viewerUID)
+	if err != nil {
+		return nil, "", err
+	}
+	excludedUIDs := toIDSet(hiddenUIDs)
+
+	// 逐批扫描热门榜单，直到凑满一页或达到扫描上限
+	var (
+		ids       = make([]uint64, 0, length)
+		lastScore = math.Float64frombits(uint64(fromKey))
+		lastID    = fromID
+		exhausted bool
+	)
+	batchSize := length * 2
+	for round := 0; round < consts.POST_HOT_MAX_SCAN_ROUNDS && len(ids) < length && !exhausted; round++ {
+		batch, scores, err := service.trendingStore.GetHotPosts(lastScore, lastID, batchSize)
+		if err != nil {
+			return nil, "", err
+		}
+		posts, err := service.postStore.GetPostsByIDs(batch)
+		if err != nil {
+			return nil, "", err
+		}
+		postMap := make(map[uint64]models.PostInfo, len(posts))
+		authorIDs := make([]uint64, len(posts))
+		for index, post := range posts {
+			postMap[uint64(post.ID)] = post
+			authorIDs[index] = post.UID
+		}
+
+		// 排除本批博文作者中被限流的其他用户和查看者无权查看的私密账号
+		shadowbannedUIDs, err := service.blockStore.GetShadowbannedUIDsAmong(viewerUID, authorIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		inaccessibleUIDs, err := service.followStore.GetInaccessibleUIDsAmong(viewerUID, authorIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, uid := range append(shadowbannedUIDs, inaccessibleUIDs...) {
+			excludedUIDs[uid] = struct{}{}
+		}
+
+		consumed := 0
+		for index, postID := range batch {
+			if len(ids) >= length {
+				break
+			}
+			consumed++
+			lastID, lastScore = postID, scores[index]
+
+			// 榜单中的博文可能已被删除
+			post, ok := postMap[postID]
+			if !ok {
+				continue
+			}
+			if _, ok := excludedUIDs[post.UID]; ok {
+				continue
+			}
+			ids = append(ids, postID)
+		}
+		exhausted = len(batch) < batchSize && consumed == len(batch)
+	}
+
+	var nextCursor string
+	if !exhausted && lastID != 0 {
+		nextCursor = generators.GenerateCursor(int64(math.Float64bits(lastScore)), lastID)
+	}
+	return ids, nextCursor, nil
+}
+
+// RecordImpressions 记录热门列表中博文的曝光
+//
+// 参数：
+//   - postIDs：出现在列表中的博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *TrendingService) RecordImpressions(postIDs []uint64) error {
+	return service.analyticsStore.RecordImpressions(postIDs)
+}
+
+// GetTrendingHashtags 获取热门话题，按最近一小时热度相对统计窗口内基线的增幅排序
+//
+// 参数：
+//   - hours：统计窗口（小时），包含当前小时
+//   - length：获取数量
+//
+// 返回值：
+//   - []types.TrendingHashtag：热门话题列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (service *TrendingService) GetTrendingHashtags(hours, length int) ([]types.TrendingHashtag, error) {
+	counts, err := service.trendingStore.GetHashtagHourlyCounts(hours, consts.HASHTAG_TRENDING_CANDIDATES)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	elapsed := float64(now.Minute()*60+now.Second()) / 3600
+	hashtags := make([]types.TrendingHashtag, 0, len(counts))
+	for hashtag, hourly := range counts {
+		velocity, baseline, score := calculators.TrendingScore(hourly, elapsed)
+		if velocity < consts.HASHTAG_TRENDING_MIN_VELOCITY {
+			continue
+		}
+		hashtags = append(hashtags, types.TrendingHashtag{
+			Hashtag:  hashtag,
+			Velocity: velocity,
+			Baseline: baseline,
+			Score:    score,
+			Hourly:   hourly,
+		})
+	}
+	sort.Slice(hashtags, func(i, j int) bool {
+		if hashtags[i].Score != hashtags[j].Score {
+			return hashtags[i].Score > hashtags[j].Score
+		}
+		return hashtags[i].Hashtag < hashtags[j].Hashtag
+	})
+	if len(hashtags) > length {
+		hashtags = hashtags[:length]
+	}
+	return hashtags, nil
+}
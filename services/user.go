@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
@@ -28,7 +29,12 @@ import (
 
 // UserService 用户服务
 type UserService struct {
-	userStore *stores.UserStore
+	userStore          *stores.UserStore
+	followStore        *stores.FollowStore
+	autocompleteStore  *stores.AutocompleteStore
+	sensitiveWordStore *stores.SensitiveWordStore
+	moderationStore    *stores.ModerationStore
+	logger             *logrus.Logger
 }
 
 // NewUserService 返回一个新的 UserService 实例。
@@ -37,7 +43,12 @@ type UserService struct {
 //   - *UserService：新的 UserService 实例。
 func (factory *Factory) NewUserService() *UserService {
 	return &UserService{
-		userStore: factory.storeFactory.NewUserStore(),
+		userStore:          factory.storeFactory.NewUserStore(),
+		followStore:        factory.storeFactory.NewFollowStore(),
+		autocompleteStore:  factory.storeFactory.NewAutocompleteStore(),
+		sensitiveWordStore: factory.storeFactory.NewSensitiveWordStore(),
+		moderationStore:    factory.storeFactory.NewModerationStore(),
+		logger:             factory.logger,
 	}
 }
 
@@ -92,6 +103,15 @@ func (service *UserService) RegisterUser(username string, password string) error
 		return errors.New("invalid password")
 	}
 
+	// 检查用户名中的敏感词，用户名不允许包含被替换的字符
+	checkedUsername := username
+	if err := checkSensitiveName(service.sensitiveWordStore, &checkedUsername); err != nil {
+		return err
+	}
+	if checkedUsername != username {
+		return ErrSensitiveContent
+	}
+
 	// 检验用户名是否重复
 	_, err := service.userStore.GetUserByUsername(username)
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -114,6 +134,16 @@ func (service *UserService) RegisterUser(username string, password string) error
 		return err
 	}
 
+	// 写入自动补全索引，新用户的昵称与用户名相同
+	// 用户已注册，索引写入失败时仅记录日志，避免客户端重试注册
+	user, err := service.userStore.GetUserByUsername(username)
+	if err != nil {
+		service.logger.Warnln("写入用户自动补全索引失败:", err.Error())
+		return nil
+	}
+	if err := service.autocompleteStore.IndexUser(uint64(user.ID), user.UserName, username, ""); err != nil {
+		service.logger.Warnln("写入用户自动补全索引失败:", err.Error())
+	}
 	return nil
 }
 
@@ -156,6 +186,21 @@ func (service *UserService) LoginUser(username string, password string, ip strin
 		return "", errors.New("password error")
 	}
 
+	// 校验账号是否被暂停或封禁
+	suspension, err := service.moderationStore.GetActiveSuspension(userAuthInfo.UID)
+	if err == nil {
+		suspendedErr := &AccountSuspendedError{Suspension: suspension}
+		userLoginLog.Reason = "account suspended"
+		inner_err := service.userStore.CreateUserLoginLog(userLoginLog)
+		if inner_err != nil {
+			return "", errors.Join(suspendedErr, inner_err)
+		}
+		return "", suspendedErr
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
 	// 生成令牌
 	token, claims, err := generators.GenerateToken(userAuthInfo.UID, username)
 	if err != nil {
@@ -280,6 +325,13 @@ func (service *UserService) UserUpdatePassword(username string, password string,
 // 返回值：
 //   - error：如果在更新过程中发生错误，则返回相应的错误信息，否则返回nil。
 func (service *UserService) UpdateUserInfo(uid uint64, reqBody *types.UserUpdateProfileBody) error {
+	// 检查昵称中的敏感词
+	if reqBody.NickName != nil {
+		if err := checkSensitiveName(service.sensitiveWordStore, reqBody.NickName); err != nil {
+			return err
+		}
+	}
+
 	// 构造更新Profile结构体
 	updatedProfile := &models.UserInfo{
 		NickName: reqBody.NickName,
@@ -300,10 +352,48 @@ func (service *UserService) UpdateUserInfo(uid uint64, reqBody *types.UserUpdate
 	}
 
 	// 执行数据库更新操作
-	err := service.userStore.UpdateUserInfoByUID(uid, updatedProfile)
+	oldProfile, err := service.userStore.GetUserByUID(uid)
+	if err != nil {
+		return err
+	}
+	err = service.userStore.UpdateUserInfoByUID(uid, updatedProfile)
+	if err != nil {
+		return err
+	}
+
+	// 更新自动补全索引
+	var nickname, oldNickname string
+	if updatedProfile.NickName != nil {
+		nickname = *updatedProfile.NickName
+	}
+	if oldProfile.NickName != nil {
+		oldNickname = *oldProfile.NickName
+	}
+	// 资料已更新，索引写入失败时仅记录日志
+	if err := service.autocompleteStore.IndexUser(uid, oldProfile.UserName, nickname, oldNickname); err != nil {
+		service.logger.Warnln("更新用户自动补全索引失败:", err.Error())
+	}
+	return nil
+}
+
+// UpdateUserPrivacy 更新用户的私密账号设置，切换为公开账号时自动通过所有待处理的关注请求。
+//
+// 参数：
+//   - uid：用户ID
+//   - isPrivate：是否为私密账号
+//
+// 返回值：
+//   - error：如果在更新过程中发生错误，则返回相应的错误信息，否则返回nil。
+func (service *UserService) UpdateUserPrivacy(uid uint64, isPrivate bool) error {
+	err := service.userStore.UpdateUserPrivacyByUID(uid, isPrivate)
 	if err != nil {
 		return err
 	}
 
+	// 切换为公开账号时，将待处理的关注请求转换为关注
+	if !isPrivate {
+		return service.followStore.ApproveAllFollowRequests(uid)
+	}
+
 	return nil
 }
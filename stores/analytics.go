@@ -0,0 +1,311 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for post analytics storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+)
+
+// analyticsEngagementFields 每日统计中记录累计值的互动计数字段
+var analyticsEngagementFields = []string{
+	consts.POST_COUNTER_LIKES,
+	consts.POST_COUNTER_FAVOURITES,
+	consts.POST_COUNTER_COMMENTS,
+	consts.POST_COUNTER_REPOSTS,
+}
+
+// AnalyticsStore 博文数据分析存储，当日数据记录在 Redis 中并定期汇总到 PostgreSQL
+type AnalyticsStore struct {
+	db           *gorm.DB
+	rds          *redis.Client
+	counterStore *CounterStore
+}
+
+// NewAnalyticsStore 返回一个新的博文数据分析存储实例。
+//
+// 返回：
+//   - *AnalyticsStore: 返回一个指向新的博文数据分析存储实例的指针。
+func (factory *Factory) NewAnalyticsStore() *AnalyticsStore {
+	return &AnalyticsStore{
+		db:           factory.db,
+		rds:          factory.rds,
+		counterStore: factory.NewCounterStore(),
+	}
+}
+
+// analyticsKey 获取按日期划分的统计缓存键
+//
+// 参数：
+//   - prefix：键前缀
+//   - date：统计日期
+//
+// 返回值：
+//   - string：缓存键
+func analyticsKey(prefix string, date time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteString(":")
+	sb.WriteString(date.Format(consts.POST_ANALYTICS_DATE_LAYOUT))
+	return sb.String()
+}
+
+// postViewKey 获取博文某日独立浏览者 HyperLogLog 的键
+//
+// 参数：
+//   - postID：博文ID
+//   - date：统计日期
+//
+// 返回值：
+//   - string：缓存键
+func postViewKey(postID uint64, date time.Time) string {
+	return analyticsKey(consts.REDIS_POST_VIEW_HLL, date) + ":" + strconv.FormatUint(postID, 10)
+}
+
+// engagementField 获取每日互动增量哈希中博文某项计数的字段
+//
+// 参数：
+//   - postID：博文ID
+//   - field：计数字段
+//
+// 返回值：
+//   - string：哈希字段
+func engagementField(postID uint64, field string) string {
+	return strconv.FormatUint(postID, 10) + ":" + field
+}
+
+// RecordView 记录博文浏览，同一浏览者每天只计一次
+//
+// 参数：
+//   - postID：博文ID
+//   - viewerKey：浏览者标识，登录用户为用户ID，匿名用户为IP地址
+//
+// 返回值：
+//   - bool：是否为当日新的浏览者
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AnalyticsStore) RecordView(postID uint64, viewerKey string) (bool, error) {
+	ctx := context.Background()
+	now := time.Now()
+	key := postViewKey(postID, now)
+	added, err := store.rds.PFAdd(ctx, key, viewerKey).Result()
+	if err != nil {
+		return false, err
+	}
+	if added == 0 {
+		return false, nil
+	}
+
+	activeKey := analyticsKey(consts.REDIS_POST_ANALYTICS_ACTIVE, now)
+	tx := store.rds.TxPipeline()
+	tx.Expire(ctx, key, consts.POST_ANALYTICS_KEY_EXPIRE*time.Second)
+	tx.SAdd(ctx, activeKey, postID)
+	tx.Expire(ctx, activeKey, consts.POST_ANALYTICS_KEY_EXPIRE*time.Second)
+	_, err = tx.Exec(ctx)
+	return true, err
+}
+
+// RecordImpressions 记录博文在列表中的曝光
+//
+// 参数：
+//   - postIDs：出现在列表中的博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AnalyticsStore) RecordImpressions(postIDs []uint64) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	now := time.Now()
+	impressionKey := analyticsKey(consts.REDIS_POST_IMPRESSIONS, now)
+	activeKey := analyticsKey(consts.REDIS_POST_ANALYTICS_ACTIVE, now)
+
+	tx := store.rds.TxPipeline()
+	members := make([]interface{}, 0, len(postIDs))
+	for _, postID := range postIDs {
+		tx.HIncrBy(ctx, impressionKey, strconv.FormatUint(postID, 10), 1)
+		members = append(members, postID)
+	}
+	tx.SAdd(ctx, activeKey, members...)
+	tx.Expire(ctx, impressionKey, consts.POST_ANALYTICS_KEY_EXPIRE*time.Second)
+	tx.Expire(ctx, activeKey, consts.POST_ANALYTICS_KEY_EXPIRE*time.Second)
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+// RollupDay 将某日有活动的博文的统计数据汇总到数据库，可重复执行
+//
+// 参数：
+//   - date：统计日期
+//
+// 返回值：
+//   - int：汇总的博文数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AnalyticsStore) RollupDay(date time.Time) (int, error) {
+	members, err := store.rds.SMembers(context.Background(), analyticsKey(consts.REDIS_POST_ANALYTICS_ACTIVE, date)).Result()
+	if err != nil {
+		return 0, err
+	}
+	postIDs := make([]uint64, 0, len(members))
+	for _, member := range members {
+		postID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	for start := 0; start < len(postIDs); start += consts.POST_ANALYTICS_ROLLUP_BATCH {
+		end := start + consts.POST_ANALYTICS_ROLLUP_BATCH
+		if end > len(postIDs) {
+			end = len(postIDs)
+		}
+		stats, err := store.getLiveDailyStats(postIDs[start:end], date)
+		if err != nil {
+			return 0, err
+		}
+		result := store.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "post_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"views", "impressions", "likes", "favourites", "comments", "reposts", "updated_at"}),
+		}).Create(&stats)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+	}
+	return len(postIDs), nil
+}
+
+// GetDailyStats 获取博文在日期范围内的每日统计，当日数据实时计算，没有活动的日期不返回
+//
+// 参数：
+//   - postID：博文ID
+//   - from：起始日期
+//   - to：结束日期
+//
+// 返回值：
+//   - []models.PostDailyStat：按日期正序排列的每日统计
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AnalyticsStore) GetDailyStats(postID uint64, from, to time.Time) ([]models.PostDailyStat, error) {
+	var stats []models.PostDailyStat
+	result := store.db.
+		Where("post_id = ? AND date >= ? AND date <= ?", postID, from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Order("date ASC").
+		Find(&stats)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// 以实时数据替换当日尚未汇总完成的统计
+	today := time.Now()
+	if to.Format(time.DateOnly) != today.Format(time.DateOnly) {
+		return stats, nil
+	}
+	live, err := store.getLiveDailyStats([]uint64{postID}, today)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) > 0 && stats[len(stats)-1].Date.Format(time.DateOnly) == today.Format(time.DateOnly) {
+		stats = stats[:len(stats)-1]
+	}
+	return append(stats, live...), nil
+}
+
+// getLiveDailyStats 根据 Redis 中的数据计算博文某日的统计，互动数由实时累计值减去该日之后的互动增量得到，
+// 因此跨日后重复汇总前一日不会计入次日的互动
+//
+// 参数：
+//   - postIDs：博文ID列表
+//   - date：统计日期
+//
+// 返回值：
+//   - []models.PostDailyStat：每日统计，与参数顺序一致
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AnalyticsStore) getLiveDailyStats(postIDs []uint64, date time.Time) ([]models.PostDailyStat, error) {
+	ctx := context.Background()
+	fields := make([]string, len(postIDs))
+	pipe := store.rds.Pipeline()
+	viewCmds := make([]*redis.IntCmd, len(postIDs))
+	for index, postID := range postIDs {
+		fields[index] = strconv.FormatUint(postID, 10)
+		viewCmds[index] = pipe.PFCount(ctx, postViewKey(postID, date))
+	}
+	impressionCmd := pipe.HMGet(ctx, analyticsKey(consts.REDIS_POST_IMPRESSIONS, date), fields...)
+
+	// 获取统计日期之后各日的互动增量
+	now := time.Now()
+	engagementFields := make([]string, 0, len(postIDs)*len(analyticsEngagementFields))
+	for _, postID := range postIDs {
+		for _, field := range analyticsEngagementFields {
+			engagementFields = append(engagementFields, engagementField(postID, field))
+		}
+	}
+	var laterCmds []*redis.SliceCmd
+	for later := date.AddDate(0, 0, 1); later.Format(time.DateOnly) <= now.Format(time.DateOnly); later = later.AddDate(0, 0, 1) {
+		laterCmds = append(laterCmds, pipe.HMGet(ctx, analyticsKey(consts.REDIS_POST_ENGAGEMENTS, later), engagementFields...))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	impressions, err := impressionCmd.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counters, err := store.counterStore.GetPostCounters(postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	laterDeltas := make(map[string]int64)
+	for _, cmd := range laterCmds {
+		values, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		for index, value := range values {
+			if value, ok := value.(string); ok {
+				delta, _ := strconv.ParseInt(value, 10, 64)
+				laterDeltas[engagementFields[index]] += delta
+			}
+		}
+	}
+
+	day, _ := time.Parse(time.DateOnly, date.Format(time.DateOnly))
+	stats := make([]models.PostDailyStat, len(postIDs))
+	for index, postID := range postIDs {
+		views, err := viewCmds[index].Result()
+		if err != nil {
+			return nil, err
+		}
+		var impressionCount int64
+		if value, ok := impressions[index].(string); ok {
+			impressionCount, _ = strconv.ParseInt(value, 10, 64)
+		}
+		counter := counters[postID]
+		stats[index] = models.PostDailyStat{
+			PostID:      postID,
+			Date:        day,
+			Views:       views,
+			Impressions: impressionCount,
+			Likes:       counter.Likes - laterDeltas[engagementField(postID, consts.POST_COUNTER_LIKES)],
+			Favourites:  counter.Favourites - laterDeltas[engagementField(postID, consts.POST_COUNTER_FAVOURITES)],
+			Comments:    counter.Comments - laterDeltas[engagementField(postID, consts.POST_COUNTER_COMMENTS)],
+			Reposts:     counter.Reposts - laterDeltas[engagementField(postID, consts.POST_COUNTER_REPOSTS)],
+			UpdatedAt:   now,
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,493 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for search autocomplete and history storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/converters"
+)
+
+// AutocompleteStore 搜索自动补全存储，前缀索引和最近搜索保存在 Redis 中
+type AutocompleteStore struct {
+	db  *gorm.DB
+	rds *redis.Client
+}
+
+// NewAutocompleteStore 返回一个新的搜索自动补全存储实例。
+//
+// 返回：
+//   - *AutocompleteStore: 返回一个指向新的搜索自动补全存储实例的指针。
+func (factory *Factory) NewAutocompleteStore() *AutocompleteStore {
+	return &AutocompleteStore{
+		db:  factory.db,
+		rds: factory.rds,
+	}
+}
+
+// NormalizeQuery 规范化搜索字符串：去除首尾空白、合并连续空白并转换为小写
+//
+// 参数：
+//   - query：搜索字符串
+//
+// 返回值：
+//   - string：规范化后的搜索字符串
+func NormalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// textPrefixes 获取文本的所有前缀，最长不超过 AUTOCOMPLETE_MAX_PREFIX_LENGTH 个字符
+//
+// 参数：
+//   - text：规范化后的文本
+//
+// 返回值：
+//   - []string：由短到长排列的前缀
+func textPrefixes(text string) []string {
+	prefixes := make([]string, 0, consts.AUTOCOMPLETE_MAX_PREFIX_LENGTH)
+	length := 0
+	for index := range text {
+		if index == 0 {
+			continue
+		}
+		prefixes = append(prefixes, text[:index])
+		length++
+		if length == consts.AUTOCOMPLETE_MAX_PREFIX_LENGTH {
+			return prefixes
+		}
+	}
+	if text != "" {
+		prefixes = append(prefixes, text)
+	}
+	return prefixes
+}
+
+// nameKeys 获取名称及其拼音的前缀索引键，得分为名称长度的相反数，多个名称具有相同前缀时取较短的名称
+//
+// 参数：
+//   - category：前缀索引类别
+//   - names：规范化后的名称
+//
+// 返回值：
+//   - map[string]float64：前缀索引键及其得分，名称越短得分越高
+func nameKeys(category string, names ...string) map[string]float64 {
+	keys := make(map[string]float64)
+	add := func(text string) {
+		score := -float64(utf8.RuneCountInString(text))
+		for _, prefix := range textPrefixes(text) {
+			key := category + ":" + prefix
+			if current, ok := keys[key]; !ok || score > current {
+				keys[key] = score
+			}
+		}
+	}
+	for _, name := range names {
+		add(name)
+		pinyin, pinyinInitials := converters.ToPinyin(name)
+		if pinyin != name {
+			add(pinyin)
+			add(pinyinInitials)
+		}
+	}
+	return keys
+}
+
+// trimPrefixKey 在管道中将前缀索引裁剪为得分最高的 AUTOCOMPLETE_PREFIX_CAPACITY 项
+//
+// 参数：
+//   - ctx：上下文
+//   - pipe：Redis 管道
+//   - key：前缀索引键
+func trimPrefixKey(ctx context.Context, pipe redis.Pipeliner, key string) {
+	pipe.ZRemRangeByRank(ctx, key, 0, -consts.AUTOCOMPLETE_PREFIX_CAPACITY-1)
+}
+
+// IndexPostTitle 将博文标题写入前缀索引，较新的博文优先，同名标题按博文分别索引
+//
+// 参数：
+//   - postID：博文ID
+//   - uid：作者ID
+//   - title：博文标题
+//   - createdAt：博文发布时间
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) IndexPostTitle(postID, uid uint64, title string, createdAt time.Time) error {
+	title = NormalizeQuery(title)
+	if title == "" {
+		return nil
+	}
+	ctx := context.Background()
+	member := strconv.FormatUint(postID, 10)
+	pipe := store.rds.TxPipeline()
+	pipe.HSet(ctx, consts.REDIS_AUTOCOMPLETE_TITLE_POST, member, strconv.FormatUint(uid, 10)+"\n"+title)
+	for _, prefix := range textPrefixes(title) {
+		key := consts.REDIS_AUTOCOMPLETE_TITLE + ":" + prefix
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(createdAt.Unix()), Member: member})
+		trimPrefixKey(ctx, pipe, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RemovePostTitle 从前缀索引中移除博文标题，不影响其他同名博文的标题建议
+//
+// 参数：
+//   - postID：博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) RemovePostTitle(postID uint64) error {
+	ctx := context.Background()
+	member := strconv.FormatUint(postID, 10)
+	profile, err := store.rds.HGet(ctx, consts.REDIS_AUTOCOMPLETE_TITLE_POST, member).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, title, _ := strings.Cut(profile, "\n")
+	pipe := store.rds.TxPipeline()
+	for _, prefix := range textPrefixes(title) {
+		pipe.ZRem(ctx, consts.REDIS_AUTOCOMPLETE_TITLE+":"+prefix, member)
+	}
+	pipe.HDel(ctx, consts.REDIS_AUTOCOMPLETE_TITLE_POST, member)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// IncrHashtags 调整话题在前缀索引中的使用次数，次数不大于 0 的话题将被移除
+//
+// 参数：
+//   - hashtags：去重后的小写话题列表
+//   - delta：使用次数的变化量
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) IncrHashtags(hashtags []string, delta float64) error {
+	if len(hashtags) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	pipe := store.rds.Pipeline()
+	for _, hashtag := range hashtags {
+		for key := range nameKeys(consts.REDIS_AUTOCOMPLETE_HASHTAG, hashtag) {
+			pipe.ZIncrBy(ctx, key, delta, hashtag)
+			if delta > 0 {
+				trimPrefixKey(ctx, pipe, key)
+			} else {
+				pipe.ZRemRangeByScore(ctx, key, "-inf", "0")
+			}
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// IndexUser 将用户名和昵称写入前缀索引，较短的名称优先，并移除旧昵称的前缀索引
+//
+// 参数：
+//   - uid：用户ID
+//   - username：用户名
+//   - nickname：昵称
+//   - oldNickname：旧昵称，新用户为空字符串
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) IndexUser(uid uint64, username, nickname, oldNickname string) error {
+	ctx := context.Background()
+	member := strconv.FormatUint(uid, 10)
+	username, nickname, oldNickname = NormalizeQuery(username), NormalizeQuery(nickname), NormalizeQuery(oldNickname)
+	keys := nameKeys(consts.REDIS_AUTOCOMPLETE_USER, username, nickname)
+
+	pipe := store.rds.TxPipeline()
+	if oldNickname != "" && oldNickname != nickname {
+		for key := range nameKeys(consts.REDIS_AUTOCOMPLETE_USER, oldNickname) {
+			pipe.ZRem(ctx, key, member)
+		}
+	}
+	for key, score := range keys {
+		pipe.ZAdd(ctx, key, redis.Z{Score: score, Member: member})
+		trimPrefixKey(ctx, pipe, key)
+	}
+	pipe.HSet(ctx, consts.REDIS_AUTOCOMPLETE_USER_PROFILE, member, username+"\n"+nickname)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RecordQuery 记录一次搜索，按设置写入用户的最近搜索和热门搜索
+//
+// 参数：
+//   - uid：用户ID，为 0 时表示匿名用户，仅计入热门搜索
+//   - query：规范化后的搜索字符串
+//   - saveHistory：是否写入最近搜索
+//   - shareQuery：是否计入热门搜索
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) RecordQuery(uid uint64, query string, saveHistory, shareQuery bool) error {
+	if query == "" || utf8.RuneCountInString(query) > consts.AUTOCOMPLETE_QUERY_MAX_LENGTH {
+		return nil
+	}
+	ctx := context.Background()
+	pipe := store.rds.Pipeline()
+	if uid != 0 && saveHistory {
+		key := recentSearchKey(uid)
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(time.Now().UnixMilli()), Member: query})
+		pipe.ZRemRangeByRank(ctx, key, 0, -consts.SEARCH_RECENT_MAX_LENGTH-1)
+	}
+	if shareQuery {
+		for _, prefix := range textPrefixes(query) {
+			key := consts.REDIS_AUTOCOMPLETE_QUERY + ":" + prefix
+			pipe.ZIncrBy(ctx, key, 1, query)
+			trimPrefixKey(ctx, pipe, key)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Suggest 获取与前缀匹配的标题、话题、用户和热门搜索建议
+//
+// 参数：
+//   - prefix：规范化后的前缀
+//   - length：每类建议的数量
+//
+// 返回值：
+//   - types.AutocompleteSuggestions：自动补全建议，标题和用户建议未排除不可见的作者和用户
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) Suggest(prefix string, length int) (types.AutocompleteSuggestions, error) {
+	ctx := context.Background()
+	pipe := store.rds.Pipeline()
+	titles := pipe.ZRevRange(ctx, consts.REDIS_AUTOCOMPLETE_TITLE+":"+prefix, 0, int64(consts.AUTOCOMPLETE_PREFIX_CAPACITY-1))
+	hashtags := pipe.ZRevRange(ctx, consts.REDIS_AUTOCOMPLETE_HASHTAG+":"+prefix, 0, int64(length-1))
+	users := pipe.ZRevRange(ctx, consts.REDIS_AUTOCOMPLETE_USER+":"+prefix, 0, int64(consts.AUTOCOMPLETE_PREFIX_CAPACITY-1))
+	queries := pipe.ZRevRangeByScore(ctx, consts.REDIS_AUTOCOMPLETE_QUERY+":"+prefix, &redis.ZRangeBy{
+		Min:   strconv.Itoa(consts.AUTOCOMPLETE_QUERY_MIN_COUNT),
+		Max:   "+inf",
+		Count: int64(length),
+	})
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return types.AutocompleteSuggestions{}, err
+	}
+
+	suggestions := types.AutocompleteSuggestions{
+		Hashtags: hashtags.Val(),
+		Queries:  queries.Val(),
+	}
+	if postIDs := titles.Val(); len(postIDs) > 0 {
+		profiles, err := store.rds.HMGet(ctx, consts.REDIS_AUTOCOMPLETE_TITLE_POST, postIDs...).Result()
+		if err != nil {
+			return types.AutocompleteSuggestions{}, err
+		}
+		for index, member := range postIDs {
+			profile, ok := profiles[index].(string)
+			if !ok {
+				continue
+			}
+			postID, err := strconv.ParseUint(member, 10, 64)
+			if err != nil {
+				continue
+			}
+			author, title, _ := strings.Cut(profile, "\n")
+			uid, err := strconv.ParseUint(author, 10, 64)
+			if err != nil {
+				continue
+			}
+			suggestions.Titles = append(suggestions.Titles, types.TitleSuggestion{PostID: postID, UID: uid, Title: title})
+		}
+	}
+
+	members := users.Val()
+	if len(members) == 0 {
+		return suggestions, nil
+	}
+	profiles, err := store.rds.HMGet(ctx, consts.REDIS_AUTOCOMPLETE_USER_PROFILE, members...).Result()
+	if err != nil {
+		return types.AutocompleteSuggestions{}, err
+	}
+	for index, member := range members {
+		profile, ok := profiles[index].(string)
+		if !ok {
+			continue
+		}
+		uid, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		username, nickname, _ := strings.Cut(profile, "\n")
+		suggestions.Users = append(suggestions.Users, types.UserSuggestion{UID: uid, UserName: username, NickName: nickname})
+	}
+	return suggestions, nil
+}
+
+// recentSearchKey 获取用户最近搜索的缓存键
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - string：缓存键
+func recentSearchKey(uid uint64) string {
+	return consts.REDIS_SEARCH_RECENT + ":" + strconv.FormatUint(uid, 10)
+}
+
+// GetRecentSearches 获取用户的最近搜索
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []types.RecentSearch：按时间倒序排列的最近搜索
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) GetRecentSearches(uid uint64) ([]types.RecentSearch, error) {
+	members, err := store.rds.ZRevRangeWithScores(context.Background(), recentSearchKey(uid), 0, consts.SEARCH_RECENT_MAX_LENGTH-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	searches := make([]types.RecentSearch, 0, len(members))
+	for _, member := range members {
+		query, ok := member.Member.(string)
+		if !ok {
+			continue
+		}
+		searches = append(searches, types.RecentSearch{Query: query, SearchedAt: time.UnixMilli(int64(member.Score))})
+	}
+	return searches, nil
+}
+
+// ClearRecentSearches 清除用户的最近搜索
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) ClearRecentSearches(uid uint64) error {
+	return store.rds.Del(context.Background(), recentSearchKey(uid)).Err()
+}
+
+// GetPrivacySetting 获取用户的搜索隐私设置，没有记录时返回默认设置
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - models.SearchPrivacySetting：搜索隐私设置
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) GetPrivacySetting(uid uint64) (models.SearchPrivacySetting, error) {
+	setting := models.SearchPrivacySetting{UID: uid, SaveHistory: true, ShareQueries: true}
+	err := store.db.Where("uid = ?", uid).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return setting, nil
+	}
+	return setting, err
+}
+
+// SavePrivacySetting 保存用户的搜索隐私设置
+//
+// 参数：
+//   - setting：搜索隐私设置
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) SavePrivacySetting(setting models.SearchPrivacySetting) error {
+	setting.UpdatedAt = time.Now()
+	return store.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&setting).Error
+}
+
+// RebuildAutocomplete 根据数据库重建用户、话题和博文标题的前缀索引，话题使用次数取自话题搜索索引
+//
+// 参数：
+//   - batchSize：每批处理的数量
+//
+// 返回值：
+//   - int64：写入前缀索引的条目数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *AutocompleteStore) RebuildAutocomplete(batchSize int) (int64, error) {
+	var indexed int64
+
+	// 用户
+	var afterID uint
+	for {
+		var users []models.UserInfo
+		result := store.db.Select("id", "username", "nickname").Where("id > ?", afterID).Order("id asc").Limit(batchSize).Find(&users)
+		if result.Error != nil {
+			return indexed, result.Error
+		}
+		for _, user := range users {
+			var nickname string
+			if user.NickName != nil {
+				nickname = *user.NickName
+			}
+			if err := store.IndexUser(uint64(user.ID), user.UserName, nickname, ""); err != nil {
+				return indexed, err
+			}
+		}
+		indexed += int64(len(users))
+		if len(users) < batchSize {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+
+	// 话题
+	ctx := context.Background()
+	var hashtags []models.HashtagSearchIndex
+	if err := store.db.Where("post_count > 0").Find(&hashtags).Error; err != nil {
+		return indexed, err
+	}
+	for _, hashtag := range hashtags {
+		pipe := store.rds.Pipeline()
+		for key := range nameKeys(consts.REDIS_AUTOCOMPLETE_HASHTAG, hashtag.Name) {
+			pipe.ZAdd(ctx, key, redis.Z{Score: float64(hashtag.PostCount), Member: hashtag.Name})
+			trimPrefixKey(ctx, pipe, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return indexed, err
+		}
+	}
+	indexed += int64(len(hashtags))
+
+	// 公开博文标题，私密账号和被限流作者的标题在获取建议时排除
+	afterID = 0
+	for {
+		var posts []models.PostInfo
+		result := store.db.Select("id", "uid", "title", "created_at").
+			Where("is_public = ? AND id > ?", true, afterID).
+			Order("id asc").
+			Limit(batchSize).
+			Find(&posts)
+		if result.Error != nil {
+			return indexed, result.Error
+		}
+		for _, post := range posts {
+			if err := store.IndexPostTitle(uint64(post.ID), post.UID, post.Title, post.CreatedAt); err != nil {
+				return indexed, err
+			}
+		}
+		indexed += int64(len(posts))
+		if len(posts) < batchSize {
+			break
+		}
+		afterID = posts[len(posts)-1].ID
+	}
+	return indexed, nil
+}
@@ -0,0 +1,414 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for block and mute storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+// BlockStore 拉黑与屏蔽信息数据库
+type BlockStore struct {
+	db    *gorm.DB
+	mongo *mongo.Client
+	rds   *redis.Client
+}
+
+// NewBlockStore 返回一个新的拉黑存储实例。
+//
+// 返回：
+//   - *BlockStore: 返回一个指向新的拉黑存储实例的指针。
+func (factory *Factory) NewBlockStore() *BlockStore {
+	return &BlockStore{
+		db:    factory.db,
+		mongo: factory.mongo,
+		rds:   factory.rds,
+	}
+}
+
+// BlockUser 拉黑用户，并解除双方之间的关注关系和关注请求
+//
+// 参数：
+//   - uid：用户ID
+//   - blockedID：被拉黑用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) BlockUser(uid, blockedID uint64) error {
+	ctx := context.Background()
+
+	// 写入拉黑记录
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "blocked_id", Value: blockedID},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "blocked_at", Value: time.Now()},
+		}},
+	}
+	blockRecordCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.BLOCK_RECORD_COLLECTION)
+	_, err := blockRecordCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	// 删除双向关注记录
+	followFilter := bson.D{
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "uid", Value: uid}, {Key: "followed_id", Value: blockedID}},
+			bson.D{{Key: "uid", Value: blockedID}, {Key: "followed_id", Value: uid}},
+		}},
+	}
+	followRecordCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION)
+	_, err = followRecordCollection.DeleteMany(ctx, followFilter)
+	if err != nil {
+		return err
+	}
+
+	// 删除双向关注请求
+	followRequestCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_REQUEST_COLLECTION)
+	_, err = followRequestCollection.DeleteMany(ctx, followFilter)
+	return err
+}
+
+// CancelBlockUser 取消拉黑用户
+//
+// 参数：
+//   - uid：用户ID
+//   - blockedID：被拉黑用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) CancelBlockUser(uid, blockedID uint64) error {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "blocked_id", Value: blockedID},
+	}
+
+	blockRecordCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.BLOCK_RECORD_COLLECTION)
+	_, err := blockRecordCollection.DeleteOne(context.Background(), filter)
+	return err
+}
+
+// IsBlockedBetween 检查两个用户之间是否存在任一方向的拉黑关系
+//
+// 参数：
+//   - uid：用户ID
+//   - targetID：目标用户ID
+//
+// 返回值：
+//   - bool：存在拉黑关系返回 true
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) IsBlockedBetween(uid, targetID uint64) (bool, error) {
+	filter := bson.D{
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "uid", Value: uid}, {Key: "blocked_id", Value: targetID}},
+			bson.D{{Key: "uid", Value: targetID}, {Key: "blocked_id", Value: uid}},
+		}},
+	}
+
+	blockRecordCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.BLOCK_RECORD_COLLECTION)
+	count, err := blockRecordCollection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetBlockList 获取拉黑列表
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []models.BlockInfo：拉黑列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) GetBlockList(uid uint64) ([]models.BlockInfo, error) {
+	var blockInfos []models.BlockInfo
+	filter := bson.D{{Key: "uid", Value: uid}}
+	sort := bson.D{{Key: "blocked_at", Value: -1}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.BLOCK_RECORD_COLLECTION).Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &blockInfos); err != nil {
+		return nil, err
+	}
+	return blockInfos, nil
+}
+
+// getBlockerIDs 获取拉黑了用户的用户ID
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []uint64：拉黑了该用户的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) getBlockerIDs(uid uint64) ([]uint64, error) {
+	filter := bson.D{{Key: "blocked_id", Value: uid}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.BLOCK_RECORD_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blockInfos []models.BlockInfo
+	if err := cursor.All(ctx, &blockInfos); err != nil {
+		return nil, err
+	}
+	blockerIDs := make([]uint64, len(blockInfos))
+	for index, blockInfo := range blockInfos {
+		blockerIDs[index] = blockInfo.UserID
+	}
+	return blockerIDs, nil
+}
+
+// MuteUser 屏蔽用户
+//
+// 参数：
+//   - uid：用户ID
+//   - mutedID：被屏蔽用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) MuteUser(uid, mutedID uint64) error {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "muted_id", Value: mutedID},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "muted_at", Value: time.Now()},
+		}},
+	}
+
+	muteRecordCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.MUTE_RECORD_COLLECTION)
+	_, err := muteRecordCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// CancelMuteUser 取消屏蔽用户
+//
+// 参数：
+//   - uid：用户ID
+//   - mutedID：被屏蔽用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) CancelMuteUser(uid, mutedID uint64) error {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "muted_id", Value: mutedID},
+	}
+
+	muteRecordCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.MUTE_RECORD_COLLECTION)
+	_, err := muteRecordCollection.DeleteOne(context.Background(), filter)
+	return err
+}
+
+// GetMuteList 获取屏蔽列表
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []models.MuteInfo：屏蔽列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) GetMuteList(uid uint64) ([]models.MuteInfo, error) {
+	var muteInfos []models.MuteInfo
+	filter := bson.D{{Key: "uid", Value: uid}}
+	sort := bson.D{{Key: "muted_at", Value: -1}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.MUTE_RECORD_COLLECTION).Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &muteInfos); err != nil {
+		return nil, err
+	}
+	return muteInfos, nil
+}
+
+// IsShadowbanned 判断用户是否被限流
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - bool：用户是否被限流
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) IsShadowbanned(uid uint64) (bool, error) {
+	var count int64
+	result := store.db.Model(&models.UserInfo{}).Where("id = ? AND is_shadowbanned", uid).Count(&count)
+	return count > 0, result.Error
+}
+
+// GetShadowbannedUIDsAmong 获取给定用户中被限流的其他用户
+//
+// 参数：
+//   - uid：查看者ID，为 0 时表示匿名用户，被限流的查看者不会被排除
+//   - targetIDs：待检查的用户ID列表
+//
+// 返回值：
+//   - []uint64：被限流的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) GetShadowbannedUIDsAmong(uid uint64, targetIDs []uint64) ([]uint64, error) {
+	if len(targetIDs) == 0 {
+		return nil, nil
+	}
+	var shadowbannedUIDs []uint64
+	result := store.db.Model(&models.UserInfo{}).
+		Where("id IN ? AND id <> ? AND is_shadowbanned", targetIDs, uid).
+		Pluck("id", &shadowbannedUIDs)
+	return shadowbannedUIDs, result.Error
+}
+
+// GetHiddenUIDs 获取对用户不可见的用户ID，包括用户屏蔽和拉黑的用户以及拉黑了用户的用户。
+// 被限流的用户不在其中，查询时通过 HiddenAuthors 或 AuthorFilter 关联用户表排除，
+// 在内存中过滤时使用 GetShadowbannedUIDsAmong 排除
+//
+// 参数：
+//   - uid：用户ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - []uint64：不可见的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) GetHiddenUIDs(uid uint64) ([]uint64, error) {
+	if uid == 0 {
+		return nil, nil
+	}
+
+	muteInfos, err := store.GetMuteList(uid)
+	if err != nil {
+		return nil, err
+	}
+	blockInfos, err := store.GetBlockList(uid)
+	if err != nil {
+		return nil, err
+	}
+	blockerIDs, err := store.getBlockerIDs(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	hiddenUIDs := make([]uint64, 0, len(muteInfos)+len(blockInfos)+len(blockerIDs))
+	for _, muteInfo := range muteInfos {
+		hiddenUIDs = append(hiddenUIDs, muteInfo.MutedID)
+	}
+	for _, blockInfo := range blockInfos {
+		hiddenUIDs = append(hiddenUIDs, blockInfo.BlockedID)
+	}
+	hiddenUIDs = append(hiddenUIDs, blockerIDs...)
+	return hiddenUIDs, nil
+}
+
+// GetHiddenAuthors 获取对用户不可见的内容作者，用于在查询中排除屏蔽、拉黑和被限流的用户
+//
+// 参数：
+//   - uid：用户ID，为 0 时表示匿名用户
+//
+// 返回值：
+//   - HiddenAuthors：不可见的内容作者
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) GetHiddenAuthors(uid uint64) (HiddenAuthors, error) {
+	hiddenUIDs, err := store.GetHiddenUIDs(uid)
+	if err != nil {
+		return HiddenAuthors{}, err
+	}
+	return HiddenAuthors{ViewerUID: uid, UIDs: hiddenUIDs}, nil
+}
+
+// GetBlockedIDsAmong 获取用户在给定用户中拉黑了哪些用户
+//
+// 参数：
+//   - uid：用户ID
+//   - targetIDs：待检查的用户ID列表
+//
+// 返回值：
+//   - []uint64：已拉黑的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) GetBlockedIDsAmong(uid uint64, targetIDs []uint64) ([]uint64, error) {
+	if uid == 0 || len(targetIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "blocked_id", Value: bson.D{{Key: "$in", Value: targetIDs}}},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.BLOCK_RECORD_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blockInfos []models.BlockInfo
+	if err := cursor.All(ctx, &blockInfos); err != nil {
+		return nil, err
+	}
+	blockedIDs := make([]uint64, len(blockInfos))
+	for index, blockInfo := range blockInfos {
+		blockedIDs[index] = blockInfo.BlockedID
+	}
+	return blockedIDs, nil
+}
+
+// GetBlockerIDsAmong 获取给定用户中拉黑了用户的用户
+//
+// 参数：
+//   - uid：用户ID
+//   - targetIDs：待检查的用户ID列表
+//
+// 返回值：
+//   - []uint64：拉黑了该用户的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *BlockStore) GetBlockerIDsAmong(uid uint64, targetIDs []uint64) ([]uint64, error) {
+	if uid == 0 || len(targetIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.D{
+		{Key: "uid", Value: bson.D{{Key: "$in", Value: targetIDs}}},
+		{Key: "blocked_id", Value: uid},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.BLOCK_RECORD_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blockInfos []models.BlockInfo
+	if err := cursor.All(ctx, &blockInfos); err != nil {
+		return nil, err
+	}
+	blockerIDs := make([]uint64, len(blockInfos))
+	for index, blockInfo := range blockInfos {
+		blockerIDs[index] = blockInfo.UserID
+	}
+	return blockerIDs, nil
+}
@@ -23,8 +23,9 @@ import (
 
 // Comment 评论信息数据库
 type CommentStore struct {
-	db    *gorm.DB
-	mongo *mongo.Client
+	db         *gorm.DB
+	mongo      *mongo.Client
+	scoreCache scoreCache
 }
 
 // NewCommentStore 返回一个新的用户存储实例。
@@ -32,19 +33,23 @@ type CommentStore struct {
 //   - *CommentStore: 返回一个指向新的用户存储实例的指针。
 func (factory *Factory) NewCommentStore() *CommentStore {
 	return &CommentStore{
-		factory.db,
-		factory.mongo,
+		db:    factory.db,
+		mongo: factory.mongo,
+		scoreCache: scoreCache{
+			rds:    factory.rds,
+			prefix: consts.REDIS_COMMENT_SCORE_CACHE,
+		},
 	}
 }
 
 // NewCommentStore 存储comment
 //
-// 参数 ：- uid：用户id，- username: 用户名，- postID: 博文id，- content: 博文内容
+// 参数 ：- uid：用户id，- username: 用户名，- postID: 博文id，- content: 博文内容，- hold: 暂扣信息，为 nil 时直接发布
 //
 // 返回：
 //
 //	-error 正确返回nil
-func (store *CommentStore) CreateComment(uid uint64, username string, postID uint64, content string) (uint64, error) {
+func (store *CommentStore) CreateComment(uid uint64, username string, postID uint64, content string, hold *ReviewHold) (uint64, error) {
 	newComment := models.CommentInfo{
 		PostID:   postID,
 		Username: username,
@@ -55,9 +60,22 @@ func (store *CommentStore) CreateComment(uid uint64, username string, postID uin
 		IsPublic: true,
 	}
 
-	result := store.db.Create(&newComment)
-	if result.Error != nil {
-		return 0, result.Error
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newComment).Error; err != nil {
+			return err
+		}
+		if hold != nil {
+			return holdForReview(tx, consts.REPORT_TARGET_COMMENT, uint64(newComment.ID), uid, hold)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 将新评论加入排序得分缓存
+	if err := store.scoreCache.update(postID, ratingCount{ID: uint64(newComment.ID)}); err != nil {
+		return 0, err
 	}
 	return uint64(newComment.ID), nil
 }
@@ -87,22 +105,28 @@ func (store *CommentStore) ValidateCommentExistence(commentID uint64) (bool, err
 //	参数：
 //	- commentID: 评论ID
 //	- content: 修改内容
+//	- hold: 暂扣信息，为 nil 时直接生效，否则评论在同一事务中被暂扣至审核通过
 //
 // 返回值：
 //   - error：如果评论存在返回true，不存在判断具体的错误类型返回false
-func (store *CommentStore) UpdateComment(commentID uint64, content string) error {
-	commentInfo := new(models.CommentInfo)
-	result := store.db.Where("id = ?", commentID).First(commentInfo)
-	if result.Error != nil {
-		return result.Error
-	}
+func (store *CommentStore) UpdateComment(commentID uint64, content string, hold *ReviewHold) error {
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		commentInfo := new(models.CommentInfo)
+		result := tx.Where("id = ?", commentID).First(commentInfo)
+		if result.Error != nil {
+			return result.Error
+		}
 
-	commentInfo.Content = content
-	result = store.db.Save(commentInfo)
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+		commentInfo.Content = content
+		result = tx.Save(commentInfo)
+		if result.Error != nil {
+			return result.Error
+		}
+		if hold != nil {
+			return holdForReview(tx, consts.REPORT_TARGET_COMMENT, commentID, commentInfo.UID, hold)
+		}
+		return nil
+	})
 }
 
 // DeleteComment 删除评论
@@ -113,23 +137,314 @@ func (store *CommentStore) UpdateComment(commentID uint64, content string) error
 // 返回值：
 //   - error：返回删除处理的成功与否
 func (store *CommentStore) DeleteComment(commentID uint64) error {
-	return store.db.Where("id = ?", commentID).Unscoped().Delete(&models.CommentInfo{}).Error
+	comment, err := store.GetComment(commentID)
+	if err != nil {
+		return err
+	}
+	if err := store.db.Where("id = ?", commentID).Unscoped().Delete(&models.CommentInfo{}).Error; err != nil {
+		return err
+	}
+	return store.ClearCommentRecords(comment.PostID, commentID)
 }
 
-// GetCommentList 获取评论列表
+// ClearCommentRecords 清理已删除评论在数据库之外的关联数据
+//
+// 参数：
+//   - postID：博文ID
+//   - commentID：评论ID
 //
 // 返回值：
-//   - 成功则返回评论列表
-//   - 失败返回nil
-func (store *CommentStore) GetCommentList(postID uint64) ([]models.CommentInfo, error) {
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) ClearCommentRecords(postID, commentID uint64) error {
+	// 从排序得分缓存中移除
+	return store.scoreCache.remove(postID, commentID)
+}
+
+// GetCommentListByID 按评论ID分页获取评论列表
+//
+// 参数：
+//   - postID：博文ID
+//   - fromID：上一页最后一条评论的ID，为 0 时获取第一页
+//   - ascending：是否按ID正序排列
+//   - length：获取数量
+//   - hidden：对查看者不可见的评论者
+//
+// 返回值：
+//   - []models.CommentInfo：评论列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) GetCommentListByID(postID, fromID uint64, ascending bool, length int, hidden HiddenAuthors) ([]models.CommentInfo, error) {
 	var commentInfos []models.CommentInfo
-	result := store.db.Where("post_id = ?", postID).Order("id desc").Find(&commentInfos)
+	query := store.db.Where("post_id = ?", postID).Limit(length)
+	if ascending {
+		query = query.Order("id asc")
+		if fromID != 0 {
+			query = query.Where("id > ?", fromID)
+		}
+	} else {
+		query = query.Order("id desc")
+		if fromID != 0 {
+			query = query.Where("id < ?", fromID)
+		}
+	}
+	query = hidden.apply(query, "comment_infos.uid")
+	result := query.Find(&commentInfos)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return commentInfos, nil
 }
 
+// GetCommentIDsByScore 按缓存的排序得分分页获取评论ID，缓存不存在时重建
+//
+// 参数：
+//   - postID：博文ID
+//   - mode：排序方式，top 或 hot
+//   - fromScore：上一页最后一条评论的得分
+//   - fromID：上一页最后一条评论的ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []uint64：评论ID列表
+//   - []float64：对应的得分
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) GetCommentIDsByScore(postID uint64, mode string, fromScore float64, fromID uint64, length int) ([]uint64, []float64, error) {
+	exists, err := store.scoreCache.exists(mode, postID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		counts, err := store.getCommentRatingCounts(postID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := store.scoreCache.rebuild(postID, counts); err != nil {
+			return nil, nil, err
+		}
+	}
+	return store.scoreCache.page(mode, postID, fromScore, fromID, length)
+}
+
+// FilterCommentIDs 过滤已删除及评论者对查看者不可见的评论，保持原有顺序
+//
+// 参数：
+//   - commentIDs：评论ID列表
+//   - hidden：对查看者不可见的评论者
+//
+// 返回值：
+//   - []uint64：过滤后的评论ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) FilterCommentIDs(commentIDs []uint64, hidden HiddenAuthors) ([]uint64, error) {
+	if len(commentIDs) == 0 {
+		return commentIDs, nil
+	}
+
+	var keptIDs []uint64
+	query := store.db.Model(&models.CommentInfo{}).Where("id IN ?", commentIDs)
+	query = hidden.apply(query, "comment_infos.uid")
+	if result := query.Pluck("id", &keptIDs); result.Error != nil {
+		return nil, result.Error
+	}
+
+	kept := make(map[uint64]struct{}, len(keptIDs))
+	for _, id := range keptIDs {
+		kept[id] = struct{}{}
+	}
+	filtered := make([]uint64, 0, len(keptIDs))
+	for _, id := range commentIDs {
+		if _, ok := kept[id]; ok {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+// RefreshCommentScore 重新统计评论的点赞和点踩数并更新排序得分缓存
+//
+// 参数：
+//   - commentID：评论ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) RefreshCommentScore(commentID uint64) error {
+	comment, err := store.GetComment(commentID)
+	if err != nil {
+		return err
+	}
+
+	commentRateCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.COMMENT_RATE_COLLECTION)
+	ctx := context.Background()
+	count := ratingCount{ID: commentID}
+	count.Likes, err = commentRateCollection.CountDocuments(ctx, bson.D{
+		{Key: "comment_id", Value: commentID},
+		{Key: "rate", Value: "like"},
+	})
+	if err != nil {
+		return err
+	}
+	count.Dislikes, err = commentRateCollection.CountDocuments(ctx, bson.D{
+		{Key: "comment_id", Value: commentID},
+		{Key: "rate", Value: "dislike"},
+	})
+	if err != nil {
+		return err
+	}
+
+	return store.scoreCache.update(comment.PostID, count)
+}
+
+// getCommentRatingCounts 统计博文下所有评论的点赞和点踩数
+//
+// 参数：
+//   - postID：博文ID
+//
+// 返回值：
+//   - []ratingCount：评价计数
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) getCommentRatingCounts(postID uint64) ([]ratingCount, error) {
+	var commentIDs []uint64
+	result := store.db.Model(&models.CommentInfo{}).Where("post_id = ?", postID).Pluck("id", &commentIDs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(commentIDs) == 0 {
+		return nil, nil
+	}
+
+	return countRatings(store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.COMMENT_RATE_COLLECTION), "comment_id", commentIDs)
+}
+
+// GetComment 获取评论基本信息，不包含点赞计数
+//
+// 参数：
+//   - commentID：评论ID
+//
+// 返回值：
+//   - models.CommentInfo：成功返回评论信息
+//   - error：失败返回error
+func (store *CommentStore) GetComment(commentID uint64) (models.CommentInfo, error) {
+	var comment models.CommentInfo
+	result := store.db.Where("id = ?", commentID).First(&comment)
+	if result.Error != nil {
+		return models.CommentInfo{}, result.Error
+	}
+	return comment, nil
+}
+
+// GetCommentsByIDs 批量获取评论
+//
+// 参数：
+//   - commentIDs：评论ID列表
+//
+// 返回值：
+//   - []models.CommentInfo：评论列表，顺序不保证与参数一致
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) GetCommentsByIDs(commentIDs []uint64) ([]models.CommentInfo, error) {
+	if len(commentIDs) == 0 {
+		return nil, nil
+	}
+	var comments []models.CommentInfo
+	result := store.db.Where("id IN ?", commentIDs).Find(&comments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return comments, nil
+}
+
+// CountCommentsByPostIDs 批量统计博文的评论数
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]int64：博文ID到评论数的映射，没有评论的博文不在映射中
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) CountCommentsByPostIDs(postIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		PostID uint64
+		Count  int64
+	}
+	result := store.db.Model(&models.CommentInfo{}).
+		Select("post_id, COUNT(*) AS count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		counts[row.PostID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetCommentRatingCounts 批量统计评论的点赞和点踩数
+//
+// 参数：
+//   - commentIDs：评论ID列表
+//
+// 返回值：
+//   - map[uint64]int64：评论ID到点赞数的映射
+//   - map[uint64]int64：评论ID到点踩数的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) GetCommentRatingCounts(commentIDs []uint64) (map[uint64]int64, map[uint64]int64, error) {
+	likes := make(map[uint64]int64, len(commentIDs))
+	dislikes := make(map[uint64]int64, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return likes, dislikes, nil
+	}
+	counts, err := countRatings(store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.COMMENT_RATE_COLLECTION), "comment_id", commentIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, count := range counts {
+		likes[count.ID] = count.Likes
+		dislikes[count.ID] = count.Dislikes
+	}
+	return likes, dislikes, nil
+}
+
+// GetCommentRatesAmong 获取用户对给定评论的评价
+//
+// 参数：
+//   - uid：用户ID
+//   - commentIDs：评论ID列表
+//
+// 返回值：
+//   - map[uint64]string：评论ID到评价类型（like 或 dislike）的映射，未评价的评论不在映射中
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CommentStore) GetCommentRatesAmong(uid uint64, commentIDs []uint64) (map[uint64]string, error) {
+	rates := make(map[uint64]string, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return rates, nil
+	}
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "comment_id", Value: bson.D{{Key: "$in", Value: commentIDs}}},
+	}
+	ctx := context.Background()
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.COMMENT_RATE_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []struct {
+		CommentID uint64 `bson:"comment_id"`
+		Rate      string `bson:"rate"`
+	}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		rates[record.CommentID] = record.Rate
+	}
+	return rates, nil
+}
+
 // GetCommentInfo 获取评论信息
 //
 // 参数：
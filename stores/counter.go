@@ -0,0 +1,495 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for engagement counter storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+)
+
+// postCounterFields 博文计数缓存中的字段
+var postCounterFields = []string{
+	consts.POST_COUNTER_LIKES,
+	consts.POST_COUNTER_FAVOURITES,
+	consts.POST_COUNTER_REPOSTS,
+	consts.POST_COUNTER_COMMENTS,
+	consts.POST_COUNTER_VIEWS,
+}
+
+// incrCounterScript 缓存存在时原子地增加计数，标记待回写，记入当日有活动的博文及当日互动增量，缓存不存在时返回 0。
+// ARGV[2] 为当日互动增量，ARGV[7] 为计数增量，缓存刚从互动记录统计得到时计数已包含本次互动，计数增量为 0
+var incrCounterScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("HINCRBY", KEYS[1], ARGV[1], ARGV[7])
+redis.call("EXPIRE", KEYS[1], ARGV[3])
+redis.call("SADD", KEYS[2], ARGV[4])
+redis.call("SADD", KEYS[3], ARGV[4])
+redis.call("EXPIRE", KEYS[3], ARGV[5])
+redis.call("HINCRBY", KEYS[4], ARGV[6], ARGV[2])
+redis.call("EXPIRE", KEYS[4], ARGV[5])
+return 1
+`)
+
+// fillCounterScript 缓存不存在时写入全部计数，避免覆盖并发写入的增量
+var fillCounterScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+redis.call("HSET", KEYS[1], unpack(ARGV, 2))
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+return 1
+`)
+
+// repairCounterScript 缓存存在时以对账结果覆盖计数
+var repairCounterScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("HSET", KEYS[1], unpack(ARGV))
+return 1
+`)
+
+// CounterStore 互动计数存储，计数以 Redis 为准并异步回写 PostgreSQL
+type CounterStore struct {
+	db           *gorm.DB
+	rds          *redis.Client
+	postStore    *PostStore
+	commentStore *CommentStore
+}
+
+// NewCounterStore 返回一个新的互动计数存储实例。
+//
+// 返回：
+//   - *CounterStore: 返回一个指向新的互动计数存储实例的指针。
+func (factory *Factory) NewCounterStore() *CounterStore {
+	return &CounterStore{
+		db:           factory.db,
+		rds:          factory.rds,
+		postStore:    factory.NewPostStore(),
+		commentStore: factory.NewCommentStore(),
+	}
+}
+
+// postCounterKey 获取博文计数缓存的键
+//
+// 参数：
+//   - postID：博文ID
+//
+// 返回值：
+//   - string：缓存键
+func postCounterKey(postID uint64) string {
+	var sb strings.Builder
+	sb.WriteString(consts.REDIS_POST_COUNTER_CACHE)
+	sb.WriteString(":")
+	sb.WriteString(strconv.FormatUint(postID, 10))
+	return sb.String()
+}
+
+// IncrPostCounter 增加博文的某项计数，缓存不存在时先从数据库加载
+//
+// 参数：
+//   - postID：博文ID
+//   - field：计数字段
+//   - delta：增量，可以为负数
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) IncrPostCounter(postID uint64, field string, delta int64) error {
+	ctx := context.Background()
+	now := time.Now()
+	keys := []string{
+		postCounterKey(postID),
+		consts.REDIS_POST_COUNTER_DIRTY_SET,
+		analyticsKey(consts.REDIS_POST_ANALYTICS_ACTIVE, now),
+		analyticsKey(consts.REDIS_POST_ENGAGEMENTS, now),
+	}
+	counterDelta := delta
+	for attempt := 0; attempt < 2; attempt++ {
+		args := []interface{}{field, delta, consts.POST_COUNTER_CACHE_EXPIRE, postID, consts.POST_ANALYTICS_KEY_EXPIRE, engagementField(postID, field), counterDelta}
+		applied, err := incrCounterScript.Run(ctx, store.rds, keys, args...).Int()
+		if err != nil {
+			return err
+		}
+		if applied == 1 {
+			return nil
+		}
+		_, countedIDs, err := store.loadPostCounters([]uint64{postID})
+		if err != nil {
+			return err
+		}
+		// 从互动记录统计的计数已包含刚写入的点赞、收藏、转发或评论，不再重复增加，浏览数不在统计范围内
+		if _, ok := countedIDs[postID]; ok && field != consts.POST_COUNTER_VIEWS {
+			counterDelta = 0
+		}
+	}
+	return nil
+}
+
+// GetPostCounter 获取博文的互动计数
+//
+// 参数：
+//   - postID：博文ID
+//
+// 返回值：
+//   - models.PostCounter：博文的互动计数
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) GetPostCounter(postID uint64) (models.PostCounter, error) {
+	counters, err := store.GetPostCounters([]uint64{postID})
+	if err != nil {
+		return models.PostCounter{}, err
+	}
+	return counters[postID], nil
+}
+
+// GetPostCounters 批量获取博文的互动计数，未命中缓存的博文从数据库加载
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]models.PostCounter：博文ID到互动计数的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) GetPostCounters(postIDs []uint64) (map[uint64]models.PostCounter, error) {
+	counters := make(map[uint64]models.PostCounter, len(postIDs))
+	if len(postIDs) == 0 {
+		return counters, nil
+	}
+	cached, err := store.getCachedPostCounters(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	var missedIDs []uint64
+	for _, postID := range postIDs {
+		counter, ok := cached[postID]
+		if !ok {
+			missedIDs = append(missedIDs, postID)
+			continue
+		}
+		counters[postID] = counter
+	}
+
+	loaded, _, err := store.loadPostCounters(missedIDs)
+	if err != nil {
+		return nil, err
+	}
+	for postID, counter := range loaded {
+		counters[postID] = counter
+	}
+	return counters, nil
+}
+
+// DeletePostCounter 删除博文的互动计数
+//
+// 参数：
+//   - postID：博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) DeletePostCounter(postID uint64) error {
+	ctx := context.Background()
+	tx := store.rds.TxPipeline()
+	tx.Del(ctx, postCounterKey(postID))
+	tx.SRem(ctx, consts.REDIS_POST_COUNTER_DIRTY_SET, postID)
+	if _, err := tx.Exec(ctx); err != nil {
+		return err
+	}
+	return store.db.Where("post_id = ?", postID).Delete(&models.PostCounter{}).Error
+}
+
+// FlushPostCounters 将一批被修改过的博文计数回写数据库
+//
+// 参数：
+//   - limit：本批最多回写的博文数量
+//
+// 返回值：
+//   - int：本批回写的博文数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) FlushPostCounters(limit int) (int, error) {
+	ctx := context.Background()
+	members, err := store.rds.SPopN(ctx, consts.REDIS_POST_COUNTER_DIRTY_SET, int64(limit)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+	postIDs := make([]uint64, 0, len(members))
+	for _, member := range members {
+		postID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	cached, err := store.getCachedPostCounters(postIDs)
+	if err == nil {
+		err = store.savePostCounters(cached)
+	}
+	if err != nil {
+		// 回写失败时重新标记，等待下次回写
+		store.rds.SAdd(ctx, consts.REDIS_POST_COUNTER_DIRTY_SET, members)
+		return 0, err
+	}
+	return len(members), nil
+}
+
+// ReconcilePostCounters 以点赞、收藏、评论和转发记录为准校正一批博文的计数，浏览数没有明细记录因此不参与对账
+//
+// 参数：
+//   - afterID：上一批最后一篇博文的ID，为 0 时从头开始
+//   - limit：本批最多对账的博文数量
+//
+// 返回值：
+//   - uint64：本批最后一篇博文的ID，为 0 时表示已全部对账
+//   - int：本批被校正的博文数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) ReconcilePostCounters(afterID uint64, limit int) (uint64, int, error) {
+	var postIDs []uint64
+	result := store.db.Model(&models.PostInfo{}).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &postIDs)
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	if len(postIDs) == 0 {
+		return 0, 0, nil
+	}
+
+	current, err := store.GetPostCounters(postIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+	actual, err := store.countPostEngagements(postIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ctx := context.Background()
+	repaired := make(map[uint64]models.PostCounter)
+	for _, postID := range postIDs {
+		counter, expected := current[postID], actual[postID]
+		if counter.Likes == expected.Likes &&
+			counter.Favourites == expected.Favourites &&
+			counter.Reposts == expected.Reposts &&
+			counter.Comments == expected.Comments {
+			continue
+		}
+		expected.Views = counter.Views
+		repaired[postID] = expected
+		err := repairCounterScript.Run(ctx, store.rds, []string{postCounterKey(postID)},
+			consts.POST_COUNTER_LIKES, expected.Likes,
+			consts.POST_COUNTER_FAVOURITES, expected.Favourites,
+			consts.POST_COUNTER_REPOSTS, expected.Reposts,
+			consts.POST_COUNTER_COMMENTS, expected.Comments,
+		).Err()
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if err := store.savePostCounters(repaired); err != nil {
+		return 0, 0, err
+	}
+
+	lastID := postIDs[len(postIDs)-1]
+	if len(postIDs) < limit {
+		lastID = 0
+	}
+	return lastID, len(repaired), nil
+}
+
+// getCachedPostCounters 从缓存批量获取博文计数
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]models.PostCounter：命中缓存的博文ID到互动计数的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) getCachedPostCounters(postIDs []uint64) (map[uint64]models.PostCounter, error) {
+	ctx := context.Background()
+	pipe := store.rds.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(postIDs))
+	for index, postID := range postIDs {
+		cmds[index] = pipe.HGetAll(ctx, postCounterKey(postID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	counters := make(map[uint64]models.PostCounter, len(postIDs))
+	for index, postID := range postIDs {
+		values, err := cmds[index].Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		counter := models.PostCounter{PostID: postID}
+		counter.Likes, _ = strconv.ParseInt(values[consts.POST_COUNTER_LIKES], 10, 64)
+		counter.Favourites, _ = strconv.ParseInt(values[consts.POST_COUNTER_FAVOURITES], 10, 64)
+		counter.Reposts, _ = strconv.ParseInt(values[consts.POST_COUNTER_REPOSTS], 10, 64)
+		counter.Comments, _ = strconv.ParseInt(values[consts.POST_COUNTER_COMMENTS], 10, 64)
+		counter.Views, _ = strconv.ParseInt(values[consts.POST_COUNTER_VIEWS], 10, 64)
+		counters[postID] = counter
+	}
+	return counters, nil
+}
+
+// loadPostCounters 从数据库加载博文计数并写入缓存，数据库中没有计数的博文将根据记录重新统计
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]models.PostCounter：博文ID到互动计数的映射
+//   - map[uint64]struct{}：根据互动记录重新统计计数的博文ID集合
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) loadPostCounters(postIDs []uint64) (map[uint64]models.PostCounter, map[uint64]struct{}, error) {
+	counters := make(map[uint64]models.PostCounter, len(postIDs))
+	countedIDs := make(map[uint64]struct{})
+	if len(postIDs) == 0 {
+		return counters, countedIDs, nil
+	}
+	var rows []models.PostCounter
+	if err := store.db.Where("post_id IN ?", postIDs).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, row := range rows {
+		counters[row.PostID] = row
+	}
+
+	// 统计数据库中尚无计数的博文
+	var missingIDs []uint64
+	for _, postID := range postIDs {
+		if _, ok := counters[postID]; !ok {
+			missingIDs = append(missingIDs, postID)
+		}
+	}
+	if len(missingIDs) > 0 {
+		counted, err := store.countPostEngagements(missingIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows := counterList(counted)
+		result := store.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows)
+		if result.Error != nil {
+			return nil, nil, result.Error
+		}
+		for postID, counter := range counted {
+			counters[postID] = counter
+			countedIDs[postID] = struct{}{}
+		}
+	}
+
+	// 写入缓存
+	ctx := context.Background()
+	for postID, counter := range counters {
+		err := fillCounterScript.Run(ctx, store.rds, []string{postCounterKey(postID)},
+			consts.POST_COUNTER_CACHE_EXPIRE,
+			consts.POST_COUNTER_LIKES, counter.Likes,
+			consts.POST_COUNTER_FAVOURITES, counter.Favourites,
+			consts.POST_COUNTER_REPOSTS, counter.Reposts,
+			consts.POST_COUNTER_COMMENTS, counter.Comments,
+			consts.POST_COUNTER_VIEWS, counter.Views,
+		).Err()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return counters, countedIDs, nil
+}
+
+// savePostCounters 将博文计数写入数据库
+//
+// 参数：
+//   - counters：博文ID到互动计数的映射
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) savePostCounters(counters map[uint64]models.PostCounter) error {
+	if len(counters) == 0 {
+		return nil
+	}
+	rows := counterList(counters)
+	now := time.Now()
+	for index := range rows {
+		rows[index].UpdatedAt = now
+	}
+	return store.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns(append(postCounterFields, "updated_at")),
+	}).Create(&rows).Error
+}
+
+// countPostEngagements 根据点赞、收藏、评论和转发记录统计博文计数
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]models.PostCounter：博文ID到互动计数的映射，浏览数为 0
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *CounterStore) countPostEngagements(postIDs []uint64) (map[uint64]models.PostCounter, error) {
+	likes, err := store.postStore.CountPostLikes(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	favourites, err := store.postStore.CountPostFavourites(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	reposts, err := store.postStore.CountPostReposts(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := store.commentStore.CountCommentsByPostIDs(postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[uint64]models.PostCounter, len(postIDs))
+	for _, postID := range postIDs {
+		counters[postID] = models.PostCounter{
+			PostID:     postID,
+			Likes:      likes[postID],
+			Favourites: favourites[postID],
+			Reposts:    reposts[postID],
+			Comments:   comments[postID],
+		}
+	}
+	return counters, nil
+}
+
+// counterList 将博文计数映射转换为列表
+//
+// 参数：
+//   - counters：博文ID到互动计数的映射
+//
+// 返回值：
+//   - []models.PostCounter：互动计数列表
+func counterList(counters map[uint64]models.PostCounter) []models.PostCounter {
+	rows := make([]models.PostCounter, 0, len(counters))
+	for _, counter := range counters {
+		rows = append(rows, counter)
+	}
+	return rows
+}
@@ -7,24 +7,27 @@ Copyright (c) [2024], Author(s):
 package stores
 
 import (
-	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
+	"github.com/Kirisakiii/neko-micro-blog-backend/engines"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/filters"
 	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
 )
 
 type Factory struct {
-	db            *gorm.DB
-	rds           *redis.Client
-	mongo         *mongo.Client
-	searchService search.SearchEngineClient
+	db           *gorm.DB
+	rds          *redis.Client
+	mongo        *mongo.Client
+	searchEngine engines.SearchEngine
+	sensitive    *filters.SensitiveFilter
 }
 
-func NewFactory(db *gorm.DB, redisClient *redis.Client, mongoClient *mongo.Client, searchService search.SearchEngineClient) *Factory {
+func NewFactory(db *gorm.DB, redisClient *redis.Client, mongoClient *mongo.Client, searchEngine engines.SearchEngine, sensitiveFilter *filters.SensitiveFilter) *Factory {
 	return &Factory{
-		db:            db,
-		rds:           redisClient,
-		mongo:         mongoClient,
-		searchService: searchService,
+		db:           db,
+		rds:          redisClient,
+		mongo:        mongoClient,
+		searchEngine: searchEngine,
+		sensitive:    sensitiveFilter,
 	}
 }
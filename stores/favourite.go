@@ -0,0 +1,315 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for favourite folder storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+)
+
+// FavouriteStore 收藏夹存储，收藏夹保存在 PostgreSQL 中，收藏记录保存在 MongoDB 中
+type FavouriteStore struct {
+	db    *gorm.DB
+	mongo *mongo.Client
+}
+
+// NewFavouriteStore 返回一个新的收藏夹存储实例。
+//
+// 返回：
+//   - *FavouriteStore: 返回一个指向新的收藏夹存储实例的指针。
+func (factory *Factory) NewFavouriteStore() *FavouriteStore {
+	return &FavouriteStore{
+		db:    factory.db,
+		mongo: factory.mongo,
+	}
+}
+
+// favouriteCollection 获取收藏记录集合
+//
+// 返回值：
+//   - *mongo.Collection：收藏记录集合
+func (store *FavouriteStore) favouriteCollection() *mongo.Collection {
+	return store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_FAVORITE_COLLECTION)
+}
+
+// folderFilter 获取匹配收藏夹中收藏记录的过滤条件，旧的收藏记录没有收藏夹字段，视为默认收藏夹
+//
+// 参数：
+//   - folderID：收藏夹ID
+//
+// 返回值：
+//   - interface{}：收藏夹字段的过滤条件
+func folderFilter(folderID uint64) interface{} {
+	if folderID == consts.FAVOURITE_DEFAULT_FOLDER_ID {
+		return bson.D{{Key: "$in", Value: bson.A{consts.FAVOURITE_DEFAULT_FOLDER_ID, nil}}}
+	}
+	return folderID
+}
+
+// CreateFolder 创建收藏夹
+//
+// 参数：
+//   - ownerUID：创建者ID
+//   - name：收藏夹名称
+//   - description：收藏夹描述
+//   - isPrivate：是否为私密收藏夹
+//
+// 返回值：
+//   - models.FavouriteFolderInfo：创建的收藏夹信息
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) CreateFolder(ownerUID uint64, name, description string, isPrivate bool) (models.FavouriteFolderInfo, error) {
+	folderInfo := models.FavouriteFolderInfo{
+		OwnerUID:    ownerUID,
+		Name:        name,
+		Description: description,
+		IsPrivate:   isPrivate,
+	}
+	result := store.db.Create(&folderInfo)
+	return folderInfo, result.Error
+}
+
+// GetFolder 获取收藏夹信息
+//
+// 参数：
+//   - folderID：收藏夹ID
+//
+// 返回值：
+//   - models.FavouriteFolderInfo：收藏夹信息
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) GetFolder(folderID uint64) (models.FavouriteFolderInfo, error) {
+	var folderInfo models.FavouriteFolderInfo
+	result := store.db.Where("id = ?", folderID).First(&folderInfo)
+	return folderInfo, result.Error
+}
+
+// GetFoldersByOwner 获取用户创建的收藏夹
+//
+// 参数：
+//   - ownerUID：创建者ID
+//   - includePrivate：是否包含私密收藏夹
+//
+// 返回值：
+//   - []models.FavouriteFolderInfo：按创建时间正序排列的收藏夹信息
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) GetFoldersByOwner(ownerUID uint64, includePrivate bool) ([]models.FavouriteFolderInfo, error) {
+	var folderInfos []models.FavouriteFolderInfo
+	query := store.db.Where("owner_uid = ?", ownerUID).Order("id asc")
+	if !includePrivate {
+		query = query.Where("is_private = ?", false)
+	}
+	result := query.Find(&folderInfos)
+	return folderInfos, result.Error
+}
+
+// GetPrivateFolderIDs 获取用户的私密收藏夹ID
+//
+// 参数：
+//   - ownerUID：创建者ID
+//
+// 返回值：
+//   - []uint64：私密收藏夹ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) GetPrivateFolderIDs(ownerUID uint64) ([]uint64, error) {
+	var folderIDs []uint64
+	result := store.db.Model(&models.FavouriteFolderInfo{}).
+		Where("owner_uid = ? AND is_private = ?", ownerUID, true).
+		Pluck("id", &folderIDs)
+	return folderIDs, result.Error
+}
+
+// CountFoldersByOwner 获取用户创建的收藏夹数量
+//
+// 参数：
+//   - ownerUID：创建者ID
+//
+// 返回值：
+//   - int64：收藏夹数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) CountFoldersByOwner(ownerUID uint64) (int64, error) {
+	var count int64
+	result := store.db.Model(&models.FavouriteFolderInfo{}).Where("owner_uid = ?", ownerUID).Count(&count)
+	return count, result.Error
+}
+
+// UpdateFolder 更新收藏夹信息
+//
+// 参数：
+//   - folderID：收藏夹ID
+//   - name：收藏夹名称
+//   - description：收藏夹描述
+//   - isPrivate：是否为私密收藏夹
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) UpdateFolder(folderID uint64, name, description string, isPrivate bool) error {
+	return store.db.Model(&models.FavouriteFolderInfo{}).Where("id = ?", folderID).Updates(map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"is_private":  isPrivate,
+	}).Error
+}
+
+// DeleteFolder 删除收藏夹，其中的收藏移回默认收藏夹
+//
+// 参数：
+//   - ownerUID：创建者ID
+//   - folderID：收藏夹ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) DeleteFolder(ownerUID, folderID uint64) error {
+	// 先移动收藏再删除收藏夹，中途失败时收藏夹仍然存在，可以重试
+	filter := bson.D{
+		{Key: "uid", Value: int64(ownerUID)},
+		{Key: "folder_id", Value: folderID},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "folder_id", Value: consts.FAVOURITE_DEFAULT_FOLDER_ID}}}}
+	if _, err := store.favouriteCollection().UpdateMany(context.Background(), filter, update); err != nil {
+		return err
+	}
+	return store.db.Where("id = ?", folderID).Unscoped().Delete(&models.FavouriteFolderInfo{}).Error
+}
+
+// CountFavouritesByFolder 统计用户每个收藏夹中的收藏数量
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - map[uint64]int64：收藏夹ID到收藏数量的映射，默认收藏夹的ID为 0
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) CountFavouritesByFolder(uid uint64) (map[uint64]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "uid", Value: int64(uid)}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$folder_id", consts.FAVOURITE_DEFAULT_FOLDER_ID}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.favouriteCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		FolderID uint64 `bson:"_id"`
+		Count    int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	counts := make(map[uint64]int64, len(results))
+	for _, result := range results {
+		counts[result.FolderID] += result.Count
+	}
+	return counts, nil
+}
+
+// MoveFavourite 将收藏移动到另一个收藏夹
+//
+// 参数：
+//   - uid：用户ID
+//   - postID：博文ID
+//   - folderID：目标收藏夹ID
+//
+// 返回值：
+//   - bool：是否存在对应的收藏记录
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) MoveFavourite(uid, postID, folderID uint64) (bool, error) {
+	filter := bson.D{
+		{Key: "uid", Value: int64(uid)},
+		{Key: "post_id", Value: int64(postID)},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "folder_id", Value: folderID}}}}
+	result, err := store.favouriteCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// UpdateFavouriteNote 更新收藏备注
+//
+// 参数：
+//   - uid：用户ID
+//   - postID：博文ID
+//   - note：收藏备注
+//
+// 返回值：
+//   - bool：是否存在对应的收藏记录
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) UpdateFavouriteNote(uid, postID uint64, note string) (bool, error) {
+	filter := bson.D{
+		{Key: "uid", Value: int64(uid)},
+		{Key: "post_id", Value: int64(postID)},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "note", Value: note}}}}
+	result, err := store.favouriteCollection().UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// GetFavouritePage 按收藏时间倒序分页获取收藏记录
+//
+// 参数：
+//   - uid：用户ID
+//   - folderID：收藏夹ID，为 nil 时获取所有收藏夹中的收藏
+//   - excludedFolderIDs：需要排除的收藏夹ID，仅在获取所有收藏夹时生效
+//   - fromTime：上一页最后一条记录的收藏时间
+//   - fromPostID：上一页最后一条记录的博文ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []models.PostFavouriteInfo：收藏记录
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FavouriteStore) GetFavouritePage(uid uint64, folderID *uint64, excludedFolderIDs []uint64, fromTime time.Time, fromPostID uint64, length int) ([]models.PostFavouriteInfo, error) {
+	filter := bson.D{{Key: "uid", Value: int64(uid)}}
+	if folderID != nil {
+		filter = append(filter, bson.E{Key: "folder_id", Value: folderFilter(*folderID)})
+	} else if len(excludedFolderIDs) > 0 {
+		filter = append(filter, bson.E{Key: "folder_id", Value: bson.D{{Key: "$nin", Value: excludedFolderIDs}}})
+	}
+	if fromPostID != 0 {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "favourited_at", Value: bson.D{{Key: "$lt", Value: fromTime}}}},
+			bson.D{
+				{Key: "favourited_at", Value: fromTime},
+				{Key: "post_id", Value: bson.D{{Key: "$lt", Value: int64(fromPostID)}}},
+			},
+		}})
+	}
+	sort := bson.D{
+		{Key: "favourited_at", Value: -1},
+		{Key: "post_id", Value: -1},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.favouriteCollection().Find(ctx, filter, options.Find().SetSort(sort).SetLimit(int64(length)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var favouriteInfos []models.PostFavouriteInfo
+	if err := cursor.All(ctx, &favouriteInfos); err != nil {
+		return nil, err
+	}
+	return favouriteInfos, nil
+}
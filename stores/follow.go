@@ -88,6 +88,13 @@ func (store *FollowStore) CancelFollowUser(uid, followedID uint64) error {
 	if err == mongo.ErrNoDocuments {
 		return errors.New("user has not liked this followed")
 	}
+	if err != nil {
+		return err
+	}
+
+	// 同时撤回未处理的关注请求
+	followRequestCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_REQUEST_COLLECTION)
+	_, err = followRequestCollection.DeleteOne(context.Background(), filter)
 	return err
 }
 
@@ -165,4 +172,418 @@ func (store *FollowStore) GetFollowersByUID(uid uint64) (int64, error) {
 		"followed_id": uid,
 	}
 	return store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION).CountDocuments(context.Background(), filter)
-}
\ No newline at end of file
+}
+
+// CreateFollowRequest 创建关注请求
+//
+// 参数：
+//   - uid：请求者ID
+//   - followedID：被请求关注用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) CreateFollowRequest(uid, followedID uint64) error {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "followed_id", Value: followedID},
+	}
+	update := bson.D{
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "requested_at", Value: time.Now()},
+		}},
+	}
+
+	followRequestCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_REQUEST_COLLECTION)
+	_, err := followRequestCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetFollowRequestList 获取待处理的关注请求列表
+//
+// 参数：
+//   - uid：被请求关注用户ID
+//
+// 返回值：
+//   - []models.FollowRequestInfo：关注请求列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) GetFollowRequestList(uid uint64) ([]models.FollowRequestInfo, error) {
+	var requestInfos []models.FollowRequestInfo
+	filter := bson.D{{Key: "followed_id", Value: uid}}
+	sort := bson.D{{Key: "requested_at", Value: -1}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_REQUEST_COLLECTION).Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &requestInfos); err != nil {
+		return nil, err
+	}
+	return requestInfos, nil
+}
+
+// ApproveFollowRequest 通过关注请求，先写入关注记录再删除请求，重复调用不会出错
+// 单机 MongoDB 不支持多文档事务，中途失败时请求仍保留，重试即可完成
+//
+// 参数：
+//   - uid：被请求关注用户ID
+//   - requesterID：请求者ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) ApproveFollowRequest(uid, requesterID uint64) error {
+	filter := bson.D{
+		{Key: "uid", Value: requesterID},
+		{Key: "followed_id", Value: uid},
+	}
+	database := store.mongo.Database(consts.MONGODB_DATABASE_NAME)
+	ctx := context.Background()
+
+	// 请求已不存在时，若关注记录已写入则视为已通过
+	requestCount, err := database.Collection(consts.FOLLOW_REQUEST_COLLECTION).CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if requestCount == 0 {
+		isFollowing, err := store.IsFollowing(requesterID, uid)
+		if err != nil {
+			return err
+		}
+		if !isFollowing {
+			return errors.New("follow request does not exist")
+		}
+		return nil
+	}
+
+	// 写入关注记录，重试时保留首次通过的时间
+	update := bson.D{
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "followed_at", Value: time.Now()},
+		}},
+	}
+	_, err = database.Collection(consts.FOLLOW_RECORD_COLLECTION).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	// 删除关注请求
+	_, err = database.Collection(consts.FOLLOW_REQUEST_COLLECTION).DeleteOne(ctx, filter)
+	return err
+}
+
+// RejectFollowRequest 拒绝关注请求
+//
+// 参数：
+//   - uid：被请求关注用户ID
+//   - requesterID：请求者ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) RejectFollowRequest(uid, requesterID uint64) error {
+	filter := bson.D{
+		{Key: "uid", Value: requesterID},
+		{Key: "followed_id", Value: uid},
+	}
+
+	followRequestCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_REQUEST_COLLECTION)
+	result, err := followRequestCollection.DeleteOne(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("follow request does not exist")
+	}
+	return nil
+}
+
+// ApproveAllFollowRequests 通过用户所有待处理的关注请求
+//
+// 参数：
+//   - uid：被请求关注用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) ApproveAllFollowRequests(uid uint64) error {
+	requestInfos, err := store.GetFollowRequestList(uid)
+	if err != nil {
+		return err
+	}
+
+	for _, requestInfo := range requestInfos {
+		if err := store.ApproveFollowRequest(uid, requestInfo.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsFollowing 检查用户是否关注了目标用户
+//
+// 参数：
+//   - uid：用户ID
+//   - followedID：目标用户ID
+//
+// 返回值：
+//   - bool：已关注返回 true
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) IsFollowing(uid, followedID uint64) (bool, error) {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "followed_id", Value: followedID},
+	}
+
+	count, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION).CountDocuments(context.Background(), filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsAccessible 检查查看者是否可以查看目标用户的内容
+// 公开账号对所有人可见，私密账号仅对本人和已关注者可见
+//
+// 参数：
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - uid：目标用户ID
+//
+// 返回值：
+//   - bool：可以查看返回 true
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) IsAccessible(viewerUID, uid uint64) (bool, error) {
+	if viewerUID == uid {
+		return true, nil
+	}
+
+	var user models.UserInfo
+	result := store.db.Select("id", "is_private").Where("id = ?", uid).First(&user)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if !user.IsPrivate {
+		return true, nil
+	}
+	if viewerUID == 0 {
+		return false, nil
+	}
+
+	return store.IsFollowing(viewerUID, uid)
+}
+
+// GetAuthorFilter 构建查看者的作者过滤条件，私密账号仅对本人和已关注者可见，被限流用户仅对本人可见
+//
+// 参数：
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - hiddenUIDs：需要排除的作者ID
+//
+// 返回值：
+//   - AuthorFilter：作者过滤条件
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) GetAuthorFilter(viewerUID uint64, hiddenUIDs []uint64) (AuthorFilter, error) {
+	filter := AuthorFilter{ViewerUID: viewerUID, HiddenUIDs: hiddenUIDs}
+	if viewerUID == 0 {
+		return filter, nil
+	}
+
+	followInfos, err := store.GetFollowList(viewerUID)
+	if err != nil {
+		return AuthorFilter{}, err
+	}
+	filter.AccessibleUIDs = make([]uint64, 0, len(followInfos)+1)
+	filter.AccessibleUIDs = append(filter.AccessibleUIDs, viewerUID)
+	for _, followInfo := range followInfos {
+		filter.AccessibleUIDs = append(filter.AccessibleUIDs, followInfo.FollowedID)
+	}
+	return filter, nil
+}
+
+// GetInaccessibleUIDsAmong 获取给定用户中查看者无权查看内容的私密账号ID
+//
+// 参数：
+//   - viewerUID：查看者ID，为 0 时表示匿名用户
+//   - uids：待检查的用户ID列表
+//
+// 返回值：
+//   - []uint64：无权查看的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) GetInaccessibleUIDsAmong(viewerUID uint64, uids []uint64) ([]uint64, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	var privateUIDs []uint64
+	result := store.db.Model(&models.UserInfo{}).Where("id IN ? AND is_private = ? AND id <> ?", uids, true, viewerUID).Pluck("id", &privateUIDs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(privateUIDs) == 0 || viewerUID == 0 {
+		return privateUIDs, nil
+	}
+
+	// 排除查看者已关注的私密账号
+	followedIDs, err := store.GetFollowedIDsAmong(viewerUID, privateUIDs)
+	if err != nil {
+		return nil, err
+	}
+	followed := make(map[uint64]struct{}, len(followedIDs))
+	for _, followedID := range followedIDs {
+		followed[followedID] = struct{}{}
+	}
+
+	inaccessibleUIDs := make([]uint64, 0, len(privateUIDs))
+	for _, uid := range privateUIDs {
+		if _, ok := followed[uid]; !ok {
+			inaccessibleUIDs = append(inaccessibleUIDs, uid)
+		}
+	}
+	return inaccessibleUIDs, nil
+}
+
+// getFollowPage 按关注时间倒序分页获取关注记录
+//
+// 参数：
+//   - field：用于匹配用户ID的字段
+//   - counterpartField：列表中对方用户ID的字段，用于区分关注时间相同的记录
+//   - uid：用户ID
+//   - fromTime：上一页最后一条记录的关注时间
+//   - fromID：上一页最后一条记录的对方用户ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []models.FollowInfo：关注记录列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) getFollowPage(field, counterpartField string, uid uint64, fromTime time.Time, fromID uint64, length int) ([]models.FollowInfo, error) {
+	filter := bson.D{{Key: field, Value: uid}}
+	if fromID != 0 {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "followed_at", Value: bson.D{{Key: "$lt", Value: fromTime}}}},
+			bson.D{
+				{Key: "followed_at", Value: fromTime},
+				{Key: counterpartField, Value: bson.D{{Key: "$lt", Value: fromID}}},
+			},
+		}})
+	}
+	sort := bson.D{
+		{Key: "followed_at", Value: -1},
+		{Key: counterpartField, Value: -1},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(sort).SetLimit(int64(length)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followInfos []models.FollowInfo
+	if err := cursor.All(ctx, &followInfos); err != nil {
+		return nil, err
+	}
+	return followInfos, nil
+}
+
+// GetFollowListPage 分页获取关注列表
+//
+// 参数：
+//   - uid：用户ID
+//   - fromTime：上一页最后一条记录的关注时间
+//   - fromID：上一页最后一条记录的被关注者ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []models.FollowInfo：关注列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) GetFollowListPage(uid uint64, fromTime time.Time, fromID uint64, length int) ([]models.FollowInfo, error) {
+	return store.getFollowPage("uid", "followed_id", uid, fromTime, fromID, length)
+}
+
+// GetFollowerListPage 分页获取粉丝列表
+//
+// 参数：
+//   - uid：用户ID
+//   - fromTime：上一页最后一条记录的关注时间
+//   - fromID：上一页最后一条记录的粉丝ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []models.FollowInfo：粉丝列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) GetFollowerListPage(uid uint64, fromTime time.Time, fromID uint64, length int) ([]models.FollowInfo, error) {
+	return store.getFollowPage("followed_id", "uid", uid, fromTime, fromID, length)
+}
+
+// GetFollowedIDsAmong 获取用户在给定用户中关注了哪些用户
+//
+// 参数：
+//   - uid：用户ID
+//   - targetIDs：待检查的用户ID列表
+//
+// 返回值：
+//   - []uint64：已关注的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) GetFollowedIDsAmong(uid uint64, targetIDs []uint64) ([]uint64, error) {
+	if len(targetIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "followed_id", Value: bson.D{{Key: "$in", Value: targetIDs}}},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followInfos []models.FollowInfo
+	if err := cursor.All(ctx, &followInfos); err != nil {
+		return nil, err
+	}
+	followedIDs := make([]uint64, len(followInfos))
+	for index, followInfo := range followInfos {
+		followedIDs[index] = followInfo.FollowedID
+	}
+	return followedIDs, nil
+}
+
+// GetFollowerIDsAmong 获取给定用户中有哪些用户关注了该用户
+//
+// 参数：
+//   - uid：用户ID
+//   - targetIDs：待检查的用户ID列表
+//
+// 返回值：
+//   - []uint64：关注了该用户的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *FollowStore) GetFollowerIDsAmong(uid uint64, targetIDs []uint64) ([]uint64, error) {
+	if len(targetIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.D{
+		{Key: "uid", Value: bson.D{{Key: "$in", Value: targetIDs}}},
+		{Key: "followed_id", Value: uid},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followInfos []models.FollowInfo
+	if err := cursor.All(ctx, &followInfos); err != nil {
+		return nil, err
+	}
+	followerIDs := make([]uint64, len(followInfos))
+	for index, followInfo := range followInfos {
+		followerIDs[index] = followInfo.UserID
+	}
+	return followerIDs, nil
+}
@@ -0,0 +1,58 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for MongoDB index management.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+)
+
+// mongoIndexes 各集合需要的索引，分页查询的过滤和排序字段均由索引覆盖，唯一索引保证每位用户对同一对象只有一条记录
+var mongoIndexes = map[string][]mongo.IndexModel{
+	consts.POST_LIKE_COLLECTION: {
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "liked_at", Value: -1}, {Key: "post_id", Value: -1}}},
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "post_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "post_id", Value: 1}}},
+	},
+	consts.POST_FAVORITE_COLLECTION: {
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "favourited_at", Value: -1}, {Key: "post_id", Value: -1}}},
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "folder_id", Value: 1}, {Key: "favourited_at", Value: -1}, {Key: "post_id", Value: -1}}},
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "post_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "post_id", Value: 1}}},
+	},
+	consts.REPLY_RATE_COLLECTION: {
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "reply_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "reply_id", Value: 1}}},
+	},
+	consts.FOLLOW_RECORD_COLLECTION: {
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "followed_at", Value: -1}, {Key: "followed_id", Value: -1}}},
+		{Keys: bson.D{{Key: "followed_id", Value: 1}, {Key: "followed_at", Value: -1}, {Key: "uid", Value: -1}}},
+	},
+	consts.BLOCK_RECORD_COLLECTION: {
+		{Keys: bson.D{{Key: "blocked_id", Value: 1}, {Key: "uid", Value: 1}}},
+	},
+}
+
+// EnsureIndexes 创建 MongoDB 集合的索引，已存在的索引不会重复创建
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (factory *Factory) EnsureIndexes() error {
+	ctx := context.Background()
+	database := factory.mongo.Database(consts.MONGODB_DATABASE_NAME)
+	for collection, indexes := range mongoIndexes {
+		if _, err := database.Collection(collection).Indexes().CreateMany(ctx, indexes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
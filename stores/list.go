@@ -0,0 +1,360 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for user list storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+)
+
+// UserListStore 用户列表数据库
+type UserListStore struct {
+	db    *gorm.DB
+	mongo *mongo.Client
+}
+
+// NewUserListStore 返回一个新的用户列表存储实例。
+//
+// 返回：
+//   - *UserListStore: 返回一个指向新的用户列表存储实例的指针。
+func (factory *Factory) NewUserListStore() *UserListStore {
+	return &UserListStore{
+		db:    factory.db,
+		mongo: factory.mongo,
+	}
+}
+
+// CreateList 创建用户列表
+//
+// 参数：
+//   - ownerUID：创建者ID
+//   - name：列表名称
+//   - description：列表描述
+//   - isPrivate：是否为私密列表
+//
+// 返回值：
+//   - models.UserListInfo：创建的列表信息
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) CreateList(ownerUID uint64, name, description string, isPrivate bool) (models.UserListInfo, error) {
+	listInfo := models.UserListInfo{
+		OwnerUID:    ownerUID,
+		Name:        name,
+		Description: description,
+		IsPrivate:   isPrivate,
+	}
+	result := store.db.Create(&listInfo)
+	return listInfo, result.Error
+}
+
+// GetList 获取用户列表信息
+//
+// 参数：
+//   - listID：列表ID
+//
+// 返回值：
+//   - models.UserListInfo：列表信息
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) GetList(listID uint64) (models.UserListInfo, error) {
+	var listInfo models.UserListInfo
+	result := store.db.Where("id = ?", listID).First(&listInfo)
+	return listInfo, result.Error
+}
+
+// GetListsByIDs 批量获取用户列表信息
+//
+// 参数：
+//   - listIDs：列表ID列表
+//
+// 返回值：
+//   - []models.UserListInfo：列表信息，顺序不保证与参数一致
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) GetListsByIDs(listIDs []uint64) ([]models.UserListInfo, error) {
+	if len(listIDs) == 0 {
+		return nil, nil
+	}
+	var listInfos []models.UserListInfo
+	result := store.db.Where("id IN ?", listIDs).Find(&listInfos)
+	return listInfos, result.Error
+}
+
+// GetListsByOwner 获取用户创建的列表
+//
+// 参数：
+//   - ownerUID：创建者ID
+//   - includePrivate：是否包含私密列表
+//
+// 返回值：
+//   - []models.UserListInfo：列表信息
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) GetListsByOwner(ownerUID uint64, includePrivate bool) ([]models.UserListInfo, error) {
+	var listInfos []models.UserListInfo
+	query := store.db.Where("owner_uid = ?", ownerUID).Order("id desc")
+	if !includePrivate {
+		query = query.Where("is_private = ?", false)
+	}
+	result := query.Find(&listInfos)
+	return listInfos, result.Error
+}
+
+// CountListsByOwner 获取用户创建的列表数量
+//
+// 参数：
+//   - ownerUID：创建者ID
+//
+// 返回值：
+//   - int64：列表数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) CountListsByOwner(ownerUID uint64) (int64, error) {
+	var count int64
+	result := store.db.Model(&models.UserListInfo{}).Where("owner_uid = ?", ownerUID).Count(&count)
+	return count, result.Error
+}
+
+// UpdateList 更新用户列表信息
+//
+// 参数：
+//   - listID：列表ID
+//   - name：列表名称
+//   - description：列表描述
+//   - isPrivate：是否为私密列表
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) UpdateList(listID uint64, name, description string, isPrivate bool) error {
+	return store.db.Model(&models.UserListInfo{}).Where("id = ?", listID).Updates(map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"is_private":  isPrivate,
+	}).Error
+}
+
+// DeleteList 删除用户列表及其成员和订阅记录
+//
+// 参数：
+//   - listID：列表ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) DeleteList(listID uint64) error {
+	if err := store.db.Where("id = ?", listID).Unscoped().Delete(&models.UserListInfo{}).Error; err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	filter := bson.D{{Key: "list_id", Value: listID}}
+	database := store.mongo.Database(consts.MONGODB_DATABASE_NAME)
+	if _, err := database.Collection(consts.USER_LIST_MEMBER_COLLECTION).DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	_, err := database.Collection(consts.USER_LIST_SUBSCRIPTION_COLLECTION).DeleteMany(ctx, filter)
+	return err
+}
+
+// AddMember 向列表中添加成员
+//
+// 参数：
+//   - listID：列表ID
+//   - uid：成员ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) AddMember(listID, uid uint64) error {
+	filter := bson.D{
+		{Key: "list_id", Value: listID},
+		{Key: "uid", Value: uid},
+	}
+	update := bson.D{
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "added_at", Value: time.Now()},
+		}},
+	}
+
+	memberCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_MEMBER_COLLECTION)
+	_, err := memberCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// RemoveMember 从列表中移除成员
+//
+// 参数：
+//   - listID：列表ID
+//   - uid：成员ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) RemoveMember(listID, uid uint64) error {
+	filter := bson.D{
+		{Key: "list_id", Value: listID},
+		{Key: "uid", Value: uid},
+	}
+
+	memberCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_MEMBER_COLLECTION)
+	_, err := memberCollection.DeleteOne(context.Background(), filter)
+	return err
+}
+
+// GetMemberIDs 获取列表成员ID
+//
+// 参数：
+//   - listID：列表ID
+//
+// 返回值：
+//   - []uint64：成员ID列表，按加入时间倒序排列
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) GetMemberIDs(listID uint64) ([]uint64, error) {
+	filter := bson.D{{Key: "list_id", Value: listID}}
+	sort := bson.D{{Key: "added_at", Value: -1}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_MEMBER_COLLECTION).Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var memberInfos []models.UserListMemberInfo
+	if err := cursor.All(ctx, &memberInfos); err != nil {
+		return nil, err
+	}
+	memberIDs := make([]uint64, len(memberInfos))
+	for index, memberInfo := range memberInfos {
+		memberIDs[index] = memberInfo.UserID
+	}
+	return memberIDs, nil
+}
+
+// CountMembers 获取列表成员数量
+//
+// 参数：
+//   - listID：列表ID
+//
+// 返回值：
+//   - int64：成员数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) CountMembers(listID uint64) (int64, error) {
+	filter := bson.D{{Key: "list_id", Value: listID}}
+	memberCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_MEMBER_COLLECTION)
+	return memberCollection.CountDocuments(context.Background(), filter)
+}
+
+// Subscribe 订阅列表
+//
+// 参数：
+//   - listID：列表ID
+//   - uid：订阅者ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) Subscribe(listID, uid uint64) error {
+	filter := bson.D{
+		{Key: "list_id", Value: listID},
+		{Key: "uid", Value: uid},
+	}
+	update := bson.D{
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "subscribed_at", Value: time.Now()},
+		}},
+	}
+
+	subscriptionCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_SUBSCRIPTION_COLLECTION)
+	_, err := subscriptionCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// Unsubscribe 取消订阅列表
+//
+// 参数：
+//   - listID：列表ID
+//   - uid：订阅者ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) Unsubscribe(listID, uid uint64) error {
+	filter := bson.D{
+		{Key: "list_id", Value: listID},
+		{Key: "uid", Value: uid},
+	}
+
+	subscriptionCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_SUBSCRIPTION_COLLECTION)
+	_, err := subscriptionCollection.DeleteOne(context.Background(), filter)
+	return err
+}
+
+// IsSubscribed 检查用户是否订阅了列表
+//
+// 参数：
+//   - listID：列表ID
+//   - uid：用户ID
+//
+// 返回值：
+//   - bool：已订阅返回 true
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) IsSubscribed(listID, uid uint64) (bool, error) {
+	filter := bson.D{
+		{Key: "list_id", Value: listID},
+		{Key: "uid", Value: uid},
+	}
+
+	subscriptionCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_SUBSCRIPTION_COLLECTION)
+	count, err := subscriptionCollection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountSubscribers 获取列表订阅者数量
+//
+// 参数：
+//   - listID：列表ID
+//
+// 返回值：
+//   - int64：订阅者数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) CountSubscribers(listID uint64) (int64, error) {
+	filter := bson.D{{Key: "list_id", Value: listID}}
+	subscriptionCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_SUBSCRIPTION_COLLECTION)
+	return subscriptionCollection.CountDocuments(context.Background(), filter)
+}
+
+// GetSubscribedListIDs 获取用户订阅的列表ID
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []uint64：列表ID列表，按订阅时间倒序排列
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserListStore) GetSubscribedListIDs(uid uint64) ([]uint64, error) {
+	filter := bson.D{{Key: "uid", Value: uid}}
+	sort := bson.D{{Key: "subscribed_at", Value: -1}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.USER_LIST_SUBSCRIPTION_COLLECTION).Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptionInfos []models.UserListSubscriptionInfo
+	if err := cursor.All(ctx, &subscriptionInfos); err != nil {
+		return nil, err
+	}
+	listIDs := make([]uint64, len(subscriptionInfos))
+	for index, subscriptionInfo := range subscriptionInfos {
+		listIDs[index] = subscriptionInfo.ListID
+	}
+	return listIDs, nil
+}
@@ -0,0 +1,649 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for report and moderation storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// ModerationStore 举报与审核信息数据库
+type ModerationStore struct {
+	db *gorm.DB
+}
+
+// NewModerationStore 返回一个新的举报与审核存储实例。
+//
+// 返回：
+//   - *ModerationStore: 返回一个指向新的举报与审核存储实例的指针。
+func (factory *Factory) NewModerationStore() *ModerationStore {
+	return &ModerationStore{
+		db: factory.db,
+	}
+}
+
+// contentModel 返回内容类型对应的模型
+//
+// 参数：
+//   - targetType：内容类型，post、comment 或 reply
+//
+// 返回值：
+//   - interface{}：模型指针
+//   - error：内容类型不合法时返回错误
+func contentModel(targetType string) (interface{}, error) {
+	switch targetType {
+	case consts.REPORT_TARGET_POST:
+		return &models.PostInfo{}, nil
+	case consts.REPORT_TARGET_COMMENT:
+		return &models.CommentInfo{}, nil
+	case consts.REPORT_TARGET_REPLY:
+		return &models.ReplyInfo{}, nil
+	default:
+		return nil, errors.New("invalid content type")
+	}
+}
+
+// GetTargetUID 获取举报或处理目标的作者ID
+//
+// 参数：
+//   - targetType：目标类型，post、comment、reply 或 user
+//   - targetID：目标ID
+//   - includeHidden：是否包含已被隐藏的内容
+//
+// 返回值：
+//   - uint64：目标作者ID，目标为用户时即用户ID
+//   - error：目标不存在时返回 gorm.ErrRecordNotFound
+func (store *ModerationStore) GetTargetUID(targetType string, targetID uint64, includeHidden bool) (uint64, error) {
+	if targetType == consts.REPORT_TARGET_USER {
+		var user models.UserInfo
+		if err := store.db.Select("id").Where("id = ?", targetID).First(&user).Error; err != nil {
+			return 0, err
+		}
+		return uint64(user.ID), nil
+	}
+
+	model, err := contentModel(targetType)
+	if err != nil {
+		return 0, err
+	}
+	query := store.db.Model(model)
+	if includeHidden {
+		query = query.Unscoped()
+	}
+	var uids []uint64
+	if err := query.Where("id = ?", targetID).Limit(1).Pluck("uid", &uids).Error; err != nil {
+		return 0, err
+	}
+	if len(uids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return uids[0], nil
+}
+
+// IsContentHidden 判断内容是否已被隐藏
+//
+// 参数：
+//   - targetType：内容类型，post、comment 或 reply
+//   - targetID：内容ID
+//
+// 返回值：
+//   - bool：内容是否已被隐藏
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) IsContentHidden(targetType string, targetID uint64) (bool, error) {
+	model, err := contentModel(targetType)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	err = store.db.Model(model).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", targetID).Count(&count).Error
+	return count > 0, err
+}
+
+// createReport 在事务中创建举报，同一目标的举报汇总到同一个待处理工单
+//
+// 参数：
+//   - tx：事务
+//   - report：举报记录，CaseID 由本方法填写
+//   - targetType：目标类型
+//   - targetID：目标ID
+//   - targetUID：目标作者ID
+//   - severity：举报原因的严重程度
+//
+// 返回值：
+//   - bool：是否新建了举报，举报人已举报过该工单时为 false
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func createReport(tx *gorm.DB, report *models.ContentReport, targetType string, targetID, targetUID uint64, severity int) (bool, error) {
+	// 获取或创建待处理工单，并发举报依赖部分唯一索引只创建一个工单
+	now := time.Now()
+	err := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "target_type"}, {Name: "target_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status = '" + consts.MODERATION_CASE_PENDING + "'"}}},
+		DoNothing:   true,
+	}).Create(&models.ModerationCase{
+		TargetType:     targetType,
+		TargetID:       targetID,
+		TargetUID:      targetUID,
+		Status:         consts.MODERATION_CASE_PENDING,
+		LastReportedAt: now,
+	}).Error
+	if err != nil {
+		return false, err
+	}
+	var moderationCase models.ModerationCase
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, consts.MODERATION_CASE_PENDING).
+		First(&moderationCase).Error
+	if err != nil {
+		return false, err
+	}
+
+	// 同一举报人对同一工单只记录一次
+	report.CaseID = moderationCase.ID
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	err = tx.Model(&models.ModerationCase{}).Where("id = ?", moderationCase.ID).Updates(map[string]interface{}{
+		"report_count":     gorm.Expr("report_count + 1"),
+		"severity":         gorm.Expr("GREATEST(severity, ?)", severity),
+		"last_reported_at": now,
+	}).Error
+	return err == nil, err
+}
+
+// CreateReport 创建举报，同一目标的举报汇总到同一个待处理工单
+//
+// 参数：
+//   - report：举报记录，CaseID 由本方法填写
+//   - targetType：目标类型
+//   - targetID：目标ID
+//   - targetUID：目标作者ID
+//   - severity：举报原因的严重程度
+//
+// 返回值：
+//   - bool：是否新建了举报，举报人已举报过该工单时为 false
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) CreateReport(report *models.ContentReport, targetType string, targetID, targetUID uint64, severity int) (bool, error) {
+	var created bool
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		created, err = createReport(tx, report, targetType, targetID, targetUID, severity)
+		return err
+	})
+	return created, err
+}
+
+// ReviewHold 命中待审核敏感词的内容的暂扣信息，内容在写入的同一事务中被隐藏并送入审核队列
+type ReviewHold struct {
+	Words []string // 命中的敏感词
+}
+
+// holdForReview 在事务中暂扣内容并送入审核队列，内容被隐藏直到版主恢复
+//
+// 参数：
+//   - tx：数据库事务
+//   - targetType：内容类型
+//   - targetID：内容ID
+//   - targetUID：内容作者ID
+//   - hold：暂扣信息
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func holdForReview(tx *gorm.DB, targetType string, targetID, targetUID uint64, hold *ReviewHold) error {
+	model, err := contentModel(targetType)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("id = ?", targetID).Delete(model).Error; err != nil {
+		return err
+	}
+
+	detail := strings.Join(hold.Words, ", ")
+	_, err = createReport(tx, &models.ContentReport{
+		Reason: consts.REPORT_REASON_SENSITIVE,
+		Detail: string([]rune(detail)[:min(utf8.RuneCountInString(detail), consts.REPORT_DETAIL_MAX_LENGTH)]),
+	}, targetType, targetID, targetUID, consts.SENSITIVE_REVIEW_SEVERITY)
+	if err != nil {
+		return err
+	}
+	return recordAction(tx, &models.ModerationLog{
+		Action:     consts.MODERATION_ACTION_HOLD,
+		TargetType: targetType,
+		TargetID:   targetID,
+		TargetUID:  targetUID,
+		Reason:     detail,
+	}, false)
+}
+
+// GetModerationQueue 获取审核工单列表，待处理工单按严重程度、举报人数倒序排列，其余按处理时间倒序排列
+//
+// 参数：
+//   - status：工单状态
+//   - offset：偏移量
+//   - limit：获取数量
+//
+// 返回值：
+//   - []models.ModerationCase：审核工单列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) GetModerationQueue(status string, offset, limit int) ([]models.ModerationCase, error) {
+	query := store.db.Where("status = ?", status)
+	if status == consts.MODERATION_CASE_PENDING {
+		query = query.Order("severity DESC").Order("report_count DESC").Order("id")
+	} else {
+		query = query.Order("resolved_at DESC").Order("id DESC")
+	}
+	var cases []models.ModerationCase
+	result := query.Offset(offset).Limit(limit).Find(&cases)
+	return cases, result.Error
+}
+
+// GetModerationCase 获取审核工单
+//
+// 参数：
+//   - caseID：工单ID
+//
+// 返回值：
+//   - models.ModerationCase：审核工单
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) GetModerationCase(caseID uint64) (models.ModerationCase, error) {
+	var moderationCase models.ModerationCase
+	result := store.db.Where("id = ?", caseID).First(&moderationCase)
+	return moderationCase, result.Error
+}
+
+// GetCaseReports 获取审核工单下的举报，按举报时间正序排列
+//
+// 参数：
+//   - caseID：工单ID
+//   - offset：偏移量
+//   - limit：获取数量
+//
+// 返回值：
+//   - []models.ContentReport：举报列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) GetCaseReports(caseID uint64, offset, limit int) ([]models.ContentReport, error) {
+	var reports []models.ContentReport
+	result := store.db.Where("case_id = ?", caseID).Order("id").Offset(offset).Limit(limit).Find(&reports)
+	return reports, result.Error
+}
+
+// recordAction 在事务中写入审核日志，并按需结束目标的待处理工单
+//
+// 参数：
+//   - tx：事务
+//   - entry：审核日志
+//   - resolve：是否结束目标的待处理工单
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func recordAction(tx *gorm.DB, entry *models.ModerationLog, resolve bool) error {
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+	if !resolve {
+		return nil
+	}
+
+	status := consts.MODERATION_CASE_RESOLVED
+	if entry.Action == consts.MODERATION_ACTION_DISMISS {
+		status = consts.MODERATION_CASE_DISMISSED
+	}
+	return tx.Model(&models.ModerationCase{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", entry.TargetType, entry.TargetID, consts.MODERATION_CASE_PENDING).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolution":  entry.Action,
+			"resolved_by": entry.ModeratorUID,
+			"resolved_at": entry.CreatedAt,
+		}).Error
+}
+
+// RecordAction 写入审核日志并结束目标的待处理工单，用于驳回举报或在删除内容之后记录
+//
+// 参数：
+//   - entry：审核日志
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) RecordAction(entry *models.ModerationLog) error {
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		return recordAction(tx, entry, true)
+	})
+}
+
+// HideContent 隐藏内容并记录审核日志，被隐藏的内容以软删除的形式保留，可被恢复
+//
+// 参数：
+//   - entry：审核日志
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) HideContent(entry *models.ModerationLog) error {
+	model, err := contentModel(entry.TargetType)
+	if err != nil {
+		return err
+	}
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", entry.TargetID).Delete(model).Error; err != nil {
+			return err
+		}
+		if entry.TargetType == consts.REPORT_TARGET_POST {
+			if err := enqueueSearchIndex(tx, entry.TargetID, consts.SEARCH_INDEX_OP_DELETE); err != nil {
+				return err
+			}
+		}
+		return recordAction(tx, entry, true)
+	})
+}
+
+// RestoreContent 恢复已隐藏的内容，记录日志时一并结束目标的待处理工单
+//
+// 参数：
+//   - targetType：内容类型
+//   - targetID：内容ID
+//   - entry：审核日志，为 nil 时不记录日志
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) RestoreContent(targetType string, targetID uint64, entry *models.ModerationLog) error {
+	model, err := contentModel(targetType)
+	if err != nil {
+		return err
+	}
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(model).Unscoped().Where("id = ?", targetID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if targetType == consts.REPORT_TARGET_POST {
+			if err := enqueueSearchIndex(tx, targetID, consts.SEARCH_INDEX_OP_CREATE); err != nil {
+				return err
+			}
+		}
+		if entry == nil {
+			return nil
+		}
+		return recordAction(tx, entry, true)
+	})
+}
+
+// DeleteContent 彻底删除内容（包括已隐藏的内容）并在同一事务中记录审核日志，
+// 博文会一并删除话题记录、扣减话题使用次数并写入删除索引操作
+//
+// 参数：
+//   - entry：审核日志
+//
+// 返回值：
+//   - interface{}：删除前的内容，博文、评论和回复分别为 *models.PostInfo、*models.CommentInfo 和 *models.ReplyInfo，
+//     已隐藏的内容 DeletedAt 有效
+//   - error：内容不存在时返回 gorm.ErrRecordNotFound
+func (store *ModerationStore) DeleteContent(entry *models.ModerationLog) (interface{}, error) {
+	model, err := contentModel(entry.TargetType)
+	if err != nil {
+		return nil, err
+	}
+	err = store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ?", entry.TargetID).First(model).Error; err != nil {
+			return err
+		}
+		if entry.TargetType == consts.REPORT_TARGET_POST {
+			if err := deletePostRecords(tx, entry.TargetID); err != nil {
+				return err
+			}
+		} else if err := tx.Unscoped().Where("id = ?", entry.TargetID).Delete(model).Error; err != nil {
+			return err
+		}
+		return recordAction(tx, entry, true)
+	})
+	return model, err
+}
+
+// WarnUser 警告用户并记录审核日志
+//
+// 参数：
+//   - entry：审核日志
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) WarnUser(entry *models.ModerationLog) error {
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&models.UserWarning{
+			UID:          entry.TargetUID,
+			ModeratorUID: entry.ModeratorUID,
+			TargetType:   entry.TargetType,
+			TargetID:     entry.TargetID,
+			Reason:       entry.Reason,
+			CreatedAt:    entry.CreatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordAction(tx, entry, true)
+	})
+}
+
+// activeSanction 生效中的处罚的查询条件
+//
+// 参数：
+//   - db：数据库连接或事务
+//   - now：当前时间
+//
+// 返回值：
+//   - *gorm.DB：添加了查询条件的数据库连接
+func activeSanction(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Model(&models.UserSuspension{}).Where("lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", now)
+}
+
+// SanctionUser 暂停、封禁或限流用户并记录审核日志，处罚类型即审核操作
+//
+// 参数：
+//   - entry：审核日志，ExpiresAt 为处罚到期时间，为空时永久有效
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) SanctionUser(entry *models.ModerationLog) error {
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&models.UserSuspension{
+			UID:          entry.TargetUID,
+			Kind:         entry.Action,
+			ModeratorUID: entry.ModeratorUID,
+			Reason:       entry.Reason,
+			ExpiresAt:    entry.ExpiresAt,
+			CreatedAt:    entry.CreatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if entry.Action == consts.MODERATION_ACTION_SHADOWBAN {
+			if _, err := refreshShadowbanFlags(tx, entry.CreatedAt, []uint64{entry.TargetUID}); err != nil {
+				return err
+			}
+		}
+		return recordAction(tx, entry, true)
+	})
+}
+
+// GetActiveSuspension 获取用户生效中的暂停或封禁，存在多条时优先返回永久封禁，其次返回到期最晚的暂停
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - models.UserSuspension：处罚记录
+//   - error：用户未被暂停或封禁时返回 gorm.ErrRecordNotFound
+func (store *ModerationStore) GetActiveSuspension(uid uint64) (models.UserSuspension, error) {
+	var suspension models.UserSuspension
+	result := activeSanction(store.db, time.Now()).
+		Where("uid = ? AND kind IN ?", uid, []string{consts.MODERATION_ACTION_SUSPEND, consts.MODERATION_ACTION_BAN}).
+		Order("expires_at DESC NULLS FIRST").
+		First(&suspension)
+	return suspension, result.Error
+}
+
+// LiftSanctions 提前解除用户所有生效中的处罚并记录审核日志
+//
+// 参数：
+//   - entry：审核日志
+//
+// 返回值：
+//   - int64：解除的处罚数量，为 0 时不记录日志
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) LiftSanctions(entry *models.ModerationLog) (int64, error) {
+	var lifted int64
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		result := activeSanction(tx, entry.CreatedAt).Where("uid = ?", entry.TargetUID).Updates(map[string]interface{}{
+			"lifted_at": entry.CreatedAt,
+			"lifted_by": entry.ModeratorUID,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		lifted = result.RowsAffected
+		if _, err := refreshShadowbanFlags(tx, entry.CreatedAt, []uint64{entry.TargetUID}); err != nil {
+			return err
+		}
+		return recordAction(tx, entry, false)
+	})
+	return lifted, err
+}
+
+// LiftExpiredSanctions 解除一批已到期的处罚，并为每条处罚记录操作人ID为 0 的审核日志
+//
+// 参数：
+//   - limit：每批解除的最大数量
+//
+// 返回值：
+//   - int：解除的处罚数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) LiftExpiredSanctions(limit int) (int, error) {
+	var lifted int
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		// 跳过被其他实例锁定的记录，避免多实例重复解除
+		var sanctions []models.UserSuspension
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("lifted_at IS NULL AND expires_at <= ?", time.Now()).
+			Order("expires_at").Limit(limit).Find(&sanctions).Error
+		if err != nil || len(sanctions) == 0 {
+			return err
+		}
+
+		ids := make([]uint64, len(sanctions))
+		logs := make([]models.ModerationLog, len(sanctions))
+		var shadowbannedUIDs []uint64
+		for index, sanction := range sanctions {
+			ids[index] = sanction.ID
+			if sanction.Kind == consts.MODERATION_ACTION_SHADOWBAN {
+				shadowbannedUIDs = append(shadowbannedUIDs, sanction.UID)
+			}
+			logs[index] = models.ModerationLog{
+				Action:     consts.MODERATION_ACTION_LIFT,
+				TargetType: consts.REPORT_TARGET_USER,
+				TargetID:   sanction.UID,
+				TargetUID:  sanction.UID,
+				Reason:     sanction.Kind + " " + consts.MODERATION_SANCTION_EXPIRED_REASON,
+			}
+		}
+		if err := tx.Model(&models.UserSuspension{}).Where("id IN ?", ids).Update("lifted_at", gorm.Expr("expires_at")).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&logs).Error; err != nil {
+			return err
+		}
+		// 用户可能仍有其他生效中的限流，需重新计算限流标记
+		if len(shadowbannedUIDs) > 0 {
+			if _, err := refreshShadowbanFlags(tx, time.Now(), shadowbannedUIDs); err != nil {
+				return err
+			}
+		}
+		lifted = len(sanctions)
+		return nil
+	})
+	return lifted, err
+}
+
+// refreshShadowbanFlags 按生效中的限流处罚重新计算用户的限流标记
+//
+// 参数：
+//   - tx：数据库事务
+//   - now：当前时间
+//   - uids：需要重新计算的用户ID，为 nil 时重新计算所有用户
+//
+// 返回值：
+//   - int64：限流标记发生变化的用户数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func refreshShadowbanFlags(tx *gorm.DB, now time.Time, uids []uint64) (int64, error) {
+	shadowbanned := gorm.Expr("EXISTS (?)", activeSanction(tx.Session(&gorm.Session{NewDB: true}), now).
+		Select("1").
+		Where("user_suspensions.uid = user_infos.id AND user_suspensions.kind = ?", consts.MODERATION_ACTION_SHADOWBAN))
+	query := tx.Model(&models.UserInfo{}).Where("is_shadowbanned <> ?", shadowbanned)
+	if uids != nil {
+		query = query.Where("id IN ?", uids)
+	}
+	result := query.UpdateColumn("is_shadowbanned", shadowbanned)
+	return result.RowsAffected, result.Error
+}
+
+// SyncShadowbannedUsers 按生效中的限流处罚修正所有用户的限流标记
+//
+// 返回值：
+//   - int64：被修正的用户数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) SyncShadowbannedUsers() (int64, error) {
+	return refreshShadowbanFlags(store.db, time.Now(), nil)
+}
+
+// GetModerationLogs 获取审核日志，按时间倒序排列
+//
+// 参数：
+//   - query：筛选条件
+//
+// 返回值：
+//   - []models.ModerationLog：审核日志
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) GetModerationLogs(query types.ModerationLogQuery) ([]models.ModerationLog, error) {
+	db := store.db.Model(&models.ModerationLog{})
+	if query.TargetType != "" {
+		db = db.Where("target_type = ? AND target_id = ?", query.TargetType, query.TargetID)
+	}
+	if query.TargetUID != 0 {
+		db = db.Where("target_uid = ?", query.TargetUID)
+	}
+	if query.ModeratorUID != 0 {
+		db = db.Where("moderator_uid = ?", query.ModeratorUID)
+	}
+	var logs []models.ModerationLog
+	result := db.Order("id DESC").Offset(query.Offset).Limit(query.Length).Find(&logs)
+	return logs, result.Error
+}
+
+// GetUserWarnings 获取用户收到的警告，按时间倒序排列
+//
+// 参数：
+//   - uid：用户ID
+//   - offset：偏移量
+//   - limit：获取数量
+//
+// 返回值：
+//   - []models.UserWarning：警告列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ModerationStore) GetUserWarnings(uid uint64, offset, limit int) ([]models.UserWarning, error) {
+	var warnings []models.UserWarning
+	result := store.db.Where("uid = ?", uid).Order("id DESC").Offset(offset).Limit(limit).Find(&warnings)
+	return warnings, result.Error
+}
@@ -16,12 +16,14 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
 	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
@@ -55,37 +57,183 @@ func (factory *Factory) NewPostStore() *PostStore {
 
 // GetPostList 获取适用于用户查看的帖子信息列表。
 //
+// 参数：
+// - fromID：上一页最后一篇博文的ID，为 0 时从最新的博文开始
+// - length：获取数量
+// - filter：作者过滤条件
+//
 // 返回值：
 // - []models.UserPostInfo: 包含适用于用户查看的帖子信息的切片。
 // - error: 在检索过程中遇到的任何错误，如果有的话。
-func (store *PostStore) GetPostList(from string, length int) ([]models.PostInfo, error) {
-	var posts []models.PostInfo
+func (store *PostStore) GetPostList(fromID uint64, length int, filter AuthorFilter) ([]models.PostInfo, error) {
+	return store.paginatePosts(filter.apply(store.db, "post_infos.uid"), fromID, length)
+}
+
+// GetPostListByUIDs 获取指定作者的帖子信息列表，分页方式与 GetPostList 相同。
+//
+// 参数：
+// - uids：作者ID列表
+// - from：起始博文ID
+// - length：获取数量
+// - filter：作者过滤条件
+//
+// 返回值：
+// - []models.PostInfo: 包含帖子信息的切片。
+// - error: 在检索过程中遇到的任何错误，如果有的话。
+func (store *PostStore) GetPostListByUIDs(uids []uint64, from string, length int, filter AuthorFilter) ([]models.PostInfo, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	var fromID uint64
 	if from != "" {
-		if result := store.db.Where("id < ?", from).Order("id desc").Limit(length).Find(&posts); result.Error != nil {
-			return nil, result.Error
+		var err error
+		fromID, err = strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			return nil, err
 		}
-		return posts, nil
 	}
-	if result := store.db.Order("id desc").Limit(length).Find(&posts); result.Error != nil {
+	return store.paginatePosts(filter.apply(store.db.Where("uid IN ?", uids), "post_infos.uid"), fromID, length)
+}
+
+// paginatePosts 按博文ID倒序分页查询帖子信息。
+//
+// 参数：
+// - query：基础查询
+// - fromID：上一页最后一篇博文的ID，为 0 时从最新的博文开始
+// - length：获取数量
+//
+// 返回值：
+// - []models.PostInfo: 包含帖子信息的切片。
+// - error: 在检索过程中遇到的任何错误，如果有的话。
+func (store *PostStore) paginatePosts(query *gorm.DB, fromID uint64, length int) ([]models.PostInfo, error) {
+	var posts []models.PostInfo
+	query = query.Order("id desc").Limit(length)
+	if fromID != 0 {
+		query = query.Where("id < ?", fromID)
+	}
+	if result := query.Find(&posts); result.Error != nil {
 		return nil, result.Error
 	}
 	return posts, nil
 }
 
-// GetPostListByUID 获取适用于用户查看的帖子信息列表。
+// GetVisiblePostIDs 从博文ID列表中筛选仍存在且公开、作者对查看者可见的博文，保持原有顺序。
+//
+// 参数：
+// - postIDs：博文ID列表
+// - filter：作者过滤条件
+//
+// 返回值：
+// - []int64: 过滤后的博文ID列表
+// - error: 在检索过程中遇到的任何错误，如果有的话。
+func (store *PostStore) GetVisiblePostIDs(postIDs []int64, filter AuthorFilter) ([]int64, error) {
+	return store.filterPostIDs(store.db.Where("is_public = ?", true), postIDs, filter)
+}
+
+// FilterPostIDsByAuthor 从博文ID列表中筛选仍存在且作者对查看者可见的博文，保持原有顺序。
+//
+// 参数：
+// - postIDs：博文ID列表
+// - filter：作者过滤条件
+//
+// 返回值：
+// - []int64: 过滤后的博文ID列表
+// - error: 在检索过程中遇到的任何错误，如果有的话。
+func (store *PostStore) FilterPostIDsByAuthor(postIDs []int64, filter AuthorFilter) ([]int64, error) {
+	return store.filterPostIDs(store.db, postIDs, filter)
+}
+
+// filterPostIDs 从博文ID列表中筛选满足查询条件且作者对查看者可见的博文，保持原有顺序。
+//
+// 参数：
+// - query：基础查询
+// - postIDs：博文ID列表
+// - filter：作者过滤条件
+//
+// 返回值：
+// - []int64: 过滤后的博文ID列表
+// - error: 在检索过程中遇到的任何错误，如果有的话。
+func (store *PostStore) filterPostIDs(query *gorm.DB, postIDs []int64, filter AuthorFilter) ([]int64, error) {
+	if len(postIDs) == 0 {
+		return postIDs, nil
+	}
+
+	query = filter.apply(query.Model(&models.PostInfo{}).Where("id IN ?", postIDs), "post_infos.uid")
+	var visibleIDs []int64
+	if err := query.Pluck("id", &visibleIDs).Error; err != nil {
+		return nil, err
+	}
+
+	visible := make(map[int64]struct{}, len(visibleIDs))
+	for _, id := range visibleIDs {
+		visible[id] = struct{}{}
+	}
+	filtered := make([]int64, 0, len(visibleIDs))
+	for _, id := range postIDs {
+		if _, ok := visible[id]; ok {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+// GetPostListByUID 按博文ID倒序分页获取用户发布的帖子信息列表，调用方需先校验查看者能否查看该用户的博文。
 //
 // 参数：
 // - uid：用户ID
+// - fromID：上一页最后一篇博文的ID，为 0 时从最新的博文开始
+// - length：获取数量
 //
 // 返回值：
 // - []models.UserPostInfo: 包含适用于用户查看的帖子信息的切片。
 // - error: 在检索过程中遇到的任何错误，如果有的话。
-func (store *PostStore) GetPostListByUID(uid string) ([]models.PostInfo, error) {
-	var userPosts []models.PostInfo
-	if result := store.db.Where("uid = ?", uid).Order("id desc").Find(&userPosts); result.Error != nil {
-		return nil, result.Error
+func (store *PostStore) GetPostListByUID(uid, fromID uint64, length int) ([]models.PostInfo, error) {
+	return store.paginatePosts(store.db.Where("uid = ?", uid), fromID, length)
+}
+
+// GetLikedPage 按点赞时间倒序分页获取用户的点赞记录
+//
+// 参数：
+//   - uid：用户ID
+//   - fromTime：上一页最后一条记录的点赞时间
+//   - fromPostID：上一页最后一条记录的博文ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []models.PostLikeInfo：点赞记录
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) GetLikedPage(uid uint64, fromTime time.Time, fromPostID uint64, length int) ([]models.PostLikeInfo, error) {
+	filter := bson.D{{Key: "uid", Value: int64(uid)}}
+	if fromPostID != 0 {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "liked_at", Value: bson.D{{Key: "$lt", Value: fromTime}}}},
+			bson.D{
+				{Key: "liked_at", Value: fromTime},
+				{Key: "post_id", Value: bson.D{{Key: "$lt", Value: int64(fromPostID)}}},
+			},
+		}})
+	}
+	sort := bson.D{
+		{Key: "liked_at", Value: -1},
+		{Key: "post_id", Value: -1},
+	}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(sort).SetLimit(int64(length)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var likeInfos []models.PostLikeInfo
+	if err := cursor.All(ctx, &likeInfos); err != nil {
+		return nil, err
 	}
-	return userPosts, nil
+	return likeInfos, nil
 }
 
 // ValidatePostExistence 用来检查是否存在Post博文
@@ -108,33 +256,251 @@ func (store *PostStore) ValidatePostExistence(postID uint64) (bool, error) {
 	return true, nil
 }
 
-// GetPostByUID 通过用户UID获取用户信息。
+// GetPost 获取博文基本信息，不包含点赞和收藏计数。
 //
 // 参数：
-//   - uid：用户ID
+//   - postID：博文ID
 //
 // 返回值：
-//   - *models.PostInfo：如果找到了相应的用户信息，则返回该用户信息，否则返回nil。
+//   - models.PostInfo：博文信息
 //   - error：如果在获取过程中发生错误，则返回相应的错误信息，否则返回nil。
-func (store *PostStore) GetPostInfo(postID uint64) (models.PostInfo, int64, int64, error) {
-	post := models.PostInfo{}
+func (store *PostStore) GetPost(postID uint64) (models.PostInfo, error) {
+	var post models.PostInfo
 	result := store.db.Where("id = ?", postID).First(&post)
 	if result.Error != nil {
-		return models.PostInfo{}, 0, 0, result.Error
+		return models.PostInfo{}, result.Error
 	}
+	return post, nil
+}
 
-	postLikeCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION)
-	likeCount, err := postLikeCollection.CountDocuments(context.Background(), bson.D{{Key: "post_id", Value: postID}})
+// UpdateCommentPermission 修改博文的评论权限
+//
+// 参数：
+//   - postID：博文ID
+//   - permission：评论权限
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) UpdateCommentPermission(postID uint64, permission string) error {
+	return store.db.Model(&models.PostInfo{}).Where("id = ?", postID).Update("comment_permission", permission).Error
+}
+
+// PinComment 置顶评论，评论已置顶或置顶数量已达上限时不做修改
+//
+// 参数：
+//   - postID：博文ID
+//   - commentID：评论ID
+//   - limit：置顶数量上限
+//
+// 返回值：
+//   - bool：是否置顶成功
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) PinComment(postID, commentID uint64, limit int) (bool, error) {
+	result := store.db.Model(&models.PostInfo{}).
+		Where("id = ?", postID).
+		Where("NOT (? = ANY(COALESCE(pinned_comment_ids, '{}')))", commentID).
+		Where("COALESCE(cardinality(pinned_comment_ids), 0) < ?", limit).
+		Update("pinned_comment_ids", gorm.Expr("array_append(COALESCE(pinned_comment_ids, '{}'), ?)", commentID))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UnpinComment 取消置顶评论
+//
+// 参数：
+//   - postID：博文ID
+//   - commentID：评论ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) UnpinComment(postID, commentID uint64) error {
+	return store.db.Model(&models.PostInfo{}).
+		Where("id = ?", postID).
+		Update("pinned_comment_ids", gorm.Expr("array_remove(pinned_comment_ids, ?)", commentID)).Error
+}
+
+// GetPostsByIDs 批量获取博文
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - []models.PostInfo：博文列表，顺序不保证与参数一致
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) GetPostsByIDs(postIDs []uint64) ([]models.PostInfo, error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+	var posts []models.PostInfo
+	result := store.db.Where("id IN ?", postIDs).Find(&posts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return posts, nil
+}
+
+// CountPostLikes 批量统计博文的点赞数
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]int64：博文ID到点赞数的映射，没有点赞的博文不在映射中
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) CountPostLikes(postIDs []uint64) (map[uint64]int64, error) {
+	return countPostRecords(store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION), postIDs)
+}
+
+// CountPostFavourites 批量统计博文的收藏数
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]int64：博文ID到收藏数的映射，没有收藏的博文不在映射中
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) CountPostFavourites(postIDs []uint64) (map[uint64]int64, error) {
+	return countPostRecords(store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_FAVORITE_COLLECTION), postIDs)
+}
+
+// CountPostReposts 批量统计博文的转发数
+//
+// 参数：
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]int64：博文ID到转发数的映射，没有转发的博文不在映射中
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) CountPostReposts(postIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ParentPostID uint64
+		Count        int64
+	}
+	result := store.db.Model(&models.PostInfo{}).
+		Select("parent_post_id, COUNT(*) AS count").
+		Where("parent_post_id IN ?", postIDs).
+		Group("parent_post_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		counts[row.ParentPostID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetLikedPostIDsAmong 获取给定博文中用户已点赞的博文ID
+//
+// 参数：
+//   - uid：用户ID
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - []uint64：已点赞的博文ID
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) GetLikedPostIDsAmong(uid uint64, postIDs []uint64) ([]uint64, error) {
+	return getPostRecordIDsAmong(store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION), uid, postIDs)
+}
+
+// GetFavouritedPostIDsAmong 获取给定博文中用户已收藏的博文ID
+//
+// 参数：
+//   - uid：用户ID
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - []uint64：已收藏的博文ID
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *PostStore) GetFavouritedPostIDsAmong(uid uint64, postIDs []uint64) ([]uint64, error) {
+	return getPostRecordIDsAmong(store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_FAVORITE_COLLECTION), uid, postIDs)
+}
+
+// countPostRecords 按博文分组统计点赞或收藏记录数
+//
+// 参数：
+//   - collection：点赞或收藏记录集合
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - map[uint64]int64：博文ID到记录数的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func countPostRecords(collection *mongo.Collection, postIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "post_id", Value: bson.D{{Key: "$in", Value: postIDs}}},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$post_id"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+	ctx := context.Background()
+	cursor, err := collection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return models.PostInfo{}, 0, 0, err
+		return nil, err
 	}
-	postFavouriteCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_FAVORITE_COLLECTION)
-	favouriteCount, err := postFavouriteCollection.CountDocuments(context.Background(), bson.D{{Key: "post_id", Value: postID}})
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		PostID int64 `bson:"_id"`
+		Count  int64 `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		counts[uint64(group.PostID)] = group.Count
+	}
+	return counts, nil
+}
+
+// getPostRecordIDsAmong 获取给定博文中用户存在点赞或收藏记录的博文ID
+//
+// 参数：
+//   - collection：点赞或收藏记录集合
+//   - uid：用户ID
+//   - postIDs：博文ID列表
+//
+// 返回值：
+//   - []uint64：存在记录的博文ID
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func getPostRecordIDsAmong(collection *mongo.Collection, uid uint64, postIDs []uint64) ([]uint64, error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "post_id", Value: bson.D{{Key: "$in", Value: postIDs}}},
+	}
+	ctx := context.Background()
+	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
-		return models.PostInfo{}, 0, 0, err
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	return post, likeCount, favouriteCount, nil
+	var records []struct {
+		PostID int64 `bson:"post_id"`
+	}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	recordIDs := make([]uint64, len(records))
+	for index, record := range records {
+		recordIDs[index] = uint64(record.PostID)
+	}
+	return recordIDs, nil
 }
 
 // CreatePost 根据用户提交的帖子信息创建帖子。
@@ -144,10 +510,11 @@ func (store *PostStore) GetPostInfo(postID uint64) (models.PostInfo, int64, int6
 //   - ipAddr：IP地址
 //   - postInfo：帖子信息，包含标题、内容等。
 //   - images：帖子图片
+//   - hold：暂扣信息，为 nil 时直接发布，否则博文在同一事务中被暂扣至审核通过
 //
 // 返回值：
 //   - error：如果在创建过程中发生错误，则返回相应的错误信息，否则返回nil。
-func (store *PostStore) CreatePost(uid uint64, ipAddr string, postReqData types.PostCreateBody) (models.PostInfo, error) {
+func (store *PostStore) CreatePost(uid uint64, ipAddr string, postReqData types.PostCreateBody, hold *ReviewHold) (models.PostInfo, error) {
 	var imageFileNames []string
 	// 将文件复制出缓存
 	for _, imageUUID := range postReqData.Images {
@@ -201,19 +568,38 @@ func (store *PostStore) CreatePost(uid uint64, ipAddr string, postReqData types.
 
 	// 将博文数据写入数据库
 	postInfo := models.PostInfo{
-		ParentPostID: nil,
-		UID:          uid,
-		IpAddrress:   &ipAddr,
-		Title:        postReqData.Title,
-		Content:      postReqData.Content,
-		Images:       imageFileNames,
-		Like:         pq.Int64Array{},
-		Favourite:    pq.Int64Array{},
-		Farward:      pq.Int64Array{},
-		IsPublic:     true,
-	}
-	result := store.db.Create(&postInfo)
-	return postInfo, result.Error
+		ParentPostID:      nil,
+		UID:               uid,
+		IpAddrress:        &ipAddr,
+		Title:             postReqData.Title,
+		Content:           postReqData.Content,
+		Images:            imageFileNames,
+		Like:              pq.Int64Array{},
+		Favourite:         pq.Int64Array{},
+		Farward:           pq.Int64Array{},
+		IsPublic:          true,
+		CommentPermission: consts.COMMENT_PERMISSION_EVERYONE,
+		PinnedCommentIDs:  pq.Int64Array{},
+	}
+	// 博文与索引操作在同一事务中写入，由同步任务异步写入搜索引擎
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&postInfo).Error; err != nil {
+			return err
+		}
+		hashtags := parsers.ParseHashtags(postInfo.Title + " " + postInfo.Content)
+		if err := addHashtagSearchIndex(tx, hashtags); err != nil {
+			return err
+		}
+		if err := addPostHashtagRecords(tx, uint64(postInfo.ID), uid, hashtags); err != nil {
+			return err
+		}
+		// 暂扣的博文在审核通过恢复时再写入索引
+		if hold != nil {
+			return holdForReview(tx, consts.REPORT_TARGET_POST, uint64(postInfo.ID), uid, hold)
+		}
+		return enqueueSearchIndex(tx, uint64(postInfo.ID), consts.SEARCH_INDEX_OP_CREATE)
+	})
+	return postInfo, err
 }
 
 // CachePostImage 缓存博文图片
@@ -351,28 +737,32 @@ func (store *PostStore) CheckCacheImageAvaliable(uuid string) (bool, error) {
 //   - postID uint64：待点赞博文的ID
 //
 // 返回值：
+//   - bool：是否新增了点赞记录，重复点赞时为 false
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (store *PostStore) LikePost(uid, postID int64) error {
+func (store *PostStore) LikePost(uid, postID int64) (bool, error) {
 	// 构造查询条件
 	filter := bson.D{
 		{Key: "uid", Value: uid},
 		{Key: "post_id", Value: postID},
 	}
-	// 构造更新内容
+	// 构造更新内容，重复点赞不改变点赞时间，保证分页游标稳定
 	update := bson.D{
-		{Key: "$set", Value: bson.D{
+		{Key: "$setOnInsert", Value: bson.D{
 			{Key: "liked_at", Value: time.Now()},
 		}},
 	}
 
 	// 更新博文点赞记录
 	postLikeCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION)
-	_, err := postLikeCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	result, err := postLikeCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
 	// 重复点赞
 	if mongo.IsDuplicateKeyError(err) {
-		return errors.New("user has liked this post")
+		return false, errors.New("user has liked this post")
 	}
-	return err
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
 }
 
 // CancelLikePost 取消点赞博文
@@ -382,19 +772,23 @@ func (store *PostStore) LikePost(uid, postID int64) error {
 //   - postID：待取消点赞博文的ID
 //
 // 返回值：
+//   - bool：是否删除了点赞记录
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (store *PostStore) CancelLikePost(uid, postID int64) error {
+func (store *PostStore) CancelLikePost(uid, postID int64) (bool, error) {
 	filter := bson.D{
 		{Key: "uid", Value: uid},
 		{Key: "post_id", Value: postID},
 	}
 
 	postLikeCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION)
-	_, err := postLikeCollection.DeleteOne(context.Background(), filter)
+	result, err := postLikeCollection.DeleteOne(context.Background(), filter)
 	if mongo.ErrNoDocuments == err {
-		return errors.New("user has not liked this post")
+		return false, errors.New("user has not liked this post")
 	}
-	return err
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
 }
 
 // FavouritePost 收藏博文
@@ -402,26 +796,34 @@ func (store *PostStore) CancelLikePost(uid, postID int64) error {
 // 参数：
 //   - uid：用户ID
 //   - postID：待收藏博文的ID
+//   - folderID：收藏夹ID，为 0 时收藏到默认收藏夹
 //
 // 返回值：
+//   - bool：是否新增了收藏记录，重复收藏时为 false
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (store *PostStore) FavouritePost(uid, postID int64) error {
+func (store *PostStore) FavouritePost(uid, postID int64, folderID uint64) (bool, error) {
 	filter := bson.D{
 		{Key: "uid", Value: uid},
 		{Key: "post_id", Value: postID},
 	}
+	// 重复收藏不改变已有记录的收藏夹、备注和收藏时间
 	update := bson.D{
-		{Key: "$set", Value: bson.D{
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "folder_id", Value: folderID},
+			{Key: "note", Value: ""},
 			{Key: "favourited_at", Value: time.Now()},
 		}},
 	}
 
 	postFavouriteCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_FAVORITE_COLLECTION)
-	_, err := postFavouriteCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	result, err := postFavouriteCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
 	if mongo.IsDuplicateKeyError(err) {
-		return errors.New("user has favourited this post")
+		return false, errors.New("user has favourited this post")
 	}
-	return err
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
 }
 
 // CancelFavouritePost 取消收藏博文
@@ -431,19 +833,23 @@ func (store *PostStore) FavouritePost(uid, postID int64) error {
 //   - postID：待取消收藏博文的ID
 //
 // 返回值：
+//   - bool：是否删除了收藏记录
 //   - error：如果发生错误，返回相应错误信息；否则返回 nil
-func (store *PostStore) CancelFavouritePost(uid, postID int64) error {
+func (store *PostStore) CancelFavouritePost(uid, postID int64) (bool, error) {
 	filter := bson.D{
 		{Key: "uid", Value: uid},
 		{Key: "post_id", Value: postID},
 	}
 
 	postFavouriteCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_FAVORITE_COLLECTION)
-	_, err := postFavouriteCollection.DeleteOne(context.Background(), filter)
+	result, err := postFavouriteCollection.DeleteOne(context.Background(), filter)
 	if mongo.ErrNoDocuments == err {
-		return errors.New("user has not favourited this post")
+		return false, errors.New("user has not favourited this post")
+	}
+	if err != nil {
+		return false, err
 	}
-	return err
+	return result.DeletedCount > 0, nil
 }
 
 // GetPostUserStatus 获取用户对帖子的状态
@@ -490,5 +896,35 @@ func (store *PostStore) GetPostUserStatus(uid, postID int64) (bool, bool, error)
 // 返回值：
 // - error：如果发生错误，返回相应错误信息；否则返回 nil
 func (store *PostStore) DeletePost(postID uint64) error {
-	return store.db.Where("id = ?", postID).Unscoped().Delete(&models.PostInfo{}).Error
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		return deletePostRecords(tx, postID)
+	})
+}
+
+// deletePostRecords 在事务中彻底删除博文（包括已隐藏的博文）及其话题记录，扣减话题使用次数并写入删除索引操作
+//
+// 参数：
+//   - tx：数据库事务
+//   - postID：博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func deletePostRecords(tx *gorm.DB, postID uint64) error {
+	var post models.PostInfo
+	err := tx.Unscoped().Select("id", "title", "content").Where("id = ?", postID).First(&post).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil {
+		if err := removeHashtagSearchIndex(tx, parsers.ParseHashtags(post.Title+" "+post.Content)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("post_id = ?", postID).Delete(&models.PostHashtagRecord{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id = ?", postID).Unscoped().Delete(&models.PostInfo{}).Error; err != nil {
+		return err
+	}
+	return enqueueSearchIndex(tx, postID, consts.SEARCH_INDEX_OP_DELETE)
 }
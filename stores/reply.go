@@ -8,16 +8,24 @@ Copyright (c) [2024], Author(s):
 package stores
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
 	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"gorm.io/gorm"
 )
 
 // ReplyStore 用户信息数据库
 type ReplyStore struct {
-	db *gorm.DB
+	db         *gorm.DB
+	mongo      *mongo.Client
+	scoreCache scoreCache
 }
 
 // NewReplyStore 返回一个新的 ReplyStore 实例。
@@ -25,7 +33,14 @@ type ReplyStore struct {
 // 返回值：
 //   - *ReplyStore：新的 ReplyStore 实例。
 func (factory *Factory) NewReplyStore() *ReplyStore {
-	return &ReplyStore{factory.db}
+	return &ReplyStore{
+		db:    factory.db,
+		mongo: factory.mongo,
+		scoreCache: scoreCache{
+			rds:    factory.rds,
+			prefix: consts.REDIS_REPLY_SCORE_CACHE,
+		},
+	}
 }
 
 // CreateReply 创建回复
@@ -36,10 +51,12 @@ func (factory *Factory) NewReplyStore() *ReplyStore {
 //   - parentReplyID: 回复编号
 //   - parentReplyUID: 回复用户ID
 //   - content: 回复内容
+//   - hold: 暂扣信息，为 nil 时直接发布，否则回复在同一事务中被暂扣至审核通过
 //
 // 返回值：
+//   - uint64：回复ID
 //   - error：创建失败返回创建失败时候的具体信息
-func (store *ReplyStore) CreateReply(uid, commentID uint64, parentReplyID, parentReplyUID *uint64, content string) error {
+func (store *ReplyStore) CreateReply(uid, commentID uint64, parentReplyID, parentReplyUID *uint64, content string, hold *ReviewHold) (uint64, error) {
 	newReply := &models.ReplyInfo{
 		CommentID:      commentID,
 		ParentReplyID:  parentReplyID,
@@ -51,12 +68,21 @@ func (store *ReplyStore) CreateReply(uid, commentID uint64, parentReplyID, paren
 		IsPublic:       true,
 	}
 
-	result := store.db.Create(newReply)
-	if result.Error != nil {
-		return result.Error
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newReply).Error; err != nil {
+			return err
+		}
+		if hold != nil {
+			return holdForReview(tx, consts.REPORT_TARGET_REPLY, uint64(newReply.ID), uid, hold)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	// 将新回复加入排序得分缓存
+	return uint64(newReply.ID), store.scoreCache.update(commentID, ratingCount{ID: uint64(newReply.ID)})
 }
 
 // ValidateReplyExistence 判断回复是否存在
@@ -95,11 +121,38 @@ func (store *ReplyStore) ValidateReplyExistence(commentID, parentReplyID uint64)
 // 返回值：
 //   - error：删除失败返回错误
 func (store *ReplyStore) DeleteReply(uid, replyID uint64) error {
-	result := store.db.Model(&models.ReplyInfo{}).Where("id = ? AND uid = ?", replyID, uid).Unscoped().Delete(&models.ReplyInfo{})
+	var reply models.ReplyInfo
+	result := store.db.Where("id = ? AND uid = ?", replyID, uid).Limit(1).Find(&reply)
 	if result.Error != nil {
 		return result.Error
 	}
-	return nil
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	result = store.db.Model(&models.ReplyInfo{}).Where("id = ? AND uid = ?", replyID, uid).Unscoped().Delete(&models.ReplyInfo{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return store.ClearReplyRecords(reply.CommentID, replyID)
+}
+
+// ClearReplyRecords 清理已删除回复在数据库之外的关联数据
+//
+// 参数：
+//   - commentID：评论ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *ReplyStore) ClearReplyRecords(commentID, replyID uint64) error {
+	// 删除回复的评价记录
+	if _, err := store.replyRateCollection().DeleteMany(context.Background(), bson.D{{Key: "reply_id", Value: replyID}}); err != nil {
+		return err
+	}
+
+	// 从排序得分缓存中移除
+	return store.scoreCache.remove(commentID, replyID)
 }
 
 // UpdateReply 修改回复
@@ -107,15 +160,21 @@ func (store *ReplyStore) DeleteReply(uid, replyID uint64) error {
 // 参数：
 //   - replyID：回复ID
 //   - content: 回复内容
+//   - hold: 暂扣信息，为 nil 时直接生效，否则回复在同一事务中被暂扣至审核通过
 //
 // 返回值：
 //   - error：修改失败返回错误
-func (store *ReplyStore) UpdateReply(uid, replyID uint64, content string) error {
-	result := store.db.Model(&models.ReplyInfo{}).Where("id = ? AND uid = ?", replyID, uid).Update("content", content)
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+func (store *ReplyStore) UpdateReply(uid, replyID uint64, content string, hold *ReviewHold) error {
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ReplyInfo{}).Where("id = ? AND uid = ?", replyID, uid).Update("content", content)
+		if result.Error != nil {
+			return result.Error
+		}
+		if hold != nil && result.RowsAffected > 0 {
+			return holdForReview(tx, consts.REPORT_TARGET_REPLY, replyID, uid, hold)
+		}
+		return nil
+	})
 }
 
 // GetReply 获取回复
@@ -135,19 +194,423 @@ func (store *ReplyStore) GetReply(replyID uint64) (models.ReplyInfo, error) {
 	return reply, nil
 }
 
-// GetReplyList 获取回复列表
+// GetReplyListByID 按回复ID分页获取回复列表
 //
 // 参数：
 //   - commentID：评论ID
+//   - fromID：上一页最后一条回复的ID，为 0 时获取第一页
+//   - ascending：是否按ID正序排列
+//   - length：获取数量
+//   - hidden：对查看者不可见的回复者
 //
 // 返回值：
 //   - []models.ReplyInfo：回复列表
 //   - error：获取失败返回错误
-func (store *ReplyStore) GetReplyList(commentID uint64) ([]models.ReplyInfo, error) {
+func (store *ReplyStore) GetReplyListByID(commentID, fromID uint64, ascending bool, length int, hidden HiddenAuthors) ([]models.ReplyInfo, error) {
 	var replyList []models.ReplyInfo
-	result := store.db.Where("comment_id = ?", commentID).Order("id desc").Find(&replyList)
+	query := store.db.Where("comment_id = ?", commentID).Limit(length)
+	if ascending {
+		query = query.Order("id asc")
+		if fromID != 0 {
+			query = query.Where("id > ?", fromID)
+		}
+	} else {
+		query = query.Order("id desc")
+		if fromID != 0 {
+			query = query.Where("id < ?", fromID)
+		}
+	}
+	query = hidden.apply(query, "reply_infos.uid")
+	result := query.Find(&replyList)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return replyList, nil
 }
+
+// GetReplyIDsByScore 按缓存的排序得分分页获取回复ID，缓存不存在时重建
+//
+// 参数：
+//   - commentID：评论ID
+//   - mode：排序方式，top 或 hot
+//   - fromScore：上一页最后一条回复的得分
+//   - fromID：上一页最后一条回复的ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []uint64：回复ID列表
+//   - []float64：对应的得分
+//   - error：获取失败返回错误
+func (store *ReplyStore) GetReplyIDsByScore(commentID uint64, mode string, fromScore float64, fromID uint64, length int) ([]uint64, []float64, error) {
+	exists, err := store.scoreCache.exists(mode, commentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		counts, err := store.getReplyRatingCounts(commentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := store.scoreCache.rebuild(commentID, counts); err != nil {
+			return nil, nil, err
+		}
+	}
+	return store.scoreCache.page(mode, commentID, fromScore, fromID, length)
+}
+
+// FilterReplyIDs 过滤已删除及回复者对查看者不可见的回复，保持原有顺序
+//
+// 参数：
+//   - replyIDs：回复ID列表
+//   - hidden：对查看者不可见的回复者
+//
+// 返回值：
+//   - []uint64：过滤后的回复ID列表
+//   - error：获取失败返回错误
+func (store *ReplyStore) FilterReplyIDs(replyIDs []uint64, hidden HiddenAuthors) ([]uint64, error) {
+	if len(replyIDs) == 0 {
+		return replyIDs, nil
+	}
+
+	var keptIDs []uint64
+	query := store.db.Model(&models.ReplyInfo{}).Where("id IN ?", replyIDs)
+	query = hidden.apply(query, "reply_infos.uid")
+	if result := query.Pluck("id", &keptIDs); result.Error != nil {
+		return nil, result.Error
+	}
+
+	kept := make(map[uint64]struct{}, len(keptIDs))
+	for _, id := range keptIDs {
+		kept[id] = struct{}{}
+	}
+	filtered := make([]uint64, 0, len(keptIDs))
+	for _, id := range replyIDs {
+		if _, ok := kept[id]; ok {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+// GetTopLevelReplies 批量获取多条评论下的前若干条直接回复，按ID正序排列
+//
+// 参数：
+//   - commentIDs：评论ID列表
+//   - fromID：上一页最后一条回复的ID，为 0 时从头获取，仅适用于单条评论
+//   - length：每条评论获取的数量
+//   - hidden：对查看者不可见的回复者
+//
+// 返回值：
+//   - []models.ReplyInfo：回复列表
+//   - error：获取失败返回错误
+func (store *ReplyStore) GetTopLevelReplies(commentIDs []uint64, fromID uint64, length int, hidden HiddenAuthors) ([]models.ReplyInfo, error) {
+	query := store.db.Model(&models.ReplyInfo{}).Where("comment_id IN ? AND reply_to_reply_id IS NULL", commentIDs)
+	return store.getFirstReplies(query, "comment_id", commentIDs, fromID, length, hidden)
+}
+
+// GetChildReplies 批量获取多条回复下的前若干条子回复，按ID正序排列
+//
+// 参数：
+//   - parentReplyIDs：父回复ID列表
+//   - fromID：上一页最后一条回复的ID，为 0 时从头获取，仅适用于单条父回复
+//   - length：每条父回复获取的数量
+//   - hidden：对查看者不可见的回复者
+//
+// 返回值：
+//   - []models.ReplyInfo：回复列表
+//   - error：获取失败返回错误
+func (store *ReplyStore) GetChildReplies(parentReplyIDs []uint64, fromID uint64, length int, hidden HiddenAuthors) ([]models.ReplyInfo, error) {
+	query := store.db.Model(&models.ReplyInfo{}).Where("reply_to_reply_id IN ?", parentReplyIDs)
+	return store.getFirstReplies(query, "reply_to_reply_id", parentReplyIDs, fromID, length, hidden)
+}
+
+// GetRepliedReplyIDs 获取给定回复中存在子回复的回复ID
+//
+// 参数：
+//   - replyIDs：回复ID列表
+//   - hidden：对查看者不可见的回复者
+//
+// 返回值：
+//   - []uint64：存在子回复的回复ID
+//   - error：获取失败返回错误
+func (store *ReplyStore) GetRepliedReplyIDs(replyIDs []uint64, hidden HiddenAuthors) ([]uint64, error) {
+	if len(replyIDs) == 0 {
+		return nil, nil
+	}
+	var repliedIDs []uint64
+	query := store.db.Model(&models.ReplyInfo{}).Distinct("reply_to_reply_id").Where("reply_to_reply_id IN ?", replyIDs)
+	query = hidden.apply(query, "reply_infos.uid")
+	if result := query.Pluck("reply_to_reply_id", &repliedIDs); result.Error != nil {
+		return nil, result.Error
+	}
+	return repliedIDs, nil
+}
+
+// CountRepliesByCommentIDs 批量统计评论的回复数
+//
+// 参数：
+//   - commentIDs：评论ID列表
+//
+// 返回值：
+//   - map[uint64]int64：评论ID到回复数的映射，没有回复的评论不在映射中
+//   - error：获取失败返回错误
+func (store *ReplyStore) CountRepliesByCommentIDs(commentIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		CommentID uint64
+		Count     int64
+	}
+	result := store.db.Model(&models.ReplyInfo{}).
+		Select("comment_id, COUNT(*) AS count").
+		Where("comment_id IN ?", commentIDs).
+		Group("comment_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		counts[row.CommentID] = row.Count
+	}
+	return counts, nil
+}
+
+// getFirstReplies 使用窗口函数在一次查询中获取每个父对象下的前若干条回复
+//
+// 参数：
+//   - query：已限定父对象范围的查询
+//   - partitionColumn：父对象所在的列
+//   - parentIDs：父对象ID列表
+//   - fromID：上一页最后一条回复的ID，为 0 时从头获取
+//   - length：每个父对象获取的数量
+//   - hidden：对查看者不可见的回复者
+//
+// 返回值：
+//   - []models.ReplyInfo：回复列表
+//   - error：获取失败返回错误
+func (store *ReplyStore) getFirstReplies(query *gorm.DB, partitionColumn string, parentIDs []uint64, fromID uint64, length int, hidden HiddenAuthors) ([]models.ReplyInfo, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+	if fromID != 0 {
+		query = query.Where("id > ?", fromID)
+	}
+	query = hidden.apply(query, "reply_infos.uid")
+	query = query.Select("*, ROW_NUMBER() OVER (PARTITION BY " + partitionColumn + " ORDER BY id ASC) AS row_rank")
+
+	var replies []models.ReplyInfo
+	result := store.db.Table("(?) AS ranked_replies", query).Where("row_rank <= ?", length).Order("id ASC").Find(&replies)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return replies, nil
+}
+
+// getReplyRatingCounts 统计评论下所有回复的点赞和点踩数
+//
+// 参数：
+//   - commentID：评论ID
+//
+// 返回值：
+//   - []ratingCount：评价计数
+//   - error：获取失败返回错误
+func (store *ReplyStore) getReplyRatingCounts(commentID uint64) ([]ratingCount, error) {
+	var replyIDs []uint64
+	result := store.db.Model(&models.ReplyInfo{}).Where("comment_id = ?", commentID).Pluck("id", &replyIDs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(replyIDs) == 0 {
+		return nil, nil
+	}
+
+	return countRatings(store.replyRateCollection(), "reply_id", replyIDs)
+}
+
+// replyRateCollection 获取回复评价集合
+//
+// 返回值：
+//   - *mongo.Collection：回复评价集合
+func (store *ReplyStore) replyRateCollection() *mongo.Collection {
+	return store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.REPLY_RATE_COLLECTION)
+}
+
+// GetReplyRatingCount 获取回复的点赞和点踩数
+//
+// 参数：
+//   - replyID：回复ID
+//
+// 返回值：
+//   - int64：点赞数
+//   - int64：点踩数
+//   - error：获取失败返回错误
+func (store *ReplyStore) GetReplyRatingCount(replyID uint64) (int64, int64, error) {
+	counts, err := countRatings(store.replyRateCollection(), "reply_id", []uint64{replyID})
+	if err != nil {
+		return 0, 0, err
+	}
+	return counts[0].Likes, counts[0].Dislikes, nil
+}
+
+// RefreshReplyScore 重新统计回复的点赞和点踩数并更新排序得分缓存
+//
+// 参数：
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：更新失败返回错误
+func (store *ReplyStore) RefreshReplyScore(replyID uint64) error {
+	reply, err := store.GetReply(replyID)
+	if err != nil {
+		return err
+	}
+	likes, dislikes, err := store.GetReplyRatingCount(replyID)
+	if err != nil {
+		return err
+	}
+	return store.scoreCache.update(reply.CommentID, ratingCount{ID: replyID, Likes: likes, Dislikes: dislikes})
+}
+
+// GetReplyUserStatus 获取用户对回复的点赞和点踩状态
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - bool：是否已点赞
+//   - bool：是否已点踩
+//   - error：获取失败返回错误
+func (store *ReplyStore) GetReplyUserStatus(uid, replyID uint64) (bool, bool, error) {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "reply_id", Value: replyID},
+	}
+	var rate struct {
+		Rate string `bson:"rate"`
+	}
+	err := store.replyRateCollection().FindOne(context.Background(), filter).Decode(&rate)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return rate.Rate == "like", rate.Rate == "dislike", nil
+}
+
+// LikeReply 点赞回复，已点踩时原子地替换为点赞
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：点赞失败返回错误
+func (store *ReplyStore) LikeReply(uid, replyID uint64) error {
+	return store.rateReply(uid, replyID, "like")
+}
+
+// CancelLikeReply 取消点赞回复
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：取消失败返回错误
+func (store *ReplyStore) CancelLikeReply(uid, replyID uint64) error {
+	deleted, err := store.cancelRateReply(uid, replyID, "like")
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("user has not liked this reply")
+	}
+	return nil
+}
+
+// DislikeReply 点踩回复，已点赞时原子地替换为点踩
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：点踩失败返回错误
+func (store *ReplyStore) DislikeReply(uid, replyID uint64) error {
+	return store.rateReply(uid, replyID, "dislike")
+}
+
+// CancelDislikeReply 取消点踩回复
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//
+// 返回值：
+//   - error：取消失败返回错误
+func (store *ReplyStore) CancelDislikeReply(uid, replyID uint64) error {
+	deleted, err := store.cancelRateReply(uid, replyID, "dislike")
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("user has not disliked this reply")
+	}
+	return nil
+}
+
+// rateReply 评价回复，每个用户对每条回复只保留一条评价
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//   - rate：评价类型，like 或 dislike
+//
+// 返回值：
+//   - error：评价失败返回错误
+func (store *ReplyStore) rateReply(uid, replyID uint64, rate string) error {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "reply_id", Value: replyID},
+	}
+	update := bson.D{
+		{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "rate", Value: rate},
+				{Key: "rated_at", Value: time.Now()},
+			},
+		},
+	}
+
+	_, err := store.replyRateCollection().UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// cancelRateReply 取消指定类型的回复评价
+//
+// 参数：
+//   - uid：用户ID
+//   - replyID：回复ID
+//   - rate：评价类型，like 或 dislike
+//
+// 返回值：
+//   - bool：是否存在并删除了该评价
+//   - error：取消失败返回错误
+func (store *ReplyStore) cancelRateReply(uid, replyID uint64, rate string) (bool, error) {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "reply_id", Value: replyID},
+		{Key: "rate", Value: rate},
+	}
+
+	result, err := store.replyRateCollection().DeleteOne(context.Background(), filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,312 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for ranking score cache accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/calculators"
+)
+
+// scoreCache 基于 Redis 有序集合的排序得分缓存，每个父对象（博文或评论）下的每种排序方式对应一个有序集合
+type scoreCache struct {
+	rds    *redis.Client
+	prefix string
+}
+
+// ratingCount 评价计数
+type ratingCount struct {
+	ID       uint64 // 对象ID
+	Likes    int64  // 点赞数
+	Dislikes int64  // 点踩数
+}
+
+// key 获取有序集合的键
+//
+// 参数：
+//   - mode：排序方式
+//   - parentID：父对象ID
+//
+// 返回值：
+//   - string：缓存键
+func (cache scoreCache) key(mode string, parentID uint64) string {
+	var sb strings.Builder
+	sb.WriteString(cache.prefix)
+	sb.WriteString(":")
+	sb.WriteString(mode)
+	sb.WriteString(":")
+	sb.WriteString(strconv.FormatUint(parentID, 10))
+	return sb.String()
+}
+
+// member 将对象ID转换为定长的成员名，使得分相同时成员按ID排序
+//
+// 参数：
+//   - id：对象ID
+//
+// 返回值：
+//   - string：成员名
+func (cache scoreCache) member(id uint64) string {
+	return sortedSetMember(id)
+}
+
+// sortedSetMember 将对象ID转换为定长的有序集合成员名
+//
+// 参数：
+//   - id：对象ID
+//
+// 返回值：
+//   - string：成员名
+func sortedSetMember(id uint64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+// scores 根据评价计数计算各排序方式的得分
+//
+// 参数：
+//   - count：评价计数
+//
+// 返回值：
+//   - map[string]float64：排序方式到得分的映射
+func (cache scoreCache) scores(count ratingCount) map[string]float64 {
+	return map[string]float64{
+		consts.COMMENT_SORT_TOP: float64(count.Likes),
+		consts.COMMENT_SORT_HOT: calculators.WilsonScore(count.Likes, count.Dislikes),
+	}
+}
+
+// exists 检查父对象的得分缓存是否存在
+//
+// 参数：
+//   - mode：排序方式
+//   - parentID：父对象ID
+//
+// 返回值：
+//   - bool：缓存存在返回 true
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (cache scoreCache) exists(mode string, parentID uint64) (bool, error) {
+	count, err := cache.rds.Exists(context.Background(), cache.key(mode, parentID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// rebuild 重建父对象下所有排序方式的得分缓存
+//
+// 参数：
+//   - parentID：父对象ID
+//   - counts：父对象下所有对象的评价计数
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (cache scoreCache) rebuild(parentID uint64, counts []ratingCount) error {
+	ctx := context.Background()
+	members := map[string][]redis.Z{}
+	for _, count := range counts {
+		for mode, score := range cache.scores(count) {
+			members[mode] = append(members[mode], redis.Z{Score: score, Member: cache.member(count.ID)})
+		}
+	}
+
+	tx := cache.rds.TxPipeline()
+	for _, mode := range []string{consts.COMMENT_SORT_TOP, consts.COMMENT_SORT_HOT} {
+		key := cache.key(mode, parentID)
+		tx.Del(ctx, key)
+		if len(members[mode]) > 0 {
+			tx.ZAdd(ctx, key, members[mode]...)
+			tx.Expire(ctx, key, consts.SCORE_CACHE_EXPIRE*time.Second)
+		}
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+// update 更新单个对象的得分，缓存不存在时不做处理，待下次读取时重建
+//
+// 参数：
+//   - parentID：父对象ID
+//   - count：对象的评价计数
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (cache scoreCache) update(parentID uint64, count ratingCount) error {
+	ctx := context.Background()
+	for mode, score := range cache.scores(count) {
+		exists, err := cache.exists(mode, parentID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if err := cache.rds.ZAdd(ctx, cache.key(mode, parentID), redis.Z{Score: score, Member: cache.member(count.ID)}).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove 从得分缓存中移除对象
+//
+// 参数：
+//   - parentID：父对象ID
+//   - id：对象ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (cache scoreCache) remove(parentID, id uint64) error {
+	ctx := context.Background()
+	tx := cache.rds.TxPipeline()
+	for _, mode := range []string{consts.COMMENT_SORT_TOP, consts.COMMENT_SORT_HOT} {
+		tx.ZRem(ctx, cache.key(mode, parentID), cache.member(id))
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+// page 按得分倒序分页获取对象ID
+//
+// 参数：
+//   - mode：排序方式
+//   - parentID：父对象ID
+//   - fromScore：上一页最后一个对象的得分
+//   - fromID：上一页最后一个对象的ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []uint64：对象ID列表
+//   - []float64：对应的得分
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (cache scoreCache) page(mode string, parentID uint64, fromScore float64, fromID uint64, length int) ([]uint64, []float64, error) {
+	return pageSortedSet(cache.rds, cache.key(mode, parentID), fromScore, fromID, length)
+}
+
+// pageSortedSet 按得分倒序分页获取有序集合中的对象ID，成员须为定长的对象ID
+//
+// 参数：
+//   - rds：Redis 连接
+//   - key：有序集合的键
+//   - fromScore：上一页最后一个对象的得分
+//   - fromID：上一页最后一个对象的ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []uint64：对象ID列表
+//   - []float64：对应的得分
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func pageSortedSet(rds *redis.Client, key string, fromScore float64, fromID uint64, length int) ([]uint64, []float64, error) {
+	ctx := context.Background()
+
+	// 通过上一页最后一个对象的排名定位起始位置，对象已被移除时按得分定位
+	var start int64
+	if fromID != 0 {
+		rank, err := rds.ZRevRank(ctx, key, sortedSetMember(fromID)).Result()
+		switch {
+		case err == nil:
+			start = rank + 1
+		case errors.Is(err, redis.Nil):
+			start, err = rds.ZCount(ctx, key, "("+strconv.FormatFloat(fromScore, 'g', -1, 64), "+inf").Result()
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, err
+		}
+	}
+
+	members, err := rds.ZRevRangeWithScores(ctx, key, start, start+int64(length)-1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint64, 0, len(members))
+	scores := make([]float64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member.Member.(string), 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		scores = append(scores, member.Score)
+	}
+	return ids, scores, nil
+}
+
+// countRatings 按对象和评价类型分组统计评价集合中的点赞和点踩数
+//
+// 参数：
+//   - collection：评价集合
+//   - idField：对象ID所在的字段
+//   - ids：对象ID列表
+//
+// 返回值：
+//   - []ratingCount：评价计数，每个对象一条
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func countRatings(collection *mongo.Collection, idField string, ids []uint64) ([]ratingCount, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: idField, Value: bson.D{{Key: "$in", Value: ids}}},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "target_id", Value: "$" + idField},
+				{Key: "rate", Value: "$rate"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+	ctx := context.Background()
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			TargetID uint64 `bson:"target_id"`
+			Rate     string `bson:"rate"`
+		} `bson:"_id"`
+		Count int64 `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint64]*ratingCount, len(ids))
+	for _, id := range ids {
+		counts[id] = &ratingCount{ID: id}
+	}
+	for _, group := range groups {
+		count, ok := counts[group.ID.TargetID]
+		if !ok {
+			continue
+		}
+		switch group.ID.Rate {
+		case "like":
+			count.Likes = group.Count
+		case "dislike":
+			count.Dislikes = group.Count
+		}
+	}
+
+	ratingCounts := make([]ratingCount, 0, len(counts))
+	for _, count := range counts {
+		ratingCounts = append(ratingCounts, *count)
+	}
+	return ratingCounts, nil
+}
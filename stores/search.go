@@ -0,0 +1,327 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for search index synchronization storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/engines"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	search "github.com/Kirisakiii/neko-micro-blog-backend/proto"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/calculators"
+)
+
+// SearchIndexStore 搜索索引同步存储，通过发件箱表保证搜索引擎与数据库最终一致
+type SearchIndexStore struct {
+	db           *gorm.DB
+	rds          *redis.Client
+	searchEngine engines.SearchEngine
+}
+
+// NewSearchIndexStore 返回一个新的搜索索引同步存储实例。
+//
+// 返回：
+//   - *SearchIndexStore: 返回一个指向新的搜索索引同步存储实例的指针。
+func (factory *Factory) NewSearchIndexStore() *SearchIndexStore {
+	return &SearchIndexStore{
+		db:           factory.db,
+		rds:          factory.rds,
+		searchEngine: factory.searchEngine,
+	}
+}
+
+// enqueueSearchIndex 在事务中写入待同步的索引操作
+//
+// 参数：
+//   - tx：数据库事务
+//   - postID：博文ID
+//   - operation：索引操作：create 或 delete
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func enqueueSearchIndex(tx *gorm.DB, postID uint64, operation string) error {
+	return tx.Create(&models.SearchIndexOutbox{
+		PostID:        postID,
+		Operation:     operation,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// claimTasks 领取到期的索引操作，领取后在租约期内不会被其他实例重复领取
+//
+// 参数：
+//   - limit：领取数量
+//
+// 返回值：
+//   - []models.SearchIndexOutbox：按写入顺序排列的索引操作
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) claimTasks(limit int) ([]models.SearchIndexOutbox, error) {
+	var tasks []models.SearchIndexOutbox
+	now := time.Now()
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_attempt_at <= ?", now).
+			Order("id asc").
+			Limit(limit).
+			Find(&tasks)
+		if result.Error != nil || len(tasks) == 0 {
+			return result.Error
+		}
+		taskIDs := make([]uint64, len(tasks))
+		for index, task := range tasks {
+			taskIDs[index] = task.ID
+		}
+		return tx.Model(&models.SearchIndexOutbox{}).
+			Where("id IN ?", taskIDs).
+			Update("next_attempt_at", now.Add(consts.SEARCH_INDEX_LEASE*time.Second)).Error
+	})
+	return tasks, err
+}
+
+// syncTask 将索引操作写入搜索引擎，执行前重新读取博文的当前状态，
+// 同一博文的多个操作乱序执行时以博文的最终状态为准
+//
+// 参数：
+//   - task：索引操作
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) syncTask(task models.SearchIndexOutbox) error {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.SEARCH_INDEX_RPC_TIMEOUT*time.Second)
+	defer cancel()
+
+	var post models.PostInfo
+	err := store.db.Where("id = ?", task.PostID).First(&post).Error
+	exists := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	switch task.Operation {
+	case consts.SEARCH_INDEX_OP_CREATE:
+		if !exists {
+			// 博文在创建或恢复后又被删除，删除操作会移除可能残留的索引
+			return nil
+		}
+		return store.searchEngine.CreatePostIndex(ctx, &search.CreatePostIndexRequest{
+			Id:        int64(post.ID),
+			Title:     post.Title,
+			Content:   post.Content,
+			Uid:       post.UID,
+			CreatedAt: post.CreatedAt.Unix(),
+		})
+	case consts.SEARCH_INDEX_OP_DELETE:
+		if exists {
+			// 博文在删除后又被恢复，保留恢复时写入的索引
+			return nil
+		}
+		return store.searchEngine.DeletePostIndex(ctx, &search.DeletePostIndexRequest{Id: int64(task.PostID)})
+	default:
+		// 无法识别的操作重试也不会成功，直接丢弃
+		return nil
+	}
+}
+
+// SyncSearchIndex 领取一批到期的索引操作并写入搜索引擎，失败的操作按指数退避重新排期
+//
+// 参数：
+//   - limit：每批处理的数量
+//
+// 返回值：
+//   - int：领取的操作数量
+//   - int：失败的操作数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) SyncSearchIndex(limit int) (int, int, error) {
+	tasks, err := store.claimTasks(limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	failed := 0
+	for _, task := range tasks {
+		syncErr := store.syncTask(task)
+		if syncErr == nil {
+			if err := store.db.Where("id = ?", task.ID).Delete(&models.SearchIndexOutbox{}).Error; err != nil {
+				return len(tasks), failed, err
+			}
+			continue
+		}
+
+		failed++
+		attempts := task.Attempts + 1
+		delay := calculators.Backoff(attempts, consts.SEARCH_INDEX_RETRY_BASE*time.Second, consts.SEARCH_INDEX_RETRY_MAX*time.Second)
+		err := store.db.Model(&models.SearchIndexOutbox{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"next_attempt_at": time.Now().Add(delay),
+			"last_error":      fmt.Sprintf("%s post %d: %v", task.Operation, task.PostID, syncErr),
+		}).Error
+		if err != nil {
+			return len(tasks), failed, err
+		}
+	}
+	return len(tasks), failed, nil
+}
+
+// GetPublicPostsAfter 按博文ID正序获取指定ID之后的公开博文
+//
+// 参数：
+//   - afterID：起始博文ID，不包含该博文
+//   - limit：获取数量
+//
+// 返回值：
+//   - []models.PostInfo：博文信息，仅包含ID、作者、创建时间、标题和内容
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) GetPublicPostsAfter(afterID uint64, limit int) ([]models.PostInfo, error) {
+	var posts []models.PostInfo
+	result := store.db.Select("id", "uid", "created_at", "title", "content").
+		Where("is_public = ? AND id > ?", true, afterID).
+		Order("id asc").
+		Limit(limit).
+		Find(&posts)
+	return posts, result.Error
+}
+
+// CountPublicPostsAfter 统计指定ID之后的公开博文数量
+//
+// 参数：
+//   - afterID：起始博文ID，不包含该博文
+//
+// 返回值：
+//   - int64：博文数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) CountPublicPostsAfter(afterID uint64) (int64, error) {
+	var count int64
+	result := store.db.Model(&models.PostInfo{}).Where("is_public = ? AND id > ?", true, afterID).Count(&count)
+	return count, result.Error
+}
+
+// BulkIndexPosts 将一批博文写入搜索引擎
+//
+// 参数：
+//   - posts：博文信息
+//
+// 返回值：
+//   - int64：搜索引擎确认写入的博文数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) BulkIndexPosts(posts []models.PostInfo) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.SEARCH_REINDEX_RPC_TIMEOUT*time.Second)
+	defer cancel()
+
+	documents := make([]*search.PostDocument, len(posts))
+	for index, post := range posts {
+		documents[index] = &search.PostDocument{
+			Id:        int64(post.ID),
+			Title:     post.Title,
+			Content:   post.Content,
+			Uid:       post.UID,
+			CreatedAt: post.CreatedAt.Unix(),
+		}
+	}
+	return store.searchEngine.BulkIndexPosts(ctx, documents)
+}
+
+// SearchPosts 通过搜索引擎搜索博文
+//
+// 参数：
+//   - request：搜索请求
+//
+// 返回值：
+//   - *search.SearchResponse：搜索结果
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) SearchPosts(request *search.SearchRequest) (*search.SearchResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.SEARCH_QUERY_RPC_TIMEOUT*time.Second)
+	defer cancel()
+	return store.searchEngine.Search(ctx, request)
+}
+
+// CheckSearchEngineHealth 检查搜索引擎是否可用，不支持健康检查的搜索引擎视为可用
+//
+// 返回值：
+//   - error：如果搜索引擎不可用，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) CheckSearchEngineHealth() error {
+	checker, ok := store.searchEngine.(engines.HealthChecker)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), consts.SEARCH_HEALTH_CHECK_TIMEOUT*time.Second)
+	defer cancel()
+	return checker.CheckHealth(ctx)
+}
+
+// EnqueueDeletedPosts 为已不存在的博文写入删除索引操作，避免重建期间被删除的博文重新出现在索引中
+//
+// 参数：
+//   - postIDs：已写入搜索引擎的博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) EnqueueDeletedPosts(postIDs []uint64) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	var existingIDs []uint64
+	if err := store.db.Model(&models.PostInfo{}).Where("id IN ?", postIDs).Pluck("id", &existingIDs).Error; err != nil {
+		return err
+	}
+	existing := make(map[uint64]struct{}, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = struct{}{}
+	}
+	for _, postID := range postIDs {
+		if _, ok := existing[postID]; ok {
+			continue
+		}
+		if err := enqueueSearchIndex(store.db, postID, consts.SEARCH_INDEX_OP_DELETE); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetReindexCheckpoint 获取全量重建索引的检查点
+//
+// 返回值：
+//   - uint64：最后一篇已确认写入的博文ID，没有检查点时为 0
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) GetReindexCheckpoint() (uint64, error) {
+	value, err := store.rds.Get(context.Background(), consts.REDIS_SEARCH_REINDEX_CHECKPOINT).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// SaveReindexCheckpoint 保存全量重建索引的检查点
+//
+// 参数：
+//   - postID：最后一篇已确认写入的博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) SaveReindexCheckpoint(postID uint64) error {
+	return store.rds.Set(context.Background(), consts.REDIS_SEARCH_REINDEX_CHECKPOINT, postID, 0).Err()
+}
+
+// ClearReindexCheckpoint 清除全量重建索引的检查点
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) ClearReindexCheckpoint() error {
+	return store.rds.Del(context.Background(), consts.REDIS_SEARCH_REINDEX_CHECKPOINT).Err()
+}
@@ -0,0 +1,296 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for user and hashtag search index storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/converters"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+// likeEscaper 转义 LIKE 模式中的通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// newUserSearchIndex 根据用户信息生成用户搜索索引
+//
+// 参数：
+//   - user：用户信息
+//
+// 返回值：
+//   - models.UserSearchIndex：用户搜索索引
+func newUserSearchIndex(user models.UserInfo) models.UserSearchIndex {
+	var nickname string
+	if user.NickName != nil {
+		nickname = strings.ToLower(*user.NickName)
+	}
+	pinyin, pinyinInitials := converters.ToPinyin(nickname)
+	return models.UserSearchIndex{
+		UID:            uint64(user.ID),
+		UserName:       strings.ToLower(user.UserName),
+		NickName:       nickname,
+		Pinyin:         pinyin,
+		PinyinInitials: pinyinInitials,
+		UpdatedAt:      time.Now(),
+	}
+}
+
+// upsertUserSearchIndex 在事务中写入或更新用户搜索索引
+//
+// 参数：
+//   - tx：数据库事务
+//   - users：用户信息
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func upsertUserSearchIndex(tx *gorm.DB, users ...models.UserInfo) error {
+	if len(users) == 0 {
+		return nil
+	}
+	indexes := make([]models.UserSearchIndex, len(users))
+	for i, user := range users {
+		indexes[i] = newUserSearchIndex(user)
+	}
+	return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&indexes).Error
+}
+
+// addHashtagSearchIndex 在事务中为话题增加一次使用记录
+//
+// 参数：
+//   - tx：数据库事务
+//   - hashtags：去重后的小写话题列表
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func addHashtagSearchIndex(tx *gorm.DB, hashtags []string) error {
+	if len(hashtags) == 0 {
+		return nil
+	}
+	now := time.Now()
+	indexes := make([]models.HashtagSearchIndex, len(hashtags))
+	for i, hashtag := range hashtags {
+		pinyin, pinyinInitials := converters.ToPinyin(hashtag)
+		indexes[i] = models.HashtagSearchIndex{
+			Name:           hashtag,
+			Pinyin:         pinyin,
+			PinyinInitials: pinyinInitials,
+			PostCount:      1,
+			LastUsedAt:     now,
+		}
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"post_count":   gorm.Expr("hashtag_search_indices.post_count + 1"),
+			"last_used_at": now,
+		}),
+	}).Create(&indexes).Error
+}
+
+// addPostHashtagRecords 在事务中记录博文使用的话题，已存在的记录会被忽略
+//
+// 参数：
+//   - tx：数据库事务
+//   - postID：博文ID
+//   - uid：作者ID
+//   - hashtags：去重后的小写话题列表
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func addPostHashtagRecords(tx *gorm.DB, postID, uid uint64, hashtags []string) error {
+	if len(hashtags) == 0 {
+		return nil
+	}
+	records := make([]models.PostHashtagRecord, len(hashtags))
+	for i, hashtag := range hashtags {
+		records[i] = models.PostHashtagRecord{PostID: postID, Name: hashtag, UID: uid}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error
+}
+
+// removeHashtagSearchIndex 在事务中为话题减少一次使用记录
+//
+// 参数：
+//   - tx：数据库事务
+//   - hashtags：去重后的小写话题列表
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func removeHashtagSearchIndex(tx *gorm.DB, hashtags []string) error {
+	if len(hashtags) == 0 {
+		return nil
+	}
+	return tx.Model(&models.HashtagSearchIndex{}).
+		Where("name IN ?", hashtags).
+		Update("post_count", gorm.Expr("GREATEST(post_count - 1, 0)")).Error
+}
+
+// SearchUsers 按用户名、昵称或昵称拼音前缀搜索用户，完全匹配优先，其次为用户名和昵称前缀，最后为拼音前缀
+//
+// 参数：
+//   - keyword：小写搜索关键字
+//   - hidden：对查看者不可见的用户
+//   - offset：结果偏移量
+//   - limit：获取数量
+//
+// 返回值：
+//   - []uint64：用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) SearchUsers(keyword string, hidden HiddenAuthors, offset, limit int) ([]uint64, error) {
+	prefix := likeEscaper.Replace(keyword) + "%"
+	query := store.db.Model(&models.UserSearchIndex{}).
+		Where("username LIKE ? OR nickname LIKE ? OR pinyin LIKE ? OR pinyin_initials LIKE ?", prefix, prefix, prefix, prefix)
+	query = hidden.apply(query, "user_search_indices.uid")
+
+	var uids []uint64
+	result := query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "CASE WHEN username = ? OR nickname = ? THEN 0 WHEN username LIKE ? OR nickname LIKE ? THEN 1 ELSE 2 END, length(nickname), uid",
+		Vars:               []interface{}{keyword, keyword, prefix, prefix},
+		WithoutParentheses: true,
+	}}).
+		Offset(offset).
+		Limit(limit).
+		Pluck("uid", &uids)
+	return uids, result.Error
+}
+
+// SearchHashtags 按话题或话题拼音前缀搜索话题，完全匹配优先，其余按使用次数倒序排列
+//
+// 参数：
+//   - keyword：小写搜索关键字，不包含 # 前缀
+//   - offset：结果偏移量
+//   - limit：获取数量
+//
+// 返回值：
+//   - []models.HashtagSearchIndex：话题列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) SearchHashtags(keyword string, offset, limit int) ([]models.HashtagSearchIndex, error) {
+	prefix := likeEscaper.Replace(keyword) + "%"
+	var hashtags []models.HashtagSearchIndex
+	result := store.db.
+		Where("post_count > 0 AND (name LIKE ? OR pinyin LIKE ? OR pinyin_initials LIKE ?)", prefix, prefix, prefix).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN name = ? THEN 0 WHEN name LIKE ? THEN 1 ELSE 2 END, post_count DESC, name",
+			Vars:               []interface{}{keyword, prefix},
+			WithoutParentheses: true,
+		}}).
+		Offset(offset).
+		Limit(limit).
+		Find(&hashtags)
+	return hashtags, result.Error
+}
+
+// RebuildUserSearchIndex 按用户ID分批重建用户搜索索引，并删除已不存在的用户的索引
+//
+// 参数：
+//   - batchSize：每批处理的用户数量
+//
+// 返回值：
+//   - int64：已索引的用户数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) RebuildUserSearchIndex(batchSize int) (int64, error) {
+	var (
+		indexed int64
+		afterID uint
+	)
+	for {
+		var users []models.UserInfo
+		result := store.db.Select("id", "username", "nickname").
+			Where("id > ?", afterID).
+			Order("id asc").
+			Limit(batchSize).
+			Find(&users)
+		if result.Error != nil {
+			return indexed, result.Error
+		}
+		if len(users) == 0 {
+			break
+		}
+		if err := upsertUserSearchIndex(store.db, users...); err != nil {
+			return indexed, err
+		}
+		indexed += int64(len(users))
+		afterID = users[len(users)-1].ID
+		if len(users) < batchSize {
+			break
+		}
+	}
+
+	result := store.db.
+		Where("uid NOT IN (?)", store.db.Model(&models.UserInfo{}).Select("id")).
+		Delete(&models.UserSearchIndex{})
+	return indexed, result.Error
+}
+
+// RebuildHashtagSearchIndex 按博文ID分批统计所有博文中的话题并替换话题搜索索引，同时补全博文话题记录，重建期间发布的博文可能未被统计
+//
+// 参数：
+//   - batchSize：每批读取的博文数量
+//
+// 返回值：
+//   - int：已索引的话题数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SearchIndexStore) RebuildHashtagSearchIndex(batchSize int) (int, error) {
+	indexes := make(map[string]*models.HashtagSearchIndex)
+	var afterID uint
+	for {
+		var posts []models.PostInfo
+		result := store.db.Select("id", "uid", "created_at", "title", "content").
+			Where("id > ?", afterID).
+			Order("id asc").
+			Limit(batchSize).
+			Find(&posts)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if len(posts) == 0 {
+			break
+		}
+		for _, post := range posts {
+			hashtags := parsers.ParseHashtags(post.Title + " " + post.Content)
+			if err := addPostHashtagRecords(store.db, uint64(post.ID), post.UID, hashtags); err != nil {
+				return 0, err
+			}
+			for _, hashtag := range hashtags {
+				index, ok := indexes[hashtag]
+				if !ok {
+					pinyin, pinyinInitials := converters.ToPinyin(hashtag)
+					index = &models.HashtagSearchIndex{Name: hashtag, Pinyin: pinyin, PinyinInitials: pinyinInitials}
+					indexes[hashtag] = index
+				}
+				index.PostCount++
+				if post.CreatedAt.After(index.LastUsedAt) {
+					index.LastUsedAt = post.CreatedAt
+				}
+			}
+		}
+		afterID = posts[len(posts)-1].ID
+		if len(posts) < batchSize {
+			break
+		}
+	}
+
+	rows := make([]models.HashtagSearchIndex, 0, len(indexes))
+	for _, index := range indexes {
+		rows = append(rows, *index)
+	}
+	err := store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.HashtagSearchIndex{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, batchSize).Error
+	})
+	return len(rows), err
+}
@@ -0,0 +1,46 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for sensitive word storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/filters"
+)
+
+// SensitiveWordStore 敏感词库存储
+type SensitiveWordStore struct {
+	filter *filters.SensitiveFilter
+}
+
+// NewSensitiveWordStore 返回一个新的敏感词库存储实例。
+//
+// 返回：
+//   - *SensitiveWordStore: 返回一个指向新的敏感词库存储实例的指针。
+func (factory *Factory) NewSensitiveWordStore() *SensitiveWordStore {
+	return &SensitiveWordStore{
+		filter: factory.sensitive,
+	}
+}
+
+// CheckText 检查文本中的敏感词
+//
+// 参数：
+//   - text：待检查的文本
+//
+// 返回值：
+//   - filters.Result：检查结果
+func (store *SensitiveWordStore) CheckText(text string) filters.Result {
+	return store.filter.Check(text)
+}
+
+// ReloadSensitiveWords 词库文件发生变化时重新加载敏感词库，加载失败时继续使用原有词库
+//
+// 返回值：
+//   - int：重新加载后的规则数量，词库未变化时为 -1
+//   - error：如果加载词库失败，返回相应错误信息；否则返回 nil
+func (store *SensitiveWordStore) ReloadSensitiveWords() (int, error) {
+	return store.filter.Reload()
+}
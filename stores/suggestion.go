@@ -0,0 +1,528 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for follow suggestion storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+// SuggestionStore 关注推荐数据库
+type SuggestionStore struct {
+	db    *gorm.DB
+	rds   *redis.Client
+	mongo *mongo.Client
+}
+
+// NewSuggestionStore 返回一个新的关注推荐存储实例。
+//
+// 返回：
+//   - *SuggestionStore: 返回一个指向新的关注推荐存储实例的指针。
+func (factory *Factory) NewSuggestionStore() *SuggestionStore {
+	return &SuggestionStore{
+		db:    factory.db,
+		rds:   factory.rds,
+		mongo: factory.mongo,
+	}
+}
+
+// ComputeSuggestions 根据关注关系、共同话题和共同点赞计算关注推荐
+//
+// 参数：
+//   - uid：用户ID
+//   - limit：最大推荐数量
+//
+// 返回值：
+//   - []types.FollowSuggestion：按得分降序排列的关注推荐
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) ComputeSuggestions(uid uint64, limit int) ([]types.FollowSuggestion, error) {
+	candidates := make(map[uint64]*types.FollowSuggestion)
+	getCandidate := func(candidateID uint64) *types.FollowSuggestion {
+		candidate, ok := candidates[candidateID]
+		if !ok {
+			candidate = &types.FollowSuggestion{UID: candidateID}
+			candidates[candidateID] = candidate
+		}
+		return candidate
+	}
+
+	// 好友的好友
+	friendIDs, err := store.getFollowedIDs(uid)
+	if err != nil {
+		return nil, err
+	}
+	friendsOfFriends, err := store.getFriendsOfFriends(friendIDs)
+	if err != nil {
+		return nil, err
+	}
+	for candidateID, viaFriendIDs := range friendsOfFriends {
+		candidate := getCandidate(candidateID)
+		candidate.FriendCount = len(viaFriendIDs)
+		if len(viaFriendIDs) > consts.FOLLOW_SUGGESTION_REASON_USER_LIMIT {
+			viaFriendIDs = viaFriendIDs[:consts.FOLLOW_SUGGESTION_REASON_USER_LIMIT]
+		}
+		candidate.FriendIDs = viaFriendIDs
+		candidate.Score += float64(candidate.FriendCount) * consts.FOLLOW_SUGGESTION_FRIEND_WEIGHT
+	}
+
+	// 共同话题
+	sharedHashtags, err := store.getSharedHashtagUsers(uid)
+	if err != nil {
+		return nil, err
+	}
+	for candidateID, hashtags := range sharedHashtags {
+		candidate := getCandidate(candidateID)
+		candidate.SharedHashtags = hashtags
+		candidate.Score += float64(len(hashtags)) * consts.FOLLOW_SUGGESTION_HASHTAG_WEIGHT
+	}
+
+	// 共同点赞
+	sharedLikes, err := store.getSharedLikeUsers(uid)
+	if err != nil {
+		return nil, err
+	}
+	for candidateID, count := range sharedLikes {
+		candidate := getCandidate(candidateID)
+		candidate.SharedLikes = count
+		candidate.Score += float64(count) * consts.FOLLOW_SUGGESTION_LIKE_WEIGHT
+	}
+
+	// 排除自己、已关注、已发送关注请求、存在拉黑关系以及已忽略的用户
+	excludedIDs, err := store.getExcludedIDs(uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, friendID := range friendIDs {
+		excludedIDs[friendID] = struct{}{}
+	}
+
+	suggestions := make([]types.FollowSuggestion, 0, len(candidates))
+	for candidateID, candidate := range candidates {
+		if _, ok := excludedIDs[candidateID]; ok {
+			continue
+		}
+		suggestions = append(suggestions, *candidate)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].UID < suggestions[j].UID
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// getFollowedIDs 获取用户最近关注的用户ID
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []uint64：关注的用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) getFollowedIDs(uid uint64) ([]uint64, error) {
+	filter := bson.D{{Key: "uid", Value: uid}}
+	sort := bson.D{{Key: "followed_at", Value: -1}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(sort).SetLimit(consts.FOLLOW_SUGGESTION_SOURCE_LIMIT),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followInfos []models.FollowInfo
+	if err := cursor.All(ctx, &followInfos); err != nil {
+		return nil, err
+	}
+	followedIDs := make([]uint64, len(followInfos))
+	for index, followInfo := range followInfos {
+		followedIDs[index] = followInfo.FollowedID
+	}
+	return followedIDs, nil
+}
+
+// getFriendsOfFriends 获取好友最近关注的用户，以及关注了这些用户的好友
+//
+// 参数：
+//   - friendIDs：好友ID列表
+//
+// 返回值：
+//   - map[uint64][]uint64：被关注用户ID到好友ID列表的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) getFriendsOfFriends(friendIDs []uint64) (map[uint64][]uint64, error) {
+	if len(friendIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.D{{Key: "uid", Value: bson.D{{Key: "$in", Value: friendIDs}}}}
+	sort := bson.D{{Key: "followed_at", Value: -1}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_RECORD_COLLECTION).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(sort).SetLimit(consts.FOLLOW_SUGGESTION_CANDIDATE_LIMIT),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followInfos []models.FollowInfo
+	if err := cursor.All(ctx, &followInfos); err != nil {
+		return nil, err
+	}
+	friendsOfFriends := make(map[uint64][]uint64)
+	for _, followInfo := range followInfos {
+		friendsOfFriends[followInfo.FollowedID] = append(friendsOfFriends[followInfo.FollowedID], followInfo.UserID)
+	}
+	return friendsOfFriends, nil
+}
+
+// getSharedHashtagUsers 获取与用户在博文中使用过相同话题的用户
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - map[uint64][]string：用户ID到共同话题列表的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) getSharedHashtagUsers(uid uint64) (map[uint64][]string, error) {
+	// 统计用户最近博文中的话题
+	var contents []string
+	result := store.db.Model(&models.PostInfo{}).
+		Where("uid = ?", uid).
+		Order("id desc").
+		Limit(consts.FOLLOW_SUGGESTION_SOURCE_LIMIT).
+		Pluck("content", &contents)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	hashtagCounts := make(map[string]int)
+	for _, content := range contents {
+		for _, hashtag := range parsers.ParseHashtags(content) {
+			hashtagCounts[hashtag]++
+		}
+	}
+	hashtags := make([]string, 0, len(hashtagCounts))
+	for hashtag := range hashtagCounts {
+		hashtags = append(hashtags, hashtag)
+	}
+	sort.Slice(hashtags, func(i, j int) bool {
+		if hashtagCounts[hashtags[i]] != hashtagCounts[hashtags[j]] {
+			return hashtagCounts[hashtags[i]] > hashtagCounts[hashtags[j]]
+		}
+		return hashtags[i] < hashtags[j]
+	})
+	if len(hashtags) > consts.FOLLOW_SUGGESTION_HASHTAG_LIMIT {
+		hashtags = hashtags[:consts.FOLLOW_SUGGESTION_HASHTAG_LIMIT]
+	}
+
+	// 通过话题记录查找最近使用了相同话题的其他用户，已删除或被隐藏的博文不计入
+	sharedHashtags := make(map[uint64][]string)
+	for _, hashtag := range hashtags {
+		var uids []uint64
+		result := store.db.Model(&models.PostHashtagRecord{}).
+			Joins("JOIN post_infos ON post_infos.id = post_hashtag_records.post_id AND post_infos.deleted_at IS NULL").
+			Where("post_hashtag_records.name = ? AND post_hashtag_records.uid <> ?", hashtag, uid).
+			Order("post_hashtag_records.post_id desc").
+			Limit(consts.FOLLOW_SUGGESTION_SOURCE_LIMIT).
+			Pluck("post_hashtag_records.uid", &uids)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		matchedUIDs := make(map[uint64]struct{}, len(uids))
+		for _, matchedUID := range uids {
+			if _, ok := matchedUIDs[matchedUID]; ok {
+				continue
+			}
+			matchedUIDs[matchedUID] = struct{}{}
+			sharedHashtags[matchedUID] = append(sharedHashtags[matchedUID], hashtag)
+		}
+	}
+	return sharedHashtags, nil
+}
+
+// getSharedLikeUsers 获取与用户点赞过相同博文的用户
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - map[uint64]int：用户ID到共同点赞博文数量的映射
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) getSharedLikeUsers(uid uint64) (map[uint64]int, error) {
+	postLikeCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION)
+	ctx := context.Background()
+
+	// 获取用户最近点赞的博文
+	filter := bson.D{{Key: "uid", Value: uid}}
+	sort := bson.D{{Key: "liked_at", Value: -1}}
+	cursor, err := postLikeCollection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(consts.FOLLOW_SUGGESTION_SOURCE_LIMIT))
+	if err != nil {
+		return nil, err
+	}
+	var likedPosts []struct {
+		PostID int64 `bson:"post_id"`
+	}
+	err = cursor.All(ctx, &likedPosts)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(likedPosts) == 0 {
+		return nil, nil
+	}
+	postIDs := make([]int64, len(likedPosts))
+	for index, likedPost := range likedPosts {
+		postIDs[index] = likedPost.PostID
+	}
+
+	// 获取最近点赞过这些博文的其他用户
+	filter = bson.D{
+		{Key: "post_id", Value: bson.D{{Key: "$in", Value: postIDs}}},
+		{Key: "uid", Value: bson.D{{Key: "$ne", Value: uid}}},
+	}
+	sort = bson.D{{Key: "liked_at", Value: -1}}
+	cursor, err = postLikeCollection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(consts.FOLLOW_SUGGESTION_CANDIDATE_LIMIT))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var likes []struct {
+		UID uint64 `bson:"uid"`
+	}
+	if err := cursor.All(ctx, &likes); err != nil {
+		return nil, err
+	}
+
+	sharedLikes := make(map[uint64]int)
+	for _, like := range likes {
+		sharedLikes[like.UID]++
+	}
+	return sharedLikes, nil
+}
+
+// getExcludedIDs 获取不应被推荐给用户的用户ID集合
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - map[uint64]struct{}：需要排除的用户ID集合，包括用户自身、已发送关注请求、存在拉黑关系和已忽略的用户
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) getExcludedIDs(uid uint64) (map[uint64]struct{}, error) {
+	excludedIDs := map[uint64]struct{}{uid: {}}
+	database := store.mongo.Database(consts.MONGODB_DATABASE_NAME)
+	ctx := context.Background()
+
+	// 已发送关注请求的用户
+	cursor, err := database.Collection(consts.FOLLOW_REQUEST_COLLECTION).Find(ctx, bson.D{{Key: "uid", Value: uid}})
+	if err != nil {
+		return nil, err
+	}
+	var requestInfos []models.FollowRequestInfo
+	err = cursor.All(ctx, &requestInfos)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, requestInfo := range requestInfos {
+		excludedIDs[requestInfo.FollowedID] = struct{}{}
+	}
+
+	// 任一方向存在拉黑关系的用户
+	blockFilter := bson.D{
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "uid", Value: uid}},
+			bson.D{{Key: "blocked_id", Value: uid}},
+		}},
+	}
+	cursor, err = database.Collection(consts.BLOCK_RECORD_COLLECTION).Find(ctx, blockFilter)
+	if err != nil {
+		return nil, err
+	}
+	var blockInfos []models.BlockInfo
+	err = cursor.All(ctx, &blockInfos)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, blockInfo := range blockInfos {
+		excludedIDs[blockInfo.UserID] = struct{}{}
+		excludedIDs[blockInfo.BlockedID] = struct{}{}
+	}
+
+	// 已忽略的用户
+	dismissedIDs, err := store.GetDismissedIDs(uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, dismissedID := range dismissedIDs {
+		excludedIDs[dismissedID] = struct{}{}
+	}
+
+	return excludedIDs, nil
+}
+
+// suggestionCacheKey 获取关注推荐缓存键
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - string：缓存键
+func suggestionCacheKey(uid uint64) string {
+	var sb strings.Builder
+	sb.WriteString(consts.REDIS_FOLLOW_SUGGESTION_CACHE)
+	sb.WriteString(":")
+	sb.WriteString(strconv.FormatUint(uid, 10))
+	return sb.String()
+}
+
+// CacheSuggestions 缓存用户的关注推荐
+//
+// 参数：
+//   - uid：用户ID
+//   - suggestions：关注推荐
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) CacheSuggestions(uid uint64, suggestions []types.FollowSuggestion) error {
+	data, err := json.Marshal(suggestions)
+	if err != nil {
+		return err
+	}
+	return store.rds.Set(
+		context.Background(),
+		suggestionCacheKey(uid),
+		data,
+		consts.FOLLOW_SUGGESTION_CACHE_EXPIRE*time.Second,
+	).Err()
+}
+
+// GetCachedSuggestions 获取缓存的关注推荐
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []types.FollowSuggestion：关注推荐
+//   - bool：缓存是否存在
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) GetCachedSuggestions(uid uint64) ([]types.FollowSuggestion, bool, error) {
+	data, err := store.rds.Get(context.Background(), suggestionCacheKey(uid)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var suggestions []types.FollowSuggestion
+	if err := json.Unmarshal(data, &suggestions); err != nil {
+		return nil, false, err
+	}
+	return suggestions, true, nil
+}
+
+// DismissSuggestion 忽略关注推荐
+//
+// 参数：
+//   - uid：用户ID
+//   - dismissedID：被忽略的推荐用户ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) DismissSuggestion(uid, dismissedID uint64) error {
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "dismissed_id", Value: dismissedID},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "dismissed_at", Value: time.Now()},
+		}},
+	}
+
+	dismissCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_SUGGESTION_DISMISS_COLLECTION)
+	_, err := dismissCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetDismissedIDs 获取用户忽略的推荐用户ID
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - []uint64：被忽略的推荐用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) GetDismissedIDs(uid uint64) ([]uint64, error) {
+	filter := bson.D{{Key: "uid", Value: uid}}
+	ctx := context.Background()
+
+	cursor, err := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.FOLLOW_SUGGESTION_DISMISS_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dismissInfos []models.FollowSuggestionDismissInfo
+	if err := cursor.All(ctx, &dismissInfos); err != nil {
+		return nil, err
+	}
+	dismissedIDs := make([]uint64, len(dismissInfos))
+	for index, dismissInfo := range dismissInfos {
+		dismissedIDs[index] = dismissInfo.DismissedID
+	}
+	return dismissedIDs, nil
+}
+
+// GetActiveUIDs 获取最近登录过的用户ID
+//
+// 参数：
+//   - days：判定为活跃的天数
+//
+// 返回值：
+//   - []uint64：活跃用户ID列表
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *SuggestionStore) GetActiveUIDs(days int) ([]uint64, error) {
+	var uids []uint64
+	result := store.db.Model(&models.UserLoginLog{}).
+		Where("is_succeed = ? AND login_time > ?", true, time.Now().AddDate(0, 0, -days)).
+		Distinct("uid").
+		Pluck("uid", &uids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return uids, nil
+}
@@ -0,0 +1,347 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for trending posts and hashtags storage accessing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+)
+
+// hotWeights 各类互动对博文热度的权重
+var hotWeights = map[string]float64{
+	consts.POST_COUNTER_LIKES:      consts.POST_HOT_WEIGHT_LIKE,
+	consts.POST_COUNTER_FAVOURITES: consts.POST_HOT_WEIGHT_FAVOURITE,
+	consts.POST_COUNTER_COMMENTS:   consts.POST_HOT_WEIGHT_COMMENT,
+	consts.POST_COUNTER_REPOSTS:    consts.POST_HOT_WEIGHT_REPOST,
+	consts.POST_COUNTER_VIEWS:      consts.POST_HOT_WEIGHT_VIEW,
+}
+
+// decayScript 将有序集合中一段成员的得分乘以衰减系数
+var decayScript = redis.NewScript(`
+local members = redis.call("ZRANGE", KEYS[1], ARGV[1], ARGV[2], "WITHSCORES")
+for index = 1, #members, 2 do
+	redis.call("ZADD", KEYS[1], "XX", tonumber(members[index + 1]) * tonumber(ARGV[3]), members[index])
+end
+return #members / 2
+`)
+
+// contributeHashtagScript 在作者贡献未达上限时增加话题的小时热度
+var contributeHashtagScript = redis.NewScript(`
+local contributed = redis.call("HINCRBY", KEYS[2], ARGV[1], 1)
+redis.call("EXPIRE", KEYS[2], ARGV[4])
+if contributed > tonumber(ARGV[3]) then
+	return 0
+end
+redis.call("ZINCRBY", KEYS[1], 1, ARGV[2])
+redis.call("EXPIRE", KEYS[1], ARGV[4])
+return 1
+`)
+
+// TrendingStore 热门博文及热门话题存储
+type TrendingStore struct {
+	rds       *redis.Client
+	postStore *PostStore
+}
+
+// NewTrendingStore 返回一个新的热门存储实例。
+//
+// 返回：
+//   - *TrendingStore: 返回一个指向新的热门存储实例的指针。
+func (factory *Factory) NewTrendingStore() *TrendingStore {
+	return &TrendingStore{
+		rds:       factory.rds,
+		postStore: factory.NewPostStore(),
+	}
+}
+
+// AddHotPost 将新博文加入热门榜单
+//
+// 参数：
+//   - postID：博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) AddHotPost(postID uint64) error {
+	return store.rds.ZAddNX(context.Background(), consts.REDIS_POST_HOT_RANKING, redis.Z{
+		Score:  consts.POST_HOT_INITIAL_SCORE,
+		Member: sortedSetMember(postID),
+	}).Err()
+}
+
+// IncrHotScore 根据互动类型增加博文热度，已移出热门榜单的博文及作者对自己博文的互动不计入
+//
+// 参数：
+//   - postID：博文ID
+//   - actorUID：互动者ID，为 0 时表示匿名用户
+//   - field：互动计数字段
+//   - delta：互动数量的变化，可以为负数
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) IncrHotScore(postID, actorUID uint64, field string, delta int64) error {
+	weight, ok := hotWeights[field]
+	if !ok {
+		return nil
+	}
+	if actorUID != 0 {
+		post, err := store.postStore.GetPost(postID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if post.UID == actorUID {
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	args := redis.ZAddArgs{
+		XX:      true,
+		Members: []redis.Z{{Score: weight * float64(delta), Member: sortedSetMember(postID)}},
+	}
+	pipe := store.rds.Pipeline()
+	pipe.ZAddArgsIncr(ctx, consts.REDIS_POST_HOT_RANKING, args)
+	pipe.ZAddArgsIncr(ctx, consts.REDIS_POST_HOT_FEED, args)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveHotPost 将博文移出热门榜单
+//
+// 参数：
+//   - postID：博文ID
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) RemoveHotPost(postID uint64) error {
+	ctx := context.Background()
+	pipe := store.rds.TxPipeline()
+	pipe.ZRem(ctx, consts.REDIS_POST_HOT_RANKING, sortedSetMember(postID))
+	pipe.ZRem(ctx, consts.REDIS_POST_HOT_FEED, sortedSetMember(postID))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetHotPosts 按热度倒序分页获取按作者限额筛选后的博文ID
+//
+// 参数：
+//   - fromScore：上一页最后一篇博文的热度
+//   - fromID：上一页最后一篇博文的ID，为 0 时获取第一页
+//   - length：获取数量
+//
+// 返回值：
+//   - []uint64：博文ID列表
+//   - []float64：对应的热度
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) GetHotPosts(fromScore float64, fromID uint64, length int) ([]uint64, []float64, error) {
+	return pageSortedSet(store.rds, consts.REDIS_POST_HOT_FEED, fromScore, fromID, length)
+}
+
+// RebuildHotFeed 按热度倒序扫描热门榜单，每位作者只保留热度最高的若干篇博文，重建供分页的热门列表
+//
+// 返回值：
+//   - int：热门列表中的博文数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) RebuildHotFeed() (int, error) {
+	ctx := context.Background()
+	feed := make([]redis.Z, 0, consts.POST_HOT_FEED_SIZE)
+	authorCounts := make(map[uint64]int)
+	for start := int64(0); len(feed) < consts.POST_HOT_FEED_SIZE; start += consts.POST_HOT_DECAY_BATCH {
+		members, err := store.rds.ZRevRangeWithScores(ctx, consts.REDIS_POST_HOT_RANKING, start, start+consts.POST_HOT_DECAY_BATCH-1).Result()
+		if err != nil {
+			return 0, err
+		}
+		postIDs := make([]uint64, 0, len(members))
+		for _, member := range members {
+			postID, err := strconv.ParseUint(member.Member.(string), 10, 64)
+			if err != nil {
+				continue
+			}
+			postIDs = append(postIDs, postID)
+		}
+
+		// 榜单中的博文可能已被删除
+		posts, err := store.postStore.GetPostsByIDs(postIDs)
+		if err != nil {
+			return 0, err
+		}
+		authors := make(map[uint64]uint64, len(posts))
+		for _, post := range posts {
+			authors[uint64(post.ID)] = post.UID
+		}
+		for _, member := range members {
+			if len(feed) >= consts.POST_HOT_FEED_SIZE {
+				break
+			}
+			postID, err := strconv.ParseUint(member.Member.(string), 10, 64)
+			if err != nil {
+				continue
+			}
+			authorUID, ok := authors[postID]
+			if !ok || authorCounts[authorUID] >= consts.POST_HOT_AUTHOR_CAP {
+				continue
+			}
+			authorCounts[authorUID]++
+			feed = append(feed, member)
+		}
+		if len(members) < consts.POST_HOT_DECAY_BATCH {
+			break
+		}
+	}
+
+	// 写入临时键后原子替换，避免分页时读到重建中的列表
+	pipe := store.rds.TxPipeline()
+	if len(feed) == 0 {
+		pipe.Del(ctx, consts.REDIS_POST_HOT_FEED)
+	} else {
+		tempKey := consts.REDIS_POST_HOT_FEED + ":REBUILD"
+		pipe.Del(ctx, tempKey)
+		pipe.ZAdd(ctx, tempKey, feed...)
+		pipe.Rename(ctx, tempKey, consts.REDIS_POST_HOT_FEED)
+	}
+	_, err := pipe.Exec(ctx)
+	return len(feed), err
+}
+
+// DecayHotScores 按衰减系数降低所有博文的热度，并移除热度过低的博文
+//
+// 参数：
+//   - factor：衰减系数，范围为 (0, 1)
+//   - threshold：移除阈值
+//
+// 返回值：
+//   - int64：被移除的博文数量
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) DecayHotScores(factor, threshold float64) (int64, error) {
+	ctx := context.Background()
+	// 同比例衰减不改变排名，分批执行以免长时间阻塞 Redis
+	for start := int64(0); ; start += consts.POST_HOT_DECAY_BATCH {
+		stop := start + consts.POST_HOT_DECAY_BATCH - 1
+		decayed, err := decayScript.Run(ctx, store.rds, []string{consts.REDIS_POST_HOT_RANKING}, start, stop, factor).Int64()
+		if err != nil {
+			return 0, err
+		}
+		if decayed < consts.POST_HOT_DECAY_BATCH {
+			break
+		}
+	}
+	return store.rds.ZRemRangeByScore(ctx, consts.REDIS_POST_HOT_RANKING, "-inf", "("+strconv.FormatFloat(threshold, 'g', -1, 64)).Result()
+}
+
+// hashtagHourKey 获取话题某小时热度的键
+//
+// 参数：
+//   - prefix：键前缀
+//   - hour：统计小时
+//
+// 返回值：
+//   - string：缓存键
+func hashtagHourKey(prefix string, hour time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteString(":")
+	sb.WriteString(hour.Format(consts.HASHTAG_TRENDING_HOUR_LAYOUT))
+	return sb.String()
+}
+
+// RecordHashtags 记录博文中的话题，每位作者每小时对同一话题的贡献有上限
+//
+// 参数：
+//   - authorUID：作者ID
+//   - hashtags：话题列表
+//
+// 返回值：
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) RecordHashtags(authorUID uint64, hashtags []string) error {
+	ctx := context.Background()
+	now := time.Now()
+	trendingKey := hashtagHourKey(consts.REDIS_HASHTAG_TRENDING, now)
+	for _, hashtag := range hashtags {
+		authorKey := hashtagHourKey(consts.REDIS_HASHTAG_TRENDING_AUTHORS, now) + ":" + hashtag
+		err := contributeHashtagScript.Run(ctx, store.rds, []string{trendingKey, authorKey},
+			authorUID, hashtag, consts.HASHTAG_TRENDING_AUTHOR_CAP, consts.HASHTAG_TRENDING_KEY_EXPIRE,
+		).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHashtagHourlyCounts 获取最近两小时中热度最高的候选话题在统计窗口内每小时的热度
+//
+// 参数：
+//   - hours：统计窗口（小时），包含当前小时
+//   - candidates：候选话题数量
+//
+// 返回值：
+//   - map[string][]int64：话题到每小时热度的映射，按时间正序排列，最后一项为当前小时
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *TrendingStore) GetHashtagHourlyCounts(hours, candidates int) (map[string][]int64, error) {
+	ctx := context.Background()
+	now := time.Now()
+	keys := make([]string, hours)
+	for index := range keys {
+		keys[index] = hashtagHourKey(consts.REDIS_HASHTAG_TRENDING, now.Add(time.Duration(index-hours+1)*time.Hour))
+	}
+
+	// 从当前小时和上一小时中选取候选话题
+	hashtagSet := make(map[string]struct{})
+	for _, key := range keys[max(hours-2, 0):] {
+		members, err := store.rds.ZRevRange(ctx, key, 0, int64(candidates)-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			hashtagSet[member] = struct{}{}
+		}
+	}
+	if len(hashtagSet) == 0 {
+		return map[string][]int64{}, nil
+	}
+	hashtags := make([]string, 0, len(hashtagSet))
+	for hashtag := range hashtagSet {
+		hashtags = append(hashtags, hashtag)
+	}
+
+	// 批量获取候选话题在每小时的热度
+	pipe := store.rds.Pipeline()
+	cmds := make([]*redis.FloatSliceCmd, hours)
+	for index, key := range keys {
+		cmds[index] = pipe.ZMScore(ctx, key, hashtags...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	counts := make(map[string][]int64, len(hashtags))
+	for _, hashtag := range hashtags {
+		counts[hashtag] = make([]int64, hours)
+	}
+	for hourIndex, cmd := range cmds {
+		scores, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		for hashtagIndex, score := range scores {
+			counts[hashtags[hashtagIndex]][hourIndex] = int64(score)
+		}
+	}
+	return counts, nil
+}
@@ -18,9 +18,7 @@ import (
 	"strings"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"gorm.io/gorm"
 
 	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
@@ -109,6 +107,11 @@ func (store *UserStore) RegisterUserByUsername(username string, salt string, has
 		return result.Error
 	}
 
+	if err := upsertUserSearchIndex(tx, user); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
@@ -129,6 +132,26 @@ func (store *UserStore) GetUserByUID(uid uint64) (*models.UserInfo, error) {
 	return user, nil
 }
 
+// GetUsersByUIDs 通过用户ID列表批量获取用户信息。
+//
+// 参数：
+//   - uids：用户ID列表
+//
+// 返回值：
+//   - []models.UserInfo：找到的用户信息，顺序不保证与参数一致。
+//   - error：如果在获取过程中发生错误，则返回相应的错误信息，否则返回nil。
+func (store *UserStore) GetUsersByUIDs(uids []uint64) ([]models.UserInfo, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	var users []models.UserInfo
+	result := store.db.Where("id IN ?", uids).Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
 // GetUserByUsername 通过用户名获取用户信息。
 //
 // 参数：
@@ -265,6 +288,23 @@ func (store *UserStore) BanUserToken(uid uint64, token string) error {
 	return nil
 }
 
+// RevokeUserTokens 禁用用户的全部 Token。
+//
+// 参数：
+//   - uid：用户ID
+//
+// 返回值：
+//   - error：如果在禁用过程中发生错误，则返回相应的错误信息，否则返回nil。
+func (store *UserStore) RevokeUserTokens(uid uint64) error {
+	var sb strings.Builder
+	sb.WriteString(consts.REDIS_AVAILABLE_USER_TOKEN_LIST)
+	sb.WriteRune(':')
+	sb.WriteString(strconv.FormatUint(uid, 10))
+	key := sb.String()
+
+	return store.rds.Del(context.Background(), key).Err()
+}
+
 // IsUserTokenAvaliable 检查 Token 是否可用。
 //
 // 参数：
@@ -397,77 +437,37 @@ func (store *UserStore) UpdateUserInfoByUID(uid uint64, updatedProfile *models.U
 	userProfile.Birth = updatedProfile.Birth
 	userProfile.Gender = updatedProfile.Gender
 
-	return store.db.Save(&userProfile).Error
+	// 资料与用户搜索索引在同一事务中更新
+	return store.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&userProfile).Error; err != nil {
+			return err
+		}
+		return upsertUserSearchIndex(tx, userProfile)
+	})
 }
 
-// GetUserLikedRecord 获取用户点赞记录。
+// UpdateUserPrivacyByUID 更新用户的私密账号设置。
 //
 // 参数：
 //   - uid：用户ID
+//   - isPrivate：是否为私密账号
 //
 // 返回值：
-//   - pq.Int64Array：用户点赞记录。
-func (store *UserStore) GetUserLikedRecord(uid int64) ([]int64, error) {
-	postLikeCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_LIKE_COLLECTION)
-	filter := bson.D{{Key: "uid", Value: uid}}
-	sort := bson.D{{Key: "liked_at", Value: 1}}
-	ctx := context.Background()
-	defer ctx.Done()
-
-	cursor, err := postLikeCollection.Find(ctx, filter, options.Find().SetSort(sort))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var postLikes []struct {
-		PostID int64 `bson:"post_id"`
-	}
-	err = cursor.All(ctx, &postLikes)
-	if err != nil {
-		return nil, err
-	}
-
-	liked := make([]int64, len(postLikes))
-	for index, postLike := range postLikes {
-		liked[index] = postLike.PostID
-	}
-
-	return liked, nil
+//   - error：如果在更新过程中发生错误，则返回相应的错误信息，否则返回nil。
+func (store *UserStore) UpdateUserPrivacyByUID(uid uint64, isPrivate bool) error {
+	return store.db.Model(&models.UserInfo{}).Where("id = ?", uid).Update("is_private", isPrivate).Error
 }
 
-// GetUserFavoriteRecord 获取用户收藏记录。
+// AddViewedRecord 记录用户浏览过的博文，已记录的博文不重复添加。
 //
 // 参数：
 //   - uid：用户ID
+//   - postID：博文ID
 //
 // 返回值：
-//   - pq.Int64Array：用户收藏记录。
-func (store *UserStore) GetUserFavoriteRecord(uid int64) ([]int64, error) {
-	postFavoriteCollection := store.mongo.Database(consts.MONGODB_DATABASE_NAME).Collection(consts.POST_FAVORITE_COLLECTION)
-	filter := bson.D{{Key: "uid", Value: uid}}
-	sort := bson.D{{Key: "favourited_at", Value: 1}}
-	ctx := context.Background()
-	defer ctx.Done()
-
-	cursor, err := postFavoriteCollection.Find(ctx, filter, options.Find().SetSort(sort))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var postFavorites []struct {
-		PostID int64 `bson:"post_id"`
-	}
-	err = cursor.All(ctx, &postFavorites)
-	if err != nil {
-		return nil, err
-	}
-
-	favorited := make([]int64, len(postFavorites))
-	for index, postFavorite := range postFavorites {
-		favorited[index] = postFavorite.PostID
-	}
-
-	return favorited, nil
+//   - error：如果发生错误，返回相应错误信息；否则返回 nil
+func (store *UserStore) AddViewedRecord(uid, postID uint64) error {
+	return store.db.Model(&models.UserPostStatus{}).
+		Where("uid = ? AND NOT (? = ANY(COALESCE(viewed, '{}')))", uid, postID).
+		Update("viewed", gorm.Expr("array_append(viewed, ?)", postID)).Error
 }
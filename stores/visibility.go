@@ -0,0 +1,58 @@
+/*
+Package stores - NekoBlog backend server data access objects.
+This file is for content author visibility filtering.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"gorm.io/gorm"
+)
+
+// HiddenAuthors 对查看者不可见的内容作者，被限流的用户通过关联用户表判断，无需加载全部被限流用户
+type HiddenAuthors struct {
+	ViewerUID uint64   // 查看者ID，被限流的查看者仍可看到自己的内容，为 0 时表示匿名用户
+	UIDs      []uint64 // 需要排除的作者ID，包括屏蔽、拉黑的用户
+}
+
+// apply 将过滤条件附加到查询上
+//
+// 参数：
+//   - query：基础查询
+//   - column：作者ID所在的列，需带表名以避免与子查询中的列混淆
+//
+// 返回值：
+//   - *gorm.DB：附加过滤条件后的查询
+func (hidden HiddenAuthors) apply(query *gorm.DB, column string) *gorm.DB {
+	if len(hidden.UIDs) > 0 {
+		query = query.Where(column+" NOT IN ?", hidden.UIDs)
+	}
+	return query.Where("NOT EXISTS (SELECT 1 FROM user_infos WHERE user_infos.id = "+column+" AND user_infos.is_shadowbanned AND user_infos.id <> ?)", hidden.ViewerUID)
+}
+
+// AuthorFilter 按查看者过滤内容作者，私密账号和被限流的用户通过关联用户表判断，无需加载全部私密账号
+type AuthorFilter struct {
+	ViewerUID      uint64   // 查看者ID，被限流的查看者仍可看到自己的内容，为 0 时表示匿名用户
+	HiddenUIDs     []uint64 // 需要排除的作者ID，包括屏蔽、拉黑的用户
+	AccessibleUIDs []uint64 // 可查看其私密账号内容的作者ID，即查看者本人及其关注的用户
+}
+
+// apply 将过滤条件附加到查询上
+//
+// 参数：
+//   - query：基础查询
+//   - column：作者ID所在的列，需带表名以避免与子查询中的列混淆
+//
+// 返回值：
+//   - *gorm.DB：附加过滤条件后的查询
+func (filter AuthorFilter) apply(query *gorm.DB, column string) *gorm.DB {
+	if len(filter.HiddenUIDs) > 0 {
+		query = query.Where(column+" NOT IN ?", filter.HiddenUIDs)
+	}
+	exists := "EXISTS (SELECT 1 FROM user_infos WHERE user_infos.id = " + column + " AND (NOT user_infos.is_shadowbanned OR user_infos.id = ?)"
+	if len(filter.AccessibleUIDs) == 0 {
+		return query.Where(exists+" AND NOT user_infos.is_private)", filter.ViewerUID)
+	}
+	return query.Where(exists+" AND (NOT user_infos.is_private OR user_infos.id IN ?))", filter.ViewerUID, filter.AccessibleUIDs)
+}
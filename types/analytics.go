@@ -0,0 +1,16 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for post analytics related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+import "github.com/Kirisakiii/neko-micro-blog-backend/models"
+
+// PostAnalytics 博文数据分析
+type PostAnalytics struct {
+	PostID  uint64                 // 博文ID
+	Counter models.PostCounter     // 当前互动计数
+	Daily   []models.PostDailyStat // 每日统计，按日期正序排列
+}
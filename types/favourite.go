@@ -0,0 +1,15 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for favourite folder related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+import "github.com/Kirisakiii/neko-micro-blog-backend/models"
+
+// FavouriteFolder 收藏夹及其收藏数量，默认收藏夹的ID为 0
+type FavouriteFolder struct {
+	Folder models.FavouriteFolderInfo // 收藏夹信息
+	Count  int64                      // 收藏数量
+}
@@ -0,0 +1,44 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for follow related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+import (
+	"time"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+)
+
+// UserRelation 查看者与用户之间的关系
+type UserRelation struct {
+	Following  bool // 查看者是否关注了该用户
+	FollowedBy bool // 该用户是否关注了查看者
+	Blocked    bool // 查看者是否拉黑了该用户
+}
+
+// FollowListItem 关注及粉丝列表项
+type FollowListItem struct {
+	User       models.UserInfo // 用户信息
+	FollowedAt time.Time       // 关注时间
+	Relation   UserRelation    // 查看者与该用户之间的关系
+}
+
+// FollowSuggestion 关注推荐，记录推荐得分和可解释的推荐理由
+type FollowSuggestion struct {
+	UID            uint64   `json:"uid"`             // 被推荐用户ID
+	Score          float64  `json:"score"`           // 推荐得分
+	FriendIDs      []uint64 `json:"friend_ids"`      // 关注了该用户的部分好友ID
+	FriendCount    int      `json:"friend_count"`    // 关注了该用户的好友数量
+	SharedHashtags []string `json:"shared_hashtags"` // 共同话题
+	SharedLikes    int      `json:"shared_likes"`    // 共同点赞的博文数量
+}
+
+// FollowSuggestionItem 补全用户资料后的关注推荐项
+type FollowSuggestionItem struct {
+	User       models.UserInfo   // 被推荐用户信息
+	Friends    []models.UserInfo // 关注了该用户的部分好友信息
+	Suggestion FollowSuggestion  // 关注推荐
+}
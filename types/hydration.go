@@ -0,0 +1,35 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for batch hydration related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+import "github.com/Kirisakiii/neko-micro-blog-backend/models"
+
+// HydratedPost 补全作者资料、计数和查看者状态的博文
+type HydratedPost struct {
+	Post         models.PostInfo    // 博文信息
+	Author       *models.UserInfo   // 作者资料，作者不存在时为 nil
+	Counter      models.PostCounter // 互动计数
+	IsLiked      bool               // 查看者是否已点赞
+	IsFavourited bool               // 查看者是否已收藏
+}
+
+// HydratedComment 补全作者资料、计数和查看者状态的评论
+type HydratedComment struct {
+	Comment      models.CommentInfo // 评论信息
+	Author       *models.UserInfo   // 作者资料，作者不存在时为 nil
+	LikeCount    int64              // 点赞数
+	DislikeCount int64              // 点踩数
+	ReplyCount   int64              // 回复数
+	IsLiked      bool               // 查看者是否已点赞
+	IsDisliked   bool               // 查看者是否已点踩
+}
+
+// HydratedUser 补全查看者关系的用户
+type HydratedUser struct {
+	User     models.UserInfo // 用户信息
+	Relation *UserRelation   // 查看者与该用户之间的关系，匿名查看时为 nil
+}
@@ -0,0 +1,17 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for user list related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+import "github.com/Kirisakiii/neko-micro-blog-backend/models"
+
+// UserListDetail 用户列表详情
+type UserListDetail struct {
+	List            models.UserListInfo // 列表信息
+	MemberCount     int64               // 成员数量
+	SubscriberCount int64               // 订阅者数量
+	IsSubscribed    bool                // 查看者是否已订阅
+}
@@ -0,0 +1,35 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for report and moderation related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+// ReportCreateBody 举报请求体
+type ReportCreateBody struct {
+	TargetType string `json:"target_type" form:"target_type"` // 举报目标类型：post、comment、reply 或 user
+	TargetID   uint64 `json:"target_id" form:"target_id"`     // 举报目标ID
+	Reason     string `json:"reason" form:"reason"`           // 举报原因
+	Detail     string `json:"detail" form:"detail"`           // 补充说明
+}
+
+// ModerationActionBody 审核操作请求体，指定工单时以工单的目标为准
+type ModerationActionBody struct {
+	CaseID     uint64 `json:"case_id" form:"case_id"`         // 审核工单ID
+	TargetType string `json:"target_type" form:"target_type"` // 目标类型，未指定工单时必填
+	TargetID   uint64 `json:"target_id" form:"target_id"`     // 目标ID，未指定工单时必填
+	Action     string `json:"action" form:"action"`           // 操作：dismiss、hide、restore、delete、warn、suspend、ban、shadowban 或 lift
+	Reason     string `json:"reason" form:"reason"`           // 处理理由
+	Duration   uint64 `json:"duration" form:"duration"`       // 处罚时长（小时），暂停账号时必填，限流时为 0 表示永久
+}
+
+// ModerationLogQuery 审核日志筛选条件
+type ModerationLogQuery struct {
+	TargetType   string // 目标类型，为空时不限制
+	TargetID     uint64 // 目标ID，与 TargetType 同时使用
+	TargetUID    uint64 // 目标作者ID，为 0 时不限制
+	ModeratorUID uint64 // 操作人ID，为 0 时不限制
+	Offset       int    // 偏移量
+	Length       int    // 每页数量
+}
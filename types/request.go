@@ -25,6 +25,11 @@ type UserUpdateProfileBody struct {
 	Gender   *string `json:"gender"`   // 性别
 }
 
+// UserUpdatePrivacyBody 更新用户隐私设置请求体
+type UserUpdatePrivacyBody struct {
+	IsPrivate *bool `json:"is_private" form:"is_private"` // 是否为私密账号
+}
+
 // CommentCreatebody 创建评论请求体
 type UserCommentCreateBody struct {
 	PostID  *uint64 `json:"post_id" form:"post_id"` // 博文ID
@@ -51,6 +56,12 @@ type PostCreateBody struct {
 	Images  []string `json:"images" form:"images"`   // 上传图片的UUID
 }
 
+// PostCommentPermissionBody 修改博文评论权限请求体
+type PostCommentPermissionBody struct {
+	PostID     *uint64 `json:"post_id" form:"post_id"`       // 博文ID
+	Permission string  `json:"permission" form:"permission"` // 评论权限：everyone、followers、mutuals 或 closed
+}
+
 // UserCommentDeleteBody 创建博文请求体
 type UserCommentDeleteBody struct {
 	CommentID *uint64 `json:"comment_id" form:"comment_id"` // 评论ID
@@ -73,3 +84,72 @@ type UserReplyUpdateBody struct {
 type UserReplyDeleteBody struct {
 	ReplyID uint64 `json:"reply_id" form:"reply_id"` // 回复ID
 }
+
+// UserRelationBody 用户关系操作请求体
+type UserRelationBody struct {
+	UserID uint64 `json:"user_id" form:"user_id"` // 目标用户ID
+}
+
+// UserListCreateBody 创建用户列表请求体
+type UserListCreateBody struct {
+	Name        string `json:"name" form:"name"`               // 列表名称
+	Description string `json:"description" form:"description"` // 列表描述
+	IsPrivate   bool   `json:"is_private" form:"is_private"`   // 是否为私密列表
+}
+
+// UserListUpdateBody 更新用户列表请求体
+type UserListUpdateBody struct {
+	ListID      uint64  `json:"list_id" form:"list_id"`         // 列表ID
+	Name        *string `json:"name" form:"name"`               // 列表名称
+	Description *string `json:"description" form:"description"` // 列表描述
+	IsPrivate   *bool   `json:"is_private" form:"is_private"`   // 是否为私密列表
+}
+
+// UserListBody 用户列表操作请求体
+type UserListBody struct {
+	ListID uint64 `json:"list_id" form:"list_id"` // 列表ID
+}
+
+// UserListMemberBody 用户列表成员操作请求体
+type UserListMemberBody struct {
+	ListID uint64 `json:"list_id" form:"list_id"` // 列表ID
+	UserID uint64 `json:"user_id" form:"user_id"` // 成员ID
+}
+
+// FavouriteFolderCreateBody 创建收藏夹请求体
+type FavouriteFolderCreateBody struct {
+	Name        string `json:"name" form:"name"`               // 收藏夹名称
+	Description string `json:"description" form:"description"` // 收藏夹描述
+	IsPrivate   bool   `json:"is_private" form:"is_private"`   // 是否为私密收藏夹
+}
+
+// FavouriteFolderUpdateBody 更新收藏夹请求体
+type FavouriteFolderUpdateBody struct {
+	FolderID    uint64  `json:"folder_id" form:"folder_id"`     // 收藏夹ID
+	Name        *string `json:"name" form:"name"`               // 收藏夹名称
+	Description *string `json:"description" form:"description"` // 收藏夹描述
+	IsPrivate   *bool   `json:"is_private" form:"is_private"`   // 是否为私密收藏夹
+}
+
+// FavouriteFolderBody 收藏夹操作请求体
+type FavouriteFolderBody struct {
+	FolderID uint64 `json:"folder_id" form:"folder_id"` // 收藏夹ID
+}
+
+// FavouriteMoveBody 移动收藏请求体
+type FavouriteMoveBody struct {
+	PostID   uint64 `json:"post_id" form:"post_id"`     // 博文ID
+	FolderID uint64 `json:"folder_id" form:"folder_id"` // 目标收藏夹ID，为 0 时移回默认收藏夹
+}
+
+// FavouriteNoteBody 收藏备注请求体
+type FavouriteNoteBody struct {
+	PostID uint64 `json:"post_id" form:"post_id"` // 博文ID
+	Note   string `json:"note" form:"note"`       // 收藏备注，为空时清除备注
+}
+
+// SearchPrivacyBody 搜索隐私设置请求体，未提供的字段保持不变
+type SearchPrivacyBody struct {
+	SaveHistory  *bool `json:"save_history" form:"save_history"`   // 是否保存最近搜索
+	ShareQueries *bool `json:"share_queries" form:"share_queries"` // 是否将搜索内容计入热门搜索
+}
@@ -0,0 +1,66 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for search related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+import "time"
+
+// PostSearchQuery 博文搜索条件
+type PostSearchQuery struct {
+	Query     string    // 搜索字符串
+	AuthorIDs []uint64  // 作者ID，为空时不限制
+	Since     time.Time // 起始发布时间，零值表示不限制
+	Until     time.Time // 截止发布时间，零值表示不限制
+	Sort      string    // 排序方式：relevance 或 recency
+	Cursor    string    // 分页游标，非空时优先于 Offset
+	Offset    int       // 结果偏移量
+	Length    int       // 每页数量
+}
+
+// PostSearchHit 博文搜索命中结果
+type PostSearchHit struct {
+	PostID            int64    // 博文ID
+	Score             float32  // 相关度得分
+	TitleHighlights   []string // 标题高亮片段
+	ContentHighlights []string // 内容高亮片段
+}
+
+// PostSearchResult 博文搜索结果
+type PostSearchResult struct {
+	Hits       []PostSearchHit // 过滤后的命中结果
+	Total      uint64          // 搜索引擎返回的命中总数，未排除不可见的博文
+	NextOffset int             // 下一页偏移量
+	NextCursor string          // 下一页游标，没有更多结果时为空
+	Degraded   bool            // 搜索服务不可用，结果为空
+}
+
+// UserSuggestion 用户建议
+type UserSuggestion struct {
+	UID      uint64 // 用户ID
+	UserName string // 小写用户名
+	NickName string // 小写昵称
+}
+
+// TitleSuggestion 博文标题建议
+type TitleSuggestion struct {
+	PostID uint64 // 博文ID
+	UID    uint64 // 作者ID
+	Title  string // 小写标题
+}
+
+// AutocompleteSuggestions 自动补全建议
+type AutocompleteSuggestions struct {
+	Titles   []TitleSuggestion // 博文标题建议
+	Hashtags []string          // 话题建议，不包含 # 前缀
+	Users    []UserSuggestion  // 用户建议
+	Queries  []string          // 热门搜索建议
+}
+
+// RecentSearch 最近搜索
+type RecentSearch struct {
+	Query      string    // 搜索字符串
+	SearchedAt time.Time // 搜索时间
+}
@@ -0,0 +1,39 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for comment thread related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+import "github.com/Kirisakiii/neko-micro-blog-backend/models"
+
+// ReplyNode 回复树节点
+type ReplyNode struct {
+	Reply      models.ReplyInfo // 回复信息
+	Children   []ReplyNode      // 已展开的子回复
+	NextCursor string           // 加载更多子回复的游标，没有更多时为空
+}
+
+// CommentNode 评论树节点
+type CommentNode struct {
+	Comment    models.CommentInfo // 评论信息
+	IsPinned   bool               // 是否为置顶评论
+	Replies    []ReplyNode        // 已展开的直接回复
+	NextCursor string             // 加载更多直接回复的游标，没有更多时为空
+}
+
+// CommentTree 博文评论树
+type CommentTree struct {
+	Comments     []CommentNode              // 评论列表
+	NextCursor   string                     // 下一页评论的游标，没有更多时为空
+	Users        map[uint64]models.UserInfo // 评论及回复作者资料
+	CommentLikes map[uint64]int64           // 评论点赞数
+}
+
+// ReplyTree 回复子树
+type ReplyTree struct {
+	Replies    []ReplyNode                // 回复列表
+	NextCursor string                     // 下一页回复的游标，没有更多时为空
+	Users      map[uint64]models.UserInfo // 回复作者资料
+}
@@ -0,0 +1,16 @@
+/*
+Package type - NekoBlog backend server types.
+This file is for trending posts and hashtags related types.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package types
+
+// TrendingHashtag 热门话题
+type TrendingHashtag struct {
+	Hashtag  string  // 话题，不包含 # 前缀
+	Velocity float64 // 最近一小时的热度
+	Baseline float64 // 统计窗口内更早各小时的平均热度
+	Score    float64 // 趋势得分
+	Hourly   []int64 // 每小时热度，按时间正序排列
+}
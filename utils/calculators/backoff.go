@@ -0,0 +1,33 @@
+/*
+Package calculators - NekoBlog backend server calculation utilities.
+This file is for retry backoff calculation.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package calculators
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff 计算指数退避的重试等待时间，并加入随机抖动避免大量任务同时重试。
+//
+// 参数：
+//   - attempts：已失败的次数，从 1 开始
+//   - base：首次重试的等待时间
+//   - maxDelay：最长等待时间
+//
+// 返回值：
+//   - time.Duration：等待时间，范围为 [delay/2, delay]，其中 delay 不超过 maxDelay
+func Backoff(attempts int, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for retry := 1; retry < attempts && delay < maxDelay; retry++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
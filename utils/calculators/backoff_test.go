@@ -0,0 +1,41 @@
+/*
+Package calculators - NekoBlog backend server calculation utilities.
+This file is for retry backoff calculation tests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package calculators
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		base     time.Duration
+		maxDelay time.Duration
+		want     time.Duration // 抖动前的等待时间
+	}{
+		{name: "首次重试", attempts: 1, base: time.Second, maxDelay: time.Minute, want: time.Second},
+		{name: "第二次重试翻倍", attempts: 2, base: time.Second, maxDelay: time.Minute, want: 2 * time.Second},
+		{name: "第五次重试", attempts: 5, base: time.Second, maxDelay: time.Minute, want: 16 * time.Second},
+		{name: "不超过最长等待时间", attempts: 10, base: time.Second, maxDelay: time.Minute, want: time.Minute},
+		{name: "大量失败不会溢出", attempts: 1000, base: time.Second, maxDelay: time.Minute, want: time.Minute},
+		{name: "首次等待超过上限", attempts: 1, base: time.Hour, maxDelay: time.Minute, want: time.Minute},
+		{name: "失败次数为 0 时按首次计算", attempts: 0, base: time.Second, maxDelay: time.Minute, want: time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// 抖动是随机的，多次计算检查范围
+			for round := 0; round < 100; round++ {
+				got := Backoff(test.attempts, test.base, test.maxDelay)
+				if got < test.want/2 || got > test.want {
+					t.Fatalf("Backoff(%d, %v, %v) = %v, want in [%v, %v]", test.attempts, test.base, test.maxDelay, got, test.want/2, test.want)
+				}
+			}
+		})
+	}
+}
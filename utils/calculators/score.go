@@ -0,0 +1,72 @@
+/*
+Package calculators - NekoBlog backend server calculation utilities.
+This file is for ranking score calculation.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package calculators
+
+import (
+	"math"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+)
+
+// WilsonScore 计算威尔逊得分区间下界，用于按好评率排序且兼顾样本量。
+//
+// 参数：
+//   - likes：点赞数
+//   - dislikes：点踩数
+//
+// 返回值：
+//   - float64：得分，范围为 [0, 1]，没有任何评价时为 0
+func WilsonScore(likes, dislikes int64) float64 {
+	n := float64(likes + dislikes)
+	if n == 0 {
+		return 0
+	}
+	z := consts.WILSON_SCORE_Z
+	phat := float64(likes) / n
+	return (phat + z*z/(2*n) - z*math.Sqrt((phat*(1-phat)+z*z/(4*n))/n)) / (1 + z*z/n)
+}
+
+// DecayFactor 计算按半衰期衰减时经过一段时间后的衰减系数。
+//
+// 参数：
+//   - elapsed：经过的时间（秒）
+//   - halfLife：半衰期（秒）
+//
+// 返回值：
+//   - float64：衰减系数，范围为 (0, 1]
+func DecayFactor(elapsed, halfLife float64) float64 {
+	return math.Pow(0.5, elapsed/halfLife)
+}
+
+// TrendingScore 根据每小时热度计算话题的当前速度、基线和趋势得分。
+//
+// 参数：
+//   - hourly：每小时热度，按时间正序排列，最后一项为当前小时
+//   - elapsed：当前小时已经过的比例，范围为 [0, 1]
+//
+// 返回值：
+//   - float64：最近一小时的热度，由当前小时和上一小时按时间比例合成
+//   - float64：更早各小时的平均热度
+//   - float64：趋势得分，速度相对基线的增幅越大得分越高
+func TrendingScore(hourly []int64, elapsed float64) (float64, float64, float64) {
+	if len(hourly) == 0 {
+		return 0, 0, 0
+	}
+	velocity := float64(hourly[len(hourly)-1])
+	if len(hourly) > 1 {
+		velocity += float64(hourly[len(hourly)-2]) * (1 - elapsed)
+	}
+
+	var baseline float64
+	if len(hourly) > 2 {
+		for _, count := range hourly[:len(hourly)-2] {
+			baseline += float64(count)
+		}
+		baseline /= float64(len(hourly) - 2)
+	}
+	return velocity, baseline, (velocity - baseline) / math.Sqrt(baseline+1)
+}
@@ -0,0 +1,118 @@
+/*
+Package calculators - NekoBlog backend server calculation utilities.
+This file is for ranking score calculation tests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package calculators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+)
+
+// floatEpsilon 浮点数比较的误差范围
+const floatEpsilon = 1e-9
+
+// almostEqual 判断两个浮点数是否在误差范围内相等
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatEpsilon
+}
+
+func TestWilsonScore(t *testing.T) {
+	z := consts.WILSON_SCORE_Z
+	tests := []struct {
+		name     string
+		likes    int64
+		dislikes int64
+		want     float64
+	}{
+		{name: "没有评价", likes: 0, dislikes: 0, want: 0},
+		{name: "全部点踩", likes: 0, dislikes: 10, want: 0},
+		{name: "全部点赞", likes: 10, dislikes: 0, want: 1 / (1 + z*z/10)},
+		{name: "单个点赞", likes: 1, dislikes: 0, want: 1 / (1 + z*z)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := WilsonScore(test.likes, test.dislikes); !almostEqual(got, test.want) {
+				t.Errorf("WilsonScore(%d, %d) = %v, want %v", test.likes, test.dislikes, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWilsonScoreOrdering(t *testing.T) {
+	tests := []struct {
+		name          string
+		higher, lower [2]int64 // 点赞数和点踩数
+	}{
+		{name: "好评率相同时样本越多得分越高", higher: [2]int64{100, 0}, lower: [2]int64{10, 0}},
+		{name: "好评率相同时样本越多得分越高（含点踩）", higher: [2]int64{60, 40}, lower: [2]int64{6, 4}},
+		{name: "样本相同时好评率越高得分越高", higher: [2]int64{8, 2}, lower: [2]int64{5, 5}},
+		{name: "少量全好评不一定高于大量高好评率", higher: [2]int64{950, 50}, lower: [2]int64{3, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			higher := WilsonScore(test.higher[0], test.higher[1])
+			lower := WilsonScore(test.lower[0], test.lower[1])
+			if higher <= lower {
+				t.Errorf("WilsonScore%v = %v, want greater than WilsonScore%v = %v", test.higher, higher, test.lower, lower)
+			}
+			for _, score := range []float64{higher, lower} {
+				if score < 0 || score > 1 {
+					t.Errorf("score %v out of range [0, 1]", score)
+				}
+			}
+		})
+	}
+}
+
+func TestDecayFactor(t *testing.T) {
+	const halfLife = 3600
+	tests := []struct {
+		name    string
+		elapsed float64
+		want    float64
+	}{
+		{name: "未经过时间", elapsed: 0, want: 1},
+		{name: "经过半个半衰期", elapsed: halfLife / 2, want: math.Sqrt(0.5)},
+		{name: "经过一个半衰期", elapsed: halfLife, want: 0.5},
+		{name: "经过两个半衰期", elapsed: 2 * halfLife, want: 0.25},
+		{name: "经过十个半衰期", elapsed: 10 * halfLife, want: 1.0 / 1024},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DecayFactor(test.elapsed, halfLife); !almostEqual(got, test.want) {
+				t.Errorf("DecayFactor(%v, %v) = %v, want %v", test.elapsed, float64(halfLife), got, test.want)
+			}
+		})
+	}
+}
+
+func TestTrendingScore(t *testing.T) {
+	tests := []struct {
+		name         string
+		hourly       []int64
+		elapsed      float64
+		wantVelocity float64
+		wantBaseline float64
+		wantScore    float64
+	}{
+		{name: "没有数据", hourly: nil, elapsed: 0.5},
+		{name: "仅有当前小时", hourly: []int64{5}, elapsed: 0.5, wantVelocity: 5, wantScore: 5},
+		{name: "上一小时按剩余比例计入速度", hourly: []int64{4, 10}, elapsed: 0.25, wantVelocity: 13, wantScore: 13},
+		{name: "更早的小时计入基线", hourly: []int64{2, 4, 6, 8}, elapsed: 0.5, wantVelocity: 11, wantBaseline: 3, wantScore: 4},
+		{name: "热度下降时得分为负", hourly: []int64{15, 15, 0, 0}, elapsed: 1, wantVelocity: 0, wantBaseline: 15, wantScore: -3.75},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			velocity, baseline, score := TrendingScore(test.hourly, test.elapsed)
+			if !almostEqual(velocity, test.wantVelocity) || !almostEqual(baseline, test.wantBaseline) || !almostEqual(score, test.wantScore) {
+				t.Errorf("TrendingScore(%v, %v) = (%v, %v, %v), want (%v, %v, %v)",
+					test.hourly, test.elapsed, velocity, baseline, score, test.wantVelocity, test.wantBaseline, test.wantScore)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Package converters - NekoBlog backend server data converters.
+This file is for pinyin converter.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package converters
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// pinyinArgs 拼音转换参数，使用不带声调的拼音，多音字取最常用的读音
+var pinyinArgs = pinyin.NewArgs()
+
+// ToPinyin 将文本转换为小写全拼和拼音首字母，非汉字的字母和数字保持原样，其余字符被忽略。
+//
+// 参数：
+//   - text：待转换的文本。
+//
+// 返回值：
+//   - string：全拼，如 “张三abc” 转换为 “zhangsanabc”。
+//   - string：拼音首字母，如 “张三abc” 转换为 “zsabc”。
+func ToPinyin(text string) (string, string) {
+	var full, initials strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.Is(unicode.Han, r) {
+			readings := pinyin.SinglePinyin(r, pinyinArgs)
+			if len(readings) > 0 && readings[0] != "" {
+				full.WriteString(readings[0])
+				initials.WriteByte(readings[0][0])
+			}
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			full.WriteRune(r)
+			initials.WriteRune(r)
+		}
+	}
+	return full.String(), initials.String()
+}
@@ -0,0 +1,173 @@
+/*
+Package filters - NekoBlog backend server content filters.
+This file is for Aho-Corasick multi-pattern matcher.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package filters
+
+import "unicode"
+
+// Rule 敏感词规则
+type Rule struct {
+	Word   string // 敏感词
+	Action string // 处理方式：reject、mask 或 review
+}
+
+// Hit 敏感词命中结果
+type Hit struct {
+	Rule  Rule // 命中的规则
+	Start int  // 命中片段在原文中的起始字符下标
+	End   int  // 命中片段在原文中的结束字符下标（不包含）
+}
+
+// acNode Aho-Corasick 自动机节点
+type acNode struct {
+	children map[rune]int // 子节点
+	fail     int          // 失配时跳转的节点
+	outputs  []int        // 在此节点结束的规则下标，包含失配链上的规则
+}
+
+// Matcher 基于 Aho-Corasick 自动机的多模式匹配器，匹配前对文本做归一化并跳过干扰字符
+//
+// Matcher 构建后只读，可以被多个协程同时使用。
+type Matcher struct {
+	nodes    []acNode
+	rules    []Rule
+	lengths  []int         // 规则归一化后的字符数
+	variants map[rune]rune // 变体字符到规范字符的映射
+}
+
+// normalizeRune 归一化单个字符：全角转半角、转小写并替换变体字符
+//
+// 参数：
+//   - r：字符
+//   - variants：变体字符映射
+//
+// 返回值：
+//   - rune：归一化后的字符
+//   - bool：字符是否有效，标点、空白、符号和零宽字符等干扰字符返回 false
+func normalizeRune(r rune, variants map[rune]rune) (rune, bool) {
+	switch {
+	case r == '　':
+		r = ' '
+	case r >= '！' && r <= '～':
+		r -= 0xFEE0
+	}
+	r = unicode.ToLower(r)
+	if variant, ok := variants[r]; ok {
+		r = variant
+	}
+	return r, unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// NewMatcher 根据规则构建匹配器
+//
+// 参数：
+//   - rules：敏感词规则，归一化后为空的规则会被忽略
+//   - variants：变体字符到规范字符的映射，键和值均按小写半角字符填写
+//
+// 返回值：
+//   - *Matcher：匹配器
+func NewMatcher(rules []Rule, variants map[rune]rune) *Matcher {
+	matcher := &Matcher{
+		nodes:    []acNode{{children: map[rune]int{}}},
+		variants: variants,
+	}
+
+	// 构建字典树
+	for _, rule := range rules {
+		state, length := 0, 0
+		for _, r := range rule.Word {
+			r, ok := normalizeRune(r, variants)
+			if !ok {
+				continue
+			}
+			next, exists := matcher.nodes[state].children[r]
+			if !exists {
+				next = len(matcher.nodes)
+				matcher.nodes = append(matcher.nodes, acNode{children: map[rune]int{}})
+				matcher.nodes[state].children[r] = next
+			}
+			state = next
+			length++
+		}
+		if length == 0 {
+			continue
+		}
+		matcher.nodes[state].outputs = append(matcher.nodes[state].outputs, len(matcher.rules))
+		matcher.rules = append(matcher.rules, rule)
+		matcher.lengths = append(matcher.lengths, length)
+	}
+
+	// 按广度优先顺序计算失配指针，并合并失配链上的输出
+	queue := make([]int, 0, len(matcher.nodes))
+	for _, child := range matcher.nodes[0].children {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for r, child := range matcher.nodes[state].children {
+			fail := matcher.nodes[state].fail
+			for fail != 0 {
+				if _, ok := matcher.nodes[fail].children[r]; ok {
+					break
+				}
+				fail = matcher.nodes[fail].fail
+			}
+			if next, ok := matcher.nodes[fail].children[r]; ok {
+				fail = next
+			}
+			matcher.nodes[child].fail = fail
+			matcher.nodes[child].outputs = append(matcher.nodes[child].outputs, matcher.nodes[fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+	return matcher
+}
+
+// Len 返回有效规则数量
+func (matcher *Matcher) Len() int {
+	return len(matcher.rules)
+}
+
+// Match 查找文本中的所有敏感词，敏感词中间插入的干扰字符不影响匹配
+//
+// 参数：
+//   - runes：文本字符
+//
+// 返回值：
+//   - []Hit：命中结果，按结束位置排列
+func (matcher *Matcher) Match(runes []rune) []Hit {
+	if len(matcher.rules) == 0 {
+		return nil
+	}
+
+	// 有效字符在原文中的下标，用于将命中位置映射回原文
+	positions := make([]int, 0, len(runes))
+	var hits []Hit
+	state := 0
+	for index, r := range runes {
+		r, ok := normalizeRune(r, matcher.variants)
+		if !ok {
+			continue
+		}
+		positions = append(positions, index)
+
+		for state != 0 {
+			if _, exists := matcher.nodes[state].children[r]; exists {
+				break
+			}
+			state = matcher.nodes[state].fail
+		}
+		if next, exists := matcher.nodes[state].children[r]; exists {
+			state = next
+		}
+		for _, ruleIndex := range matcher.nodes[state].outputs {
+			start := positions[len(positions)-matcher.lengths[ruleIndex]]
+			hits = append(hits, Hit{Rule: matcher.rules[ruleIndex], Start: start, End: index + 1})
+		}
+	}
+	return hits
+}
@@ -0,0 +1,127 @@
+/*
+Package filters - NekoBlog backend server content filters.
+This file is for Aho-Corasick multi-pattern matcher tests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package filters
+
+import (
+	"reflect"
+	"testing"
+)
+
+// matchedHit 便于比较的命中结果
+type matchedHit struct {
+	Word  string
+	Start int
+	End   int
+}
+
+func TestMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []string
+		variants map[rune]rune
+		text     string
+		want     []matchedHit
+	}{
+		{
+			name:  "无命中",
+			rules: []string{"bad"},
+			text:  "good words",
+			want:  nil,
+		},
+		{
+			name:  "单个命中",
+			rules: []string{"bad"},
+			text:  "so bad",
+			want:  []matchedHit{{"bad", 3, 6}},
+		},
+		{
+			name:  "大小写和全角字符归一化",
+			rules: []string{"bad"},
+			text:  "ＢａＤ",
+			want:  []matchedHit{{"bad", 0, 3}},
+		},
+		{
+			name:  "跳过插入的干扰字符",
+			rules: []string{"bad"},
+			text:  "b-a d!",
+			want:  []matchedHit{{"bad", 0, 5}},
+		},
+		{
+			name:  "中文敏感词",
+			rules: []string{"敏感词"},
+			text:  "这是敏 感*词吗",
+			want:  []matchedHit{{"敏感词", 2, 7}},
+		},
+		{
+			name:     "替换变体字符",
+			rules:    []string{"foo"},
+			variants: map[rune]rune{'0': 'o'},
+			text:     "f00",
+			want:     []matchedHit{{"foo", 0, 3}},
+		},
+		{
+			name:  "重叠命中按结束位置排列",
+			rules: []string{"he", "she", "hers"},
+			text:  "ushers",
+			want:  []matchedHit{{"she", 1, 4}, {"he", 2, 4}, {"hers", 2, 6}},
+		},
+		{
+			name:  "失配后继续匹配",
+			rules: []string{"abcd", "bc"},
+			text:  "abce",
+			want:  []matchedHit{{"bc", 1, 3}},
+		},
+		{
+			name:  "同一敏感词多次命中",
+			rules: []string{"ab"},
+			text:  "abab",
+			want:  []matchedHit{{"ab", 0, 2}, {"ab", 2, 4}},
+		},
+		{
+			name:  "没有规则",
+			rules: nil,
+			text:  "anything",
+			want:  nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules := make([]Rule, len(test.rules))
+			for index, word := range test.rules {
+				rules[index] = Rule{Word: word, Action: "mask"}
+			}
+			matcher := NewMatcher(rules, test.variants)
+
+			var got []matchedHit
+			for _, hit := range matcher.Match([]rune(test.text)) {
+				got = append(got, matchedHit{hit.Rule.Word, hit.Start, hit.End})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Match(%q) = %v, want %v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewMatcherIgnoresEmptyRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		want  int
+	}{
+		{name: "空规则", rules: []Rule{{Word: ""}}, want: 0},
+		{name: "仅包含干扰字符", rules: []Rule{{Word: " -*"}}, want: 0},
+		{name: "有效规则", rules: []Rule{{Word: "bad"}, {Word: "!"}, {Word: "worse"}}, want: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NewMatcher(test.rules, nil).Len(); got != test.want {
+				t.Errorf("Len() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
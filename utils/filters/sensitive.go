@@ -0,0 +1,179 @@
+/*
+Package filters - NekoBlog backend server content filters.
+This file is for hot-reloadable sensitive word filter.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package filters
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/consts"
+)
+
+// actionPriority 处理方式的优先级，同时命中多条规则时采用优先级最高的处理方式
+var actionPriority = map[string]int{
+	consts.SENSITIVE_ACTION_MASK:   1,
+	consts.SENSITIVE_ACTION_REVIEW: 2,
+	consts.SENSITIVE_ACTION_REJECT: 3,
+}
+
+// wordListFile 敏感词库文件结构
+type wordListFile struct {
+	Variants map[string]string `toml:"variants"` // 变体字符映射，键为变体字符，值为规范字符
+	Rules    []struct {
+		Action string   `toml:"action"` // 处理方式
+		Words  []string `toml:"words"`  // 敏感词
+	} `toml:"rules"`
+}
+
+// Result 敏感词检查结果
+type Result struct {
+	Action string   // 优先级最高的处理方式，未命中时为空
+	Text   string   // 将 mask 规则命中的片段替换为 * 后的文本
+	Words  []string // 命中的敏感词，已去重
+}
+
+// SensitiveFilter 可热加载的敏感词过滤器
+type SensitiveFilter struct {
+	path    string
+	matcher atomic.Pointer[Matcher]
+	mu      sync.Mutex // 串行化词库加载
+	modTime time.Time  // 已加载词库文件的修改时间
+}
+
+// NewSensitiveFilter 创建敏感词过滤器并加载词库
+//
+// 参数：
+//   - path：词库文件路径，为空时不过滤任何内容
+//
+// 返回值：
+//   - *SensitiveFilter：敏感词过滤器
+//   - error：如果加载词库失败，返回相应错误信息；否则返回 nil
+func NewSensitiveFilter(path string) (*SensitiveFilter, error) {
+	filter := &SensitiveFilter{path: path}
+	filter.matcher.Store(NewMatcher(nil, nil))
+	if path == "" {
+		return filter, nil
+	}
+	if _, err := filter.Reload(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// loadWordList 读取并解析词库文件
+//
+// 参数：
+//   - data：词库文件内容
+//
+// 返回值：
+//   - *Matcher：匹配器
+//   - error：词库格式不合法时返回错误
+func loadWordList(data []byte) (*Matcher, error) {
+	var file wordListFile
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	variants := make(map[rune]rune, len(file.Variants))
+	for variant, canonical := range file.Variants {
+		if utf8.RuneCountInString(variant) != 1 || utf8.RuneCountInString(canonical) != 1 {
+			return nil, fmt.Errorf("variant mapping %q = %q must map a single character to a single character", variant, canonical)
+		}
+		from, _ := utf8.DecodeRuneInString(variant)
+		to, _ := utf8.DecodeRuneInString(canonical)
+		from, _ = normalizeRune(from, nil)
+		to, _ = normalizeRune(to, nil)
+		variants[from] = to
+	}
+
+	var rules []Rule
+	for _, ruleSet := range file.Rules {
+		if _, ok := actionPriority[ruleSet.Action]; !ok {
+			return nil, fmt.Errorf("invalid sensitive word action %q", ruleSet.Action)
+		}
+		for _, word := range ruleSet.Words {
+			rules = append(rules, Rule{Word: word, Action: ruleSet.Action})
+		}
+	}
+	return NewMatcher(rules, variants), nil
+}
+
+// Reload 词库文件修改时间变化时重新加载词库，加载失败时继续使用原有词库
+//
+// 返回值：
+//   - int：重新加载后的规则数量，词库未变化时为 -1
+//   - error：如果加载词库失败，返回相应错误信息；否则返回 nil
+func (filter *SensitiveFilter) Reload() (int, error) {
+	if filter.path == "" {
+		return -1, nil
+	}
+	filter.mu.Lock()
+	defer filter.mu.Unlock()
+
+	info, err := os.Stat(filter.path)
+	if err != nil {
+		return -1, err
+	}
+	if info.ModTime().Equal(filter.modTime) {
+		return -1, nil
+	}
+	data, err := os.ReadFile(filter.path)
+	if err != nil {
+		return -1, err
+	}
+	matcher, err := loadWordList(data)
+	if err != nil {
+		return -1, err
+	}
+	filter.matcher.Store(matcher)
+	filter.modTime = info.ModTime()
+	return matcher.Len(), nil
+}
+
+// Check 检查文本中的敏感词
+//
+// 参数：
+//   - text：待检查的文本
+//
+// 返回值：
+//   - Result：检查结果
+func (filter *SensitiveFilter) Check(text string) Result {
+	runes := []rune(text)
+	hits := filter.matcher.Load().Match(runes)
+	result := Result{Text: text}
+	if len(hits) == 0 {
+		return result
+	}
+
+	masked := false
+	seen := make(map[string]struct{}, len(hits))
+	for _, hit := range hits {
+		if actionPriority[hit.Rule.Action] > actionPriority[result.Action] {
+			result.Action = hit.Rule.Action
+		}
+		if _, ok := seen[hit.Rule.Word]; !ok {
+			seen[hit.Rule.Word] = struct{}{}
+			result.Words = append(result.Words, hit.Rule.Word)
+		}
+		if hit.Rule.Action == consts.SENSITIVE_ACTION_MASK {
+			for index := hit.Start; index < hit.End; index++ {
+				runes[index] = consts.SENSITIVE_MASK_RUNE
+			}
+			masked = true
+		}
+	}
+	if masked {
+		result.Text = string(runes)
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+/*
+Package generators - NekoBlog backend server generator utils
+This file is for pagination cursor generator.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package generators
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// GenerateCursor 生成分页游标。
+// 游标由排序键和用于区分相同排序键的记录ID组成，对客户端不透明。
+//
+// 参数：
+//   - key：排序键，如时间戳
+//   - id：记录ID
+//
+// 返回值：
+//   - string：生成的游标。
+func GenerateCursor(key int64, id uint64) string {
+	var sb strings.Builder
+	sb.WriteString(strconv.FormatInt(key, 10))
+	sb.WriteRune('_')
+	sb.WriteString(strconv.FormatUint(id, 10))
+	return base64.RawURLEncoding.EncodeToString([]byte(sb.String()))
+}
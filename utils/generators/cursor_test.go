@@ -0,0 +1,64 @@
+/*
+Package generators - NekoBlog backend server generator utils
+This file is for pagination cursor generator tests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package generators
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/utils/parsers"
+)
+
+func TestGenerateCursor(t *testing.T) {
+	tests := []struct {
+		name string
+		key  int64
+		id   uint64
+		want string
+	}{
+		{name: "时间戳游标", key: 1700000000000, id: 42, want: "MTcwMDAwMDAwMDAwMF80Mg"},
+		{name: "负排序键", key: -5, id: 7, want: "LTVfNw"},
+		{name: "零值", key: 0, id: 0, want: "MF8w"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := GenerateCursor(test.key, test.id); got != test.want {
+				t.Errorf("GenerateCursor(%d, %d) = %q, want %q", test.key, test.id, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		key  int64
+		id   uint64
+	}{
+		{name: "普通值", key: 1700000000000, id: 42},
+		{name: "最大值", key: math.MaxInt64, id: math.MaxUint64},
+		{name: "最小排序键", key: math.MinInt64, id: 1},
+		{name: "浮点得分按位转换的排序键", key: int64(math.Float64bits(123.456)), id: 9},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cursor := GenerateCursor(test.key, test.id)
+			// 游标需可直接放入 URL 查询参数
+			if strings.ContainsAny(cursor, "+/=") {
+				t.Errorf("GenerateCursor(%d, %d) = %q, want URL safe cursor", test.key, test.id, cursor)
+			}
+			key, id, err := parsers.ParseCursor(cursor)
+			if err != nil {
+				t.Fatalf("ParseCursor(%q) returned error: %v", cursor, err)
+			}
+			if key != test.key || id != test.id {
+				t.Errorf("ParseCursor(GenerateCursor(%d, %d)) = (%d, %d)", test.key, test.id, key, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Package parsers - NekoBlog backend server data parsing utilities.
+This file is for pagination cursor parsing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ParseCursor 解析分页游标。
+//
+// 参数：
+//   - cursor：游标字符串。
+//
+// 返回值：
+//   - int64：排序键。
+//   - uint64：记录ID。
+//   - error：如果游标格式不正确，则返回相应的错误信息，否则返回nil。
+func ParseCursor(cursor string) (int64, uint64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, errors.New("invalid cursor")
+	}
+
+	// 拆分排序键和记录ID
+	parts := strings.SplitN(string(decoded), "_", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	key, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid cursor")
+	}
+
+	return key, id, nil
+}
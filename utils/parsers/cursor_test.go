@@ -0,0 +1,45 @@
+/*
+Package parsers - NekoBlog backend server data parsing utilities.
+This file is for pagination cursor parsing tests.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+import "testing"
+
+func TestParseCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  string
+		wantKey int64
+		wantID  uint64
+		wantErr bool
+	}{
+		{name: "时间戳游标", cursor: "MTcwMDAwMDAwMDAwMF80Mg", wantKey: 1700000000000, wantID: 42},
+		{name: "负排序键", cursor: "LTVfNw", wantKey: -5, wantID: 7},
+		{name: "零值", cursor: "MF8w", wantKey: 0, wantID: 0},
+		{name: "非 base64 字符串", cursor: "!!!", wantErr: true},
+		{name: "缺少记录ID", cursor: "MTIz", wantErr: true},
+		{name: "排序键不是整数", cursor: "YWJjXzE", wantErr: true},
+		{name: "记录ID为负数", cursor: "MV8tMg", wantErr: true},
+		{name: "空字符串", cursor: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, id, err := ParseCursor(test.cursor)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("ParseCursor(%q) = (%d, %d, nil), want error", test.cursor, key, id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCursor(%q) returned error: %v", test.cursor, err)
+			}
+			if key != test.wantKey || id != test.wantID {
+				t.Errorf("ParseCursor(%q) = (%d, %d), want (%d, %d)", test.cursor, key, id, test.wantKey, test.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Package parsers - NekoBlog backend server data parsing utilities.
+This file is for hashtag parsing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hashtagPattern 话题标签匹配规则，形如 #话题
+var hashtagPattern = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+
+// ParseHashtags 从文本中解析话题标签。
+//
+// 参数：
+//   - content：文本内容。
+//
+// 返回值：
+//   - []string：去重并转换为小写后的话题标签列表，按出现顺序排列，不包含 # 前缀。
+func ParseHashtags(content string) []string {
+	matches := hashtagPattern.FindAllStringSubmatch(content, -1)
+	hashtags := make([]string, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+	for _, match := range matches {
+		hashtag := strings.ToLower(match[1])
+		if _, ok := seen[hashtag]; ok {
+			continue
+		}
+		seen[hashtag] = struct{}{}
+		hashtags = append(hashtags, hashtag)
+	}
+	return hashtags
+}
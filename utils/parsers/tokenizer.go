@@ -0,0 +1,121 @@
+/*
+Package parsers - NekoBlog backend server data parsing utilities.
+This file is for full-text search tokenizing.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+import (
+	"unicode"
+)
+
+// maxTokenLength 词元的最大字符数，更长的单词多为链接或乱码，不参与检索
+const maxTokenLength = 64
+
+// isCJK 判断字符是否为中日韩文字，此类文字之间没有空格分隔
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// SplitSearchTerms 将文本切分为小写的检索片段，拉丁字母和数字按单词切分，连续的中日韩文字作为一个片段。
+//
+// 参数：
+//   - text：文本内容。
+//
+// 返回值：
+//   - []string：按出现顺序排列的检索片段，可能包含重复项。
+func SplitSearchTerms(text string) []string {
+	var (
+		terms   []string
+		current []rune
+		cjk     bool
+	)
+	flush := func() {
+		if len(current) > 0 {
+			terms = append(terms, string(current))
+			current = current[:0]
+		}
+	}
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		switch {
+		case isCJK(r):
+			if !cjk {
+				flush()
+			}
+			cjk = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if cjk {
+				flush()
+			}
+			cjk = false
+		default:
+			flush()
+			continue
+		}
+		current = append(current, r)
+	}
+	flush()
+	return terms
+}
+
+// TokenizeForIndex 将文本切分为索引词元，单词作为一个词元，中日韩文字片段同时生成单字和相邻双字词元。
+//
+// 参数：
+//   - text：文本内容。
+//
+// 返回值：
+//   - []string：去重后的索引词元。
+func TokenizeForIndex(text string) []string {
+	return tokenize(text, true)
+}
+
+// TokenizeForQuery 将查询字符串切分为查询词元，中日韩文字片段仅生成相邻双字词元，单个字时生成单字词元。
+//
+// 参数：
+//   - text：查询字符串。
+//
+// 返回值：
+//   - []string：去重后的查询词元，所有词元均命中时视为匹配。
+func TokenizeForQuery(text string) []string {
+	return tokenize(text, false)
+}
+
+// tokenize 切分词元
+//
+// 参数：
+//   - text：文本内容。
+//   - unigrams：是否为多字的中日韩文字片段生成单字词元。
+//
+// 返回值：
+//   - []string：去重后的词元。
+func tokenize(text string, unigrams bool) []string {
+	var tokens []string
+	seen := make(map[string]struct{})
+	add := func(token string) {
+		if _, ok := seen[token]; ok {
+			return
+		}
+		seen[token] = struct{}{}
+		tokens = append(tokens, token)
+	}
+	for _, term := range SplitSearchTerms(text) {
+		runes := []rune(term)
+		if !isCJK(runes[0]) {
+			if len(runes) <= maxTokenLength {
+				add(term)
+			}
+			continue
+		}
+		if len(runes) == 1 || unigrams {
+			for _, r := range runes {
+				add(string(r))
+			}
+		}
+		for i := 0; i+1 < len(runes); i++ {
+			add(string(runes[i : i+2]))
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,73 @@
+/*
+Package serializers - NekoBlog backend server data serialization.
+This file is for post analytics data serialization.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"time"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// PostAnalyticsTotalData 博文当前互动计数的响应结构
+type PostAnalyticsTotalData struct {
+	Views      int64 `json:"views"`      // 浏览数
+	Likes      int64 `json:"likes"`      // 点赞数
+	Favourites int64 `json:"favourites"` // 收藏数
+	Comments   int64 `json:"comments"`   // 评论数
+	Reposts    int64 `json:"reposts"`    // 转发数
+}
+
+// PostAnalyticsDailyData 博文每日统计的响应结构
+type PostAnalyticsDailyData struct {
+	Date        string `json:"date"`        // 日期，格式为 YYYY-MM-DD
+	Views       int64  `json:"views"`       // 当日独立浏览者数
+	Impressions int64  `json:"impressions"` // 当日曝光数
+	Likes       int64  `json:"likes"`       // 累计点赞数
+	Favourites  int64  `json:"favourites"`  // 累计收藏数
+	Comments    int64  `json:"comments"`    // 累计评论数
+	Reposts     int64  `json:"reposts"`     // 累计转发数
+}
+
+// PostAnalyticsResponse 博文数据分析的响应结构
+type PostAnalyticsResponse struct {
+	PostID uint64                   `json:"post_id"` // 博文ID
+	Totals PostAnalyticsTotalData   `json:"totals"`  // 当前互动计数
+	Daily  []PostAnalyticsDailyData `json:"daily"`   // 每日统计，没有活动的日期不返回
+}
+
+// NewPostAnalyticsResponse 创建博文数据分析的响应
+//
+// 参数：
+//   - analytics：博文数据分析
+//
+// 返回值：
+//   - 博文数据分析的响应
+func NewPostAnalyticsResponse(analytics types.PostAnalytics) PostAnalyticsResponse {
+	daily := make([]PostAnalyticsDailyData, 0, len(analytics.Daily))
+	for _, stat := range analytics.Daily {
+		daily = append(daily, PostAnalyticsDailyData{
+			Date:        stat.Date.Format(time.DateOnly),
+			Views:       stat.Views,
+			Impressions: stat.Impressions,
+			Likes:       stat.Likes,
+			Favourites:  stat.Favourites,
+			Comments:    stat.Comments,
+			Reposts:     stat.Reposts,
+		})
+	}
+	return PostAnalyticsResponse{
+		PostID: analytics.PostID,
+		Totals: PostAnalyticsTotalData{
+			Views:      analytics.Counter.Views,
+			Likes:      analytics.Counter.Likes,
+			Favourites: analytics.Counter.Favourites,
+			Comments:   analytics.Counter.Comments,
+			Reposts:    analytics.Counter.Reposts,
+		},
+		Daily: daily,
+	}
+}
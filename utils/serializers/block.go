@@ -0,0 +1,40 @@
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+)
+
+// BlockListResponse 拉黑及屏蔽列表响应结构
+type BlockListResponse struct {
+	IDs []uint64 `json:"ids"`
+}
+
+// NewBlockListResponse 创建拉黑列表的响应
+//
+// 参数：
+//   - 拉黑信息列表
+//
+// 返回值：
+//   - 拉黑列表的响应
+func NewBlockListResponse(blockInfos []models.BlockInfo) BlockListResponse {
+	ids := make([]uint64, 0, len(blockInfos))
+	for _, blockInfo := range blockInfos {
+		ids = append(ids, blockInfo.BlockedID)
+	}
+	return BlockListResponse{IDs: ids}
+}
+
+// NewMuteListResponse 创建屏蔽列表的响应
+//
+// 参数：
+//   - 屏蔽信息列表
+//
+// 返回值：
+//   - 屏蔽列表的响应
+func NewMuteListResponse(muteInfos []models.MuteInfo) BlockListResponse {
+	ids := make([]uint64, 0, len(muteInfos))
+	for _, muteInfo := range muteInfos {
+		ids = append(ids, muteInfo.MutedID)
+	}
+	return BlockListResponse{IDs: ids}
+}
@@ -5,22 +5,26 @@ import (
 )
 
 type CommentListResponse struct {
-	IDs []uint64 `json:"ids"`
+	IDs        []uint64 `json:"ids"`         // 评论ID列表
+	PinnedIDs  []uint64 `json:"pinned_ids"`  // 置顶评论ID列表
+	NextCursor string   `json:"next_cursor"` // 下一页游标
+	HasMore    bool     `json:"has_more"`    // 是否还有更多
 }
 
 // NewCommentListResponse 创建评论列表的响应
 //
 // 参数：
-//   - 评论体列表
+//   - ids：评论ID列表
+//   - pinnedIDs：置顶评论ID列表
+//   - nextCursor：下一页游标
 //
 // 返回值：
 //   - 评论列表的响应
-func NewCommentListResponse(commentInfos []models.CommentInfo) CommentListResponse {
-	var ids []uint64
-	for _, commentInfos := range commentInfos {
-		ids = append(ids, uint64(commentInfos.ID))
+func NewCommentListResponse(ids, pinnedIDs []uint64, nextCursor string) CommentListResponse {
+	if ids == nil {
+		ids = []uint64{}
 	}
-	return CommentListResponse{IDs: ids}
+	return CommentListResponse{IDs: ids, PinnedIDs: pinnedIDs, NextCursor: nextCursor, HasMore: nextCursor != ""}
 }
 
 // CommentDetailResponse 文章信息响应结构
@@ -0,0 +1,107 @@
+/*
+Package serializers - NekoBlog backend server data serialization.
+This file is for favourite folder data serialization.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// FavouriteFolderData 收藏夹响应结构
+type FavouriteFolderData struct {
+	FolderID    uint64 `json:"folder_id"`   // 收藏夹ID，默认收藏夹为 0
+	OwnerUID    uint64 `json:"owner_uid"`   // 创建者ID
+	Name        string `json:"name"`        // 收藏夹名称
+	Description string `json:"description"` // 收藏夹描述
+	IsPrivate   bool   `json:"is_private"`  // 是否为私密收藏夹
+	Count       int64  `json:"count"`       // 收藏数量
+	CreatedAt   int64  `json:"created_at"`  // 创建时间，默认收藏夹为 0
+}
+
+// FavouriteFolderListResponse 收藏夹列表响应结构
+type FavouriteFolderListResponse struct {
+	Folders []FavouriteFolderData `json:"folders"`
+}
+
+// FavouriteFolderCreateResponse 新建收藏夹响应结构
+type FavouriteFolderCreateResponse struct {
+	FolderID uint64 `json:"folder_id"` // 收藏夹ID
+}
+
+// FavouriteItemData 收藏记录响应结构
+type FavouriteItemData struct {
+	PostID       int64  `json:"post_id"`       // 博文ID
+	FolderID     uint64 `json:"folder_id"`     // 所属收藏夹ID
+	Note         string `json:"note"`          // 收藏备注
+	FavouritedAt int64  `json:"favourited_at"` // 收藏时间
+}
+
+// FavouriteListResponse 收藏记录列表响应结构
+type FavouriteListResponse struct {
+	Items      []FavouriteItemData `json:"items"`       // 收藏记录
+	NextCursor string              `json:"next_cursor"` // 下一页游标
+	HasMore    bool                `json:"has_more"`    // 是否还有更多
+}
+
+// NewFavouriteFolderListResponse 创建收藏夹列表响应
+//
+// 参数：
+//   - folders：收藏夹列表
+//
+// 返回值：
+//   - 收藏夹列表响应
+func NewFavouriteFolderListResponse(folders []types.FavouriteFolder) FavouriteFolderListResponse {
+	data := make([]FavouriteFolderData, 0, len(folders))
+	for _, folder := range folders {
+		var createdAt int64
+		if folder.Folder.ID != 0 {
+			createdAt = folder.Folder.CreatedAt.Unix()
+		}
+		data = append(data, FavouriteFolderData{
+			FolderID:    uint64(folder.Folder.ID),
+			OwnerUID:    folder.Folder.OwnerUID,
+			Name:        folder.Folder.Name,
+			Description: folder.Folder.Description,
+			IsPrivate:   folder.Folder.IsPrivate,
+			Count:       folder.Count,
+			CreatedAt:   createdAt,
+		})
+	}
+	return FavouriteFolderListResponse{Folders: data}
+}
+
+// NewFavouriteFolderCreateResponse 创建新建收藏夹响应
+//
+// 参数：
+//   - folderID：收藏夹ID
+//
+// 返回值：
+//   - 新建收藏夹响应
+func NewFavouriteFolderCreateResponse(folderID uint64) FavouriteFolderCreateResponse {
+	return FavouriteFolderCreateResponse{FolderID: folderID}
+}
+
+// NewFavouriteListResponse 创建收藏记录列表响应
+//
+// 参数：
+//   - records：收藏记录
+//   - nextCursor：下一页游标
+//
+// 返回值：
+//   - 收藏记录列表响应
+func NewFavouriteListResponse(records []models.PostFavouriteInfo, nextCursor string) FavouriteListResponse {
+	items := make([]FavouriteItemData, 0, len(records))
+	for _, record := range records {
+		items = append(items, FavouriteItemData{
+			PostID:       record.PostID,
+			FolderID:     record.FolderID,
+			Note:         record.Note,
+			FavouritedAt: record.FavouritedAt.Unix(),
+		})
+	}
+	return FavouriteListResponse{Items: items, NextCursor: nextCursor, HasMore: nextCursor != ""}
+}
@@ -2,6 +2,7 @@ package serializers
 
 import (
 	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
 )
 
 type FollowListResponse struct {
@@ -36,4 +37,79 @@ func NewFollowerListResponse(followInfos []models.FollowInfo) FollowListResponse
 		ids = append(ids, followInfos.UserID)
 	}
 	return FollowListResponse{IDs: ids}
-}
\ No newline at end of file
+}
+
+// NewFollowRequestListResponse 创建关注请求列表的响应
+//
+// 参数：
+//   - 关注请求信息列表
+//
+// 返回值：
+//   - 关注请求列表的响应，包含请求者ID
+func NewFollowRequestListResponse(requestInfos []models.FollowRequestInfo) FollowListResponse {
+	ids := make([]uint64, 0, len(requestInfos))
+	for _, requestInfo := range requestInfos {
+		ids = append(ids, requestInfo.UserID)
+	}
+	return FollowListResponse{IDs: ids}
+}
+
+// UserRelationData 查看者与用户之间关系的响应结构
+type UserRelationData struct {
+	Following  bool `json:"following"`   // 查看者是否关注了该用户
+	FollowedBy bool `json:"followed_by"` // 该用户是否关注了查看者
+	Mutual     bool `json:"mutual"`      // 是否互相关注
+	Blocked    bool `json:"blocked"`     // 查看者是否拉黑了该用户
+}
+
+// FollowListItemData 关注及粉丝列表项的响应结构
+type FollowListItemData struct {
+	UID        uint64           `json:"uid"`          // 用户 ID
+	Username   string           `json:"username"`     // 用户名
+	Nickname   string           `json:"nickname"`     // 昵称
+	Avatar     string           `json:"avatar_url"`   // 头像 URL
+	Level      uint64           `json:"level"`        // 等级
+	FollowedAt int64            `json:"followed_at"`  // 关注时间
+	Relation   UserRelationData `json:"relationship"` // 查看者与该用户之间的关系
+}
+
+// FollowListPageResponse 分页关注及粉丝列表的响应结构
+type FollowListPageResponse struct {
+	Items      []FollowListItemData `json:"items"`       // 列表项
+	NextCursor string               `json:"next_cursor"` // 下一页游标
+	HasMore    bool                 `json:"has_more"`    // 是否有更多数据
+}
+
+// NewFollowListPageResponse 创建分页关注及粉丝列表的响应
+//
+// 参数：
+//   - items：列表项
+//   - nextCursor：下一页游标，没有更多数据时为空
+//
+// 返回值：
+//   - 分页关注及粉丝列表的响应
+func NewFollowListPageResponse(items []types.FollowListItem, nextCursor string) FollowListPageResponse {
+	itemDatas := make([]FollowListItemData, 0, len(items))
+	for _, item := range items {
+		profile := NewUserProfileData(&item.User)
+		itemDatas = append(itemDatas, FollowListItemData{
+			UID:        profile.UID,
+			Username:   profile.Username,
+			Nickname:   profile.Nickname,
+			Avatar:     profile.Avatar,
+			Level:      profile.Level,
+			FollowedAt: item.FollowedAt.Unix(),
+			Relation: UserRelationData{
+				Following:  item.Relation.Following,
+				FollowedBy: item.Relation.FollowedBy,
+				Mutual:     item.Relation.Following && item.Relation.FollowedBy,
+				Blocked:    item.Relation.Blocked,
+			},
+		})
+	}
+	return FollowListPageResponse{
+		Items:      itemDatas,
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	}
+}
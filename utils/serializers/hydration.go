@@ -0,0 +1,114 @@
+/*
+Package serializers - NekoBlog backend server data serialization.
+This file is for batch hydration data serialization.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// PostBatchItemData 批量获取博文的列表项响应结构
+type PostBatchItemData struct {
+	*PostDetailResponse
+	Author       *UserBriefData `json:"author"`        // 作者资料
+	IsLiked      bool           `json:"is_liked"`      // 查看者是否已点赞
+	IsFavourited bool           `json:"is_favourited"` // 查看者是否已收藏
+}
+
+// PostBatchResponse 批量获取博文的响应结构
+type PostBatchResponse struct {
+	Items []PostBatchItemData `json:"items"` // 博文列表
+}
+
+// NewPostBatchResponse 创建批量获取博文的响应
+//
+// 参数：
+//   - posts：补全后的博文列表
+//
+// 返回值：
+//   - 批量获取博文的响应
+func NewPostBatchResponse(posts []types.HydratedPost) PostBatchResponse {
+	items := make([]PostBatchItemData, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, PostBatchItemData{
+			PostDetailResponse: NewPostDetailResponse(post.Post, post.Counter),
+			Author:             toUserBriefData(post.Author),
+			IsLiked:            post.IsLiked,
+			IsFavourited:       post.IsFavourited,
+		})
+	}
+	return PostBatchResponse{Items: items}
+}
+
+// CommentBatchItemData 批量获取评论的列表项响应结构
+type CommentBatchItemData struct {
+	*CommentDetailResponse
+	Dislikes int64          `json:"dislikes"` // 点踩数
+	Author   *UserBriefData `json:"author"`   // 作者资料
+}
+
+// CommentBatchResponse 批量获取评论的响应结构
+type CommentBatchResponse struct {
+	Items []CommentBatchItemData `json:"items"` // 评论列表
+}
+
+// NewCommentBatchResponse 创建批量获取评论的响应
+//
+// 参数：
+//   - comments：补全后的评论列表
+//
+// 返回值：
+//   - 批量获取评论的响应
+func NewCommentBatchResponse(comments []types.HydratedComment) CommentBatchResponse {
+	items := make([]CommentBatchItemData, 0, len(comments))
+	for _, comment := range comments {
+		detail := NewCommentDetailResponse(comment.Comment, comment.LikeCount)
+		detail.Replies = int(comment.ReplyCount)
+		detail.Is_liked = comment.IsLiked
+		detail.Is_disliked = comment.IsDisliked
+		items = append(items, CommentBatchItemData{
+			CommentDetailResponse: detail,
+			Dislikes:              comment.DislikeCount,
+			Author:                toUserBriefData(comment.Author),
+		})
+	}
+	return CommentBatchResponse{Items: items}
+}
+
+// UserBatchItemData 批量获取用户的列表项响应结构
+type UserBatchItemData struct {
+	*UserProfileData
+	Relation *UserRelationData `json:"relationship"` // 查看者与该用户之间的关系，匿名查看时为 null
+}
+
+// UserBatchResponse 批量获取用户的响应结构
+type UserBatchResponse struct {
+	Items []UserBatchItemData `json:"items"` // 用户列表
+}
+
+// NewUserBatchResponse 创建批量获取用户的响应
+//
+// 参数：
+//   - users：补全后的用户列表
+//
+// 返回值：
+//   - 批量获取用户的响应
+func NewUserBatchResponse(users []types.HydratedUser) UserBatchResponse {
+	items := make([]UserBatchItemData, 0, len(users))
+	for _, user := range users {
+		item := UserBatchItemData{UserProfileData: NewUserProfileData(&user.User)}
+		if user.Relation != nil {
+			item.Relation = &UserRelationData{
+				Following:  user.Relation.Following,
+				FollowedBy: user.Relation.FollowedBy,
+				Mutual:     user.Relation.Following && user.Relation.FollowedBy,
+				Blocked:    user.Relation.Blocked,
+			}
+		}
+		items = append(items, item)
+	}
+	return UserBatchResponse{Items: items}
+}
@@ -0,0 +1,110 @@
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// UserListData 用户列表响应结构
+type UserListData struct {
+	ListID      uint64 `json:"list_id"`     // 列表ID
+	OwnerUID    uint64 `json:"owner_uid"`   // 创建者ID
+	Name        string `json:"name"`        // 列表名称
+	Description string `json:"description"` // 列表描述
+	IsPrivate   bool   `json:"is_private"`  // 是否为私密列表
+	CreatedAt   int64  `json:"created_at"`  // 创建时间
+}
+
+// UserListDetailResponse 用户列表详情响应结构
+type UserListDetailResponse struct {
+	UserListData
+	MemberCount     int64 `json:"member_count"`     // 成员数量
+	SubscriberCount int64 `json:"subscriber_count"` // 订阅者数量
+	IsSubscribed    bool  `json:"is_subscribed"`    // 查看者是否已订阅
+}
+
+// UserListListResponse 用户列表集合响应结构
+type UserListListResponse struct {
+	Lists []UserListData `json:"lists"`
+}
+
+// UserListCreateResponse 创建用户列表响应结构
+type UserListCreateResponse struct {
+	ListID uint64 `json:"list_id"`
+}
+
+// NewUserListData 创建用户列表响应
+//
+// 参数：
+//   - listInfo：列表信息
+//
+// 返回值：
+//   - 用户列表响应
+func NewUserListData(listInfo models.UserListInfo) UserListData {
+	return UserListData{
+		ListID:      uint64(listInfo.ID),
+		OwnerUID:    listInfo.OwnerUID,
+		Name:        listInfo.Name,
+		Description: listInfo.Description,
+		IsPrivate:   listInfo.IsPrivate,
+		CreatedAt:   listInfo.CreatedAt.Unix(),
+	}
+}
+
+// NewUserListDetailResponse 创建用户列表详情响应
+//
+// 参数：
+//   - detail：列表详情
+//
+// 返回值：
+//   - 用户列表详情响应
+func NewUserListDetailResponse(detail types.UserListDetail) UserListDetailResponse {
+	return UserListDetailResponse{
+		UserListData:    NewUserListData(detail.List),
+		MemberCount:     detail.MemberCount,
+		SubscriberCount: detail.SubscriberCount,
+		IsSubscribed:    detail.IsSubscribed,
+	}
+}
+
+// NewUserListListResponse 创建用户列表集合响应
+//
+// 参数：
+//   - listInfos：列表信息
+//
+// 返回值：
+//   - 用户列表集合响应
+func NewUserListListResponse(listInfos []models.UserListInfo) UserListListResponse {
+	lists := make([]UserListData, 0, len(listInfos))
+	for _, listInfo := range listInfos {
+		lists = append(lists, NewUserListData(listInfo))
+	}
+	return UserListListResponse{Lists: lists}
+}
+
+// NewUserListCreateResponse 创建新建用户列表响应
+//
+// 参数：
+//   - listID：列表ID
+//
+// 返回值：
+//   - 新建用户列表响应
+func NewUserListCreateResponse(listID uint64) UserListCreateResponse {
+	return UserListCreateResponse{ListID: listID}
+}
+
+// UserListMemberListResponse 用户列表成员响应结构
+type UserListMemberListResponse struct {
+	IDs []uint64 `json:"ids"`
+}
+
+// NewUserListMemberListResponse 创建用户列表成员响应
+//
+// 参数：
+//   - memberIDs：成员ID列表
+//
+// 返回值：
+//   - 用户列表成员响应
+func NewUserListMemberListResponse(memberIDs []uint64) UserListMemberListResponse {
+	return UserListMemberListResponse{IDs: memberIDs}
+}
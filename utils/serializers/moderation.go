@@ -0,0 +1,226 @@
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+)
+
+// ModerationCaseResponse 审核工单的响应结构
+type ModerationCaseResponse struct {
+	ID             uint64  `json:"id"`               // 工单ID
+	TargetType     string  `json:"target_type"`      // 目标类型
+	TargetID       uint64  `json:"target_id"`        // 目标ID
+	TargetUID      uint64  `json:"target_uid"`       // 目标作者ID
+	Status         string  `json:"status"`           // 状态
+	Severity       int     `json:"severity"`         // 严重程度
+	ReportCount    int64   `json:"report_count"`     // 举报人数
+	Resolution     string  `json:"resolution"`       // 处理方式
+	ResolvedBy     *uint64 `json:"resolved_by"`      // 处理人ID
+	ResolvedAt     *int64  `json:"resolved_at"`      // 处理时间戳
+	LastReportedAt int64   `json:"last_reported_at"` // 最近一次被举报的时间戳
+	CreatedAt      int64   `json:"created_at"`       // 创建时间戳
+}
+
+// ModerationQueueResponse 审核队列的响应结构
+type ModerationQueueResponse struct {
+	Cases   []ModerationCaseResponse `json:"cases"`    // 审核工单
+	HasMore bool                     `json:"has_more"` // 是否还有更多
+}
+
+// NewModerationQueueResponse 创建审核队列的响应
+//
+// 参数：
+//   - cases：审核工单
+//   - hasMore：是否还有更多
+//
+// 返回值：
+//   - 审核队列的响应
+func NewModerationQueueResponse(cases []models.ModerationCase, hasMore bool) ModerationQueueResponse {
+	response := ModerationQueueResponse{
+		Cases:   make([]ModerationCaseResponse, len(cases)),
+		HasMore: hasMore,
+	}
+	for index, moderationCase := range cases {
+		var resolvedAt *int64
+		if moderationCase.ResolvedAt != nil {
+			timestamp := moderationCase.ResolvedAt.Unix()
+			resolvedAt = &timestamp
+		}
+		response.Cases[index] = ModerationCaseResponse{
+			ID:             moderationCase.ID,
+			TargetType:     moderationCase.TargetType,
+			TargetID:       moderationCase.TargetID,
+			TargetUID:      moderationCase.TargetUID,
+			Status:         moderationCase.Status,
+			Severity:       moderationCase.Severity,
+			ReportCount:    moderationCase.ReportCount,
+			Resolution:     moderationCase.Resolution,
+			ResolvedBy:     moderationCase.ResolvedBy,
+			ResolvedAt:     resolvedAt,
+			LastReportedAt: moderationCase.LastReportedAt.Unix(),
+			CreatedAt:      moderationCase.CreatedAt.Unix(),
+		}
+	}
+	return response
+}
+
+// ContentReportResponse 举报的响应结构
+type ContentReportResponse struct {
+	ID          uint64 `json:"id"`           // 举报ID
+	ReporterUID uint64 `json:"reporter_uid"` // 举报人ID
+	Reason      string `json:"reason"`       // 举报原因
+	Detail      string `json:"detail"`       // 补充说明
+	CreatedAt   int64  `json:"created_at"`   // 举报时间戳
+}
+
+// ContentReportListResponse 举报列表的响应结构
+type ContentReportListResponse struct {
+	Reports []ContentReportResponse `json:"reports"`  // 举报
+	HasMore bool                    `json:"has_more"` // 是否还有更多
+}
+
+// NewContentReportListResponse 创建举报列表的响应
+//
+// 参数：
+//   - reports：举报
+//   - hasMore：是否还有更多
+//
+// 返回值：
+//   - 举报列表的响应
+func NewContentReportListResponse(reports []models.ContentReport, hasMore bool) ContentReportListResponse {
+	response := ContentReportListResponse{
+		Reports: make([]ContentReportResponse, len(reports)),
+		HasMore: hasMore,
+	}
+	for index, report := range reports {
+		response.Reports[index] = ContentReportResponse{
+			ID:          report.ID,
+			ReporterUID: report.ReporterUID,
+			Reason:      report.Reason,
+			Detail:      report.Detail,
+			CreatedAt:   report.CreatedAt.Unix(),
+		}
+	}
+	return response
+}
+
+// ModerationLogResponse 审核日志的响应结构
+type ModerationLogResponse struct {
+	ID           uint64  `json:"id"`            // 日志ID
+	ModeratorUID uint64  `json:"moderator_uid"` // 操作人ID
+	Action       string  `json:"action"`        // 操作
+	TargetType   string  `json:"target_type"`   // 目标类型
+	TargetID     uint64  `json:"target_id"`     // 目标ID
+	TargetUID    uint64  `json:"target_uid"`    // 目标作者ID
+	CaseID       *uint64 `json:"case_id"`       // 审核工单ID
+	Reason       string  `json:"reason"`        // 处理理由
+	ExpiresAt    *int64  `json:"expires_at"`    // 处罚到期时间戳
+	CreatedAt    int64   `json:"created_at"`    // 操作时间戳
+}
+
+// ModerationLogListResponse 审核日志列表的响应结构
+type ModerationLogListResponse struct {
+	Logs    []ModerationLogResponse `json:"logs"`     // 审核日志
+	HasMore bool                    `json:"has_more"` // 是否还有更多
+}
+
+// NewModerationLogListResponse 创建审核日志列表的响应
+//
+// 参数：
+//   - logs：审核日志
+//   - hasMore：是否还有更多
+//
+// 返回值：
+//   - 审核日志列表的响应
+func NewModerationLogListResponse(logs []models.ModerationLog, hasMore bool) ModerationLogListResponse {
+	response := ModerationLogListResponse{
+		Logs:    make([]ModerationLogResponse, len(logs)),
+		HasMore: hasMore,
+	}
+	for index, log := range logs {
+		var expiresAt *int64
+		if log.ExpiresAt != nil {
+			timestamp := log.ExpiresAt.Unix()
+			expiresAt = &timestamp
+		}
+		response.Logs[index] = ModerationLogResponse{
+			ID:           log.ID,
+			ModeratorUID: log.ModeratorUID,
+			Action:       log.Action,
+			TargetType:   log.TargetType,
+			TargetID:     log.TargetID,
+			TargetUID:    log.TargetUID,
+			CaseID:       log.CaseID,
+			Reason:       log.Reason,
+			ExpiresAt:    expiresAt,
+			CreatedAt:    log.CreatedAt.Unix(),
+		}
+	}
+	return response
+}
+
+// UserWarningResponse 警告的响应结构
+type UserWarningResponse struct {
+	ID         uint64 `json:"id"`          // 警告ID
+	TargetType string `json:"target_type"` // 违规内容类型
+	TargetID   uint64 `json:"target_id"`   // 违规内容ID
+	Reason     string `json:"reason"`      // 警告理由
+	CreatedAt  int64  `json:"created_at"`  // 警告时间戳
+}
+
+// UserWarningListResponse 警告列表的响应结构
+type UserWarningListResponse struct {
+	Warnings []UserWarningResponse `json:"warnings"` // 警告
+	HasMore  bool                  `json:"has_more"` // 是否还有更多
+}
+
+// NewUserWarningListResponse 创建警告列表的响应，不包含操作人信息
+//
+// 参数：
+//   - warnings：警告
+//   - hasMore：是否还有更多
+//
+// 返回值：
+//   - 警告列表的响应
+func NewUserWarningListResponse(warnings []models.UserWarning, hasMore bool) UserWarningListResponse {
+	response := UserWarningListResponse{
+		Warnings: make([]UserWarningResponse, len(warnings)),
+		HasMore:  hasMore,
+	}
+	for index, warning := range warnings {
+		response.Warnings[index] = UserWarningResponse{
+			ID:         warning.ID,
+			TargetType: warning.TargetType,
+			TargetID:   warning.TargetID,
+			Reason:     warning.Reason,
+			CreatedAt:  warning.CreatedAt.Unix(),
+		}
+	}
+	return response
+}
+
+// AccountSuspensionResponse 账号暂停或封禁信息的响应结构
+type AccountSuspensionResponse struct {
+	Kind      string `json:"kind"`       // 处罚类型：suspend 或 ban
+	Reason    string `json:"reason"`     // 处罚理由
+	ExpiresAt *int64 `json:"expires_at"` // 到期时间戳，永久封禁时为空
+}
+
+// NewAccountSuspensionResponse 创建账号暂停或封禁信息的响应
+//
+// 参数：
+//   - suspension：处罚记录
+//
+// 返回值：
+//   - 账号暂停或封禁信息的响应
+func NewAccountSuspensionResponse(suspension models.UserSuspension) AccountSuspensionResponse {
+	var expiresAt *int64
+	if suspension.ExpiresAt != nil {
+		timestamp := suspension.ExpiresAt.Unix()
+		expiresAt = &timestamp
+	}
+	return AccountSuspensionResponse{
+		Kind:      suspension.Kind,
+		Reason:    suspension.Reason,
+		ExpiresAt: expiresAt,
+	}
+}
@@ -12,39 +12,73 @@ func NewPostListResponse(posts []int64) *PostListResponse {
 	return &PostListResponse{IDs: posts}
 }
 
+// PostPageResponse 分页博文列表的响应结构
+type PostPageResponse struct {
+	IDs        []int64 `json:"ids"`         // 博文ID列表
+	NextCursor string  `json:"next_cursor"` // 下一页游标
+	HasMore    bool    `json:"has_more"`    // 是否还有更多
+}
+
+// NewPostPageResponse 创建分页博文列表的响应
+//
+// 参数：
+//   - posts：博文ID列表
+//   - nextCursor：下一页游标
+//
+// 返回值：
+//   - 分页博文列表的响应
+func NewPostPageResponse(posts []int64, nextCursor string) PostPageResponse {
+	if posts == nil {
+		posts = []int64{}
+	}
+	return PostPageResponse{IDs: posts, NextCursor: nextCursor, HasMore: nextCursor != ""}
+}
+
 // PostDetailResponse 文章信息响应结构
 type PostDetailResponse struct {
-	CommentID    uint64   `json:"comment_id"`     //
-	UID          uint64   `json:"uid"`            // 用户ID
-	Timestamp    int64    `json:"timestamp"`      // 时间戳
-	Title        string   `json:"title"`          // 标题
-	Content      string   `json:"content"`        // 内容
-	ParentPostID *uint64  `json:"parent_post_id"` // 转发自文章ID
-	Images       []string `json:"images"`         // 图片
-	Like         int64    `json:"like"`           // 点赞数
-	Favourite    int64    `json:"favourite"`      // 收藏数
-	Farward      int      `json:"farward"`        // 转发数
+	CommentID         uint64   `json:"comment_id"`         //
+	UID               uint64   `json:"uid"`                // 用户ID
+	Timestamp         int64    `json:"timestamp"`          // 时间戳
+	Title             string   `json:"title"`              // 标题
+	Content           string   `json:"content"`            // 内容
+	ParentPostID      *uint64  `json:"parent_post_id"`     // 转发自文章ID
+	Images            []string `json:"images"`             // 图片
+	Like              int64    `json:"like"`               // 点赞数
+	Favourite         int64    `json:"favourite"`          // 收藏数
+	Farward           int64    `json:"farward"`            // 转发数
+	Comments          int64    `json:"comments"`           // 评论数
+	Views             int64    `json:"views"`              // 浏览数
+	CommentPermission string   `json:"comment_permission"` // 评论权限：everyone、followers、mutuals 或 closed
+	PinnedCommentIDs  []uint64 `json:"pinned_comment_ids"` // 置顶评论ID
 }
 
 // NewPostDetailResponse 创建新的文章信息响应
 //
 // 参数：
 //   - model：文章信息模型
+//   - counter：文章的互动计数
 //
 // 返回值：
 //   - *PostProfileData：新的文章信息响应结构
-func NewPostDetailResponse(post models.PostInfo, likeCount, favouriteCount int64) *PostDetailResponse {
+func NewPostDetailResponse(post models.PostInfo, counter models.PostCounter) *PostDetailResponse {
 	// 创建一个新的 PostProfileData 实例
 	profileData := &PostDetailResponse{
-		CommentID:    uint64(post.ID),
-		UID:          post.UID,
-		Timestamp:    post.CreatedAt.Unix(),
-		Title:        post.Title,
-		Content:      post.Content,
-		ParentPostID: post.ParentPostID,
-		Like:         likeCount,
-		Favourite:    favouriteCount,
-		Farward:      len(post.Farward),
+		CommentID:         uint64(post.ID),
+		UID:               post.UID,
+		Timestamp:         post.CreatedAt.Unix(),
+		Title:             post.Title,
+		Content:           post.Content,
+		ParentPostID:      post.ParentPostID,
+		Like:              counter.Likes,
+		Favourite:         counter.Favourites,
+		Farward:           counter.Reposts,
+		Comments:          counter.Comments,
+		Views:             counter.Views,
+		CommentPermission: post.CommentPermission,
+	}
+	profileData.PinnedCommentIDs = make([]uint64, 0, len(post.PinnedCommentIDs))
+	for _, id := range post.PinnedCommentIDs {
+		profileData.PinnedCommentIDs = append(profileData.PinnedCommentIDs, uint64(id))
 	}
 	for _, image := range post.Images {
 		profileData.Images = append(profileData.Images, "/resources/image/"+image)
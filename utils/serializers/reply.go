@@ -4,18 +4,24 @@ import "github.com/Kirisakiii/neko-micro-blog-backend/models"
 
 // ReplyListResponse 回复列表响应结构
 type ReplyListResponse struct {
-	IDs []uint64 `json:"ids"`
+	IDs        []uint64 `json:"ids"`         // 回复ID列表
+	NextCursor string   `json:"next_cursor"` // 下一页游标
+	HasMore    bool     `json:"has_more"`    // 是否还有更多
 }
 
 // NewReplyListResponse 创建新的回复列表响应
 //
 // 参数：
 //   - replies：回复ID列表
+//   - nextCursor：下一页游标
 //
 // 返回值：
 //   - ReplyListResponse：新的回复列表响应结构
-func NewReplyListResponse(replies []uint64) ReplyListResponse {
-	return ReplyListResponse{IDs: replies}
+func NewReplyListResponse(replies []uint64, nextCursor string) ReplyListResponse {
+	if replies == nil {
+		replies = []uint64{}
+	}
+	return ReplyListResponse{IDs: replies, NextCursor: nextCursor, HasMore: nextCursor != ""}
 }
 
 // ReplyDetailResponse 回复信息响应结构
@@ -26,18 +32,20 @@ type ReplyDetailResponse struct {
 	ParentReplyID  *uint64 `json:"parent_reply_id"`  // 父回复ID
 	ParentReplyUID *uint64 `json:"parent_reply_uid"` // 父回复UID
 	Content        string  `json:"content"`          // 内容
-	// Like           int     `json:"like"`             // 点赞数
-	// Dislike        int     `json:"dislike"`          // 踩数
+	Like           int64   `json:"like"`             // 点赞数
+	Dislike        int64   `json:"dislike"`          // 踩数
 }
 
 // NewReplyDetailResponse 创建新的回复信息响应
 //
 // 参数：
 //   - model：回复信息模型
+//   - likes：点赞数
+//   - dislikes：点踩数
 //
 // 返回值：
 //   - *ReplyDetailResponse：新的回复信息响应结构
-func NewReplyDetailResponse(reply models.ReplyInfo) ReplyDetailResponse {
+func NewReplyDetailResponse(reply models.ReplyInfo, likes, dislikes int64) ReplyDetailResponse {
 	// 创建一个新的 ReplyDetailResponse 实例
 	profileData := ReplyDetailResponse{
 		CreateTime:     reply.CreatedAt.Unix(),
@@ -46,9 +54,30 @@ func NewReplyDetailResponse(reply models.ReplyInfo) ReplyDetailResponse {
 		ParentReplyID:  reply.ParentReplyID,
 		ParentReplyUID: reply.ParentReplyUID,
 		Content:        reply.Content,
-		// Like:           len(reply.Like),
-		// Dislike:        len(reply.Dislike),
+		Like:           likes,
+		Dislike:        dislikes,
 	}
 
 	return profileData
 }
+
+// ReplyUserStatusResponse 回复用户状态响应
+type ReplyUserStatusResponse struct {
+	IsLiked    bool `json:"is_liked"`    // 是否点赞
+	IsDisliked bool `json:"is_disliked"` // 是否点踩
+}
+
+// NewReplyUserStatusResponse 创建回复用户状态响应
+//
+// 参数：
+//   - isLiked：是否点赞
+//   - isDisliked：是否点踩
+//
+// 返回值：
+//   - ReplyUserStatusResponse：回复用户状态响应
+func NewReplyUserStatusResponse(isLiked, isDisliked bool) ReplyUserStatusResponse {
+	return ReplyUserStatusResponse{
+		IsLiked:    isLiked,
+		IsDisliked: isDisliked,
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Package serializers - NekoBlog backend server data serialization.
+This file is for search result data serialization.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// PostSearchHitResponse 博文搜索命中结果的响应结构
+type PostSearchHitResponse struct {
+	PostID            int64    `json:"post_id"`            // 博文ID
+	Score             float32  `json:"score"`              // 相关度得分
+	TitleHighlights   []string `json:"title_highlights"`   // 标题高亮片段
+	ContentHighlights []string `json:"content_highlights"` // 内容高亮片段
+}
+
+// PostSearchResponse 博文搜索结果的响应结构
+type PostSearchResponse struct {
+	IDs        []int64                 `json:"ids"`         // 博文ID列表
+	Hits       []PostSearchHitResponse `json:"hits"`        // 命中结果
+	Total      uint64                  `json:"total"`       // 命中总数
+	NextOffset int                     `json:"next_offset"` // 下一页偏移量
+	NextCursor string                  `json:"next_cursor"` // 下一页游标
+	HasMore    bool                    `json:"has_more"`    // 是否还有更多
+	Degraded   bool                    `json:"degraded"`    // 搜索服务暂不可用，结果为空
+}
+
+// NewPostSearchResponse 创建博文搜索结果的响应
+//
+// 参数：
+//   - result：博文搜索结果
+//
+// 返回值：
+//   - 博文搜索结果的响应
+func NewPostSearchResponse(result types.PostSearchResult) PostSearchResponse {
+	response := PostSearchResponse{
+		IDs:        make([]int64, len(result.Hits)),
+		Hits:       make([]PostSearchHitResponse, len(result.Hits)),
+		Total:      result.Total,
+		NextOffset: result.NextOffset,
+		NextCursor: result.NextCursor,
+		HasMore:    result.NextCursor != "",
+		Degraded:   result.Degraded,
+	}
+	for index, hit := range result.Hits {
+		titleHighlights, contentHighlights := hit.TitleHighlights, hit.ContentHighlights
+		if titleHighlights == nil {
+			titleHighlights = []string{}
+		}
+		if contentHighlights == nil {
+			contentHighlights = []string{}
+		}
+		response.IDs[index] = hit.PostID
+		response.Hits[index] = PostSearchHitResponse{
+			PostID:            hit.PostID,
+			Score:             hit.Score,
+			TitleHighlights:   titleHighlights,
+			ContentHighlights: contentHighlights,
+		}
+	}
+	return response
+}
+
+// UserSearchResponse 用户搜索结果的响应结构
+type UserSearchResponse struct {
+	UIDs       []uint64 `json:"uids"`        // 用户ID列表
+	NextCursor string   `json:"next_cursor"` // 下一页游标
+	HasMore    bool     `json:"has_more"`    // 是否还有更多
+}
+
+// NewUserSearchResponse 创建用户搜索结果的响应
+//
+// 参数：
+//   - uids：用户ID列表
+//   - nextCursor：下一页游标
+//
+// 返回值：
+//   - 用户搜索结果的响应
+func NewUserSearchResponse(uids []uint64, nextCursor string) UserSearchResponse {
+	if uids == nil {
+		uids = []uint64{}
+	}
+	return UserSearchResponse{UIDs: uids, NextCursor: nextCursor, HasMore: nextCursor != ""}
+}
+
+// HashtagSearchItemResponse 话题搜索结果项的响应结构
+type HashtagSearchItemResponse struct {
+	Hashtag    string `json:"hashtag"`      // 话题，不包含 # 前缀
+	PostCount  int64  `json:"post_count"`   // 使用该话题的博文数量
+	LastUsedAt int64  `json:"last_used_at"` // 最近一次被使用的时间戳
+}
+
+// HashtagSearchResponse 话题搜索结果的响应结构
+type HashtagSearchResponse struct {
+	Hashtags   []HashtagSearchItemResponse `json:"hashtags"`    // 话题列表
+	NextCursor string                      `json:"next_cursor"` // 下一页游标
+	HasMore    bool                        `json:"has_more"`    // 是否还有更多
+}
+
+// NewHashtagSearchResponse 创建话题搜索结果的响应
+//
+// 参数：
+//   - hashtags：话题搜索索引
+//   - nextCursor：下一页游标
+//
+// 返回值：
+//   - 话题搜索结果的响应
+func NewHashtagSearchResponse(hashtags []models.HashtagSearchIndex, nextCursor string) HashtagSearchResponse {
+	response := HashtagSearchResponse{
+		Hashtags:   make([]HashtagSearchItemResponse, len(hashtags)),
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	}
+	for index, hashtag := range hashtags {
+		response.Hashtags[index] = HashtagSearchItemResponse{
+			Hashtag:    hashtag.Name,
+			PostCount:  hashtag.PostCount,
+			LastUsedAt: hashtag.LastUsedAt.Unix(),
+		}
+	}
+	return response
+}
+
+// UserSuggestionResponse 用户建议的响应结构
+type UserSuggestionResponse struct {
+	UID      uint64 `json:"uid"`      // 用户ID
+	UserName string `json:"username"` // 用户名
+	NickName string `json:"nickname"` // 昵称
+}
+
+// AutocompleteResponse 自动补全建议的响应结构
+type AutocompleteResponse struct {
+	Titles   []string                 `json:"titles"`   // 博文标题建议
+	Hashtags []string                 `json:"hashtags"` // 话题建议
+	Users    []UserSuggestionResponse `json:"users"`    // 用户建议
+	Queries  []string                 `json:"queries"`  // 热门搜索建议
+}
+
+// NewAutocompleteResponse 创建自动补全建议的响应
+//
+// 参数：
+//   - suggestions：自动补全建议
+//
+// 返回值：
+//   - 自动补全建议的响应
+func NewAutocompleteResponse(suggestions types.AutocompleteSuggestions) AutocompleteResponse {
+	response := AutocompleteResponse{
+		Titles:   make([]string, len(suggestions.Titles)),
+		Hashtags: suggestions.Hashtags,
+		Users:    make([]UserSuggestionResponse, len(suggestions.Users)),
+		Queries:  suggestions.Queries,
+	}
+	if response.Hashtags == nil {
+		response.Hashtags = []string{}
+	}
+	if response.Queries == nil {
+		response.Queries = []string{}
+	}
+	for index, title := range suggestions.Titles {
+		response.Titles[index] = title.Title
+	}
+	for index, user := range suggestions.Users {
+		response.Users[index] = UserSuggestionResponse{UID: user.UID, UserName: user.UserName, NickName: user.NickName}
+	}
+	return response
+}
+
+// RecentSearchResponse 最近搜索的响应结构
+type RecentSearchResponse struct {
+	Query      string `json:"query"`       // 搜索字符串
+	SearchedAt int64  `json:"searched_at"` // 搜索时间戳
+}
+
+// NewRecentSearchListResponse 创建最近搜索列表的响应
+//
+// 参数：
+//   - searches：最近搜索
+//
+// 返回值：
+//   - 最近搜索列表的响应
+func NewRecentSearchListResponse(searches []types.RecentSearch) []RecentSearchResponse {
+	response := make([]RecentSearchResponse, len(searches))
+	for index, search := range searches {
+		response[index] = RecentSearchResponse{Query: search.Query, SearchedAt: search.SearchedAt.Unix()}
+	}
+	return response
+}
+
+// SearchPrivacyResponse 搜索隐私设置的响应结构
+type SearchPrivacyResponse struct {
+	SaveHistory  bool `json:"save_history"`  // 是否保存最近搜索
+	ShareQueries bool `json:"share_queries"` // 是否将搜索内容计入热门搜索
+}
+
+// NewSearchPrivacyResponse 创建搜索隐私设置的响应
+//
+// 参数：
+//   - setting：搜索隐私设置
+//
+// 返回值：
+//   - 搜索隐私设置的响应
+func NewSearchPrivacyResponse(setting models.SearchPrivacySetting) SearchPrivacyResponse {
+	return SearchPrivacyResponse{SaveHistory: setting.SaveHistory, ShareQueries: setting.ShareQueries}
+}
@@ -0,0 +1,117 @@
+package serializers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// SuggestionReasonData 关注推荐理由的响应结构
+type SuggestionReasonData struct {
+	Type     string   `json:"type"`               // 理由类型：followed_by、shared_hashtags、shared_likes
+	Text     string   `json:"text"`               // 理由描述
+	UserIDs  []uint64 `json:"user_ids,omitempty"` // 关注了该用户的好友ID
+	Hashtags []string `json:"hashtags,omitempty"` // 共同话题
+	Count    int      `json:"count"`              // 相关数量
+}
+
+// FollowSuggestionData 关注推荐项的响应结构
+type FollowSuggestionData struct {
+	UID      uint64                 `json:"uid"`        // 用户 ID
+	Username string                 `json:"username"`   // 用户名
+	Nickname string                 `json:"nickname"`   // 昵称
+	Avatar   string                 `json:"avatar_url"` // 头像 URL
+	Level    uint64                 `json:"level"`      // 等级
+	Score    float64                `json:"score"`      // 推荐得分
+	Reasons  []SuggestionReasonData `json:"reasons"`    // 推荐理由
+}
+
+// FollowSuggestionListResponse 关注推荐列表的响应结构
+type FollowSuggestionListResponse struct {
+	Items []FollowSuggestionData `json:"items"`
+}
+
+// NewFollowSuggestionListResponse 创建关注推荐列表的响应
+//
+// 参数：
+//   - items：关注推荐列表
+//
+// 返回值：
+//   - 关注推荐列表的响应
+func NewFollowSuggestionListResponse(items []types.FollowSuggestionItem) FollowSuggestionListResponse {
+	itemDatas := make([]FollowSuggestionData, 0, len(items))
+	for _, item := range items {
+		profile := NewUserProfileData(&item.User)
+		itemDatas = append(itemDatas, FollowSuggestionData{
+			UID:      profile.UID,
+			Username: profile.Username,
+			Nickname: profile.Nickname,
+			Avatar:   profile.Avatar,
+			Level:    profile.Level,
+			Score:    item.Suggestion.Score,
+			Reasons:  newSuggestionReasons(item),
+		})
+	}
+	return FollowSuggestionListResponse{Items: itemDatas}
+}
+
+// newSuggestionReasons 生成可读的关注推荐理由
+//
+// 参数：
+//   - item：关注推荐项
+//
+// 返回值：
+//   - 推荐理由列表
+func newSuggestionReasons(item types.FollowSuggestionItem) []SuggestionReasonData {
+	reasons := make([]SuggestionReasonData, 0, 3)
+	suggestion := item.Suggestion
+
+	// 共同关注，如 followed by A, B and 2 others
+	if suggestion.FriendCount > 0 && len(item.Friends) > 0 {
+		names := make([]string, 0, len(item.Friends))
+		userIDs := make([]uint64, 0, len(item.Friends))
+		for index := range item.Friends {
+			profile := NewUserProfileData(&item.Friends[index])
+			names = append(names, profile.Nickname)
+			userIDs = append(userIDs, profile.UID)
+		}
+		var text string
+		others := suggestion.FriendCount - len(names)
+		switch {
+		case others > 0:
+			text = fmt.Sprintf("followed by %s and %d others", strings.Join(names, ", "), others)
+		case len(names) == 1:
+			text = "followed by " + names[0]
+		default:
+			text = fmt.Sprintf("followed by %s and %s", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+		}
+		reasons = append(reasons, SuggestionReasonData{
+			Type:    "followed_by",
+			Text:    text,
+			UserIDs: userIDs,
+			Count:   suggestion.FriendCount,
+		})
+	}
+
+	// 共同话题
+	if len(suggestion.SharedHashtags) > 0 {
+		reasons = append(reasons, SuggestionReasonData{
+			Type:     "shared_hashtags",
+			Text:     "also posts about #" + strings.Join(suggestion.SharedHashtags, ", #"),
+			Hashtags: suggestion.SharedHashtags,
+			Count:    len(suggestion.SharedHashtags),
+		})
+	}
+
+	// 共同点赞
+	if suggestion.SharedLikes > 0 {
+		reasons = append(reasons, SuggestionReasonData{
+			Type:  "shared_likes",
+			Text:  fmt.Sprintf("liked %d of the same posts as you", suggestion.SharedLikes),
+			Count: suggestion.SharedLikes,
+		})
+	}
+
+	return reasons
+}
@@ -0,0 +1,124 @@
+/*
+Package serializers - NekoBlog backend server data serialization.
+This file is for comment thread data serialization.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/models"
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// ReplyNodeData 回复树节点的响应结构
+type ReplyNodeData struct {
+	ReplyID       uint64          `json:"reply_id"`        // 回复ID
+	CommentID     uint64          `json:"comment_id"`      // 评论ID
+	ParentReplyID *uint64         `json:"parent_reply_id"` // 父回复ID
+	CreateTime    int64           `json:"create_time"`     // 创建时间
+	Content       string          `json:"content"`         // 内容
+	Author        *UserBriefData  `json:"author"`          // 作者资料，作者不存在时为空
+	Replies       []ReplyNodeData `json:"replies"`         // 已展开的子回复
+	NextCursor    string          `json:"next_cursor"`     // 加载更多子回复的游标
+	HasMore       bool            `json:"has_more"`        // 是否还有更多子回复
+}
+
+// CommentNodeData 评论树节点的响应结构
+type CommentNodeData struct {
+	CommentID  uint64          `json:"comment_id"`  // 评论ID
+	PostID     uint64          `json:"post_id"`     // 博文ID
+	CreateTime int64           `json:"create_time"` // 创建时间
+	Content    string          `json:"content"`     // 内容
+	Likes      int64           `json:"likes"`       // 点赞数
+	IsPinned   bool            `json:"is_pinned"`   // 是否为置顶评论
+	Author     *UserBriefData  `json:"author"`      // 作者资料，作者不存在时为空
+	Replies    []ReplyNodeData `json:"replies"`     // 已展开的直接回复
+	NextCursor string          `json:"next_cursor"` // 加载更多直接回复的游标
+	HasMore    bool            `json:"has_more"`    // 是否还有更多直接回复
+}
+
+// CommentTreeResponse 评论树的响应结构
+type CommentTreeResponse struct {
+	Comments   []CommentNodeData `json:"comments"`    // 评论列表
+	NextCursor string            `json:"next_cursor"` // 下一页游标
+	HasMore    bool              `json:"has_more"`    // 是否还有更多评论
+}
+
+// ReplyTreeResponse 回复子树的响应结构
+type ReplyTreeResponse struct {
+	Replies    []ReplyNodeData `json:"replies"`     // 回复列表
+	NextCursor string          `json:"next_cursor"` // 下一页游标
+	HasMore    bool            `json:"has_more"`    // 是否还有更多回复
+}
+
+// NewCommentTreeResponse 创建评论树的响应
+//
+// 参数：
+//   - tree：评论树
+//
+// 返回值：
+//   - CommentTreeResponse：评论树的响应
+func NewCommentTreeResponse(tree types.CommentTree) CommentTreeResponse {
+	comments := make([]CommentNodeData, 0, len(tree.Comments))
+	for _, node := range tree.Comments {
+		comments = append(comments, CommentNodeData{
+			CommentID:  uint64(node.Comment.ID),
+			PostID:     node.Comment.PostID,
+			CreateTime: node.Comment.CreatedAt.Unix(),
+			Content:    node.Comment.Content,
+			Likes:      tree.CommentLikes[uint64(node.Comment.ID)],
+			IsPinned:   node.IsPinned,
+			Author:     newUserBriefData(tree.Users, node.Comment.UID),
+			Replies:    newReplyNodeDatas(node.Replies, tree.Users),
+			NextCursor: node.NextCursor,
+			HasMore:    node.NextCursor != "",
+		})
+	}
+	return CommentTreeResponse{
+		Comments:   comments,
+		NextCursor: tree.NextCursor,
+		HasMore:    tree.NextCursor != "",
+	}
+}
+
+// NewReplyTreeResponse 创建回复子树的响应
+//
+// 参数：
+//   - tree：回复子树
+//
+// 返回值：
+//   - ReplyTreeResponse：回复子树的响应
+func NewReplyTreeResponse(tree types.ReplyTree) ReplyTreeResponse {
+	return ReplyTreeResponse{
+		Replies:    newReplyNodeDatas(tree.Replies, tree.Users),
+		NextCursor: tree.NextCursor,
+		HasMore:    tree.NextCursor != "",
+	}
+}
+
+// newReplyNodeDatas 递归创建回复树节点的响应
+//
+// 参数：
+//   - nodes：回复树节点
+//   - users：作者资料
+//
+// 返回值：
+//   - []ReplyNodeData：回复树节点的响应
+func newReplyNodeDatas(nodes []types.ReplyNode, users map[uint64]models.UserInfo) []ReplyNodeData {
+	datas := make([]ReplyNodeData, 0, len(nodes))
+	for _, node := range nodes {
+		datas = append(datas, ReplyNodeData{
+			ReplyID:       uint64(node.Reply.ID),
+			CommentID:     node.Reply.CommentID,
+			ParentReplyID: node.Reply.ParentReplyID,
+			CreateTime:    node.Reply.CreatedAt.Unix(),
+			Content:       node.Reply.Content,
+			Author:        newUserBriefData(users, node.Reply.UID),
+			Replies:       newReplyNodeDatas(node.Children, users),
+			NextCursor:    node.NextCursor,
+			HasMore:       node.NextCursor != "",
+		})
+	}
+	return datas
+}
@@ -0,0 +1,68 @@
+/*
+Package serializers - NekoBlog backend server data serialization.
+This file is for trending posts and hashtags data serialization.
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"github.com/Kirisakiii/neko-micro-blog-backend/types"
+)
+
+// HotPostListResponse 热门博文列表的响应结构
+type HotPostListResponse struct {
+	IDs        []uint64 `json:"ids"`         // 博文ID列表
+	NextCursor string   `json:"next_cursor"` // 下一页游标
+	HasMore    bool     `json:"has_more"`    // 是否还有更多
+}
+
+// NewHotPostListResponse 创建热门博文列表的响应
+//
+// 参数：
+//   - ids：博文ID列表
+//   - nextCursor：下一页游标
+//
+// 返回值：
+//   - 热门博文列表的响应
+func NewHotPostListResponse(ids []uint64, nextCursor string) HotPostListResponse {
+	if ids == nil {
+		ids = []uint64{}
+	}
+	return HotPostListResponse{IDs: ids, NextCursor: nextCursor, HasMore: nextCursor != ""}
+}
+
+// TrendingHashtagData 热门话题的响应结构
+type TrendingHashtagData struct {
+	Hashtag  string  `json:"hashtag"`  // 话题，不包含 # 前缀
+	Velocity float64 `json:"velocity"` // 最近一小时的热度
+	Baseline float64 `json:"baseline"` // 统计窗口内更早各小时的平均热度
+	Score    float64 `json:"score"`    // 趋势得分
+	Hourly   []int64 `json:"hourly"`   // 每小时热度，按时间正序排列，最后一项为当前小时
+}
+
+// TrendingHashtagListResponse 热门话题列表的响应结构
+type TrendingHashtagListResponse struct {
+	Items []TrendingHashtagData `json:"items"` // 热门话题列表
+}
+
+// NewTrendingHashtagListResponse 创建热门话题列表的响应
+//
+// 参数：
+//   - hashtags：热门话题列表
+//
+// 返回值：
+//   - 热门话题列表的响应
+func NewTrendingHashtagListResponse(hashtags []types.TrendingHashtag) TrendingHashtagListResponse {
+	items := make([]TrendingHashtagData, 0, len(hashtags))
+	for _, hashtag := range hashtags {
+		items = append(items, TrendingHashtagData{
+			Hashtag:  hashtag.Hashtag,
+			Velocity: hashtag.Velocity,
+			Baseline: hashtag.Baseline,
+			Score:    hashtag.Score,
+			Hourly:   hashtag.Hourly,
+		})
+	}
+	return TrendingHashtagListResponse{Items: items}
+}
@@ -14,13 +14,14 @@ import (
 
 // UserProfileData 用户资料响应结构。
 type UserProfileData struct {
-	UID      uint64  `json:"uid"`        // 用户 ID
-	Username string  `json:"username"`   // 用户名
-	Nickname string  `json:"nickname"`   // 昵称
-	Avatar   string  `json:"avatar_url"` // 头像 URL
-	Birth    *int64  `json:"birth"`      // 生日
-	Gender   *string `json:"gender"`     // 性别
-	Level    uint64  `json:"level"`      // 等级
+	UID       uint64  `json:"uid"`        // 用户 ID
+	Username  string  `json:"username"`   // 用户名
+	Nickname  string  `json:"nickname"`   // 昵称
+	Avatar    string  `json:"avatar_url"` // 头像 URL
+	Birth     *int64  `json:"birth"`      // 生日
+	Gender    *string `json:"gender"`     // 性别
+	Level     uint64  `json:"level"`      // 等级
+	IsPrivate bool    `json:"is_private"` // 是否为私密账号
 }
 
 // NewUserProfileData 创建一个新的用户资料响应。
@@ -64,11 +65,58 @@ func NewUserProfileData(model *models.UserInfo) *UserProfileData {
 		profile.Gender = nil
 	}
 	profile.Level = model.Level
+	profile.IsPrivate = model.IsPrivate
 
 	// 返回用户资料响应
 	return profile
 }
 
+// UserBriefData 用户简要资料响应结构，用于在列表项中附带作者资料
+type UserBriefData struct {
+	UID      uint64 `json:"uid"`        // 用户 ID
+	Username string `json:"username"`   // 用户名
+	Nickname string `json:"nickname"`   // 昵称
+	Avatar   string `json:"avatar_url"` // 头像 URL
+	Level    uint64 `json:"level"`      // 等级
+}
+
+// newUserBriefData 从用户资料映射中创建用户简要资料响应
+//
+// 参数：
+//   - users：用户ID到用户资料的映射
+//   - uid：用户ID
+//
+// 返回值：
+//   - *UserBriefData：用户简要资料响应，用户不存在时为 nil
+func newUserBriefData(users map[uint64]models.UserInfo, uid uint64) *UserBriefData {
+	user, ok := users[uid]
+	if !ok {
+		return nil
+	}
+	return toUserBriefData(&user)
+}
+
+// toUserBriefData 将用户资料模型转换为用户简要资料响应
+//
+// 参数：
+//   - user：用户资料模型，可以为 nil
+//
+// 返回值：
+//   - *UserBriefData：用户简要资料响应，用户为 nil 时为 nil
+func toUserBriefData(user *models.UserInfo) *UserBriefData {
+	if user == nil {
+		return nil
+	}
+	profile := NewUserProfileData(user)
+	return &UserBriefData{
+		UID:      profile.UID,
+		Username: profile.Username,
+		Nickname: profile.Nickname,
+		Avatar:   profile.Avatar,
+		Level:    profile.Level,
+	}
+}
+
 // UserToken
 type UserToken struct {
 	Token string `json:"token"`